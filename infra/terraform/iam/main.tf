@@ -110,6 +110,15 @@ resource "aws_iam_role_policy" "expense_service_sns" {
           "sns:Publish"
         ]
         Resource = var.expense_events_topic_arn
+      },
+      {
+        Effect = "Allow"
+        Action = [
+          "sqs:ReceiveMessage",
+          "sqs:DeleteMessage",
+          "sqs:GetQueueAttributes"
+        ]
+        Resource = var.expense_user_events_queue_arn
       }
     ]
   })
@@ -174,6 +183,15 @@ resource "aws_iam_role_policy" "receipt_service_s3_sns" {
           "sns:Publish"
         ]
         Resource = var.receipt_events_topic_arn
+      },
+      {
+        Effect = "Allow"
+        Action = [
+          "sqs:ReceiveMessage",
+          "sqs:DeleteMessage",
+          "sqs:GetQueueAttributes"
+        ]
+        Resource = var.receipt_user_events_queue_arn
       }
     ]
   })
@@ -66,6 +66,16 @@ variable "receipt_events_queue_arn" {
   type        = string
 }
 
+variable "expense_user_events_queue_arn" {
+  description = "ARN of the expense-service account deletion SQS queue"
+  type        = string
+}
+
+variable "receipt_user_events_queue_arn" {
+  description = "ARN of the receipt-service account deletion SQS queue"
+  type        = string
+}
+
 variable "enable_external_secrets" {
   description = "Enable External Secrets Operator IAM role"
   type        = bool
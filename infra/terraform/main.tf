@@ -201,6 +201,8 @@ module "iam" {
   auth_events_queue_arn         = "arn:aws:sqs:${var.aws_region}:${data.aws_caller_identity.current.account_id}:${var.project_name}-auth-events-queue"
   expense_events_queue_arn       = "arn:aws:sqs:${var.aws_region}:${data.aws_caller_identity.current.account_id}:${var.project_name}-expense-events-queue"
   receipt_events_queue_arn       = "arn:aws:sqs:${var.aws_region}:${data.aws_caller_identity.current.account_id}:${var.project_name}-receipt-events-queue"
+  expense_user_events_queue_arn  = module.messaging.expense_user_events_queue_arn
+  receipt_user_events_queue_arn  = module.messaging.receipt_user_events_queue_arn
   enable_external_secrets        = var.enable_external_secrets
   secrets_manager_arns           = [
     module.rds.auth_db_secret_arn,
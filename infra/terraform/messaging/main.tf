@@ -105,6 +105,33 @@ resource "aws_sqs_queue" "receipt_events" {
   }
 }
 
+# Account deletion queues
+# expense-service and receipt-service erase a deleted user's data when
+# auth-service publishes user.deleted (see the filtered subscriptions below)
+resource "aws_sqs_queue" "expense_user_events" {
+  name                      = "${var.project_name}-expense-user-events-queue"
+  message_retention_seconds = 1209600 # 14 days - erasure must not be lost
+  receive_wait_time_seconds = 20
+  
+  tags = {
+    Name        = "${var.project_name}-expense-user-events-queue"
+    Environment = var.environment
+    Service     = "expense-service"
+  }
+}
+
+resource "aws_sqs_queue" "receipt_user_events" {
+  name                      = "${var.project_name}-receipt-user-events-queue"
+  message_retention_seconds = 1209600
+  receive_wait_time_seconds = 20
+  
+  tags = {
+    Name        = "${var.project_name}-receipt-user-events-queue"
+    Environment = var.environment
+    Service     = "receipt-service"
+  }
+}
+
 # ============================================================================
 # SNS to SQS Subscriptions
 # ============================================================================
@@ -128,6 +155,23 @@ resource "aws_sns_topic_subscription" "receipt_events_to_queue" {
   endpoint  = aws_sqs_queue.receipt_events.arn
 }
 
+# Only user.deleted events reach the account deletion queues
+resource "aws_sns_topic_subscription" "auth_events_to_expense_user_queue" {
+  topic_arn           = aws_sns_topic.auth_events.arn
+  protocol            = "sqs"
+  endpoint            = aws_sqs_queue.expense_user_events.arn
+  filter_policy_scope = "MessageBody"
+  filter_policy       = jsonencode({ event_type = ["user.deleted"] })
+}
+
+resource "aws_sns_topic_subscription" "auth_events_to_receipt_user_queue" {
+  topic_arn           = aws_sns_topic.auth_events.arn
+  protocol            = "sqs"
+  endpoint            = aws_sqs_queue.receipt_user_events.arn
+  filter_policy_scope = "MessageBody"
+  filter_policy       = jsonencode({ event_type = ["user.deleted"] })
+}
+
 # ============================================================================
 # SQS Queue Policies
 # ============================================================================
@@ -202,3 +246,49 @@ resource "aws_sqs_queue_policy" "receipt_events" {
   })
 }
 
+
+resource "aws_sqs_queue_policy" "expense_user_events" {
+  queue_url = aws_sqs_queue.expense_user_events.id
+  
+  policy = jsonencode({
+    Version = "2012-10-17"
+    Statement = [
+      {
+        Effect = "Allow"
+        Principal = {
+          Service = "sns.amazonaws.com"
+        }
+        Action   = "sqs:SendMessage"
+        Resource = aws_sqs_queue.expense_user_events.arn
+        Condition = {
+          ArnEquals = {
+            "aws:SourceArn" = aws_sns_topic.auth_events.arn
+          }
+        }
+      }
+    ]
+  })
+}
+
+resource "aws_sqs_queue_policy" "receipt_user_events" {
+  queue_url = aws_sqs_queue.receipt_user_events.id
+  
+  policy = jsonencode({
+    Version = "2012-10-17"
+    Statement = [
+      {
+        Effect = "Allow"
+        Principal = {
+          Service = "sns.amazonaws.com"
+        }
+        Action   = "sqs:SendMessage"
+        Resource = aws_sqs_queue.receipt_user_events.arn
+        Condition = {
+          ArnEquals = {
+            "aws:SourceArn" = aws_sns_topic.auth_events.arn
+          }
+        }
+      }
+    ]
+  })
+}
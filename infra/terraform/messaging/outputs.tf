@@ -35,3 +35,23 @@ output "receipt_events_queue_url" {
   value       = aws_sqs_queue.receipt_events.url
 }
 
+
+output "expense_user_events_queue_url" {
+  description = "URL of the expense-service account deletion SQS queue"
+  value       = aws_sqs_queue.expense_user_events.url
+}
+
+output "receipt_user_events_queue_url" {
+  description = "URL of the receipt-service account deletion SQS queue"
+  value       = aws_sqs_queue.receipt_user_events.url
+}
+
+output "expense_user_events_queue_arn" {
+  description = "ARN of the expense-service account deletion SQS queue"
+  value       = aws_sqs_queue.expense_user_events.arn
+}
+
+output "receipt_user_events_queue_arn" {
+  description = "ARN of the receipt-service account deletion SQS queue"
+  value       = aws_sqs_queue.receipt_user_events.arn
+}
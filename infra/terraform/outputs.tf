@@ -84,6 +84,8 @@ output "sqs_queue_urls" {
     auth_events    = module.messaging.auth_events_queue_url
     expense_events = module.messaging.expense_events_queue_url
     receipt_events = module.messaging.receipt_events_queue_url
+    expense_user_events = module.messaging.expense_user_events_queue_url
+    receipt_user_events = module.messaging.receipt_user_events_queue_url
   }
 }
 
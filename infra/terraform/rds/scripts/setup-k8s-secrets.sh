@@ -72,18 +72,23 @@ kubectl create secret generic auth-db-credentials \
 echo ""
 echo "✅ Database secret created successfully!"
 
-# Generate and create JWT secret
+# Generate and create JWT signing key (RS256)
+# The file name (without .pem) is the key ID used in the "kid" token header
 echo ""
-echo "Generating JWT secret..."
-JWT_SECRET=$(openssl rand -base64 32)
+echo "Generating JWT signing key..."
+JWT_KEY_ID=$(date +%Y-%m)
+JWT_KEY_FILE=$(mktemp -d)/${JWT_KEY_ID}.pem
+openssl genpkey -algorithm RSA -pkeyopt rsa_keygen_bits:2048 -out ${JWT_KEY_FILE}
 
-kubectl delete secret auth-jwt-secret -n ${NAMESPACE} 2>/dev/null || true
+kubectl delete secret auth-jwt-keys -n ${NAMESPACE} 2>/dev/null || true
 
-kubectl create secret generic auth-jwt-secret \
-    --from-literal=JWT_SECRET=${JWT_SECRET} \
+kubectl create secret generic auth-jwt-keys \
+    --from-file=${JWT_KEY_FILE} \
     -n ${NAMESPACE}
 
-echo "✅ JWT secret created successfully!"
+rm -f ${JWT_KEY_FILE}
+
+echo "✅ JWT signing key created successfully! (key ID: ${JWT_KEY_ID})"
 
 # Create ConfigMap for non-sensitive config
 echo ""
@@ -93,7 +98,9 @@ kubectl delete configmap auth-service-config -n ${NAMESPACE} 2>/dev/null || true
 
 kubectl create configmap auth-service-config \
     --from-literal=SERVER_PORT=8080 \
-    --from-literal=JWT_EXPIRATION_HOURS=24 \
+    --from-literal=JWT_EXPIRATION_MINUTES=15 \
+    --from-literal=JWT_KEYS_DIR=/etc/auth-service/jwt-keys \
+    --from-literal=JWT_ACTIVE_KEY_ID=${JWT_KEY_ID} \
     -n ${NAMESPACE}
 
 echo "✅ ConfigMap created successfully!"
@@ -103,7 +110,7 @@ echo "Summary:"
 echo "  Namespace: ${NAMESPACE}"
 echo "  Secrets created:"
 echo "    - auth-db-credentials"
-echo "    - auth-jwt-secret"
+echo "    - auth-jwt-keys (mount at /etc/auth-service/jwt-keys)"
 echo "  ConfigMap created:"
 echo "    - auth-service-config"
 echo ""
@@ -7,12 +7,15 @@ import (
 	"expense-tracker/auth-service/internal/config"
 	"expense-tracker/auth-service/internal/handler"
 	"expense-tracker/auth-service/internal/middleware"
+	"expense-tracker/auth-service/internal/model"
 	"expense-tracker/auth-service/internal/repository"
 	"expense-tracker/auth-service/internal/service"
+	"expense-tracker/shared/clientip"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
@@ -43,14 +46,78 @@ func main() {
 	}
 	log.Println("Database connection established!")
 
-	// Initialize repository (data access layer)
+	// Initialize repositories (data access layer)
 	userRepo := repository.NewPostgresUserRepository(dbPool)
+	refreshTokenRepo := repository.NewPostgresRefreshTokenRepository(dbPool)
+	sessionRepo := repository.NewPostgresSessionRepository(dbPool)
+	adminAuditRepo := repository.NewPostgresAdminAuditRepository(dbPool)
+	loginThrottleRepo := repository.NewPostgresLoginThrottleRepository(dbPool)
+	apiKeyRepo := repository.NewPostgresAPIKeyRepository(dbPool)
+	oidcRepo := repository.NewPostgresOIDCRepository(dbPool)
+	authAuditRepo := repository.NewPostgresAuthAuditRepository(dbPool)
+
+	// Load JWT signing keys
+	// Without JWT_KEYS_DIR we generate a key in memory - fine for local development,
+	// but tokens stop validating on restart and replicas can't share them
+	var signingKeys *service.KeySet
+	if cfg.JWTKeysDir != "" {
+		signingKeys, err = service.LoadKeySet(cfg.JWTKeysDir, cfg.JWTActiveKeyID)
+		if err != nil {
+			log.Fatalf("Failed to load JWT signing keys: %v", err)
+		}
+	} else {
+		log.Println("WARNING: JWT_KEYS_DIR not set - using an ephemeral signing key (development only)")
+		signingKeys, err = service.NewEphemeralKeySet()
+		if err != nil {
+			log.Fatalf("Failed to generate JWT signing key: %v", err)
+		}
+	}
+	log.Printf("Signing JWTs with key %s (%s)", signingKeys.Active().ID, signingKeys.Active().Method.Alg())
 
 	// Initialize JWT service
-	jwtService := service.NewJWTService(cfg.JWTSecret, cfg.JWTExpiration)
+	jwtService := service.NewJWTService(signingKeys, cfg.JWTExpiration)
+
+	// Initialize login throttling (brute force protection)
+	loginThrottler := service.NewLoginThrottler(loginThrottleRepo, service.ThrottleSettings{
+		MaxAttemptsPerAccount: cfg.LoginMaxAttemptsPerAccount,
+		MaxAttemptsPerIP:      cfg.LoginMaxAttemptsPerIP,
+		LockoutDuration:       cfg.LoginLockoutDuration,
+		MaxLockoutDuration:    cfg.LoginMaxLockoutDuration,
+		AttemptWindow:         cfg.LoginAttemptWindow,
+	})
 
 	// Initialize auth service (business logic layer)
-	authService := service.NewAuthService(userRepo, jwtService)
+	authService := service.NewAuthService(userRepo, refreshTokenRepo, sessionRepo, apiKeyRepo, oidcRepo, authAuditRepo, jwtService, loginThrottler, service.AuthSettings{
+		RefreshTokenExpiration:  cfg.RefreshTokenExpiration,
+		PasswordResetExpiration: cfg.PasswordResetExpiration,
+		PasswordResetURL:        cfg.PasswordResetURL,
+
+		RequireEmailVerification:    cfg.RequireEmailVerification,
+		EmailVerificationExpiration: cfg.EmailVerificationExpiration,
+		EmailVerificationURL:        cfg.EmailVerificationURL,
+
+		MFAIssuer:              cfg.MFAIssuer,
+		MFAChallengeExpiration: cfg.MFAChallengeExpiration,
+
+		AccountDeletionGracePeriod: cfg.AccountDeletionGracePeriod,
+
+		AuditSuccessfulValidations: cfg.AuditSuccessfulValidations,
+	})
+
+	// Enable OpenID Connect login (optional - "Sign in with ..." an external provider)
+	if cfg.OIDCIssuerURL != "" {
+		authService.SetOIDCClient(service.NewOIDCClient(service.OIDCSettings{
+			IssuerURL:    cfg.OIDCIssuerURL,
+			ClientID:     cfg.OIDCClientID,
+			ClientSecret: cfg.OIDCClientSecret,
+			RedirectURL:  cfg.OIDCRedirectURL,
+			Scopes:       cfg.OIDCScopes,
+		}))
+		log.Printf("OpenID Connect login enabled (issuer %s)", cfg.OIDCIssuerURL)
+	}
+
+	// Initialize account purger (erases deleted accounts after the grace period)
+	accountPurger := service.NewAccountPurger(userRepo, cfg.AccountDeletionGracePeriod, cfg.AccountPurgeInterval)
 
 	// Initialize event publisher (optional - for notifications)
 	if cfg.AuthEventsTopicARN != "" && cfg.AWSAccessKeyID != "" && cfg.AWSSecretKey != "" {
@@ -65,31 +132,54 @@ func main() {
 			log.Printf("Warning: Failed to initialize event publisher: %v (events will not be published)", err)
 		} else {
 			authService.SetEventPublisher(eventPublisher)
+			accountPurger.SetEventPublisher(eventPublisher)
 			log.Println("Event publisher initialized!")
 		}
 	}
 
+	// Start the account purger in the background (stopped on shutdown)
+	bgCtx, bgCancel := context.WithCancel(context.Background())
+	defer bgCancel()
+	go accountPurger.Run(bgCtx)
+
 	// Initialize handlers (HTTP layer)
 	authHandler := handler.NewAuthHandler(authService)
+	adminHandler := handler.NewAdminHandler(authService)
+	apiKeyHandler := handler.NewAPIKeyHandler(authService)
+	sessionHandler := handler.NewSessionHandler(authService)
+	oidcHandler := handler.NewOIDCHandler(authService, strings.HasPrefix(cfg.OIDCRedirectURL, "https://"))
 
 	// Initialize middleware
-	authMiddleware := middleware.NewAuthMiddleware(jwtService) // For protected routes
-	loggingMiddleware := middleware.NewLoggingMiddleware()     // For request/response logging
+	authMiddleware := middleware.NewAuthMiddleware(authService)      // For protected routes
+	auditMiddleware := middleware.NewAuditMiddleware(adminAuditRepo) // For admin endpoints
+	loggingMiddleware := middleware.NewLoggingMiddleware()           // For request/response logging
+
+	// Works out the client IP (X-Forwarded-For only from TRUSTED_PROXIES)
+	ipResolver, err := clientip.NewResolver(cfg.TrustedProxies)
+	if err != nil {
+		log.Fatalf("Invalid TRUSTED_PROXIES: %v", err)
+	}
 
 	// Setup HTTP router
 	// gorilla/mux is a powerful HTTP router for Go
 	router := mux.NewRouter()
 
+	// Resolve the client IP before anything uses it
+	router.Use(ipResolver.Middleware)
+
 	// Add logging middleware FIRST (so it logs all requests)
 	// Middleware is executed in the order it's added
 	router.Use(loggingMiddleware.LogRequest)
 
+	// Make the client IP and user agent available to the auth audit log
+	router.Use(middleware.RequestInfo)
+
 	// Add CORS middleware (allows frontend to call the API)
 	// In production, restrict this to your frontend domain!
 	router.Use(func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			w.Header().Set("Access-Control-Allow-Origin", "*")
-			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
+			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PATCH, DELETE, OPTIONS")
 			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
 			if r.Method == "OPTIONS" {
 				w.WriteHeader(http.StatusOK)
@@ -99,18 +189,44 @@ func main() {
 		})
 	})
 
-	// Store authMiddleware for later use (we'll use it for protected routes)
-	_ = authMiddleware
-
 	// Define routes
 	// Public routes (no authentication required)
 	router.HandleFunc("/health", authHandler.Health).Methods("GET")
+	router.HandleFunc("/.well-known/jwks.json", authHandler.JWKS).Methods("GET") // Public keys for verifying tokens
 	router.HandleFunc("/auth/register", authHandler.Register).Methods("POST")
 	router.HandleFunc("/auth/login", authHandler.Login).Methods("POST")
+	router.HandleFunc("/auth/refresh", authHandler.Refresh).Methods("POST")
+	router.HandleFunc("/auth/logout", authHandler.Logout).Methods("POST")
+	router.HandleFunc("/auth/password/forgot", authHandler.ForgotPassword).Methods("POST")
+	router.HandleFunc("/auth/password/reset", authHandler.ResetPassword).Methods("POST")
+	router.HandleFunc("/auth/verify-email", authHandler.VerifyEmail).Methods("GET")
+	router.HandleFunc("/auth/verify-email/resend", authHandler.ResendVerification).Methods("POST")
 	router.HandleFunc("/auth/validate", authHandler.Validate).Methods("GET")
+	router.HandleFunc("/auth/mfa/verify", authHandler.MFAVerify).Methods("POST") // Second step of an MFA login
+	if authService.OIDCEnabled() {
+		router.HandleFunc("/auth/oidc/login", oidcHandler.Login).Methods("GET")       // Redirects to the identity provider
+		router.HandleFunc("/auth/oidc/callback", oidcHandler.Callback).Methods("GET") // The provider redirects back here
+	}
 
 	// Protected routes (require authentication)
-	// Example: router.HandleFunc("/auth/profile", authMiddleware.RequireAuth(getProfile)).Methods("GET")
+	router.HandleFunc("/auth/profile", authMiddleware.RequireAuth(authHandler.GetProfile)).Methods("GET")
+	router.HandleFunc("/auth/profile", authMiddleware.RequireAuth(authHandler.UpdateProfile)).Methods("PATCH")
+	router.HandleFunc("/auth/password/change", authMiddleware.RequireAuth(authHandler.ChangePassword)).Methods("POST")
+	router.HandleFunc("/auth/email/change", authMiddleware.RequireAuth(authHandler.ChangeEmail)).Methods("POST")
+	router.HandleFunc("/auth/account", authMiddleware.RequireAuth(authHandler.DeleteAccount)).Methods("DELETE")
+	router.HandleFunc("/auth/audit", authMiddleware.RequireAuth(authHandler.AuditLog)).Methods("GET")
+	router.HandleFunc("/auth/sessions", authMiddleware.RequireAuth(sessionHandler.ListSessions)).Methods("GET")
+	router.HandleFunc("/auth/sessions/{id}", authMiddleware.RequireAuth(sessionHandler.RevokeSession)).Methods("DELETE")
+	router.HandleFunc("/auth/api-keys", authMiddleware.RequireAuth(apiKeyHandler.CreateAPIKey)).Methods("POST")
+	router.HandleFunc("/auth/api-keys", authMiddleware.RequireAuth(apiKeyHandler.ListAPIKeys)).Methods("GET")
+	router.HandleFunc("/auth/api-keys/{id}", authMiddleware.RequireAuth(apiKeyHandler.RevokeAPIKey)).Methods("DELETE")
+	router.HandleFunc("/auth/mfa/enroll", authMiddleware.RequireAuth(authHandler.MFAEnroll)).Methods("POST")
+	router.HandleFunc("/auth/mfa/confirm", authMiddleware.RequireAuth(authHandler.MFAConfirm)).Methods("POST")
+
+	// Admin routes (require the admin role, every request is audited)
+	router.HandleFunc("/auth/admin/users", authMiddleware.RequireRole(model.RoleAdmin, auditMiddleware.Audit("users.list", adminHandler.ListUsers))).Methods("GET")
+	router.HandleFunc("/auth/admin/users/{id}", authMiddleware.RequireRole(model.RoleAdmin, auditMiddleware.Audit("users.view", adminHandler.GetUser))).Methods("GET")
+	router.HandleFunc("/auth/admin/audit", authMiddleware.RequireRole(model.RoleAdmin, auditMiddleware.Audit("audit.list", adminHandler.ListAuditEvents))).Methods("GET")
 
 	// Create HTTP server
 	// http.Server is Go's built-in HTTP server
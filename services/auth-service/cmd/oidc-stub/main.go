@@ -0,0 +1,249 @@
+// oidc-stub is a minimal OpenID Connect provider for trying out
+// "Sign in with OpenID Connect" locally. It signs in a fixed user
+// without asking anything - never run it anywhere else.
+//
+// Usage:
+//
+//	go run ./cmd/oidc-stub -email alice@example.com
+//
+// and start auth-service with OIDC_ISSUER_URL=http://localhost:9000,
+// OIDC_CLIENT_ID=expense-tracker and OIDC_CLIENT_SECRET=stub-secret.
+package main
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"flag"
+	"log"
+	"math/big"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// stubKeyID is the "kid" of the stub's only signing key
+const stubKeyID = "stub-key"
+
+// authorization is an issued code waiting to be exchanged
+type authorization struct {
+	redirectURI   string
+	nonce         string
+	codeChallenge string
+	expiresAt     time.Time
+}
+
+// stubProvider holds the provider's key and outstanding codes
+type stubProvider struct {
+	issuer       string
+	clientID     string
+	clientSecret string
+	email        string
+	name         string
+	key          *rsa.PrivateKey
+
+	mu    sync.Mutex
+	codes map[string]*authorization
+}
+
+func main() {
+	addr := flag.String("addr", ":9000", "address to listen on")
+	issuer := flag.String("issuer", "http://localhost:9000", "issuer URL (must match how auth-service reaches the stub)")
+	clientID := flag.String("client-id", "expense-tracker", "client ID auth-service uses")
+	clientSecret := flag.String("client-secret", "stub-secret", "client secret auth-service uses (empty = public client)")
+	email := flag.String("email", "stub.user@example.com", "email of the signed-in user")
+	name := flag.String("name", "Stub User", "name of the signed-in user")
+	flag.Parse()
+
+	// A fresh key on every start - auth-service refetches unknown key IDs
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		log.Fatalf("Failed to generate signing key: %v", err)
+	}
+
+	p := &stubProvider{
+		issuer:       *issuer,
+		clientID:     *clientID,
+		clientSecret: *clientSecret,
+		email:        *email,
+		name:         *name,
+		key:          key,
+		codes:        make(map[string]*authorization),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", p.discovery)
+	mux.HandleFunc("/authorize", p.authorize)
+	mux.HandleFunc("/token", p.token)
+	mux.HandleFunc("/jwks", p.jwks)
+
+	log.Printf("OIDC stub provider for %s listening on %s (issuer %s)", p.email, *addr, p.issuer)
+	log.Fatal(http.ListenAndServe(*addr, mux))
+}
+
+// discovery serves the discovery document
+func (p *stubProvider) discovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"issuer":                                p.issuer,
+		"authorization_endpoint":                p.issuer + "/authorize",
+		"token_endpoint":                        p.issuer + "/token",
+		"jwks_uri":                              p.issuer + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"code_challenge_methods_supported":      []string{"S256"},
+	})
+}
+
+// authorize signs the user in immediately and redirects back with a code
+func (p *stubProvider) authorize(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	redirectURI := q.Get("redirect_uri")
+	if q.Get("client_id") != p.clientID || redirectURI == "" {
+		http.Error(w, "unknown client or missing redirect_uri", http.StatusBadRequest)
+		return
+	}
+	if q.Get("response_type") != "code" || q.Get("code_challenge_method") != "S256" || q.Get("code_challenge") == "" {
+		http.Error(w, "only the authorization code flow with S256 PKCE is supported", http.StatusBadRequest)
+		return
+	}
+
+	code := randomString()
+	p.mu.Lock()
+	p.codes[code] = &authorization{
+		redirectURI:   redirectURI,
+		nonce:         q.Get("nonce"),
+		codeChallenge: q.Get("code_challenge"),
+		expiresAt:     time.Now().Add(time.Minute),
+	}
+	p.mu.Unlock()
+
+	target, err := url.Parse(redirectURI)
+	if err != nil {
+		http.Error(w, "invalid redirect_uri", http.StatusBadRequest)
+		return
+	}
+	params := target.Query()
+	params.Set("code", code)
+	params.Set("state", q.Get("state"))
+	target.RawQuery = params.Encode()
+
+	http.Redirect(w, r, target.String(), http.StatusFound)
+}
+
+// token exchanges a code for an ID token
+func (p *stubProvider) token(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		tokenError(w, "invalid_request")
+		return
+	}
+
+	// Client authentication: basic auth with a secret, client_id alone without
+	clientID, clientSecret, hasBasic := r.BasicAuth()
+	if hasBasic {
+		clientID, _ = url.QueryUnescape(clientID)
+		clientSecret, _ = url.QueryUnescape(clientSecret)
+	} else {
+		clientID = r.PostForm.Get("client_id")
+	}
+	if clientID != p.clientID || subtle.ConstantTimeCompare([]byte(clientSecret), []byte(p.clientSecret)) != 1 {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+
+	if r.PostForm.Get("grant_type") != "authorization_code" {
+		tokenError(w, "unsupported_grant_type")
+		return
+	}
+
+	// Codes are single use
+	code := r.PostForm.Get("code")
+	p.mu.Lock()
+	auth := p.codes[code]
+	delete(p.codes, code)
+	p.mu.Unlock()
+
+	if auth == nil || time.Now().After(auth.expiresAt) || auth.redirectURI != r.PostForm.Get("redirect_uri") {
+		tokenError(w, "invalid_grant")
+		return
+	}
+
+	// PKCE: the verifier must hash to the challenge from /authorize
+	sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if base64.RawURLEncoding.EncodeToString(sum[:]) != auth.codeChallenge {
+		tokenError(w, "invalid_grant")
+		return
+	}
+
+	now := time.Now()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":            p.issuer,
+		"sub":            "stub|" + p.email,
+		"aud":            p.clientID,
+		"iat":            now.Unix(),
+		"exp":            now.Add(5 * time.Minute).Unix(),
+		"nonce":          auth.nonce,
+		"email":          p.email,
+		"email_verified": true,
+		"name":           p.name,
+	})
+	token.Header["kid"] = stubKeyID
+
+	idToken, err := token.SignedString(p.key)
+	if err != nil {
+		http.Error(w, "failed to sign ID token", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": randomString(),
+		"token_type":   "Bearer",
+		"expires_in":   300,
+		"id_token":     idToken,
+	})
+}
+
+// jwks serves the public key that verifies ID tokens
+func (p *stubProvider) jwks(w http.ResponseWriter, r *http.Request) {
+	pub := p.key.PublicKey
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": stubKeyID,
+			"use": "sig",
+			"alg": "RS256",
+			"n":   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+		}},
+	})
+}
+
+// tokenError sends an OAuth 2.0 error response
+func tokenError(w http.ResponseWriter, code string) {
+	writeJSON(w, http.StatusBadRequest, map[string]string{"error": code})
+}
+
+func writeJSON(w http.ResponseWriter, statusCode int, payload interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(payload)
+}
+
+// randomString returns a random URL-safe string for codes
+func randomString() string {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		log.Fatalf("Failed to generate random value: %v", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
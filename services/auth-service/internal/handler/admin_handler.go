@@ -0,0 +1,147 @@
+package handler
+
+import (
+	"errors"
+	"expense-tracker/auth-service/internal/model"
+	"expense-tracker/auth-service/internal/service"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// AdminHandler handles HTTP requests for admin-only endpoints
+// Routes must be wrapped in RequireRole(model.RoleAdmin, ...) and Audit(...)
+type AdminHandler struct {
+	authService *service.AuthService
+}
+
+// NewAdminHandler creates a new admin handler
+func NewAdminHandler(authService *service.AuthService) *AdminHandler {
+	return &AdminHandler{
+		authService: authService,
+	}
+}
+
+// ListUsers handles listing users
+// GET /auth/admin/users?email=example.com&page=1&limit=20
+func (h *AdminHandler) ListUsers(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Parse query parameters
+	filters := &model.ListUsersRequest{
+		Email: r.URL.Query().Get("email"),
+	}
+	if page, err := strconv.Atoi(r.URL.Query().Get("page")); err == nil && page > 0 {
+		filters.Page = page
+	}
+	if limit, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && limit > 0 {
+		filters.Limit = limit
+	}
+
+	resp, err := h.authService.ListUsers(r.Context(), filters)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to list users")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+// GetUser handles viewing a single user
+// GET /auth/admin/users/{id}
+func (h *AdminHandler) GetUser(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID := mux.Vars(r)["id"]
+	if userID == "" {
+		respondWithError(w, http.StatusBadRequest, "User ID is required")
+		return
+	}
+	if _, err := uuid.Parse(userID); err != nil {
+		respondWithError(w, http.StatusBadRequest, "User ID must be a valid UUID")
+		return
+	}
+
+	user, err := h.authService.GetUser(r.Context(), userID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			respondWithError(w, http.StatusNotFound, "User not found")
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Failed to get user")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, user)
+}
+
+// ListAuditEvents handles querying the authentication audit log
+// GET /auth/admin/audit?user_id=...&email=...&event_type=login&outcome=failure&client_ip=...&from=...&to=...&page=1&limit=50
+// from and to are RFC 3339 timestamps
+func (h *AdminHandler) ListAuditEvents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	filters, err := parseAuditEventFilters(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	filters.UserID = r.URL.Query().Get("user_id")
+	filters.Email = r.URL.Query().Get("email")
+	filters.ClientIP = r.URL.Query().Get("client_ip")
+
+	resp, err := h.authService.ListAuditEvents(r.Context(), filters)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to list audit events")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+// parseAuditEventFilters reads the audit log filters every caller may use
+// (event_type, outcome, from, to, page, limit)
+func parseAuditEventFilters(r *http.Request) (*model.ListAuthAuditEventsRequest, error) {
+	query := r.URL.Query()
+	filters := &model.ListAuthAuditEventsRequest{
+		EventType: query.Get("event_type"),
+		Outcome:   query.Get("outcome"),
+	}
+
+	if from := query.Get("from"); from != "" {
+		t, err := time.Parse(time.RFC3339, from)
+		if err != nil {
+			return nil, errors.New("from must be an RFC 3339 timestamp")
+		}
+		filters.From = &t
+	}
+	if to := query.Get("to"); to != "" {
+		t, err := time.Parse(time.RFC3339, to)
+		if err != nil {
+			return nil, errors.New("to must be an RFC 3339 timestamp")
+		}
+		filters.To = &t
+	}
+
+	if page, err := strconv.Atoi(query.Get("page")); err == nil && page > 0 {
+		filters.Page = page
+	}
+	if limit, err := strconv.Atoi(query.Get("limit")); err == nil && limit > 0 {
+		filters.Limit = limit
+	}
+
+	return filters, nil
+}
@@ -0,0 +1,113 @@
+package handler
+
+import (
+	"encoding/json"
+	"expense-tracker/auth-service/internal/middleware"
+	"expense-tracker/auth-service/internal/model"
+	"expense-tracker/auth-service/internal/service"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// APIKeyHandler handles HTTP requests for managing personal API keys
+// Routes must be wrapped in RequireAuth (which refuses API keys themselves)
+type APIKeyHandler struct {
+	authService *service.AuthService
+}
+
+// NewAPIKeyHandler creates a new API key handler
+func NewAPIKeyHandler(authService *service.AuthService) *APIKeyHandler {
+	return &APIKeyHandler{
+		authService: authService,
+	}
+}
+
+// CreateAPIKey handles POST /auth/api-keys
+// Request body: { "name": "...", "scopes": ["expenses:write"], "expires_in_days": 90 }
+// The response contains the key - it is never shown again
+func (h *APIKeyHandler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID := middleware.GetUserID(r.Context())
+	if userID == "" {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req model.CreateAPIKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	resp, err := h.authService.CreateAPIKey(r.Context(), userID, &req)
+	if err != nil {
+		if strings.Contains(err.Error(), "name") ||
+			strings.Contains(err.Error(), "scope") ||
+			strings.Contains(err.Error(), "expires_in_days") ||
+			strings.Contains(err.Error(), "too many") {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Failed to create API key")
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, resp)
+}
+
+// ListAPIKeys handles GET /auth/api-keys
+func (h *APIKeyHandler) ListAPIKeys(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID := middleware.GetUserID(r.Context())
+	if userID == "" {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	resp, err := h.authService.ListAPIKeys(r.Context(), userID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to list API keys")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+// RevokeAPIKey handles DELETE /auth/api-keys/{id}
+func (h *APIKeyHandler) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID := middleware.GetUserID(r.Context())
+	if userID == "" {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	keyID := mux.Vars(r)["id"]
+
+	if err := h.authService.RevokeAPIKey(r.Context(), userID, keyID); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			respondWithError(w, http.StatusNotFound, "API key not found")
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Failed to revoke API key")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]string{
+		"message": "API key revoked",
+	})
+}
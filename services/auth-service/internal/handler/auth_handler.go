@@ -2,10 +2,14 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
+	"expense-tracker/auth-service/internal/middleware"
 	"expense-tracker/auth-service/internal/model"
 	"expense-tracker/auth-service/internal/service"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 )
 
 // AuthHandler handles HTTP requests for authentication
@@ -85,8 +89,19 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	resp, err := h.authService.Login(r.Context(), &req)
+	resp, err := h.authService.Login(r.Context(), &req, middleware.ClientIP(r))
 	if err != nil {
+		// Too many failed attempts for this account or client
+		var throttled *service.LoginThrottledError
+		if errors.As(err, &throttled) {
+			respondTooManyAttempts(w, throttled.RetryAfter)
+			return
+		}
+		// Correct credentials, but the email address must be verified first
+		if strings.Contains(err.Error(), "not verified") {
+			respondWithError(w, http.StatusForbidden, "Email address not verified")
+			return
+		}
 		// Invalid credentials
 		if strings.Contains(err.Error(), "invalid") {
 			respondWithError(w, http.StatusUnauthorized, "Invalid email or password")
@@ -99,6 +114,588 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 	respondWithJSON(w, http.StatusOK, resp)
 }
 
+// Refresh handles exchanging a refresh token for new tokens
+// POST /auth/refresh
+// Request body: { "refresh_token": "..." }
+func (h *AuthHandler) Refresh(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req model.RefreshRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if req.RefreshToken == "" {
+		respondWithError(w, http.StatusBadRequest, "Refresh token is required")
+		return
+	}
+
+	resp, err := h.authService.Refresh(r.Context(), &req)
+	if err != nil {
+		// Unknown, expired, revoked or reused refresh token
+		if strings.Contains(err.Error(), "invalid") || strings.Contains(err.Error(), "reuse") {
+			respondWithError(w, http.StatusUnauthorized, "Invalid or expired refresh token")
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Failed to refresh token")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+// Logout handles revoking the current session
+// POST /auth/logout
+// Request body: { "refresh_token": "..." }
+func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req model.LogoutRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if req.RefreshToken == "" {
+		respondWithError(w, http.StatusBadRequest, "Refresh token is required")
+		return
+	}
+
+	err := h.authService.Logout(r.Context(), &req)
+	if err != nil {
+		if strings.Contains(err.Error(), "invalid") {
+			respondWithError(w, http.StatusUnauthorized, "Invalid refresh token")
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Failed to logout")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]string{
+		"message": "Logged out successfully",
+	})
+}
+
+// ForgotPassword handles starting a password reset
+// POST /auth/password/forgot
+// Request body: { "email": "..." }
+// Always returns 200 so the endpoint can't be used to discover registered emails
+func (h *AuthHandler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req model.ForgotPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if req.Email == "" {
+		respondWithError(w, http.StatusBadRequest, "Email is required")
+		return
+	}
+
+	if err := h.authService.ForgotPassword(r.Context(), &req); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to process password reset request")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]string{
+		"message": "If an account exists for this email, a password reset link has been sent",
+	})
+}
+
+// ResetPassword handles completing a password reset
+// POST /auth/password/reset
+// Request body: { "token": "...", "new_password": "..." }
+func (h *AuthHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req model.ResetPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if req.Token == "" || req.NewPassword == "" {
+		respondWithError(w, http.StatusBadRequest, "Token and new password are required")
+		return
+	}
+
+	err := h.authService.ResetPassword(r.Context(), &req)
+	if err != nil {
+		if strings.Contains(err.Error(), "invalid") {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Failed to reset password")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]string{
+		"message": "Password has been reset successfully",
+	})
+}
+
+// VerifyEmail handles email verification links
+// GET /auth/verify-email?token=...
+func (h *AuthHandler) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		respondWithError(w, http.StatusBadRequest, "Token is required")
+		return
+	}
+
+	err := h.authService.VerifyEmail(r.Context(), token)
+	if err != nil {
+		if strings.Contains(err.Error(), "invalid") {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		// Email change: another account took the new address in the meantime
+		if strings.Contains(err.Error(), "already in use") {
+			respondWithError(w, http.StatusConflict, "Email address is already in use")
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Failed to verify email")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]string{
+		"message": "Email address verified successfully",
+	})
+}
+
+// ResendVerification handles requests for a new verification email
+// POST /auth/verify-email/resend
+// Request body: { "email": "..." }
+// Always returns 200 so the endpoint can't be used to discover registered emails
+func (h *AuthHandler) ResendVerification(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req model.ResendVerificationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if req.Email == "" {
+		respondWithError(w, http.StatusBadRequest, "Email is required")
+		return
+	}
+
+	if err := h.authService.ResendVerification(r.Context(), &req); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to resend verification email")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]string{
+		"message": "If an unverified account exists for this email, a verification link has been sent",
+	})
+}
+
+// GetProfile handles GET /auth/profile (requires authentication)
+// Returns the signed-in user's account information
+func (h *AuthHandler) GetProfile(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// User ID was attached to the context by the auth middleware
+	userID := middleware.GetUserID(r.Context())
+	if userID == "" {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	resp, err := h.authService.GetProfile(r.Context(), userID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Failed to get profile")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+// UpdateProfile handles PATCH /auth/profile (requires authentication)
+// Request body: { "name": "..." } - fields that are left out are not changed
+func (h *AuthHandler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPatch {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID := middleware.GetUserID(r.Context())
+	if userID == "" {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req model.UpdateProfileRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	resp, err := h.authService.UpdateProfile(r.Context(), userID, &req)
+	if err != nil {
+		if strings.Contains(err.Error(), "cannot be empty") {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if strings.Contains(err.Error(), "not found") {
+			respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Failed to update profile")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+// ChangePassword handles POST /auth/password/change (requires authentication)
+// Request body: { "current_password": "...", "new_password": "..." }
+// Other sessions are signed out; the response carries new tokens for this one
+func (h *AuthHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID := middleware.GetUserID(r.Context())
+	if userID == "" {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req model.ChangePasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if req.CurrentPassword == "" || req.NewPassword == "" {
+		respondWithError(w, http.StatusBadRequest, "Current and new password are required")
+		return
+	}
+
+	resp, err := h.authService.ChangePassword(r.Context(), userID, &req, middleware.ClientIP(r))
+	if err != nil {
+		// Too many wrong passwords for this account or client
+		var throttled *service.LoginThrottledError
+		if errors.As(err, &throttled) {
+			respondTooManyAttempts(w, throttled.RetryAfter)
+			return
+		}
+		if strings.Contains(err.Error(), "invalid current password") {
+			respondWithError(w, http.StatusForbidden, "Current password is incorrect")
+			return
+		}
+		if strings.Contains(err.Error(), "not found") {
+			respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Failed to change password")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+// ChangeEmail handles POST /auth/email/change (requires authentication)
+// Request body: { "new_email": "...", "current_password": "..." }
+// current_password can be left out within a few minutes of signing in
+// Sends a verification link to the new address; the email changes once it is used
+func (h *AuthHandler) ChangeEmail(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID := middleware.GetUserID(r.Context())
+	if userID == "" {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req model.ChangeEmailRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if req.NewEmail == "" {
+		respondWithError(w, http.StatusBadRequest, "New email is required")
+		return
+	}
+
+	err := h.authService.RequestEmailChange(r.Context(), userID, middleware.GetSessionID(r.Context()), &req, middleware.ClientIP(r))
+	if err != nil {
+		// Too many wrong passwords for this account or client
+		var throttled *service.LoginThrottledError
+		if errors.As(err, &throttled) {
+			respondTooManyAttempts(w, throttled.RetryAfter)
+			return
+		}
+		if strings.Contains(err.Error(), "invalid current password") {
+			respondWithError(w, http.StatusForbidden, "Current password is incorrect")
+			return
+		}
+		if strings.Contains(err.Error(), "recent sign-in required") {
+			respondWithError(w, http.StatusForbidden, "Enter your current password or sign in again")
+			return
+		}
+		if strings.Contains(err.Error(), "same as the current") {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if strings.Contains(err.Error(), "already in use") {
+			respondWithError(w, http.StatusConflict, "Email address is already in use")
+			return
+		}
+		if strings.Contains(err.Error(), "not found") {
+			respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Failed to change email")
+		return
+	}
+
+	respondWithJSON(w, http.StatusAccepted, map[string]string{
+		"message": "A verification link was sent to the new email address",
+	})
+}
+
+// DeleteAccount handles DELETE /auth/account (requires authentication)
+// Request body: { "current_password": "..." }
+// current_password can be left out within a few minutes of signing in
+// The account is deleted immediately; its data is erased after the grace period
+func (h *AuthHandler) DeleteAccount(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID := middleware.GetUserID(r.Context())
+	if userID == "" {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req model.DeleteAccountRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	response, err := h.authService.DeleteAccount(r.Context(), userID, middleware.GetSessionID(r.Context()), &req, middleware.ClientIP(r))
+	if err != nil {
+		// Too many wrong passwords for this account or client
+		var throttled *service.LoginThrottledError
+		if errors.As(err, &throttled) {
+			respondTooManyAttempts(w, throttled.RetryAfter)
+			return
+		}
+		if strings.Contains(err.Error(), "invalid current password") {
+			respondWithError(w, http.StatusForbidden, "Current password is incorrect")
+			return
+		}
+		if strings.Contains(err.Error(), "recent sign-in required") {
+			respondWithError(w, http.StatusForbidden, "Enter your current password or sign in again")
+			return
+		}
+		if strings.Contains(err.Error(), "not found") {
+			respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Failed to delete account")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, response)
+}
+
+// AuditLog handles GET /auth/audit (requires authentication)
+// Returns the signed-in user's own security history (logins, password changes, ...)
+// Query parameters: event_type, outcome, from, to (RFC 3339), page, limit
+func (h *AuthHandler) AuditLog(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID := middleware.GetUserID(r.Context())
+	if userID == "" {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	filters, err := parseAuditEventFilters(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	filters.UserID = userID // Only the user's own events
+
+	resp, err := h.authService.ListAuditEvents(r.Context(), filters)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to list audit events")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+// MFAEnroll handles POST /auth/mfa/enroll (requires authentication)
+// Returns a new TOTP secret and otpauth:// URI for the authenticator app
+// MFA is not enforced until the user confirms with a code
+func (h *AuthHandler) MFAEnroll(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// User ID was attached to the context by the auth middleware
+	userID := middleware.GetUserID(r.Context())
+	if userID == "" {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	resp, err := h.authService.EnrollMFA(r.Context(), userID)
+	if err != nil {
+		if strings.Contains(err.Error(), "already enabled") {
+			respondWithError(w, http.StatusConflict, "MFA is already enabled")
+			return
+		}
+		if strings.Contains(err.Error(), "not found") {
+			respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Failed to start MFA enrollment")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+// MFAConfirm handles POST /auth/mfa/confirm (requires authentication)
+// Enables MFA once the user proves the authenticator app works
+// Returns the one-time recovery codes
+func (h *AuthHandler) MFAConfirm(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID := middleware.GetUserID(r.Context())
+	if userID == "" {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req model.MFAConfirmRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if req.Code == "" {
+		respondWithError(w, http.StatusBadRequest, "Code is required")
+		return
+	}
+
+	resp, err := h.authService.ConfirmMFA(r.Context(), userID, &req)
+	if err != nil {
+		if strings.Contains(err.Error(), "already enabled") {
+			respondWithError(w, http.StatusConflict, "MFA is already enabled")
+			return
+		}
+		if strings.Contains(err.Error(), "not started") {
+			respondWithError(w, http.StatusBadRequest, "Start MFA enrollment first")
+			return
+		}
+		if strings.Contains(err.Error(), "invalid") {
+			respondWithError(w, http.StatusBadRequest, "Invalid MFA code")
+			return
+		}
+		if strings.Contains(err.Error(), "not found") {
+			respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Failed to confirm MFA enrollment")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+// MFAVerify handles POST /auth/mfa/verify
+// Second step of a login for MFA accounts: exchanges the MFA token from
+// /auth/login plus a TOTP or recovery code for access and refresh tokens
+func (h *AuthHandler) MFAVerify(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req model.MFAVerifyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if req.MFAToken == "" || req.Code == "" {
+		respondWithError(w, http.StatusBadRequest, "MFA token and code are required")
+		return
+	}
+
+	resp, err := h.authService.VerifyMFA(r.Context(), &req, middleware.ClientIP(r))
+	if err != nil {
+		// Too many failed attempts for this account or client
+		var throttled *service.LoginThrottledError
+		if errors.As(err, &throttled) {
+			respondTooManyAttempts(w, throttled.RetryAfter)
+			return
+		}
+		// Expired challenge or wrong/reused code
+		if strings.Contains(err.Error(), "invalid") {
+			respondWithError(w, http.StatusUnauthorized, "Invalid MFA token or code")
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Failed to verify MFA code")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
 // Validate handles token validation
 // GET /auth/validate
 // Headers: Authorization: Bearer <token>
@@ -140,6 +737,21 @@ func (h *AuthHandler) Validate(w http.ResponseWriter, r *http.Request) {
 	respondWithJSON(w, http.StatusOK, resp)
 }
 
+// JWKS handles GET /.well-known/jwks.json
+// Publishes the public keys so other services can verify tokens locally
+// (no shared secret, no call to /auth/validate)
+func (h *AuthHandler) JWKS(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Let clients cache the key set for a few minutes
+	// New keys are published before they sign, so short caching is safe
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	respondWithJSON(w, http.StatusOK, h.authService.JWKS())
+}
+
 // Health handles health check requests
 // GET /health
 func (h *AuthHandler) Health(w http.ResponseWriter, r *http.Request) {
@@ -177,3 +789,14 @@ func respondWithError(w http.ResponseWriter, statusCode int, message string) {
 		"error": message,
 	})
 }
+
+// respondTooManyAttempts sends 429 Too Many Requests for a locked account or client
+// Retry-After tells the client how many seconds to wait before trying again
+func respondTooManyAttempts(w http.ResponseWriter, retryAfter time.Duration) {
+	seconds := int((retryAfter + time.Second - 1) / time.Second) // Round up
+	if seconds < 1 {
+		seconds = 1
+	}
+	w.Header().Set("Retry-After", strconv.Itoa(seconds))
+	respondWithError(w, http.StatusTooManyRequests, "Too many failed login attempts, try again later")
+}
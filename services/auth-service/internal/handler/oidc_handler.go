@@ -0,0 +1,128 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"expense-tracker/auth-service/internal/service"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// oidcStateCookie holds the state of a login in progress in the browser
+// The callback only accepts a state that matches it (login CSRF protection)
+const oidcStateCookie = "oidc_state"
+
+// OIDCHandler handles the OpenID Connect login redirects
+type OIDCHandler struct {
+	authService *service.AuthService
+	// secureCookie sets the Secure flag on the state cookie (HTTPS deployments)
+	secureCookie bool
+}
+
+// NewOIDCHandler creates a new OpenID Connect handler
+func NewOIDCHandler(authService *service.AuthService, secureCookie bool) *OIDCHandler {
+	return &OIDCHandler{
+		authService:  authService,
+		secureCookie: secureCookie,
+	}
+}
+
+// Login handles GET /auth/oidc/login
+// Redirects the browser to the identity provider's sign-in page
+func (h *OIDCHandler) Login(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	authURL, state, err := h.authService.StartOIDCLogin(r.Context())
+	if err != nil {
+		log.Printf("Failed to start OpenID Connect login: %v", err)
+		if strings.Contains(err.Error(), "identity provider unavailable") {
+			respondWithError(w, http.StatusBadGateway, "Identity provider unavailable")
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Failed to start login")
+		return
+	}
+
+	// SameSite=Lax: the cookie is sent on the provider's top-level redirect back to us
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcStateCookie,
+		Value:    state,
+		Path:     "/auth/oidc",
+		MaxAge:   int((10 * time.Minute).Seconds()),
+		HttpOnly: true,
+		Secure:   h.secureCookie,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	http.Redirect(w, r, authURL, http.StatusFound)
+}
+
+// Callback handles GET /auth/oidc/callback?code=...&state=...
+// The provider redirects here after sign-in; the response is the same as POST /auth/login
+func (h *OIDCHandler) Callback(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// The state cookie is single use
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcStateCookie,
+		Value:    "",
+		Path:     "/auth/oidc",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   h.secureCookie,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	// The user cancelled or the provider refused
+	if providerErr := r.URL.Query().Get("error"); providerErr != "" {
+		respondWithError(w, http.StatusUnauthorized, "Sign-in failed: "+providerErr)
+		return
+	}
+
+	state := r.URL.Query().Get("state")
+	code := r.URL.Query().Get("code")
+	if state == "" || code == "" {
+		respondWithError(w, http.StatusBadRequest, "state and code are required")
+		return
+	}
+
+	// The login must have been started from this browser
+	cookie, err := r.Cookie(oidcStateCookie)
+	if err != nil || subtle.ConstantTimeCompare([]byte(cookie.Value), []byte(state)) != 1 {
+		respondWithError(w, http.StatusBadRequest, "Invalid or expired login state")
+		return
+	}
+
+	resp, err := h.authService.CompleteOIDCLogin(r.Context(), state, code)
+	if err != nil {
+		switch {
+		case strings.Contains(err.Error(), "login state"):
+			respondWithError(w, http.StatusBadRequest, "Invalid or expired login state")
+		case strings.Contains(err.Error(), "not verified"):
+			respondWithError(w, http.StatusForbidden, err.Error())
+		case strings.Contains(err.Error(), "identity provider unavailable"):
+			log.Printf("OpenID Connect login failed: %v", err)
+			respondWithError(w, http.StatusBadGateway, "Identity provider unavailable")
+		case strings.Contains(err.Error(), "rejected"),
+			strings.Contains(err.Error(), "invalid ID token"),
+			strings.Contains(err.Error(), "account not found"):
+			log.Printf("OpenID Connect login failed: %v", err)
+			respondWithError(w, http.StatusUnauthorized, "Sign-in failed")
+		case strings.Contains(err.Error(), "already exists"):
+			// A deleted account still holds the email until it is purged
+			respondWithError(w, http.StatusConflict, err.Error())
+		default:
+			respondWithError(w, http.StatusInternalServerError, "Failed to complete login")
+		}
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, resp)
+}
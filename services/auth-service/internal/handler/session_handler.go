@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"expense-tracker/auth-service/internal/middleware"
+	"expense-tracker/auth-service/internal/service"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// SessionHandler handles HTTP requests for the user's sessions (signed-in devices)
+// Routes must be wrapped in RequireAuth
+type SessionHandler struct {
+	authService *service.AuthService
+}
+
+// NewSessionHandler creates a new session handler
+func NewSessionHandler(authService *service.AuthService) *SessionHandler {
+	return &SessionHandler{
+		authService: authService,
+	}
+}
+
+// ListSessions handles GET /auth/sessions
+// The session of the token making the request has "current": true
+func (h *SessionHandler) ListSessions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID := middleware.GetUserID(r.Context())
+	if userID == "" {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	resp, err := h.authService.ListSessions(r.Context(), userID, middleware.GetSessionID(r.Context()))
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to list sessions")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+// RevokeSession handles DELETE /auth/sessions/{id}
+// Revoking the current session is the same as logging out
+func (h *SessionHandler) RevokeSession(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID := middleware.GetUserID(r.Context())
+	if userID == "" {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	err := h.authService.RevokeSession(r.Context(), userID, mux.Vars(r)["id"])
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			respondWithError(w, http.StatusNotFound, "Session not found")
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Failed to revoke session")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]string{
+		"message": "Session signed out",
+	})
+}
@@ -0,0 +1,82 @@
+package middleware
+
+import (
+	"expense-tracker/auth-service/internal/model"
+	"expense-tracker/auth-service/internal/repository"
+	"expense-tracker/auth-service/internal/service"
+	"expense-tracker/shared/clientip"
+	"log"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// AuditMiddleware records requests to admin endpoints in the admin audit log
+// Use it inside RequireRole so the admin's identity is in the request context
+type AuditMiddleware struct {
+	auditRepo repository.AdminAuditRepository
+}
+
+// NewAuditMiddleware creates a new audit middleware
+func NewAuditMiddleware(auditRepo repository.AdminAuditRepository) *AuditMiddleware {
+	return &AuditMiddleware{
+		auditRepo: auditRepo,
+	}
+}
+
+// Audit records the request before calling the handler
+// action names what the endpoint does (e.g. "users.view"); the target user is
+// taken from the "userId" or "id" route variable if there is one.
+// If the entry can't be written the request is refused - no unaudited access.
+// A target user ID that isn't a UUID is refused with 400 before anything is recorded
+func (m *AuditMiddleware) Audit(action string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		var targetUserID *string
+		if id := vars["userId"]; id != "" {
+			targetUserID = &id
+		} else if id := vars["id"]; id != "" {
+			targetUserID = &id
+		}
+		if targetUserID != nil {
+			if _, err := uuid.Parse(*targetUserID); err != nil {
+				http.Error(w, "User ID must be a valid UUID", http.StatusBadRequest)
+				return
+			}
+		}
+
+		entry := model.NewAdminAuditEntry(
+			GetUserID(r.Context()),
+			GetUserEmail(r.Context()),
+			action,
+			targetUserID,
+			r.Method,
+			r.URL.RequestURI(),
+			ClientIP(r),
+		)
+
+		if err := m.auditRepo.Record(r.Context(), entry); err != nil {
+			log.Printf("ERROR: Failed to write admin audit entry (%s by %s): %v", action, entry.AdminUserID, err)
+			http.Error(w, "Failed to record audit entry", http.StatusInternalServerError)
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+// RequestInfo puts the caller's IP address and user agent in the request context
+// AuthService records them in the authentication audit log
+func RequestInfo(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := service.WithRequestInfo(r.Context(), ClientIP(r), r.UserAgent())
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// ClientIP returns the caller's IP address
+// X-Forwarded-For is only used from trusted proxies (TRUSTED_PROXIES) - see clientip.Resolver
+func ClientIP(r *http.Request) string {
+	return clientip.FromRequest(r)
+}
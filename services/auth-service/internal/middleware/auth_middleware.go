@@ -16,14 +16,15 @@ import (
 
 // AuthMiddleware validates JWT tokens and attaches user info to request context
 // This protects routes that require authentication
+// Tokens are checked through AuthService, so revoked sessions are rejected too
 type AuthMiddleware struct {
-	jwtService *service.JWTService
+	authService *service.AuthService
 }
 
 // NewAuthMiddleware creates a new authentication middleware
-func NewAuthMiddleware(jwtService *service.JWTService) *AuthMiddleware {
+func NewAuthMiddleware(authService *service.AuthService) *AuthMiddleware {
 	return &AuthMiddleware{
-		jwtService: jwtService,
+		authService: authService,
 	}
 }
 
@@ -49,18 +50,32 @@ func (m *AuthMiddleware) RequireAuth(next http.HandlerFunc) http.HandlerFunc {
 
 		token := parts[1]
 
-		// Validate the token
-		claims, err := m.jwtService.ValidateToken(token)
+		// Validate the token (signature, expiration and session)
+		result, err := m.authService.ValidateToken(r.Context(), token)
 		if err != nil {
+			http.Error(w, "Failed to validate token", http.StatusInternalServerError)
+			return
+		}
+		if !result.Valid {
 			http.Error(w, "Invalid or expired token", http.StatusUnauthorized)
 			return
 		}
 
+		// API keys only work for expense-service and receipt-service
+		// Account management needs a real login, so a leaked key can't
+		// change the password or create more keys
+		if result.APIKeyID != "" {
+			http.Error(w, "API keys can't be used for account management", http.StatusForbidden)
+			return
+		}
+
 		// Attach user info to request context
 		// Context is Go's way of passing request-scoped data
 		// This allows handlers to access user info without parsing the token again
-		ctx := context.WithValue(r.Context(), "user_id", claims.UserID)
-		ctx = context.WithValue(ctx, "user_email", claims.Email)
+		ctx := context.WithValue(r.Context(), "user_id", result.UserID)
+		ctx = context.WithValue(ctx, "user_email", result.Email)
+		ctx = context.WithValue(ctx, "user_role", result.Role)
+		ctx = context.WithValue(ctx, "session_id", result.SessionID)
 
 		// Create a new request with the updated context
 		r = r.WithContext(ctx)
@@ -70,6 +85,20 @@ func (m *AuthMiddleware) RequireAuth(next http.HandlerFunc) http.HandlerFunc {
 	}
 }
 
+// RequireRole is like RequireAuth, but also requires the user to have a role
+// Authenticated users without the role get 403 Forbidden
+// Usage: authMiddleware.RequireRole(model.RoleAdmin, handler)
+func (m *AuthMiddleware) RequireRole(role string, next http.HandlerFunc) http.HandlerFunc {
+	return m.RequireAuth(func(w http.ResponseWriter, r *http.Request) {
+		if GetUserRole(r.Context()) != role {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+
+		next(w, r)
+	})
+}
+
 // GetUserID extracts the user ID from the request context
 // This is a helper function for handlers to get user info
 func GetUserID(ctx context.Context) string {
@@ -88,3 +117,21 @@ func GetUserEmail(ctx context.Context) string {
 	}
 	return email
 }
+
+// GetUserRole extracts the user role from the request context
+func GetUserRole(ctx context.Context) string {
+	role, ok := ctx.Value("user_role").(string)
+	if !ok {
+		return ""
+	}
+	return role
+}
+
+// GetSessionID extracts the session ID ("sid" claim) from the request context
+func GetSessionID(ctx context.Context) string {
+	sessionID, ok := ctx.Value("session_id").(string)
+	if !ok {
+		return ""
+	}
+	return sessionID
+}
@@ -0,0 +1,25 @@
+package model
+
+// Admin DTOs - used by the admin-only endpoints (role "admin")
+
+// ListUsersRequest represents query parameters for listing users
+// These come from URL query parameters, not JSON body
+type ListUsersRequest struct {
+	// Email filter (optional) - case-insensitive substring match
+	Email string
+
+	// Page number for pagination (default: 1)
+	Page int
+
+	// Limit is items per page (default: 20, max: 100)
+	Limit int
+}
+
+// ListUsersResponse contains the list of users and pagination info
+type ListUsersResponse struct {
+	Users []*User `json:"users"`
+	Total int     `json:"total"` // Total number of users (before pagination)
+	Page  int     `json:"page"`  // Current page number
+	Limit int     `json:"limit"` // Items per page
+	Pages int     `json:"pages"` // Total number of pages
+}
@@ -0,0 +1,110 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// APIKeyPrefix starts every API key
+// It tells API keys apart from JWTs (which start with "eyJ") in Authorization headers
+const APIKeyPrefix = "etk_"
+
+// API key scopes
+// A key without scopes can do everything its owner can (except account management)
+// ":write" includes ":read"
+const (
+	ScopeExpensesRead  = "expenses:read"
+	ScopeExpensesWrite = "expenses:write"
+	ScopeReceiptsRead  = "receipts:read"
+	ScopeReceiptsWrite = "receipts:write"
+)
+
+// APIKeyScopes lists every valid scope
+var APIKeyScopes = []string{ScopeExpensesRead, ScopeExpensesWrite, ScopeReceiptsRead, ScopeReceiptsWrite}
+
+// APIKey represents a personal API key
+// Scripts send it as "Authorization: Bearer etk_..." instead of a JWT
+type APIKey struct {
+	// ID is a UUID primary key
+	ID string `json:"id" db:"id"`
+
+	// UserID is the owner of the key
+	UserID string `json:"-" db:"user_id"`
+
+	// Name is the label chosen by the user
+	Name string `json:"name" db:"name"`
+
+	// Prefix is the start of the key, so users can recognize it in lists
+	Prefix string `json:"prefix" db:"prefix"`
+
+	// KeyHash is the SHA-256 hash of the key - the plain key is never stored
+	KeyHash string `json:"-" db:"key_hash"`
+
+	// Scopes limits what the key can do (empty = no limit)
+	Scopes []string `json:"scopes" db:"scopes"`
+
+	// ExpiresAt is when the key stops being accepted (nil = never)
+	ExpiresAt *time.Time `json:"expires_at,omitempty" db:"expires_at"`
+
+	// LastUsedAt is when the key was last used (nil = never used)
+	LastUsedAt *time.Time `json:"last_used_at,omitempty" db:"last_used_at"`
+
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+
+	// RevokedAt is set when the user revokes the key (nil = still usable)
+	RevokedAt *time.Time `json:"revoked_at,omitempty" db:"revoked_at"`
+}
+
+// NewAPIKey creates a new APIKey with generated ID and timestamps
+// expiration of 0 creates a key that never expires
+func NewAPIKey(userID, name, prefix, keyHash string, scopes []string, expiration time.Duration) *APIKey {
+	now := time.Now()
+	key := &APIKey{
+		ID:        uuid.New().String(),
+		UserID:    userID,
+		Name:      name,
+		Prefix:    prefix,
+		KeyHash:   keyHash,
+		Scopes:    scopes,
+		CreatedAt: now,
+	}
+	if expiration > 0 {
+		expiresAt := now.Add(expiration)
+		key.ExpiresAt = &expiresAt
+	}
+	return key
+}
+
+// IsActive reports whether the key is neither revoked nor expired
+func (k *APIKey) IsActive() bool {
+	if k.RevokedAt != nil {
+		return false
+	}
+	return k.ExpiresAt == nil || time.Now().Before(*k.ExpiresAt)
+}
+
+// CreateAPIKeyRequest represents the data sent to create an API key
+// POST /auth/api-keys
+type CreateAPIKeyRequest struct {
+	Name string `json:"name" binding:"required"`
+
+	// Scopes is optional - leave it out for a key without limits
+	Scopes []string `json:"scopes,omitempty"`
+
+	// ExpiresInDays is optional - leave it out for a key that never expires
+	ExpiresInDays *int `json:"expires_in_days,omitempty"`
+}
+
+// CreateAPIKeyResponse is returned once when a key is created
+// Key is the only time the plain key is ever shown
+type CreateAPIKeyResponse struct {
+	*APIKey
+	Key string `json:"key"`
+}
+
+// ListAPIKeysResponse lists the user's API keys (without the keys themselves)
+// GET /auth/api-keys
+type ListAPIKeysResponse struct {
+	APIKeys []*APIKey `json:"api_keys"`
+}
@@ -22,8 +22,37 @@ type LoginRequest struct {
 	Password string `json:"password" binding:"required"`
 }
 
-// AuthResponse is what we send back after successful registration or login
-// It includes the user info and a JWT token
+// RefreshRequest represents the data sent when exchanging a refresh token
+// for a new access token
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// LogoutRequest represents the data sent when logging out
+// The refresh token identifies which session (token family) to revoke
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// ForgotPasswordRequest represents the data sent to start a password reset
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required"`
+}
+
+// ResetPasswordRequest represents the data sent to complete a password reset
+// Token is the value from the emailed reset link
+type ResetPasswordRequest struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required"`
+}
+
+// ResendVerificationRequest represents the data sent to get a new verification email
+type ResendVerificationRequest struct {
+	Email string `json:"email" binding:"required"`
+}
+
+// AuthResponse is what we send back after successful registration, login or refresh
+// It includes the user info, a short-lived JWT access token and a refresh token
 type AuthResponse struct {
 	// UserID is the unique identifier for the user
 	UserID string `json:"user_id"`
@@ -34,8 +63,57 @@ type AuthResponse struct {
 	// Name of the user
 	Name string `json:"name"`
 
+	// EmailVerified tells the client whether the email address was verified
+	EmailVerified bool `json:"email_verified"`
+
+	// Role is the user's role ("user" or "admin")
+	Role string `json:"role,omitempty"`
+
 	// Token is the JWT token that the client will use for authenticated requests
-	Token string `json:"token"`
+	// Omitted after registration when email verification is required
+	Token string `json:"token,omitempty"`
+
+	// ExpiresIn is the access token lifetime in seconds
+	ExpiresIn int64 `json:"expires_in,omitempty"`
+
+	// RefreshToken is used to obtain a new access token when it expires
+	// It is single-use: every refresh returns a new refresh token
+	RefreshToken string `json:"refresh_token,omitempty"`
+
+	// MFARequired is set by Login when the account has two-factor authentication
+	// No tokens are issued yet - exchange MFAToken and a code at POST /auth/mfa/verify
+	MFARequired bool `json:"mfa_required,omitempty"`
+
+	// MFAToken is the short-lived challenge token for the second login step
+	MFAToken string `json:"mfa_token,omitempty"`
+}
+
+// MFAEnrollResponse is returned when a user starts TOTP enrollment
+type MFAEnrollResponse struct {
+	// Secret is the base32 TOTP secret (for manual entry in an authenticator app)
+	Secret string `json:"secret"`
+
+	// OTPAuthURI is the otpauth:// URI - render it as a QR code for the app to scan
+	OTPAuthURI string `json:"otpauth_uri"`
+}
+
+// MFAConfirmRequest represents the data sent to finish TOTP enrollment
+// Code is the current code shown by the authenticator app
+type MFAConfirmRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// MFAConfirmResponse is returned once MFA is enabled
+// The recovery codes are only ever shown this one time
+type MFAConfirmResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// MFAVerifyRequest represents the second step of an MFA login
+// Code is either a TOTP code or one of the recovery codes
+type MFAVerifyRequest struct {
+	MFAToken string `json:"mfa_token" binding:"required"`
+	Code     string `json:"code" binding:"required"`
 }
 
 // ValidateResponse is what we send back when validating a token
@@ -48,4 +126,16 @@ type ValidateResponse struct {
 
 	// Email is extracted from the token if valid
 	Email string `json:"email,omitempty"`
+
+	// Role is the user's current role if valid
+	Role string `json:"role,omitempty"`
+
+	// SessionID is the session ("sid" claim) of a valid JWT - empty for API keys
+	SessionID string `json:"session_id,omitempty"`
+
+	// APIKeyID is set when the token is an API key instead of a JWT
+	APIKeyID string `json:"api_key_id,omitempty"`
+
+	// Scopes limits what an API key can do (empty = no limit)
+	Scopes []string `json:"scopes,omitempty"`
 }
@@ -0,0 +1,121 @@
+package model
+
+import (
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// Auth audit event types
+const (
+	AuthEventRegister       = "register"
+	AuthEventLogin          = "login"
+	AuthEventMFAVerify      = "mfa_verify"
+	AuthEventOIDCLogin      = "oidc_login"
+	AuthEventTokenRefresh   = "token_refresh"
+	AuthEventTokenValidate  = "token_validate"
+	AuthEventLogout         = "logout"
+	AuthEventSessionRevoke  = "session_revoke"
+	AuthEventPasswordChange = "password_change"
+	AuthEventPasswordReset  = "password_reset"
+	AuthEventEmailChange    = "email_change"
+	AuthEventMFAEnable      = "mfa_enable"
+	AuthEventAccountDelete  = "account_delete"
+)
+
+// Auth audit event outcomes
+const (
+	AuthOutcomeSuccess = "success"
+	AuthOutcomeFailure = "failure"
+	// AuthOutcomeMFARequired means the password was right and an MFA challenge was issued
+	AuthOutcomeMFARequired = "mfa_required"
+)
+
+// AuthAuditEvent records one authentication event in the security audit log
+// Events are only ever inserted - never changed or deleted
+type AuthAuditEvent struct {
+	ID string `json:"id" db:"id"`
+
+	EventType string `json:"event_type" db:"event_type"`
+
+	// UserID is nil when the user is unknown (e.g. login with an unregistered email)
+	UserID *string `json:"user_id,omitempty" db:"user_id"`
+	Email  string  `json:"email,omitempty" db:"email"`
+
+	// Request details
+	ClientIP  string `json:"client_ip" db:"client_ip"`
+	UserAgent string `json:"user_agent" db:"user_agent"`
+
+	Outcome string `json:"outcome" db:"outcome"`
+	// Reason explains a failure (or adds detail, e.g. which API key was used)
+	Reason string `json:"reason,omitempty" db:"reason"`
+
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// Column sizes of auth_audit_events - longer values are cut so the insert can't fail
+const (
+	maxAuditEmailLength     = 255
+	maxAuditClientIPLength  = 100
+	maxAuditUserAgentLength = 1000
+	maxAuditReasonLength    = 255
+)
+
+// NewAuthAuditEvent creates a new AuthAuditEvent with generated ID and timestamp
+// Values sent by the client (the attempted email, the user agent) can be any
+// length, so every text is cut to its column size
+func NewAuthAuditEvent(eventType string, userID *string, email, clientIP, userAgent, outcome, reason string) *AuthAuditEvent {
+	return &AuthAuditEvent{
+		ID:        uuid.New().String(),
+		EventType: eventType,
+		UserID:    userID,
+		Email:     truncateAuditValue(email, maxAuditEmailLength),
+		ClientIP:  truncateAuditValue(clientIP, maxAuditClientIPLength),
+		UserAgent: truncateAuditValue(userAgent, maxAuditUserAgentLength),
+		Outcome:   outcome,
+		Reason:    truncateAuditValue(reason, maxAuditReasonLength),
+		CreatedAt: time.Now(),
+	}
+}
+
+// truncateAuditValue cuts s to at most max characters, without splitting a
+// UTF-8 sequence. Invalid UTF-8 and NUL bytes are replaced (PostgreSQL rejects them)
+func truncateAuditValue(s string, max int) string {
+	s = strings.ReplaceAll(strings.ToValidUTF8(s, "\uFFFD"), "\x00", "\uFFFD")
+	if utf8.RuneCountInString(s) <= max {
+		return s
+	}
+	runes := []rune(s)
+	return string(runes[:max])
+}
+
+// ListAuthAuditEventsRequest represents query parameters for listing audit events
+// Every filter is optional
+type ListAuthAuditEventsRequest struct {
+	UserID    string
+	Email     string // Case-insensitive exact match
+	EventType string
+	Outcome   string
+	ClientIP  string
+
+	// Only events in [From, To)
+	From *time.Time
+	To   *time.Time
+
+	// Page number for pagination (default: 1)
+	Page int
+
+	// Limit is items per page (default: 50, max: 200)
+	Limit int
+}
+
+// ListAuthAuditEventsResponse contains a page of audit events, newest first
+type ListAuthAuditEventsResponse struct {
+	Events []*AuthAuditEvent `json:"events"`
+	Total  int               `json:"total"` // Total number of matching events
+	Page   int               `json:"page"`  // Current page number
+	Limit  int               `json:"limit"` // Items per page
+	Pages  int               `json:"pages"` // Total number of pages
+}
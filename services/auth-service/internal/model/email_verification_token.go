@@ -0,0 +1,68 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Email verification token purposes
+const (
+	EmailTokenPurposeVerify = "verify" // Confirms the user's current address
+	EmailTokenPurposeChange = "change" // Confirms a new address - the email changes when used
+)
+
+// EmailVerificationToken represents a single-use token emailed to a user
+// to prove they own an email address
+type EmailVerificationToken struct {
+	// ID is a UUID primary key
+	ID string `json:"id" db:"id"`
+
+	// UserID is the user who must verify the address
+	UserID string `json:"user_id" db:"user_id"`
+
+	// Email is the address the token was sent to
+	Email string `json:"email" db:"email"`
+
+	// Purpose is EmailTokenPurposeVerify or EmailTokenPurposeChange
+	Purpose string `json:"purpose" db:"purpose"`
+
+	// TokenHash is the SHA-256 hash of the token - the plain token is never stored
+	TokenHash string `json:"-" db:"token_hash"`
+
+	// ExpiresAt is when the token stops being accepted
+	ExpiresAt time.Time `json:"expires_at" db:"expires_at"`
+
+	// CreatedAt tracks when the token was issued
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+
+	// UsedAt is set once the token has been used (nil = still usable)
+	UsedAt *time.Time `json:"used_at,omitempty" db:"used_at"`
+}
+
+// NewEmailVerificationToken creates a new EmailVerificationToken with generated ID and timestamps
+func NewEmailVerificationToken(userID, email, tokenHash string, expiration time.Duration) *EmailVerificationToken {
+	now := time.Now()
+	return &EmailVerificationToken{
+		ID:        uuid.New().String(),
+		UserID:    userID,
+		Email:     email,
+		Purpose:   EmailTokenPurposeVerify,
+		TokenHash: tokenHash,
+		ExpiresAt: now.Add(expiration),
+		CreatedAt: now,
+	}
+}
+
+// NewEmailChangeToken creates a token that confirms a change to newEmail
+// The user's email is only switched once the token is used
+func NewEmailChangeToken(userID, newEmail, tokenHash string, expiration time.Duration) *EmailVerificationToken {
+	token := NewEmailVerificationToken(userID, newEmail, tokenHash, expiration)
+	token.Purpose = EmailTokenPurposeChange
+	return token
+}
+
+// IsUsable reports whether the token is unused and not yet expired
+func (t *EmailVerificationToken) IsUsable() bool {
+	return t.UsedAt == nil && time.Now().Before(t.ExpiresAt)
+}
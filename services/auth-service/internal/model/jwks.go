@@ -0,0 +1,21 @@
+package model
+
+// JWKS is a JSON Web Key Set (RFC 7517) - the public keys that verify our JWTs
+// Served at GET /.well-known/jwks.json so other services can verify tokens locally
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWK is one public key in a JWKS
+// Which fields are set depends on the key type:
+// RSA keys have N and E, Ed25519 ("OKP") keys have Curve and X
+type JWK struct {
+	KeyType   string `json:"kty"`           // "RSA" or "OKP"
+	KeyID     string `json:"kid"`           // Matches the "kid" header of tokens
+	Use       string `json:"use"`           // Always "sig" (signature verification)
+	Algorithm string `json:"alg"`           // "RS256" or "EdDSA"
+	N         string `json:"n,omitempty"`   // RSA modulus (base64url)
+	E         string `json:"e,omitempty"`   // RSA public exponent (base64url)
+	Curve     string `json:"crv,omitempty"` // "Ed25519"
+	X         string `json:"x,omitempty"`   // Ed25519 public key (base64url)
+}
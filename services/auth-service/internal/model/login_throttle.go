@@ -0,0 +1,33 @@
+package model
+
+import "time"
+
+// Login throttle scopes
+// Failed logins are counted per account and per client IP
+const (
+	ThrottleScopeEmail = "email" // One account - catches guessing a single user's password
+	ThrottleScopeIP    = "ip"    // One client - catches trying many accounts from one place
+)
+
+// LoginThrottle counts failed login attempts for one account or client IP
+type LoginThrottle struct {
+	// Scope is ThrottleScopeEmail or ThrottleScopeIP
+	Scope string `json:"scope" db:"scope"`
+
+	// Subject is the normalized email address or the client IP
+	Subject string `json:"subject" db:"subject"`
+
+	// FailedCount is the number of consecutive failed attempts
+	FailedCount int `json:"failed_count" db:"failed_count"`
+
+	// LastFailedAt is when the last failed attempt happened
+	LastFailedAt time.Time `json:"last_failed_at" db:"last_failed_at"`
+
+	// LockedUntil is when logins are allowed again (nil = not locked)
+	LockedUntil *time.Time `json:"locked_until,omitempty" db:"locked_until"`
+}
+
+// IsLocked reports whether logins are refused at time t
+func (l *LoginThrottle) IsLocked(t time.Time) bool {
+	return l.LockedUntil != nil && t.Before(*l.LockedUntil)
+}
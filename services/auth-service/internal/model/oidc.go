@@ -0,0 +1,61 @@
+package model
+
+import "time"
+
+// OIDCLoginState is a login that was sent to the identity provider
+// and hasn't come back to the callback yet
+type OIDCLoginState struct {
+	// StateHash is the SHA-256 hash of the state parameter
+	StateHash string `json:"-" db:"state_hash"`
+
+	// Nonce must come back in the ID token
+	Nonce string `json:"-" db:"nonce"`
+
+	// CodeVerifier is the PKCE secret - the provider only saw its hash (code_challenge)
+	CodeVerifier string `json:"-" db:"code_verifier"`
+
+	ExpiresAt time.Time `json:"expires_at" db:"expires_at"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// NewOIDCLoginState creates a new OIDCLoginState
+func NewOIDCLoginState(stateHash, nonce, codeVerifier string, expiration time.Duration) *OIDCLoginState {
+	now := time.Now()
+	return &OIDCLoginState{
+		StateHash:    stateHash,
+		Nonce:        nonce,
+		CodeVerifier: codeVerifier,
+		ExpiresAt:    now.Add(expiration),
+		CreatedAt:    now,
+	}
+}
+
+// IsExpired reports whether the login took too long
+func (s *OIDCLoginState) IsExpired() bool {
+	return time.Now().After(s.ExpiresAt)
+}
+
+// OIDCIdentity links an identity provider account to a user
+type OIDCIdentity struct {
+	// Issuer and Subject identify the provider account
+	Issuer  string `json:"issuer" db:"issuer"`
+	Subject string `json:"subject" db:"subject"`
+
+	UserID string `json:"user_id" db:"user_id"`
+
+	// Email is the verified address the provider reported when linking
+	Email string `json:"email" db:"email"`
+
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// NewOIDCIdentity creates a new OIDCIdentity
+func NewOIDCIdentity(issuer, subject, userID, email string) *OIDCIdentity {
+	return &OIDCIdentity{
+		Issuer:    issuer,
+		Subject:   subject,
+		UserID:    userID,
+		Email:     email,
+		CreatedAt: time.Now(),
+	}
+}
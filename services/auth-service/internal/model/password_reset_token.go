@@ -0,0 +1,46 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PasswordResetToken represents a single-use token emailed to a user
+// who forgot their password
+type PasswordResetToken struct {
+	// ID is a UUID primary key
+	ID string `json:"id" db:"id"`
+
+	// UserID is the user who requested the reset
+	UserID string `json:"user_id" db:"user_id"`
+
+	// TokenHash is the SHA-256 hash of the token - the plain token is never stored
+	TokenHash string `json:"-" db:"token_hash"`
+
+	// ExpiresAt is when the token stops being accepted
+	ExpiresAt time.Time `json:"expires_at" db:"expires_at"`
+
+	// CreatedAt tracks when the token was issued
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+
+	// UsedAt is set once the token has been used (nil = still usable)
+	UsedAt *time.Time `json:"used_at,omitempty" db:"used_at"`
+}
+
+// NewPasswordResetToken creates a new PasswordResetToken with generated ID and timestamps
+func NewPasswordResetToken(userID, tokenHash string, expiration time.Duration) *PasswordResetToken {
+	now := time.Now()
+	return &PasswordResetToken{
+		ID:        uuid.New().String(),
+		UserID:    userID,
+		TokenHash: tokenHash,
+		ExpiresAt: now.Add(expiration),
+		CreatedAt: now,
+	}
+}
+
+// IsUsable reports whether the token is unused and not yet expired
+func (t *PasswordResetToken) IsUsable() bool {
+	return t.UsedAt == nil && time.Now().Before(t.ExpiresAt)
+}
@@ -0,0 +1,65 @@
+package model
+
+import "time"
+
+// ProfileResponse is the signed-in user's own account information
+// GET /auth/profile
+type ProfileResponse struct {
+	ID            string    `json:"id"`
+	Email         string    `json:"email"`
+	Name          string    `json:"name"`
+	Role          string    `json:"role"`
+	EmailVerified bool      `json:"email_verified"`
+	MFAEnabled    bool      `json:"mfa_enabled"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// NewProfileResponse builds the profile view of a user
+func NewProfileResponse(user *User) *ProfileResponse {
+	return &ProfileResponse{
+		ID:            user.ID,
+		Email:         user.Email,
+		Name:          user.Name,
+		Role:          user.Role,
+		EmailVerified: user.IsEmailVerified(),
+		MFAEnabled:    user.IsMFAEnabled(),
+		CreatedAt:     user.CreatedAt,
+		UpdatedAt:     user.UpdatedAt,
+	}
+}
+
+// UpdateProfileRequest represents a partial profile update
+// PATCH /auth/profile - fields that are left out are not changed
+// (email and password have their own endpoints because they need the current password)
+type UpdateProfileRequest struct {
+	Name *string `json:"name,omitempty"`
+}
+
+// ChangePasswordRequest represents the data sent to change a password
+// POST /auth/password/change
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required"`
+}
+
+// ChangeEmailRequest represents the data sent to change an email address
+// POST /auth/email/change - the new address must be verified before it is used
+// CurrentPassword may be left out right after signing in (e.g. users who only
+// sign in with an identity provider and don't know their password)
+type ChangeEmailRequest struct {
+	NewEmail        string `json:"new_email" binding:"required"`
+	CurrentPassword string `json:"current_password"`
+}
+
+// DeleteAccountRequest represents the data sent to delete an account
+// DELETE /auth/account - CurrentPassword may be left out right after signing in
+type DeleteAccountRequest struct {
+	CurrentPassword string `json:"current_password"`
+}
+
+// DeleteAccountResponse tells the user when their data will be erased
+type DeleteAccountResponse struct {
+	Message    string    `json:"message"`
+	PurgeAfter time.Time `json:"purge_after"`
+}
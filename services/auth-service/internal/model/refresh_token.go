@@ -0,0 +1,60 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RefreshToken represents a server-stored refresh token
+// Refresh tokens are long-lived and are exchanged for new access tokens
+// Each login starts a new "family"; every refresh rotates the token within that family
+type RefreshToken struct {
+	// ID is a UUID primary key
+	ID string `json:"id" db:"id"`
+
+	// UserID is the owner of the token
+	UserID string `json:"user_id" db:"user_id"`
+
+	// FamilyID groups all tokens issued from the same login
+	// It is also embedded in access tokens as the session ID ("sid" claim)
+	FamilyID string `json:"family_id" db:"family_id"`
+
+	// TokenHash is the SHA-256 hash of the token - the plain token is never stored
+	TokenHash string `json:"-" db:"token_hash"`
+
+	// ExpiresAt is when the token stops being accepted
+	ExpiresAt time.Time `json:"expires_at" db:"expires_at"`
+
+	// CreatedAt tracks when the token was issued
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+
+	// RevokedAt is set when the token is rotated or revoked (nil = still usable)
+	RevokedAt *time.Time `json:"revoked_at,omitempty" db:"revoked_at"`
+
+	// ReplacedBy is the ID of the token issued when this one was rotated
+	ReplacedBy *string `json:"replaced_by,omitempty" db:"replaced_by"`
+}
+
+// NewRefreshToken creates a new RefreshToken with generated ID and timestamps
+func NewRefreshToken(userID, familyID, tokenHash string, expiration time.Duration) *RefreshToken {
+	now := time.Now()
+	return &RefreshToken{
+		ID:        uuid.New().String(),
+		UserID:    userID,
+		FamilyID:  familyID,
+		TokenHash: tokenHash,
+		ExpiresAt: now.Add(expiration),
+		CreatedAt: now,
+	}
+}
+
+// IsExpired reports whether the token is past its expiration time
+func (t *RefreshToken) IsExpired() bool {
+	return time.Now().After(t.ExpiresAt)
+}
+
+// IsRevoked reports whether the token has been rotated or revoked
+func (t *RefreshToken) IsRevoked() bool {
+	return t.RevokedAt != nil
+}
@@ -0,0 +1,39 @@
+package model
+
+import "time"
+
+// Session is one login of a user on some device
+// Its ID is the refresh token family ID, carried by access tokens as the "sid" claim
+// A session is active as long as its family has a usable refresh token
+type Session struct {
+	ID     string `json:"id" db:"id"`
+	UserID string `json:"-" db:"user_id"`
+
+	// Device the session was started / last refreshed from
+	UserAgent string `json:"user_agent" db:"user_agent"`
+	ClientIP  string `json:"client_ip" db:"client_ip"`
+
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at" db:"last_seen_at"`
+
+	// Current marks the session of the token making the request (not stored)
+	Current bool `json:"current" db:"-"`
+}
+
+// NewSession creates a new Session for a refresh token family
+func NewSession(id, userID, userAgent, clientIP string) *Session {
+	now := time.Now()
+	return &Session{
+		ID:         id,
+		UserID:     userID,
+		UserAgent:  userAgent,
+		ClientIP:   clientIP,
+		CreatedAt:  now,
+		LastSeenAt: now,
+	}
+}
+
+// ListSessionsResponse contains the user's active sessions, most recently used first
+type ListSessionsResponse struct {
+	Sessions []*Session `json:"sessions"`
+}
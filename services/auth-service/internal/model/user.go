@@ -6,6 +6,13 @@ import (
 	"github.com/google/uuid"
 )
 
+// Roles a user can have
+// Roles are stored in the users table and copied into access tokens ("role" claim)
+const (
+	RoleUser  = "user"  // Regular user - can only access their own data
+	RoleAdmin = "admin" // Support staff - can inspect other users' data (audited)
+)
+
 // User represents a user in the system
 // In Go, structs are like classes - they group related data together
 type User struct {
@@ -23,9 +30,28 @@ type User struct {
 	// The `json:"-"` means this field is excluded from JSON responses (security!)
 	PasswordHash string `json:"-" db:"password_hash"`
 
+	// HasPassword is false for users created by an OpenID Connect login until they
+	// set a password - their password hash is random and nobody knows the password
+	HasPassword bool `json:"-" db:"has_password"`
+
 	// Name is the user's display name
 	Name string `json:"name" db:"name"`
 
+	// Role controls what the user may access (RoleUser or RoleAdmin)
+	Role string `json:"role" db:"role"`
+
+	// EmailVerifiedAt is when the user proved they own the email address
+	// nil means the address has not been verified yet
+	EmailVerifiedAt *time.Time `json:"email_verified_at,omitempty" db:"email_verified_at"`
+
+	// MFASecret is the base32 TOTP secret shared with the user's authenticator app
+	// Empty if the user never started MFA enrollment. Never sent to clients after enrollment.
+	MFASecret string `json:"-" db:"mfa_secret"`
+
+	// MFAEnabledAt is when the user confirmed MFA enrollment
+	// nil means two-factor authentication is off
+	MFAEnabledAt *time.Time `json:"mfa_enabled_at,omitempty" db:"mfa_enabled_at"`
+
 	// CreatedAt tracks when the user was created
 	// time.Time is Go's built-in time type
 	CreatedAt time.Time `json:"created_at" db:"created_at"`
@@ -48,9 +74,21 @@ func NewUser(email, passwordHash, name string) *User {
 		ID:           uuid.New().String(),
 		Email:        email,
 		PasswordHash: passwordHash,
+		HasPassword:  true,
 		Name:         name,
+		Role:         RoleUser,
 		CreatedAt:    now,
 		UpdatedAt:    now,
 		DeletedAt:    nil, // nil means not deleted
 	}
 }
+
+// IsEmailVerified reports whether the user has verified their email address
+func (u *User) IsEmailVerified() bool {
+	return u.EmailVerifiedAt != nil
+}
+
+// IsMFAEnabled reports whether login requires a second factor
+func (u *User) IsMFAEnabled() bool {
+	return u.MFAEnabledAt != nil && u.MFASecret != ""
+}
@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"context"
+	"expense-tracker/auth-service/internal/model"
+)
+
+// APIKeyRepository defines the interface for API key data operations
+type APIKeyRepository interface {
+	// Create inserts a new API key into the database
+	Create(ctx context.Context, key *model.APIKey) error
+
+	// FindByHash finds an API key by the hash of its value
+	// Returns nil if no key matches (revoked and expired keys are still returned)
+	FindByHash(ctx context.Context, keyHash string) (*model.APIKey, error)
+
+	// ListByUser lists a user's keys that weren't revoked, newest first
+	ListByUser(ctx context.Context, userID string) ([]*model.APIKey, error)
+
+	// Revoke revokes one of the user's keys
+	Revoke(ctx context.Context, id, userID string) error
+
+	// TouchLastUsed records that a key was used (at most once a minute)
+	TouchLastUsed(ctx context.Context, id string) error
+}
@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"context"
+	"expense-tracker/auth-service/internal/model"
+)
+
+// AuthAuditRepository defines the interface for the authentication audit log
+// It is append-only: there are no update or delete methods
+type AuthAuditRepository interface {
+	// Record stores one audit event
+	Record(ctx context.Context, event *model.AuthAuditEvent) error
+
+	// List finds events matching the filters, newest first
+	// Returns the events for the requested page and the total count
+	List(ctx context.Context, filters *model.ListAuthAuditEventsRequest) ([]*model.AuthAuditEvent, int, error)
+}
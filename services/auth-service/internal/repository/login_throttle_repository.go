@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"context"
+	"expense-tracker/auth-service/internal/model"
+	"time"
+)
+
+// LoginThrottleRepository defines the interface for failed login counters
+type LoginThrottleRepository interface {
+	// Find returns the counter for a scope and subject
+	// Returns nil if there were no failed attempts
+	Find(ctx context.Context, scope, subject string) (*model.LoginThrottle, error)
+
+	// RecordFailure counts a failed attempt and returns the new failure count
+	// Counters that have been quiet (no failure or lock) since resetBefore start over at 1
+	RecordFailure(ctx context.Context, scope, subject string, resetBefore time.Time) (int, error)
+
+	// Lock refuses logins for a scope and subject until the given time
+	Lock(ctx context.Context, scope, subject string, until time.Time) error
+
+	// Reset clears the counter (e.g. after a successful login)
+	Reset(ctx context.Context, scope, subject string) error
+}
@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"context"
+	"expense-tracker/auth-service/internal/model"
+)
+
+// OIDCRepository defines the interface for OpenID Connect login data
+type OIDCRepository interface {
+	// CreateLoginState stores a pending login (and drops expired ones)
+	CreateLoginState(ctx context.Context, state *model.OIDCLoginState) error
+
+	// ConsumeLoginState removes and returns a pending login
+	// Returns nil if there is none - each state can only be used once
+	ConsumeLoginState(ctx context.Context, stateHash string) (*model.OIDCLoginState, error)
+
+	// FindIdentity finds the link for a provider account
+	// Returns nil if the account isn't linked to a user
+	FindIdentity(ctx context.Context, issuer, subject string) (*model.OIDCIdentity, error)
+
+	// LinkIdentity links a provider account to a user
+	// The provider verified the email, so the user's email is marked verified too
+	LinkIdentity(ctx context.Context, identity *model.OIDCIdentity) error
+}
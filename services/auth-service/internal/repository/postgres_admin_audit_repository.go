@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"context"
+	"expense-tracker/auth-service/internal/model"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgresAdminAuditRepository implements AdminAuditRepository using PostgreSQL
+type PostgresAdminAuditRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresAdminAuditRepository creates a new PostgreSQL admin audit repository
+func NewPostgresAdminAuditRepository(pool *pgxpool.Pool) AdminAuditRepository {
+	return &PostgresAdminAuditRepository{
+		pool: pool,
+	}
+}
+
+// Record inserts an audit entry
+func (r *PostgresAdminAuditRepository) Record(ctx context.Context, entry *model.AdminAuditEntry) error {
+	query := `
+		INSERT INTO admin_audit_log (id, admin_user_id, admin_email, action, target_user_id, method, path, client_ip, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+
+	_, err := r.pool.Exec(ctx, query,
+		entry.ID,
+		entry.AdminUserID,
+		entry.AdminEmail,
+		entry.Action,
+		entry.TargetUserID,
+		entry.Method,
+		entry.Path,
+		entry.ClientIP,
+		entry.CreatedAt,
+	)
+
+	return err
+}
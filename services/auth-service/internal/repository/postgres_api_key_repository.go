@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"expense-tracker/auth-service/internal/model"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgresAPIKeyRepository implements APIKeyRepository using PostgreSQL
+type PostgresAPIKeyRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresAPIKeyRepository creates a new PostgreSQL API key repository
+func NewPostgresAPIKeyRepository(pool *pgxpool.Pool) APIKeyRepository {
+	return &PostgresAPIKeyRepository{
+		pool: pool,
+	}
+}
+
+// Create inserts a new API key
+func (r *PostgresAPIKeyRepository) Create(ctx context.Context, key *model.APIKey) error {
+	query := `
+		INSERT INTO api_keys (id, user_id, name, prefix, key_hash, scopes, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+
+	_, err := r.pool.Exec(ctx, query,
+		key.ID,
+		key.UserID,
+		key.Name,
+		key.Prefix,
+		key.KeyHash,
+		key.Scopes,
+		key.ExpiresAt,
+		key.CreatedAt,
+	)
+
+	return err
+}
+
+// apiKeyColumns is the column list selected for every API key query
+// Keep in sync with scanAPIKey
+const apiKeyColumns = `id, user_id, name, prefix, key_hash, scopes, expires_at, last_used_at, created_at, revoked_at`
+
+// scanAPIKey copies a row selected with apiKeyColumns into an APIKey
+func scanAPIKey(row pgx.Row) (*model.APIKey, error) {
+	var key model.APIKey
+	var expiresAt, lastUsedAt, revokedAt sql.NullTime
+
+	err := row.Scan(
+		&key.ID,
+		&key.UserID,
+		&key.Name,
+		&key.Prefix,
+		&key.KeyHash,
+		&key.Scopes,
+		&expiresAt,
+		&lastUsedAt,
+		&key.CreatedAt,
+		&revokedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if expiresAt.Valid {
+		key.ExpiresAt = &expiresAt.Time
+	}
+	if lastUsedAt.Valid {
+		key.LastUsedAt = &lastUsedAt.Time
+	}
+	if revokedAt.Valid {
+		key.RevokedAt = &revokedAt.Time
+	}
+
+	return &key, nil
+}
+
+// FindByHash finds an API key by the hash of its value
+func (r *PostgresAPIKeyRepository) FindByHash(ctx context.Context, keyHash string) (*model.APIKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE key_hash = $1`
+
+	key, err := scanAPIKey(r.pool.QueryRow(ctx, query, keyHash))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil // Key not found
+		}
+		return nil, err
+	}
+
+	return key, nil
+}
+
+// ListByUser lists a user's keys that weren't revoked, newest first
+func (r *PostgresAPIKeyRepository) ListByUser(ctx context.Context, userID string) ([]*model.APIKey, error) {
+	query := `SELECT ` + apiKeyColumns + `
+		FROM api_keys
+		WHERE user_id = $1 AND revoked_at IS NULL
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := []*model.APIKey{}
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+// Revoke revokes one of the user's keys
+// The user ID check stops users from revoking other users' keys
+func (r *PostgresAPIKeyRepository) Revoke(ctx context.Context, id, userID string) error {
+	result, err := r.pool.Exec(ctx, `
+		UPDATE api_keys
+		SET revoked_at = $1
+		WHERE id = $2 AND user_id = $3 AND revoked_at IS NULL
+	`, time.Now(), id, userID)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("API key not found")
+	}
+
+	return nil
+}
+
+// TouchLastUsed records that a key was used
+// Skipped if it was already recorded in the last minute, so busy scripts
+// don't write to the database on every request
+func (r *PostgresAPIKeyRepository) TouchLastUsed(ctx context.Context, id string) error {
+	now := time.Now()
+	_, err := r.pool.Exec(ctx, `
+		UPDATE api_keys
+		SET last_used_at = $1
+		WHERE id = $2 AND (last_used_at IS NULL OR last_used_at < $3)
+	`, now, id, now.Add(-time.Minute))
+
+	return err
+}
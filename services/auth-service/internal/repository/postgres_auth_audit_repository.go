@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"expense-tracker/auth-service/internal/model"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgresAuthAuditRepository implements AuthAuditRepository using PostgreSQL
+type PostgresAuthAuditRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresAuthAuditRepository creates a new PostgreSQL auth audit repository
+func NewPostgresAuthAuditRepository(pool *pgxpool.Pool) AuthAuditRepository {
+	return &PostgresAuthAuditRepository{
+		pool: pool,
+	}
+}
+
+// Record inserts an audit event
+// Empty email and reason are stored as NULL
+func (r *PostgresAuthAuditRepository) Record(ctx context.Context, event *model.AuthAuditEvent) error {
+	query := `
+		INSERT INTO auth_audit_events (id, event_type, user_id, email, client_ip, user_agent, outcome, reason, created_at)
+		VALUES ($1, $2, $3, NULLIF($4, ''), $5, $6, $7, NULLIF($8, ''), $9)
+	`
+
+	_, err := r.pool.Exec(ctx, query,
+		event.ID,
+		event.EventType,
+		event.UserID,
+		event.Email,
+		event.ClientIP,
+		event.UserAgent,
+		event.Outcome,
+		event.Reason,
+		event.CreatedAt,
+	)
+
+	return err
+}
+
+// List finds audit events with optional filters and pagination
+func (r *PostgresAuthAuditRepository) List(ctx context.Context, filters *model.ListAuthAuditEventsRequest) ([]*model.AuthAuditEvent, int, error) {
+	where := `WHERE 1=1`
+	args := []interface{}{}
+
+	addFilter := func(condition string, value interface{}) {
+		args = append(args, value)
+		where += fmt.Sprintf(" AND "+condition, len(args))
+	}
+	if filters.UserID != "" {
+		addFilter("user_id = $%d", filters.UserID)
+	}
+	if filters.Email != "" {
+		addFilter("LOWER(email) = LOWER($%d)", filters.Email)
+	}
+	if filters.EventType != "" {
+		addFilter("event_type = $%d", filters.EventType)
+	}
+	if filters.Outcome != "" {
+		addFilter("outcome = $%d", filters.Outcome)
+	}
+	if filters.ClientIP != "" {
+		addFilter("client_ip = $%d", filters.ClientIP)
+	}
+	if filters.From != nil {
+		addFilter("created_at >= $%d", *filters.From)
+	}
+	if filters.To != nil {
+		addFilter("created_at < $%d", *filters.To)
+	}
+
+	// Count total matching events (for pagination)
+	var total int
+	err := r.pool.QueryRow(ctx, `SELECT COUNT(*) FROM auth_audit_events `+where, args...).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Fetch the requested page, newest first
+	offset := (filters.Page - 1) * filters.Limit
+	args = append(args, filters.Limit, offset)
+	query := `
+		SELECT id, event_type, user_id, email, client_ip, user_agent, outcome, reason, created_at
+		FROM auth_audit_events ` + where +
+		fmt.Sprintf(" ORDER BY created_at DESC LIMIT $%d OFFSET $%d", len(args)-1, len(args))
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	events := []*model.AuthAuditEvent{}
+	for rows.Next() {
+		var event model.AuthAuditEvent
+		var userID, email, reason sql.NullString
+
+		err := rows.Scan(
+			&event.ID,
+			&event.EventType,
+			&userID,
+			&email,
+			&event.ClientIP,
+			&event.UserAgent,
+			&event.Outcome,
+			&reason,
+			&event.CreatedAt,
+		)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		if userID.Valid {
+			event.UserID = &userID.String
+		}
+		event.Email = email.String
+		event.Reason = reason.String
+
+		events = append(events, &event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return events, total, nil
+}
@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"expense-tracker/auth-service/internal/model"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgresLoginThrottleRepository implements LoginThrottleRepository using PostgreSQL
+// Counters live in the database so every replica of the service sees the same limits
+type PostgresLoginThrottleRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresLoginThrottleRepository creates a new PostgreSQL login throttle repository
+func NewPostgresLoginThrottleRepository(pool *pgxpool.Pool) LoginThrottleRepository {
+	return &PostgresLoginThrottleRepository{
+		pool: pool,
+	}
+}
+
+// Find returns the counter for a scope and subject
+func (r *PostgresLoginThrottleRepository) Find(ctx context.Context, scope, subject string) (*model.LoginThrottle, error) {
+	query := `
+		SELECT scope, subject, failed_count, last_failed_at, locked_until
+		FROM login_throttles
+		WHERE scope = $1 AND subject = $2
+	`
+
+	throttle := &model.LoginThrottle{}
+	var lockedUntil sql.NullTime
+
+	err := r.pool.QueryRow(ctx, query, scope, subject).Scan(
+		&throttle.Scope,
+		&throttle.Subject,
+		&throttle.FailedCount,
+		&throttle.LastFailedAt,
+		&lockedUntil,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil // No failed attempts
+		}
+		return nil, err
+	}
+
+	// Convert nullable fields
+	if lockedUntil.Valid {
+		throttle.LockedUntil = &lockedUntil.Time
+	}
+
+	return throttle, nil
+}
+
+// RecordFailure counts a failed attempt in a single upsert
+// so concurrent failures can't overwrite each other's counts
+func (r *PostgresLoginThrottleRepository) RecordFailure(ctx context.Context, scope, subject string, resetBefore time.Time) (int, error) {
+	query := `
+		INSERT INTO login_throttles (scope, subject, failed_count, last_failed_at)
+		VALUES ($1, $2, 1, $3)
+		ON CONFLICT (scope, subject) DO UPDATE SET
+			failed_count = CASE
+				WHEN GREATEST(login_throttles.last_failed_at, COALESCE(login_throttles.locked_until, login_throttles.last_failed_at)) < $4
+				THEN 1
+				ELSE login_throttles.failed_count + 1
+			END,
+			last_failed_at = EXCLUDED.last_failed_at
+		RETURNING failed_count
+	`
+
+	var failedCount int
+	err := r.pool.QueryRow(ctx, query, scope, subject, time.Now(), resetBefore).Scan(&failedCount)
+	if err != nil {
+		return 0, err
+	}
+
+	return failedCount, nil
+}
+
+// Lock refuses logins until the given time
+func (r *PostgresLoginThrottleRepository) Lock(ctx context.Context, scope, subject string, until time.Time) error {
+	query := `
+		UPDATE login_throttles
+		SET locked_until = $1
+		WHERE scope = $2 AND subject = $3
+	`
+
+	_, err := r.pool.Exec(ctx, query, until, scope, subject)
+	return err
+}
+
+// Reset deletes the counter
+func (r *PostgresLoginThrottleRepository) Reset(ctx context.Context, scope, subject string) error {
+	query := `
+		DELETE FROM login_throttles
+		WHERE scope = $1 AND subject = $2
+	`
+
+	_, err := r.pool.Exec(ctx, query, scope, subject)
+	return err
+}
@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"context"
+	"expense-tracker/auth-service/internal/model"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgresOIDCRepository implements OIDCRepository using PostgreSQL
+type PostgresOIDCRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresOIDCRepository creates a new PostgreSQL OIDC repository
+func NewPostgresOIDCRepository(pool *pgxpool.Pool) OIDCRepository {
+	return &PostgresOIDCRepository{
+		pool: pool,
+	}
+}
+
+// CreateLoginState stores a pending login
+// Logins that were abandoned at the provider are cleaned up here
+func (r *PostgresOIDCRepository) CreateLoginState(ctx context.Context, state *model.OIDCLoginState) error {
+	_, err := r.pool.Exec(ctx, `DELETE FROM oidc_login_states WHERE expires_at < $1`, time.Now())
+	if err != nil {
+		return err
+	}
+
+	_, err = r.pool.Exec(ctx, `
+		INSERT INTO oidc_login_states (state_hash, nonce, code_verifier, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`, state.StateHash, state.Nonce, state.CodeVerifier, state.ExpiresAt, state.CreatedAt)
+
+	return err
+}
+
+// ConsumeLoginState deletes a pending login and returns it
+// DELETE ... RETURNING makes it single-use, even with concurrent callbacks
+func (r *PostgresOIDCRepository) ConsumeLoginState(ctx context.Context, stateHash string) (*model.OIDCLoginState, error) {
+	var state model.OIDCLoginState
+
+	err := r.pool.QueryRow(ctx, `
+		DELETE FROM oidc_login_states
+		WHERE state_hash = $1
+		RETURNING state_hash, nonce, code_verifier, expires_at, created_at
+	`, stateHash).Scan(
+		&state.StateHash,
+		&state.Nonce,
+		&state.CodeVerifier,
+		&state.ExpiresAt,
+		&state.CreatedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil // Unknown or already used
+		}
+		return nil, err
+	}
+
+	return &state, nil
+}
+
+// FindIdentity finds the link for a provider account
+func (r *PostgresOIDCRepository) FindIdentity(ctx context.Context, issuer, subject string) (*model.OIDCIdentity, error) {
+	var identity model.OIDCIdentity
+
+	err := r.pool.QueryRow(ctx, `
+		SELECT issuer, subject, user_id, email, created_at
+		FROM oidc_identities
+		WHERE issuer = $1 AND subject = $2
+	`, issuer, subject).Scan(
+		&identity.Issuer,
+		&identity.Subject,
+		&identity.UserID,
+		&identity.Email,
+		&identity.CreatedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil // Not linked
+		}
+		return nil, err
+	}
+
+	return &identity, nil
+}
+
+// LinkIdentity links a provider account to a user in one transaction
+func (r *PostgresOIDCRepository) LinkIdentity(ctx context.Context, identity *model.OIDCIdentity) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	// Rollback is a no-op if the transaction was committed
+	defer tx.Rollback(ctx)
+
+	// ON CONFLICT DO NOTHING: a concurrent callback may have linked it already
+	_, err = tx.Exec(ctx, `
+		INSERT INTO oidc_identities (issuer, subject, user_id, email, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (issuer, subject) DO NOTHING
+	`, identity.Issuer, identity.Subject, identity.UserID, identity.Email, identity.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	// Only if the user still has the address the provider verified
+	_, err = tx.Exec(ctx, `
+		UPDATE users
+		SET email_verified_at = $1, updated_at = $1
+		WHERE id = $2 AND email = $3 AND email_verified_at IS NULL AND deleted_at IS NULL
+	`, identity.CreatedAt, identity.UserID, identity.Email)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,171 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"expense-tracker/auth-service/internal/model"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgresRefreshTokenRepository implements RefreshTokenRepository using PostgreSQL
+type PostgresRefreshTokenRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresRefreshTokenRepository creates a new PostgreSQL refresh token repository
+func NewPostgresRefreshTokenRepository(pool *pgxpool.Pool) RefreshTokenRepository {
+	return &PostgresRefreshTokenRepository{
+		pool: pool,
+	}
+}
+
+// Create inserts a new refresh token into the database
+func (r *PostgresRefreshTokenRepository) Create(ctx context.Context, token *model.RefreshToken) error {
+	query := `
+		INSERT INTO refresh_tokens (id, user_id, family_id, token_hash, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+
+	_, err := r.pool.Exec(ctx, query,
+		token.ID,
+		token.UserID,
+		token.FamilyID,
+		token.TokenHash,
+		token.ExpiresAt,
+		token.CreatedAt,
+	)
+
+	return err
+}
+
+// FindByHash finds a refresh token by the hash of its value
+func (r *PostgresRefreshTokenRepository) FindByHash(ctx context.Context, tokenHash string) (*model.RefreshToken, error) {
+	query := `
+		SELECT id, user_id, family_id, token_hash, expires_at, created_at, revoked_at, replaced_by
+		FROM refresh_tokens
+		WHERE token_hash = $1
+	`
+
+	var token model.RefreshToken
+	var revokedAt sql.NullTime
+	var replacedBy sql.NullString
+
+	err := r.pool.QueryRow(ctx, query, tokenHash).Scan(
+		&token.ID,
+		&token.UserID,
+		&token.FamilyID,
+		&token.TokenHash,
+		&token.ExpiresAt,
+		&token.CreatedAt,
+		&revokedAt,
+		&replacedBy,
+	)
+
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil // Token not found
+		}
+		return nil, err
+	}
+
+	// Convert nullable fields
+	if revokedAt.Valid {
+		token.RevokedAt = &revokedAt.Time
+	}
+	if replacedBy.Valid {
+		token.ReplacedBy = &replacedBy.String
+	}
+
+	return &token, nil
+}
+
+// Rotate revokes the old token and inserts its replacement atomically
+// The UPDATE only matches a token that is still active, so two concurrent
+// refreshes with the same token cannot both succeed
+func (r *PostgresRefreshTokenRepository) Rotate(ctx context.Context, oldTokenID string, newToken *model.RefreshToken) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	// Rollback is a no-op if the transaction was committed
+	defer tx.Rollback(ctx)
+
+	revokeQuery := `
+		UPDATE refresh_tokens
+		SET revoked_at = $1, replaced_by = $2
+		WHERE id = $3 AND revoked_at IS NULL
+	`
+
+	result, err := tx.Exec(ctx, revokeQuery, time.Now(), newToken.ID, oldTokenID)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrRefreshTokenAlreadyUsed
+	}
+
+	insertQuery := `
+		INSERT INTO refresh_tokens (id, user_id, family_id, token_hash, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+
+	_, err = tx.Exec(ctx, insertQuery,
+		newToken.ID,
+		newToken.UserID,
+		newToken.FamilyID,
+		newToken.TokenHash,
+		newToken.ExpiresAt,
+		newToken.CreatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// RevokeFamily revokes every active token in a family
+func (r *PostgresRefreshTokenRepository) RevokeFamily(ctx context.Context, familyID string) error {
+	query := `
+		UPDATE refresh_tokens
+		SET revoked_at = $1
+		WHERE family_id = $2 AND revoked_at IS NULL
+	`
+
+	_, err := r.pool.Exec(ctx, query, time.Now(), familyID)
+	return err
+}
+
+// RevokeAllForUser revokes every active token of a user
+func (r *PostgresRefreshTokenRepository) RevokeAllForUser(ctx context.Context, userID string) error {
+	query := `
+		UPDATE refresh_tokens
+		SET revoked_at = $1
+		WHERE user_id = $2 AND revoked_at IS NULL
+	`
+
+	_, err := r.pool.Exec(ctx, query, time.Now(), userID)
+	return err
+}
+
+// IsFamilyActive reports whether a family still has an unrevoked, unexpired token
+func (r *PostgresRefreshTokenRepository) IsFamilyActive(ctx context.Context, familyID string) (bool, error) {
+	query := `
+		SELECT EXISTS(
+			SELECT 1 FROM refresh_tokens
+			WHERE family_id = $1 AND revoked_at IS NULL AND expires_at > $2
+		)
+	`
+
+	var active bool
+	err := r.pool.QueryRow(ctx, query, familyID, time.Now()).Scan(&active)
+	if err != nil {
+		return false, err
+	}
+
+	return active, nil
+}
@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"context"
+	"expense-tracker/auth-service/internal/model"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgresSessionRepository implements SessionRepository using PostgreSQL
+type PostgresSessionRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresSessionRepository creates a new PostgreSQL session repository
+func NewPostgresSessionRepository(pool *pgxpool.Pool) SessionRepository {
+	return &PostgresSessionRepository{
+		pool: pool,
+	}
+}
+
+// sessionColumns is the column list selected for every session query
+const sessionColumns = `s.id, s.user_id, s.user_agent, s.client_ip, s.created_at, s.last_seen_at`
+
+// activeSessionCondition matches sessions whose refresh token family is still usable
+// (the same rule as IsFamilyActive); $1 must be the current time
+const activeSessionCondition = `EXISTS (
+	SELECT 1 FROM refresh_tokens t
+	WHERE t.family_id = s.id AND t.revoked_at IS NULL AND t.expires_at > $1
+)`
+
+// scanSession copies a row selected with sessionColumns into a Session
+func scanSession(row pgx.Row) (*model.Session, error) {
+	var session model.Session
+	err := row.Scan(
+		&session.ID,
+		&session.UserID,
+		&session.UserAgent,
+		&session.ClientIP,
+		&session.CreatedAt,
+		&session.LastSeenAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// Create inserts a session and its first refresh token, then removes the user's ended sessions
+// A session only counts as active once its refresh token exists, so both are
+// committed together - a concurrent login can't see (and prune) a session without one
+func (r *PostgresSessionRepository) Create(ctx context.Context, session *model.Session, refreshToken *model.RefreshToken) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	// Rollback is a no-op if the transaction was committed
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO sessions (id, user_id, user_agent, client_ip, created_at, last_seen_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, session.ID, session.UserID, session.UserAgent, session.ClientIP, session.CreatedAt, session.LastSeenAt)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO refresh_tokens (id, user_id, family_id, token_hash, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, refreshToken.ID, refreshToken.UserID, refreshToken.FamilyID, refreshToken.TokenHash, refreshToken.ExpiresAt, refreshToken.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `
+		DELETE FROM sessions s
+		WHERE s.user_id = $2 AND NOT `+activeSessionCondition,
+		time.Now(), session.UserID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// FindActiveByID finds an active session of a user
+func (r *PostgresSessionRepository) FindActiveByID(ctx context.Context, id, userID string) (*model.Session, error) {
+	query := `SELECT ` + sessionColumns + `
+		FROM sessions s
+		WHERE s.id = $2 AND s.user_id = $3 AND ` + activeSessionCondition
+
+	session, err := scanSession(r.pool.QueryRow(ctx, query, time.Now(), id, userID))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return session, nil
+}
+
+// ListActiveByUser finds the user's active sessions, most recently used first
+func (r *PostgresSessionRepository) ListActiveByUser(ctx context.Context, userID string) ([]*model.Session, error) {
+	query := `SELECT ` + sessionColumns + `
+		FROM sessions s
+		WHERE s.user_id = $2 AND ` + activeSessionCondition + `
+		ORDER BY s.last_seen_at DESC
+	`
+
+	rows, err := r.pool.Query(ctx, query, time.Now(), userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := []*model.Session{}
+	for rows.Next() {
+		session, err := scanSession(rows)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return sessions, nil
+}
+
+// TouchLastSeen updates last_seen_at (and the device details, if given)
+// Skipped if it was updated less than a minute ago, so busy sessions
+// don't cause a write on every request
+func (r *PostgresSessionRepository) TouchLastSeen(ctx context.Context, id, userAgent, clientIP string) error {
+	now := time.Now()
+	_, err := r.pool.Exec(ctx, `
+		UPDATE sessions
+		SET last_seen_at = $1,
+			user_agent = COALESCE(NULLIF($2, ''), user_agent),
+			client_ip = COALESCE(NULLIF($3, ''), client_ip)
+		WHERE id = $4 AND last_seen_at < $5
+	`, now, userAgent, clientIP, id, now.Add(-time.Minute))
+
+	return err
+}
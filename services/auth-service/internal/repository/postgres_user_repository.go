@@ -3,9 +3,14 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"expense-tracker/auth-service/internal/model"
+	"fmt"
+	"strings"
+	"time"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -32,8 +37,8 @@ func (r *PostgresUserRepository) Create(ctx context.Context, user *model.User) e
 	// SQL query with placeholders ($1, $2, etc.) - this prevents SQL injection!
 	// In Go, we use $1, $2 for PostgreSQL (MySQL uses ?)
 	query := `
-		INSERT INTO users (id, email, password_hash, name, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO users (id, email, password_hash, has_password, name, role, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 	`
 
 	// Execute the query
@@ -43,12 +48,19 @@ func (r *PostgresUserRepository) Create(ctx context.Context, user *model.User) e
 		user.ID,
 		user.Email,
 		user.PasswordHash,
+		user.HasPassword,
 		user.Name,
+		user.Role,
 		user.CreatedAt,
 		user.UpdatedAt,
 	)
 
 	if err != nil {
+		// The email is still taken by a deleted account that wasn't purged yet
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" { // unique_violation
+			return fmt.Errorf("user with this email already exists")
+		}
 		// In Go, we return errors - no exceptions!
 		// The caller will check if err != nil
 		return err
@@ -57,41 +69,47 @@ func (r *PostgresUserRepository) Create(ctx context.Context, user *model.User) e
 	return nil
 }
 
-// FindByEmail finds a user by email
-func (r *PostgresUserRepository) FindByEmail(ctx context.Context, email string) (*model.User, error) {
-	// SQL query to find user by email
-	// WHERE deleted_at IS NULL means we only get active users (soft delete)
-	query := `
-		SELECT id, email, password_hash, name, created_at, updated_at, deleted_at
-		FROM users
-		WHERE email = $1 AND deleted_at IS NULL
-	`
+// userColumns is the column list selected for every user query
+// Keep in sync with scanUser
+const userColumns = `id, email, password_hash, has_password, name, role, email_verified_at, mfa_secret, mfa_enabled_at, created_at, updated_at, deleted_at`
 
+// scanUser copies a row selected with userColumns into a User
+// pgx.Row is implemented by both QueryRow results and Rows
+func scanUser(row pgx.Row) (*model.User, error) {
 	var user model.User
+	var emailVerifiedAt sql.NullTime
+	var mfaSecret sql.NullString
+	var mfaEnabledAt sql.NullTime
 	var deletedAt sql.NullTime // sql.NullTime handles nullable timestamps
 
-	// QueryRow executes a query that returns at most one row
-	// Scan copies the columns into the variables
-	err := r.pool.QueryRow(ctx, query, email).Scan(
+	err := row.Scan(
 		&user.ID,
 		&user.Email,
 		&user.PasswordHash,
+		&user.HasPassword,
 		&user.Name,
+		&user.Role,
+		&emailVerifiedAt,
+		&mfaSecret,
+		&mfaEnabledAt,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 		&deletedAt,
 	)
-
 	if err != nil {
-		// pgx.ErrNoRows is returned when no rows match
-		if err == pgx.ErrNoRows {
-			return nil, nil // User not found - return nil user, nil error
-		}
-		// Other database errors
 		return nil, err
 	}
 
 	// Convert sql.NullTime to *time.Time
+	if emailVerifiedAt.Valid {
+		user.EmailVerifiedAt = &emailVerifiedAt.Time
+	}
+	if mfaSecret.Valid {
+		user.MFASecret = mfaSecret.String
+	}
+	if mfaEnabledAt.Valid {
+		user.MFAEnabledAt = &mfaEnabledAt.Time
+	}
 	if deletedAt.Valid {
 		user.DeletedAt = &deletedAt.Time
 	}
@@ -99,37 +117,575 @@ func (r *PostgresUserRepository) FindByEmail(ctx context.Context, email string)
 	return &user, nil
 }
 
+// FindByEmail finds a user by email
+func (r *PostgresUserRepository) FindByEmail(ctx context.Context, email string) (*model.User, error) {
+	// SQL query to find user by email
+	// WHERE deleted_at IS NULL means we only get active users (soft delete)
+	query := `SELECT ` + userColumns + `
+		FROM users
+		WHERE email = $1 AND deleted_at IS NULL
+	`
+
+	// QueryRow executes a query that returns at most one row
+	// scanUser copies the columns into a User
+	user, err := scanUser(r.pool.QueryRow(ctx, query, email))
+	if err != nil {
+		// pgx.ErrNoRows is returned when no rows match
+		if err == pgx.ErrNoRows {
+			return nil, nil // User not found - return nil user, nil error
+		}
+		// Other database errors
+		return nil, err
+	}
+
+	return user, nil
+}
+
 // FindByID finds a user by ID
 func (r *PostgresUserRepository) FindByID(ctx context.Context, id string) (*model.User, error) {
-	query := `
-		SELECT id, email, password_hash, name, created_at, updated_at, deleted_at
+	query := `SELECT ` + userColumns + `
 		FROM users
 		WHERE id = $1 AND deleted_at IS NULL
 	`
 
-	var user model.User
-	var deletedAt sql.NullTime
+	user, err := scanUser(r.pool.QueryRow(ctx, query, id))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
 
-	err := r.pool.QueryRow(ctx, query, id).Scan(
-		&user.ID,
-		&user.Email,
-		&user.PasswordHash,
-		&user.Name,
-		&user.CreatedAt,
-		&user.UpdatedAt,
-		&deletedAt,
+	return user, nil
+}
+
+// List finds users with optional email filter and pagination
+// Returns the users for the requested page and the total count
+func (r *PostgresUserRepository) List(ctx context.Context, filters *model.ListUsersRequest) ([]*model.User, int, error) {
+	where := `WHERE deleted_at IS NULL`
+	args := []interface{}{}
+
+	// Case-insensitive substring match on email
+	if filters.Email != "" {
+		args = append(args, "%"+filters.Email+"%")
+		where += fmt.Sprintf(" AND email ILIKE $%d", len(args))
+	}
+
+	// Count total matching users (for pagination)
+	var total int
+	err := r.pool.QueryRow(ctx, `SELECT COUNT(*) FROM users `+where, args...).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Fetch the requested page, newest first
+	offset := (filters.Page - 1) * filters.Limit
+	args = append(args, filters.Limit, offset)
+	query := `SELECT ` + userColumns + ` FROM users ` + where +
+		fmt.Sprintf(" ORDER BY created_at DESC LIMIT $%d OFFSET $%d", len(args)-1, len(args))
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	users := []*model.User{}
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		users = append(users, user)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return users, total, nil
+}
+
+// UpdateName changes the user's display name
+func (r *PostgresUserRepository) UpdateName(ctx context.Context, userID, name string) error {
+	result, err := r.pool.Exec(ctx, `
+		UPDATE users
+		SET name = $1, updated_at = $2
+		WHERE id = $3 AND deleted_at IS NULL
+	`, name, time.Now(), userID)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("user not found")
+	}
+
+	return nil
+}
+
+// UpdatePassword sets a new password hash in one transaction
+// Outstanding password reset tokens are invalidated too
+func (r *PostgresUserRepository) UpdatePassword(ctx context.Context, userID, passwordHash string) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	// Rollback is a no-op if the transaction was committed
+	defer tx.Rollback(ctx)
+
+	now := time.Now()
+
+	// Update the password
+	result, err := tx.Exec(ctx, `
+		UPDATE users
+		SET password_hash = $1, has_password = TRUE, updated_at = $2
+		WHERE id = $3 AND deleted_at IS NULL
+	`, passwordHash, now, userID)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("user not found")
+	}
+
+	// A reset link requested before the change shouldn't undo it
+	_, err = tx.Exec(ctx, `
+		UPDATE password_reset_tokens
+		SET used_at = $1
+		WHERE user_id = $2 AND used_at IS NULL
+	`, now, userID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// SoftDelete marks the user as deleted
+// Every lookup filters on deleted_at IS NULL, so the account is gone from then on
+func (r *PostgresUserRepository) SoftDelete(ctx context.Context, userID string, deletedAt time.Time) error {
+	result, err := r.pool.Exec(ctx, `
+		UPDATE users
+		SET deleted_at = $1, updated_at = $1
+		WHERE id = $2 AND deleted_at IS NULL
+	`, deletedAt, userID)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("user not found")
+	}
+
+	return nil
+}
+
+// FindDeletedBefore finds soft-deleted users, oldest deletion first
+func (r *PostgresUserRepository) FindDeletedBefore(ctx context.Context, before time.Time, limit int) ([]*model.User, error) {
+	query := `SELECT ` + userColumns + `
+		FROM users
+		WHERE deleted_at IS NOT NULL AND deleted_at < $1
+		ORDER BY deleted_at
+		LIMIT $2
+	`
+
+	rows, err := r.pool.Query(ctx, query, before, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := []*model.User{}
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
+// PurgeDeleted removes a soft-deleted user in one transaction
+// Rows referencing the user are deleted first (foreign keys)
+func (r *PostgresUserRepository) PurgeDeleted(ctx context.Context, user *model.User) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	// Rollback is a no-op if the transaction was committed
+	defer tx.Rollback(ctx)
+
+	for _, table := range []string{"refresh_tokens", "password_reset_tokens", "email_verification_tokens", "mfa_recovery_codes", "api_keys", "oidc_identities", "sessions"} {
+		if _, err := tx.Exec(ctx, `DELETE FROM `+table+` WHERE user_id = $1`, user.ID); err != nil {
+			return err
+		}
+	}
+
+	// Failed login counters are keyed by the (normalized) email address
+	_, err = tx.Exec(ctx, `
+		DELETE FROM login_throttles
+		WHERE scope = $1 AND subject = $2
+	`, model.ThrottleScopeEmail, strings.ToLower(strings.TrimSpace(user.Email)))
+	if err != nil {
+		return err
+	}
+
+	// The audit log is append-only but must not keep the erased user's personal data:
+	// their events stay (user_id no longer leads anywhere) without email, IP and user agent.
+	// SET LOCAL lifts the append-only rule for this transaction only
+	if _, err := tx.Exec(ctx, `SET LOCAL auth_audit.erasure = 'on'`); err != nil {
+		return err
+	}
+	_, err = tx.Exec(ctx, `
+		UPDATE auth_audit_events
+		SET email = NULL, client_ip = '', user_agent = ''
+		WHERE user_id = $1 OR (user_id IS NULL AND LOWER(email) = LOWER($2))
+	`, user.ID, user.Email)
+	if err != nil {
+		return err
+	}
+
+	// Only deleted users can be purged - an active account is never removed here
+	result, err := tx.Exec(ctx, `DELETE FROM users WHERE id = $1 AND deleted_at IS NOT NULL`, user.ID)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("user not found")
+	}
+
+	return tx.Commit(ctx)
+}
+
+// CreatePasswordResetToken stores a new password reset token
+func (r *PostgresUserRepository) CreatePasswordResetToken(ctx context.Context, token *model.PasswordResetToken) error {
+	query := `
+		INSERT INTO password_reset_tokens (id, user_id, token_hash, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+
+	_, err := r.pool.Exec(ctx, query,
+		token.ID,
+		token.UserID,
+		token.TokenHash,
+		token.ExpiresAt,
+		token.CreatedAt,
+	)
+
+	return err
+}
+
+// FindPasswordResetToken finds a password reset token by the hash of its value
+func (r *PostgresUserRepository) FindPasswordResetToken(ctx context.Context, tokenHash string) (*model.PasswordResetToken, error) {
+	query := `
+		SELECT id, user_id, token_hash, expires_at, created_at, used_at
+		FROM password_reset_tokens
+		WHERE token_hash = $1
+	`
+
+	var token model.PasswordResetToken
+	var usedAt sql.NullTime
+
+	err := r.pool.QueryRow(ctx, query, tokenHash).Scan(
+		&token.ID,
+		&token.UserID,
+		&token.TokenHash,
+		&token.ExpiresAt,
+		&token.CreatedAt,
+		&usedAt,
 	)
 
 	if err != nil {
 		if err == pgx.ErrNoRows {
-			return nil, nil
+			return nil, nil // Token not found
 		}
 		return nil, err
 	}
 
-	if deletedAt.Valid {
-		user.DeletedAt = &deletedAt.Time
+	if usedAt.Valid {
+		token.UsedAt = &usedAt.Time
 	}
 
-	return &user, nil
+	return &token, nil
+}
+
+// ResetPassword consumes a reset token and updates the password in one transaction
+func (r *PostgresUserRepository) ResetPassword(ctx context.Context, tokenID, userID, passwordHash string) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	// Rollback is a no-op if the transaction was committed
+	defer tx.Rollback(ctx)
+
+	now := time.Now()
+
+	// Mark the token as used - only succeeds once, even with concurrent requests
+	result, err := tx.Exec(ctx, `
+		UPDATE password_reset_tokens
+		SET used_at = $1
+		WHERE id = $2 AND user_id = $3 AND used_at IS NULL
+	`, now, tokenID, userID)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("password reset token already used")
+	}
+
+	// Update the password
+	result, err = tx.Exec(ctx, `
+		UPDATE users
+		SET password_hash = $1, has_password = TRUE, updated_at = $2
+		WHERE id = $3 AND deleted_at IS NULL
+	`, passwordHash, now, userID)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("user not found")
+	}
+
+	// Invalidate any other outstanding reset tokens for this user
+	_, err = tx.Exec(ctx, `
+		UPDATE password_reset_tokens
+		SET used_at = $1
+		WHERE user_id = $2 AND used_at IS NULL
+	`, now, userID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// CreateEmailVerificationToken stores a new email verification token
+func (r *PostgresUserRepository) CreateEmailVerificationToken(ctx context.Context, token *model.EmailVerificationToken) error {
+	query := `
+		INSERT INTO email_verification_tokens (id, user_id, email, purpose, token_hash, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+
+	_, err := r.pool.Exec(ctx, query,
+		token.ID,
+		token.UserID,
+		token.Email,
+		token.Purpose,
+		token.TokenHash,
+		token.ExpiresAt,
+		token.CreatedAt,
+	)
+
+	return err
+}
+
+// FindEmailVerificationToken finds an email verification token by the hash of its value
+func (r *PostgresUserRepository) FindEmailVerificationToken(ctx context.Context, tokenHash string) (*model.EmailVerificationToken, error) {
+	query := `
+		SELECT id, user_id, email, purpose, token_hash, expires_at, created_at, used_at
+		FROM email_verification_tokens
+		WHERE token_hash = $1
+	`
+
+	var token model.EmailVerificationToken
+	var usedAt sql.NullTime
+
+	err := r.pool.QueryRow(ctx, query, tokenHash).Scan(
+		&token.ID,
+		&token.UserID,
+		&token.Email,
+		&token.Purpose,
+		&token.TokenHash,
+		&token.ExpiresAt,
+		&token.CreatedAt,
+		&usedAt,
+	)
+
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil // Token not found
+		}
+		return nil, err
+	}
+
+	if usedAt.Valid {
+		token.UsedAt = &usedAt.Time
+	}
+
+	return &token, nil
+}
+
+// VerifyEmail consumes a verification token and marks the user's email as verified
+// in one transaction. The user is only updated if their current email still
+// matches the address the token was sent to.
+// Email change tokens switch the user to the new address instead.
+func (r *PostgresUserRepository) VerifyEmail(ctx context.Context, token *model.EmailVerificationToken) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	// Rollback is a no-op if the transaction was committed
+	defer tx.Rollback(ctx)
+
+	now := time.Now()
+
+	// Mark the token as used - only succeeds once, even with concurrent requests
+	result, err := tx.Exec(ctx, `
+		UPDATE email_verification_tokens
+		SET used_at = $1
+		WHERE id = $2 AND used_at IS NULL
+	`, now, token.ID)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("email verification token already used")
+	}
+
+	if token.Purpose == model.EmailTokenPurposeChange {
+		return r.changeEmail(ctx, tx, token, now)
+	}
+
+	// Mark the address as verified
+	result, err = tx.Exec(ctx, `
+		UPDATE users
+		SET email_verified_at = $1, updated_at = $1
+		WHERE id = $2 AND email = $3 AND deleted_at IS NULL
+	`, now, token.UserID, token.Email)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("user not found or email changed")
+	}
+
+	return tx.Commit(ctx)
+}
+
+// changeEmail finishes VerifyEmail for an email change token
+// The new address is verified by the token itself
+func (r *PostgresUserRepository) changeEmail(ctx context.Context, tx pgx.Tx, token *model.EmailVerificationToken, now time.Time) error {
+	result, err := tx.Exec(ctx, `
+		UPDATE users
+		SET email = $1, email_verified_at = $2, updated_at = $2
+		WHERE id = $3 AND deleted_at IS NULL
+	`, token.Email, now, token.UserID)
+	if err != nil {
+		// Someone registered with the address after the change was requested
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" { // unique_violation
+			return fmt.Errorf("email already in use")
+		}
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("user not found")
+	}
+
+	// Other outstanding tokens were sent to addresses the user no longer has
+	_, err = tx.Exec(ctx, `
+		UPDATE email_verification_tokens
+		SET used_at = $1
+		WHERE user_id = $2 AND used_at IS NULL
+	`, now, token.UserID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// SetMFASecret stores a new (not yet confirmed) TOTP secret for the user
+// Refuses to overwrite the secret once MFA is enabled
+func (r *PostgresUserRepository) SetMFASecret(ctx context.Context, userID, secret string) error {
+	result, err := r.pool.Exec(ctx, `
+		UPDATE users
+		SET mfa_secret = $1, mfa_last_used_step = NULL, updated_at = $2
+		WHERE id = $3 AND mfa_enabled_at IS NULL AND deleted_at IS NULL
+	`, secret, time.Now(), userID)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("MFA already enabled or user not found")
+	}
+
+	return nil
+}
+
+// EnableMFA turns on MFA and replaces the user's recovery codes in one transaction
+func (r *PostgresUserRepository) EnableMFA(ctx context.Context, userID string, step int64, codeHashes []string) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	// Rollback is a no-op if the transaction was committed
+	defer tx.Rollback(ctx)
+
+	now := time.Now()
+
+	// Only succeeds once - a concurrent confirm loses here
+	// The confirmation code's step is recorded so it can't be replayed at login
+	result, err := tx.Exec(ctx, `
+		UPDATE users
+		SET mfa_enabled_at = $1, mfa_last_used_step = $2, updated_at = $1
+		WHERE id = $3 AND mfa_secret IS NOT NULL AND mfa_enabled_at IS NULL AND deleted_at IS NULL
+	`, now, step, userID)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("MFA already enabled or not enrolled")
+	}
+
+	// Drop codes left over from any earlier enrollment
+	_, err = tx.Exec(ctx, `DELETE FROM mfa_recovery_codes WHERE user_id = $1`, userID)
+	if err != nil {
+		return err
+	}
+
+	for _, codeHash := range codeHashes {
+		_, err = tx.Exec(ctx, `
+			INSERT INTO mfa_recovery_codes (user_id, code_hash, created_at)
+			VALUES ($1, $2, $3)
+		`, userID, codeHash, now)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// UseTOTPStep records that a TOTP code was accepted
+// Returns false if that step (or a later one) was already used
+func (r *PostgresUserRepository) UseTOTPStep(ctx context.Context, userID string, step int64) (bool, error) {
+	result, err := r.pool.Exec(ctx, `
+		UPDATE users
+		SET mfa_last_used_step = $1
+		WHERE id = $2 AND (mfa_last_used_step IS NULL OR mfa_last_used_step < $1)
+	`, step, userID)
+	if err != nil {
+		return false, err
+	}
+
+	return result.RowsAffected() > 0, nil
+}
+
+// UseRecoveryCode consumes one of the user's recovery codes
+// Returns false if the code doesn't exist or was already used
+func (r *PostgresUserRepository) UseRecoveryCode(ctx context.Context, userID, codeHash string) (bool, error) {
+	result, err := r.pool.Exec(ctx, `
+		UPDATE mfa_recovery_codes
+		SET used_at = $1
+		WHERE user_id = $2 AND code_hash = $3 AND used_at IS NULL
+	`, time.Now(), userID, codeHash)
+	if err != nil {
+		return false, err
+	}
+
+	return result.RowsAffected() > 0, nil
 }
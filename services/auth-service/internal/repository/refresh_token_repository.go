@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"expense-tracker/auth-service/internal/model"
+)
+
+// ErrRefreshTokenAlreadyUsed is returned by Rotate when the token was already
+// rotated or revoked by a concurrent request
+var ErrRefreshTokenAlreadyUsed = errors.New("refresh token already used")
+
+// RefreshTokenRepository defines the interface for refresh token data operations
+type RefreshTokenRepository interface {
+	// Create inserts a new refresh token into the database
+	Create(ctx context.Context, token *model.RefreshToken) error
+
+	// FindByHash finds a refresh token by the hash of its value
+	// Returns nil if no token matches (revoked tokens are still returned)
+	FindByHash(ctx context.Context, tokenHash string) (*model.RefreshToken, error)
+
+	// Rotate revokes the old token and stores its replacement in one transaction
+	// Returns ErrRefreshTokenAlreadyUsed if the old token is no longer active
+	Rotate(ctx context.Context, oldTokenID string, newToken *model.RefreshToken) error
+
+	// RevokeFamily revokes every active token in a family (logout / reuse detection)
+	RevokeFamily(ctx context.Context, familyID string) error
+
+	// RevokeAllForUser revokes every active token of a user (e.g. after a password reset)
+	RevokeAllForUser(ctx context.Context, userID string) error
+
+	// IsFamilyActive reports whether a family still has a usable token
+	// Access tokens carry the family ID, so this tells us if their session is still alive
+	IsFamilyActive(ctx context.Context, familyID string) (bool, error)
+}
@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"context"
+	"expense-tracker/auth-service/internal/model"
+)
+
+// SessionRepository defines the interface for session (device) data
+// Sessions hold device details only - revoking one means revoking its
+// refresh token family (RefreshTokenRepository.RevokeFamily)
+type SessionRepository interface {
+	// Create stores a new session together with its first refresh token
+	// The user's ended sessions are cleaned up in the same transaction
+	Create(ctx context.Context, session *model.Session, refreshToken *model.RefreshToken) error
+
+	// FindActiveByID finds an active session of a user
+	// Returns nil if there is none (unknown, ended, or someone else's)
+	FindActiveByID(ctx context.Context, id, userID string) (*model.Session, error)
+
+	// ListActiveByUser finds the user's active sessions, most recently used first
+	ListActiveByUser(ctx context.Context, userID string) ([]*model.Session, error)
+
+	// TouchLastSeen records that the session was used
+	// Writes at most once a minute per session; empty userAgent/clientIP keep the stored values
+	TouchLastSeen(ctx context.Context, id, userAgent, clientIP string) error
+}
@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"expense-tracker/auth-service/internal/model"
+	"time"
 )
 
 // UserRepository defines the interface for user data operations
@@ -25,4 +26,65 @@ type UserRepository interface {
 
 	// FindByID finds a user by their ID
 	FindByID(ctx context.Context, id string) (*model.User, error)
+
+	// List finds users (for admins) with an optional email filter and pagination
+	// Returns the page of users and the total number of matches
+	List(ctx context.Context, filters *model.ListUsersRequest) ([]*model.User, int, error)
+
+	// UpdateName changes the user's display name
+	UpdateName(ctx context.Context, userID, name string) error
+
+	// UpdatePassword sets a new password hash and invalidates outstanding reset tokens
+	UpdatePassword(ctx context.Context, userID, passwordHash string) error
+
+	// SoftDelete marks the user as deleted - they disappear from every lookup
+	// The row is only removed later by PurgeDeleted (after the grace period)
+	SoftDelete(ctx context.Context, userID string, deletedAt time.Time) error
+
+	// FindDeletedBefore returns soft-deleted users whose deleted_at is before the given time
+	FindDeletedBefore(ctx context.Context, before time.Time, limit int) ([]*model.User, error)
+
+	// PurgeDeleted removes a soft-deleted user and everything that belongs to them
+	PurgeDeleted(ctx context.Context, user *model.User) error
+
+	// CreatePasswordResetToken stores a new (hashed) password reset token
+	CreatePasswordResetToken(ctx context.Context, token *model.PasswordResetToken) error
+
+	// FindPasswordResetToken finds a password reset token by the hash of its value
+	// Returns nil if no token matches (used tokens are still returned)
+	FindPasswordResetToken(ctx context.Context, tokenHash string) (*model.PasswordResetToken, error)
+
+	// ResetPassword consumes a reset token and sets the user's new password hash
+	// in one transaction. All of the user's other outstanding reset tokens are
+	// invalidated too. Returns an error if the token was already used.
+	ResetPassword(ctx context.Context, tokenID, userID, passwordHash string) error
+
+	// CreateEmailVerificationToken stores a new (hashed) email verification token
+	CreateEmailVerificationToken(ctx context.Context, token *model.EmailVerificationToken) error
+
+	// FindEmailVerificationToken finds an email verification token by the hash of its value
+	// Returns nil if no token matches (used tokens are still returned)
+	FindEmailVerificationToken(ctx context.Context, tokenHash string) (*model.EmailVerificationToken, error)
+
+	// VerifyEmail consumes the token and marks the user's email as verified
+	// Email change tokens switch the user to the token's address instead
+	// Returns an error if the token was already used, the user's email changed,
+	// or (for a change) the new address was taken in the meantime
+	VerifyEmail(ctx context.Context, token *model.EmailVerificationToken) error
+
+	// SetMFASecret stores a pending TOTP secret (MFA enrollment)
+	// Returns an error if MFA is already enabled for the user
+	SetMFASecret(ctx context.Context, userID, secret string) error
+
+	// EnableMFA confirms enrollment: marks MFA enabled, records the TOTP step
+	// used to confirm, and replaces the user's recovery codes (stored hashed)
+	EnableMFA(ctx context.Context, userID string, step int64, codeHashes []string) error
+
+	// UseTOTPStep records an accepted TOTP time step
+	// Returns false if the step was already used (replayed code)
+	UseTOTPStep(ctx context.Context, userID string, step int64) (bool, error)
+
+	// UseRecoveryCode marks a recovery code as used
+	// Returns false if no unused code with that hash exists for the user
+	UseRecoveryCode(ctx context.Context, userID, codeHash string) (bool, error)
 }
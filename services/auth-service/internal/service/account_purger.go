@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"expense-tracker/auth-service/internal/model"
+	"expense-tracker/auth-service/internal/repository"
+	"log"
+	"time"
+)
+
+// purgeBatchSize is how many deleted accounts are purged per run
+const purgeBatchSize = 100
+
+// AccountPurger erases deleted accounts once their grace period is over
+// Until then a deleted account only has deleted_at set (see AuthService.DeleteAccount)
+type AccountPurger struct {
+	userRepo       repository.UserRepository
+	gracePeriod    time.Duration
+	interval       time.Duration
+	eventPublisher *EventPublisher // Optional - can be nil if not configured
+}
+
+// NewAccountPurger creates a new account purger
+func NewAccountPurger(userRepo repository.UserRepository, gracePeriod, interval time.Duration) *AccountPurger {
+	return &AccountPurger{
+		userRepo:    userRepo,
+		gracePeriod: gracePeriod,
+		interval:    interval,
+	}
+}
+
+// SetEventPublisher sets the event publisher (optional)
+func (p *AccountPurger) SetEventPublisher(publisher *EventPublisher) {
+	p.eventPublisher = publisher
+}
+
+// Run purges due accounts periodically until ctx is cancelled
+// Start it in a goroutine
+func (p *AccountPurger) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := p.PurgeDue(ctx); err != nil {
+				log.Printf("Warning: failed to purge deleted accounts: %v (will retry)", err)
+			}
+		}
+	}
+}
+
+// PurgeDue purges every account that was deleted more than the grace period ago
+func (p *AccountPurger) PurgeDue(ctx context.Context) error {
+	for {
+		users, err := p.userRepo.FindDeletedBefore(ctx, time.Now().Add(-p.gracePeriod), purgeBatchSize)
+		if err != nil {
+			return err
+		}
+
+		for _, user := range users {
+			if err := p.purge(ctx, user); err != nil {
+				return err
+			}
+		}
+
+		if len(users) < purgeBatchSize {
+			return nil
+		}
+	}
+}
+
+// purge publishes user.deleted once more and then removes the account
+// The event is published synchronously: if it can't be sent, the account is
+// kept and retried on the next run, so the other services never miss an erasure
+func (p *AccountPurger) purge(ctx context.Context, user *model.User) error {
+	if p.eventPublisher != nil {
+		event := newUserDeletedEvent(user, *user.DeletedAt, user.DeletedAt.Add(p.gracePeriod))
+		// final marks the repeat - notification-service already emailed the user
+		event.Data["final"] = true
+		if err := p.eventPublisher.PublishEvent(ctx, event); err != nil {
+			return err
+		}
+	}
+
+	if err := p.userRepo.PurgeDeleted(ctx, user); err != nil {
+		return err
+	}
+
+	log.Printf("Purged deleted account %s", user.ID)
+	return nil
+}
+
+// newUserDeletedEvent builds the user.deleted event
+// expense-service and receipt-service hide the user's data when they receive it
+// and erase it after purge_after; notification-service emails the user
+func newUserDeletedEvent(user *model.User, deletedAt, purgeAfter time.Time) *Event {
+	return &Event{
+		EventType: "user.deleted",
+		UserID:    user.ID,
+		UserEmail: user.Email,
+		Timestamp: time.Now(),
+		Data: map[string]interface{}{
+			"user_id":     user.ID,
+			"email":       user.Email,
+			"name":        user.Name,
+			"deleted_at":  deletedAt.Format(time.RFC3339),
+			"purge_after": purgeAfter.Format(time.RFC3339),
+		},
+	}
+}
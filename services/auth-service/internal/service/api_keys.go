@@ -0,0 +1,169 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"expense-tracker/auth-service/internal/model"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// maxAPIKeysPerUser limits how many keys a user can have (revoked keys don't count)
+const maxAPIKeysPerUser = 20
+
+// apiKeyPrefixLength is how many characters of a key are stored in plain text
+// "etk_" plus 8 random characters - enough to recognize a key, useless for guessing it
+const apiKeyPrefixLength = len(model.APIKeyPrefix) + 8
+
+// CreateAPIKey creates a personal API key for the user
+// The plain key is returned only here - we store its hash, like refresh tokens
+func (s *AuthService) CreateAPIKey(ctx context.Context, userID string, req *model.CreateAPIKeyRequest) (*model.CreateAPIKeyResponse, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, errors.New("name is required")
+	}
+	if len(name) > 100 {
+		return nil, errors.New("name must be at most 100 characters")
+	}
+
+	scopes, err := normalizeScopes(req.Scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	var expiration time.Duration
+	if req.ExpiresInDays != nil {
+		if *req.ExpiresInDays < 1 {
+			return nil, errors.New("expires_in_days must be at least 1")
+		}
+		expiration = time.Duration(*req.ExpiresInDays) * 24 * time.Hour
+	}
+
+	existing, err := s.apiKeyRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) >= maxAPIKeysPerUser {
+		return nil, fmt.Errorf("too many API keys (at most %d) - revoke one first", maxAPIKeysPerUser)
+	}
+
+	secret, err := generateSecureToken()
+	if err != nil {
+		return nil, err
+	}
+	plainKey := model.APIKeyPrefix + secret
+
+	key := model.NewAPIKey(userID, name, plainKey[:apiKeyPrefixLength], hashToken(plainKey), scopes, expiration)
+	if err := s.apiKeyRepo.Create(ctx, key); err != nil {
+		return nil, err
+	}
+
+	return &model.CreateAPIKeyResponse{
+		APIKey: key,
+		Key:    plainKey,
+	}, nil
+}
+
+// ListAPIKeys lists the user's API keys (without the keys themselves)
+func (s *AuthService) ListAPIKeys(ctx context.Context, userID string) (*model.ListAPIKeysResponse, error) {
+	keys, err := s.apiKeyRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.ListAPIKeysResponse{APIKeys: keys}, nil
+}
+
+// RevokeAPIKey revokes one of the user's API keys - it stops working immediately
+func (s *AuthService) RevokeAPIKey(ctx context.Context, userID, keyID string) error {
+	// Not a UUID can't be one of the user's keys
+	if _, err := uuid.Parse(keyID); err != nil {
+		return errors.New("API key not found")
+	}
+
+	return s.apiKeyRepo.Revoke(ctx, keyID, userID)
+}
+
+// validateAPIKey is ValidateToken for API keys
+func (s *AuthService) validateAPIKey(ctx context.Context, plainKey string) (*model.ValidateResponse, error) {
+	key, err := s.apiKeyRepo.FindByHash(ctx, hashToken(plainKey))
+	if err != nil {
+		return nil, err
+	}
+	if key == nil {
+		s.auditValidationFailure(ctx, "", "", "unknown API key")
+		return &model.ValidateResponse{
+			Valid: false,
+		}, nil
+	}
+	if !key.IsActive() {
+		s.auditValidationFailure(ctx, key.UserID, "", "API key revoked or expired")
+		return &model.ValidateResponse{
+			Valid: false,
+		}, nil
+	}
+
+	// Keys of deleted users stop working with the account
+	user, err := s.userRepo.FindByID(ctx, key.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		s.auditValidationFailure(ctx, key.UserID, "", "user not found")
+		return &model.ValidateResponse{
+			Valid: false,
+		}, nil
+	}
+
+	if s.settings.AuditSuccessfulValidations {
+		s.audit(ctx, model.AuthEventTokenValidate, user.ID, user.Email, model.AuthOutcomeSuccess, "API key "+key.Prefix)
+	}
+
+	// Failing to record usage shouldn't fail the request
+	if err := s.apiKeyRepo.TouchLastUsed(ctx, key.ID); err != nil {
+		log.Printf("Failed to record API key usage for %s: %v", key.ID, err)
+	}
+
+	// API keys never carry the admin role: a leaked automation key of an admin
+	// must not open the admin endpoints of other services to every user's data
+	return &model.ValidateResponse{
+		Valid:    true,
+		UserID:   user.ID,
+		Email:    user.Email,
+		Role:     model.RoleUser,
+		APIKeyID: key.ID,
+		Scopes:   key.Scopes,
+	}, nil
+}
+
+// normalizeScopes checks requested scopes and removes duplicates
+// nil or empty means the key is not limited
+func normalizeScopes(requested []string) ([]string, error) {
+	scopes := []string{}
+	seen := make(map[string]bool)
+
+	for _, scope := range requested {
+		scope = strings.TrimSpace(scope)
+		valid := false
+		for _, known := range model.APIKeyScopes {
+			if scope == known {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return nil, fmt.Errorf("invalid scope %q (valid scopes: %s)", scope, strings.Join(model.APIKeyScopes, ", "))
+		}
+
+		if !seen[scope] {
+			seen[scope] = true
+			scopes = append(scopes, scope)
+		}
+	}
+
+	return scopes, nil
+}
@@ -0,0 +1,159 @@
+package service
+
+import (
+	"context"
+	"expense-tracker/auth-service/internal/model"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// validationAuditInterval is how often the same failed token validation
+// (same client and reason) is written to the audit log. Anyone can send
+// /auth/validate garbage tokens, so writing every failure would let them
+// fill the table
+const validationAuditInterval = time.Minute
+
+// maxValidationAuditKeys bounds the memory used to count suppressed failures
+// Once reached, failures from new clients are dropped until old entries expire
+const maxValidationAuditKeys = 10000
+
+// requestInfoKey is the context key for the caller's IP and user agent
+// Set by middleware.RequestInfo for every request
+const requestInfoKey = "request_info"
+
+// requestInfo is who made the request being handled
+type requestInfo struct {
+	clientIP  string
+	userAgent string
+}
+
+// WithRequestInfo stores the caller's IP and user agent in the context
+// so audit events can record them
+func WithRequestInfo(ctx context.Context, clientIP, userAgent string) context.Context {
+	return context.WithValue(ctx, requestInfoKey, requestInfo{clientIP: clientIP, userAgent: userAgent})
+}
+
+// requestInfoFrom returns the caller's IP and user agent (empty outside a request)
+func requestInfoFrom(ctx context.Context) requestInfo {
+	info, _ := ctx.Value(requestInfoKey).(requestInfo)
+	return info
+}
+
+// audit records an authentication event in the security audit log
+// userID is empty when the user is unknown (e.g. login with an unregistered email) -
+// email is then what was tried. A failed write is logged but doesn't fail the
+// request: people must be able to log in even if the audit table is unavailable
+func (s *AuthService) audit(ctx context.Context, eventType, userID, email, outcome, reason string) {
+	var userIDPtr *string
+	if userID != "" {
+		userIDPtr = &userID
+	}
+
+	info := requestInfoFrom(ctx)
+	event := model.NewAuthAuditEvent(eventType, userIDPtr, email, info.clientIP, info.userAgent, outcome, reason)
+
+	if err := s.auditRepo.Record(ctx, event); err != nil {
+		log.Printf("ERROR: Failed to write auth audit event (%s %s for %q): %v", eventType, outcome, email, err)
+	}
+}
+
+// auditFailure records a failed event with the error as the reason
+func (s *AuthService) auditFailure(ctx context.Context, eventType, userID, email string, failure error) {
+	s.audit(ctx, eventType, userID, email, model.AuthOutcomeFailure, failure.Error())
+}
+
+// auditValidationFailure records a failed token validation, at most once per
+// validationAuditInterval for each client, user and reason. The next event
+// written says how many were left out in between
+func (s *AuthService) auditValidationFailure(ctx context.Context, userID, email, reason string) {
+	key := requestInfoFrom(ctx).clientIP + "|" + userID + "|" + reason
+	write, suppressed := s.validationAudits.allow(key, time.Now())
+	if !write {
+		return
+	}
+	if suppressed > 0 {
+		reason = fmt.Sprintf("%s (%d more since last event)", reason, suppressed)
+	}
+	s.audit(ctx, model.AuthEventTokenValidate, userID, email, model.AuthOutcomeFailure, reason)
+}
+
+// validationAuditLimiter counts failed validations per key (client, user and reason)
+// The zero value is ready to use
+type validationAuditLimiter struct {
+	mu      sync.Mutex
+	entries map[string]*validationAuditEntry
+}
+
+// validationAuditEntry is when a key was last written and how many failures were skipped since
+type validationAuditEntry struct {
+	writtenAt  time.Time
+	suppressed int
+}
+
+// allow reports whether a failure for key should be written now, and how many
+// failures for it were suppressed since the last write
+func (l *validationAuditLimiter) allow(key string, now time.Time) (bool, int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.entries == nil {
+		l.entries = make(map[string]*validationAuditEntry)
+	}
+
+	entry, ok := l.entries[key]
+	if ok && now.Sub(entry.writtenAt) < validationAuditInterval {
+		entry.suppressed++
+		return false, 0
+	}
+	if ok {
+		suppressed := entry.suppressed
+		entry.writtenAt, entry.suppressed = now, 0
+		return true, suppressed
+	}
+
+	if len(l.entries) >= maxValidationAuditKeys {
+		// Drop expired entries (their suppressed counts are no longer reported)
+		for k, e := range l.entries {
+			if now.Sub(e.writtenAt) >= validationAuditInterval {
+				delete(l.entries, k)
+			}
+		}
+		if len(l.entries) >= maxValidationAuditKeys {
+			return false, 0
+		}
+	}
+	l.entries[key] = &validationAuditEntry{writtenAt: now}
+	return true, 0
+}
+
+// ListAuditEvents returns a page of audit events, newest first
+// Users see their own history (filters.UserID set by the handler), admins any
+func (s *AuthService) ListAuditEvents(ctx context.Context, filters *model.ListAuthAuditEventsRequest) (*model.ListAuthAuditEventsResponse, error) {
+	// Apply pagination defaults
+	if filters.Limit <= 0 {
+		filters.Limit = 50
+	}
+	if filters.Limit > 200 {
+		filters.Limit = 200
+	}
+	if filters.Page <= 0 {
+		filters.Page = 1
+	}
+
+	events, total, err := s.auditRepo.List(ctx, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	pages := (total + filters.Limit - 1) / filters.Limit // Ceiling division
+
+	return &model.ListAuthAuditEventsResponse{
+		Events: events,
+		Total:  total,
+		Page:   filters.Page,
+		Limit:  filters.Limit,
+		Pages:  pages,
+	}, nil
+}
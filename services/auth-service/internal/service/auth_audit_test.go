@@ -0,0 +1,54 @@
+package service
+
+import (
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestValidationAuditLimiter(t *testing.T) {
+	var limiter validationAuditLimiter
+	start := time.Unix(1700000000, 0)
+
+	tests := []struct {
+		name           string
+		key            string
+		at             time.Duration
+		wantWrite      bool
+		wantSuppressed int
+	}{
+		{"first failure", "a", 0, true, 0},
+		{"repeat is suppressed", "a", time.Second, false, 0},
+		{"another repeat", "a", 30 * time.Second, false, 0},
+		{"other key", "b", 30 * time.Second, true, 0},
+		{"after the interval", "a", validationAuditInterval, true, 2},
+		{"counter was reset", "a", 2 * validationAuditInterval, true, 0},
+	}
+
+	for _, tt := range tests {
+		write, suppressed := limiter.allow(tt.key, start.Add(tt.at))
+		if write != tt.wantWrite || suppressed != tt.wantSuppressed {
+			t.Errorf("%s: allow = (%v, %d), want (%v, %d)", tt.name, write, suppressed, tt.wantWrite, tt.wantSuppressed)
+		}
+	}
+}
+
+func TestValidationAuditLimiterIsBounded(t *testing.T) {
+	var limiter validationAuditLimiter
+	start := time.Unix(1700000000, 0)
+
+	for i := 0; i < maxValidationAuditKeys; i++ {
+		limiter.allow(strconv.Itoa(i), start)
+	}
+	if write, _ := limiter.allow("new", start); write {
+		t.Error("allow wrote a new key while the limiter was full")
+	}
+
+	// Expired entries make room again
+	if write, _ := limiter.allow("new", start.Add(validationAuditInterval)); !write {
+		t.Error("allow did not write a new key after the entries expired")
+	}
+	if len(limiter.entries) != 1 {
+		t.Errorf("limiter kept %d entries, want 1", len(limiter.entries))
+	}
+}
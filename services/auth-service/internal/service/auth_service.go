@@ -5,8 +5,12 @@ import (
 	"errors"
 	"expense-tracker/auth-service/internal/model"
 	"expense-tracker/auth-service/internal/repository"
+	"log"
+	"net/url"
+	"strings"
 	"time"
 
+	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -14,18 +18,92 @@ import (
 // This is the "service layer" - it contains the core business rules
 // It uses the repository (data access) and JWT service (token generation)
 type AuthService struct {
-	userRepo       repository.UserRepository
-	jwtService     *JWTService
-	eventPublisher *EventPublisher // Optional - can be nil if not configured
+	userRepo         repository.UserRepository
+	refreshTokenRepo repository.RefreshTokenRepository
+	sessionRepo      repository.SessionRepository
+	apiKeyRepo       repository.APIKeyRepository
+	oidcRepo         repository.OIDCRepository
+	auditRepo        repository.AuthAuditRepository
+	jwtService       *JWTService
+	throttler        *LoginThrottler
+	settings         AuthSettings
+	eventPublisher   *EventPublisher // Optional - can be nil if not configured
+	oidcClient       *OIDCClient     // Optional - nil unless OpenID Connect login is configured
+
+	// validationAudits limits how many failed validations are audited
+	validationAudits validationAuditLimiter
+}
+
+// AuthSettings holds the tunable parts of the authentication flows
+// (token lifetimes, links sent by email, ...). It is filled from config in main.
+type AuthSettings struct {
+	// RefreshTokenExpiration is how long refresh tokens are valid
+	RefreshTokenExpiration time.Duration
+
+	// PasswordResetExpiration is how long password reset tokens are valid
+	PasswordResetExpiration time.Duration
+
+	// PasswordResetURL is the frontend page that completes a reset
+	// The reset token is appended as a "token" query parameter
+	PasswordResetURL string
+
+	// RequireEmailVerification makes Login refuse accounts whose email is not verified
+	// Register then creates the account without issuing tokens
+	RequireEmailVerification bool
+
+	// EmailVerificationExpiration is how long email verification tokens are valid
+	EmailVerificationExpiration time.Duration
+
+	// EmailVerificationURL is where verification links point (GET /auth/verify-email)
+	// The verification token is appended as a "token" query parameter
+	EmailVerificationURL string
+
+	// MFAIssuer is the account issuer shown in authenticator apps
+	MFAIssuer string
+
+	// MFAChallengeExpiration is how long the MFA challenge token from Login is valid
+	MFAChallengeExpiration time.Duration
+
+	// AccountDeletionGracePeriod is how long a deleted account's data is kept
+	// before it is erased for good (here and in the other services)
+	AccountDeletionGracePeriod time.Duration
+
+	// AuditSuccessfulValidations records every successful token validation in the
+	// audit log, not only failures - off by default, it's one row per API request
+	AuditSuccessfulValidations bool
 }
 
+// recoveryCodeCount is how many MFA recovery codes a user gets
+const recoveryCodeCount = 10
+
+// reauthWindow is how long after signing in sensitive changes can be made
+// without the current password (see reauthenticate)
+const reauthWindow = 5 * time.Minute
+
 // NewAuthService creates a new authentication service
 // Dependency injection: we pass dependencies as parameters
 // This makes testing easier and follows clean architecture
-func NewAuthService(userRepo repository.UserRepository, jwtService *JWTService) *AuthService {
+func NewAuthService(
+	userRepo repository.UserRepository,
+	refreshTokenRepo repository.RefreshTokenRepository,
+	sessionRepo repository.SessionRepository,
+	apiKeyRepo repository.APIKeyRepository,
+	oidcRepo repository.OIDCRepository,
+	auditRepo repository.AuthAuditRepository,
+	jwtService *JWTService,
+	throttler *LoginThrottler,
+	settings AuthSettings,
+) *AuthService {
 	return &AuthService{
-		userRepo:   userRepo,
-		jwtService: jwtService,
+		userRepo:         userRepo,
+		refreshTokenRepo: refreshTokenRepo,
+		sessionRepo:      sessionRepo,
+		apiKeyRepo:       apiKeyRepo,
+		oidcRepo:         oidcRepo,
+		auditRepo:        auditRepo,
+		jwtService:       jwtService,
+		throttler:        throttler,
+		settings:         settings,
 	}
 }
 
@@ -39,8 +117,9 @@ func (s *AuthService) SetEventPublisher(publisher *EventPublisher) {
 // 1. Check if user already exists
 // 2. Hash the password (NEVER store plain passwords!)
 // 3. Create the user in database
-// 4. Generate a JWT token
-// 5. Return user info and token
+// 4. Create an email verification token (sent in the user.registered event)
+// 5. Generate a JWT access token and a refresh token (unless verification is required)
+// 6. Return user info and tokens
 func (s *AuthService) Register(ctx context.Context, req *model.RegisterRequest) (*model.AuthResponse, error) {
 	// Check if user already exists
 	existingUser, err := s.userRepo.FindByEmail(ctx, req.Email)
@@ -50,7 +129,9 @@ func (s *AuthService) Register(ctx context.Context, req *model.RegisterRequest)
 	if existingUser != nil {
 		// User already exists - return an error
 		// In Go, we use errors.New() or fmt.Errorf() to create errors
-		return nil, errors.New("user with this email already exists")
+		err := errors.New("user with this email already exists")
+		s.auditFailure(ctx, model.AuthEventRegister, "", req.Email, err)
+		return nil, err
 	}
 
 	// Hash the password using bcrypt
@@ -69,6 +150,13 @@ func (s *AuthService) Register(ctx context.Context, req *model.RegisterRequest)
 	if err != nil {
 		return nil, err
 	}
+	s.audit(ctx, model.AuthEventRegister, user.ID, user.Email, model.AuthOutcomeSuccess, "")
+
+	// Create a verification token - the welcome email carries the link
+	verificationToken, err := s.createEmailVerificationToken(ctx, user)
+	if err != nil {
+		return nil, err
+	}
 
 	// Publish event (non-blocking, async)
 	if s.eventPublisher != nil {
@@ -78,36 +166,45 @@ func (s *AuthService) Register(ctx context.Context, req *model.RegisterRequest)
 			UserEmail: user.Email,
 			Timestamp: time.Now(),
 			Data: map[string]interface{}{
-				"user_id": user.ID,
-				"email":   user.Email,
-				"name":    user.Name,
+				"user_id":            user.ID,
+				"email":              user.Email,
+				"name":               user.Name,
+				"verification_token": verificationToken,
+				"verification_url":   buildLink(s.settings.EmailVerificationURL, verificationToken),
 			},
 		}
 		s.eventPublisher.PublishEventAsync(ctx, event)
 	}
 
-	// Generate JWT token
-	token, err := s.jwtService.GenerateToken(user.ID, user.Email)
-	if err != nil {
-		return nil, err
+	// Unverified accounts can't log in, so don't hand out tokens either
+	if s.settings.RequireEmailVerification {
+		return &model.AuthResponse{
+			UserID:        user.ID,
+			Email:         user.Email,
+			Name:          user.Name,
+			EmailVerified: false,
+		}, nil
 	}
 
-	// Return the response
-	return &model.AuthResponse{
-		UserID: user.ID,
-		Email:  user.Email,
-		Name:   user.Name,
-		Token:  token,
-	}, nil
+	// Start a new session (refresh token family) and issue tokens
+	return s.startSession(ctx, user)
 }
 
 // Login authenticates a user and returns a token
 // Steps:
-// 1. Find user by email
-// 2. Compare provided password with stored hash
-// 3. If match, generate token
-// 4. Return user info and token
-func (s *AuthService) Login(ctx context.Context, req *model.LoginRequest) (*model.AuthResponse, error) {
+// 1. Refuse the attempt if the account or client IP is locked out
+// 2. Find user by email
+// 3. Compare provided password with stored hash (failures are counted)
+// 4. If MFA is enabled, return an MFA challenge instead of tokens
+// 5. Otherwise generate access and refresh tokens
+// 6. Return user info and tokens
+func (s *AuthService) Login(ctx context.Context, req *model.LoginRequest, clientIP string) (*model.AuthResponse, error) {
+	// Locked accounts don't get to try a password at all
+	if err := s.throttler.Check(ctx, req.Email, clientIP); err != nil {
+		s.auditFailure(ctx, model.AuthEventLogin, "", req.Email, err)
+		return nil, err
+	}
+
 	// Find user by email
 	user, err := s.userRepo.FindByEmail(ctx, req.Email)
 	if err != nil {
@@ -115,7 +212,9 @@ func (s *AuthService) Login(ctx context.Context, req *model.LoginRequest) (*mode
 	}
 	if user == nil {
 		// User not found - don't reveal if email exists (security best practice)
-		return nil, errors.New("invalid email or password")
+		// The attempt still counts, so unknown emails lock out like real ones
+		s.audit(ctx, model.AuthEventLogin, "", req.Email, model.AuthOutcomeFailure, "unknown email")
+		return nil, s.loginFailed(ctx, nil, req.Email, clientIP, errors.New("invalid email or password"))
 	}
 
 	// Compare password with hash
@@ -124,35 +223,280 @@ func (s *AuthService) Login(ctx context.Context, req *model.LoginRequest) (*mode
 	err = bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password))
 	if err != nil {
 		// Password doesn't match
-		return nil, errors.New("invalid email or password")
+		s.audit(ctx, model.AuthEventLogin, user.ID, user.Email, model.AuthOutcomeFailure, "invalid password")
+		return nil, s.loginFailed(ctx, user, req.Email, clientIP, errors.New("invalid email or password"))
+	}
+
+	// Refuse unverified accounts when verification is required
+	// Checked after the password so we don't leak which emails are registered
+	if s.settings.RequireEmailVerification && !user.IsEmailVerified() {
+		err := errors.New("email address not verified")
+		s.auditFailure(ctx, model.AuthEventLogin, user.ID, user.Email, err)
+		return nil, err
+	}
+
+	return s.finishLogin(ctx, user, model.AuthEventLogin)
+}
+
+// finishLogin completes a login once the user has proven who they are
+// (password or identity provider): MFA users get a challenge, others get tokens
+// eventType is what the audit log records the login as
+func (s *AuthService) finishLogin(ctx context.Context, user *model.User, eventType string) (*model.AuthResponse, error) {
+	// Two-factor accounts get a challenge token - tokens are issued by VerifyMFA
+	// Their failed attempts are only cleared once the MFA code is right too
+	if user.IsMFAEnabled() {
+		mfaToken, err := s.jwtService.GenerateMFAToken(user.ID, user.Email, s.settings.MFAChallengeExpiration)
+		if err != nil {
+			return nil, err
+		}
+		s.audit(ctx, eventType, user.ID, user.Email, model.AuthOutcomeMFARequired, "")
+
+		return &model.AuthResponse{
+			UserID:        user.ID,
+			Email:         user.Email,
+			Name:          user.Name,
+			EmailVerified: user.IsEmailVerified(),
+			MFARequired:   true,
+			MFAToken:      mfaToken,
+		}, nil
+	}
+
+	// Successful login - forget earlier failed attempts
+	if err := s.throttler.RecordSuccess(ctx, user.Email); err != nil {
+		return nil, err
+	}
+
+	s.audit(ctx, eventType, user.ID, user.Email, model.AuthOutcomeSuccess, "")
+
+	// Start a new session (refresh token family) and issue tokens
+	return s.startSession(ctx, user)
+}
+
+// loginFailed counts a failed login attempt and returns the error to give the caller
+// If this failure locked the account, the user is notified (user.account_locked)
+// and a *LoginThrottledError is returned instead of failure
+func (s *AuthService) loginFailed(ctx context.Context, user *model.User, email, clientIP string, failure error) error {
+	lockedUntil, err := s.throttler.RecordFailure(ctx, email, clientIP)
+	if err != nil {
+		return err
+	}
+	if lockedUntil.IsZero() {
+		return failure
+	}
+
+	log.Printf("Account %s locked until %s after repeated failed logins (last from %s)", email, lockedUntil.Format(time.RFC3339), clientIP)
+
+	// Let the account owner know - someone may be guessing their password
+	if user != nil && s.eventPublisher != nil {
+		event := &Event{
+			EventType: "user.account_locked",
+			UserID:    user.ID,
+			UserEmail: user.Email,
+			Timestamp: time.Now(),
+			Data: map[string]interface{}{
+				"user_id":      user.ID,
+				"email":        user.Email,
+				"name":         user.Name,
+				"locked_until": lockedUntil.Format(time.RFC3339),
+				"client_ip":    clientIP,
+			},
+		}
+		s.eventPublisher.PublishEventAsync(ctx, event)
 	}
 
-	// Generate JWT token
-	token, err := s.jwtService.GenerateToken(user.ID, user.Email)
+	return &LoginThrottledError{RetryAfter: time.Until(lockedUntil)}
+}
+
+// VerifyMFA completes a two-factor login
+// The MFA token from Login proves the password was right; the code proves
+// possession of the authenticator app (or a recovery code)
+// Wrong codes count as failed logins, just like wrong passwords
+func (s *AuthService) VerifyMFA(ctx context.Context, req *model.MFAVerifyRequest, clientIP string) (*model.AuthResponse, error) {
+	claims, err := s.jwtService.ValidateMFAToken(req.MFAToken)
 	if err != nil {
+		err := errors.New("invalid or expired MFA token")
+		s.auditFailure(ctx, model.AuthEventMFAVerify, "", "", err)
 		return nil, err
 	}
 
-	// Return the response
-	return &model.AuthResponse{
-		UserID: user.ID,
-		Email:  user.Email,
-		Name:   user.Name,
-		Token:  token,
+	user, err := s.userRepo.FindByID(ctx, claims.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil || !user.IsMFAEnabled() {
+		err := errors.New("invalid or expired MFA token")
+		s.auditFailure(ctx, model.AuthEventMFAVerify, claims.UserID, "", err)
+		return nil, err
+	}
+
+	// The account may have been locked since the password step
+	if err := s.throttler.Check(ctx, user.Email, clientIP); err != nil {
+		s.auditFailure(ctx, model.AuthEventMFAVerify, user.ID, user.Email, err)
+		return nil, err
+	}
+
+	ok, err := s.checkMFACode(ctx, user, req.Code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		failure := errors.New("invalid MFA code")
+		s.auditFailure(ctx, model.AuthEventMFAVerify, user.ID, user.Email, failure)
+		return nil, s.loginFailed(ctx, user, user.Email, clientIP, failure)
+	}
+
+	// Successful login - forget earlier failed attempts
+	if err := s.throttler.RecordSuccess(ctx, user.Email); err != nil {
+		return nil, err
+	}
+	s.audit(ctx, model.AuthEventMFAVerify, user.ID, user.Email, model.AuthOutcomeSuccess, "")
+
+	// Start a new session (refresh token family) and issue tokens
+	return s.startSession(ctx, user)
+}
+
+// EnrollMFA starts TOTP enrollment for a user
+// A new secret is generated and stored, but MFA stays off until ConfirmMFA
+// proves the authenticator app was set up correctly
+func (s *AuthService) EnrollMFA(ctx context.Context, userID string) (*model.MFAEnrollResponse, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, errors.New("user not found")
+	}
+	if user.IsMFAEnabled() {
+		return nil, errors.New("MFA already enabled")
+	}
+
+	secret, err := generateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.userRepo.SetMFASecret(ctx, user.ID, secret); err != nil {
+		if strings.Contains(err.Error(), "already enabled") {
+			return nil, errors.New("MFA already enabled")
+		}
+		return nil, err
+	}
+
+	return &model.MFAEnrollResponse{
+		Secret:     secret,
+		OTPAuthURI: totpURI(s.settings.MFAIssuer, user.Email, secret),
 	}, nil
 }
 
-// ValidateToken checks if a JWT token is valid
+// ConfirmMFA finishes enrollment with a code from the authenticator app
+// On success MFA is enabled and a fresh set of recovery codes is returned
+func (s *AuthService) ConfirmMFA(ctx context.Context, userID string, req *model.MFAConfirmRequest) (*model.MFAConfirmResponse, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, errors.New("user not found")
+	}
+	if user.IsMFAEnabled() {
+		return nil, errors.New("MFA already enabled")
+	}
+	if user.MFASecret == "" {
+		return nil, errors.New("MFA enrollment not started")
+	}
+
+	step, ok := validateTOTP(user.MFASecret, req.Code, time.Now())
+	if !ok {
+		err := errors.New("invalid MFA code")
+		s.auditFailure(ctx, model.AuthEventMFAEnable, user.ID, user.Email, err)
+		return nil, err
+	}
+
+	// Generate recovery codes - only their hashes are stored
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		code, err := generateRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		codes = append(codes, code)
+		hashes = append(hashes, hashToken(normalizeRecoveryCode(code)))
+	}
+
+	if err := s.userRepo.EnableMFA(ctx, user.ID, step, hashes); err != nil {
+		if strings.Contains(err.Error(), "already enabled") {
+			return nil, errors.New("MFA already enabled")
+		}
+		return nil, err
+	}
+	s.audit(ctx, model.AuthEventMFAEnable, user.ID, user.Email, model.AuthOutcomeSuccess, "")
+
+	return &model.MFAConfirmResponse{RecoveryCodes: codes}, nil
+}
+
+// checkMFACode accepts either a current TOTP code or an unused recovery code
+// Each TOTP code and each recovery code only works once
+func (s *AuthService) checkMFACode(ctx context.Context, user *model.User, code string) (bool, error) {
+	if step, ok := validateTOTP(user.MFASecret, code, time.Now()); ok {
+		return s.userRepo.UseTOTPStep(ctx, user.ID, step)
+	}
+
+	normalized := normalizeRecoveryCode(code)
+	if normalized == "" {
+		return false, nil
+	}
+	used, err := s.userRepo.UseRecoveryCode(ctx, user.ID, hashToken(normalized))
+	if err != nil {
+		return false, err
+	}
+	if used {
+		log.Printf("MFA recovery code used for user %s", user.ID)
+	}
+	return used, nil
+}
+
+// ValidateToken checks if a JWT token or personal API key is valid
 // This is used by middleware to protect routes
 func (s *AuthService) ValidateToken(ctx context.Context, tokenString string) (*model.ValidateResponse, error) {
+	// API keys are random strings, not JWTs - they are looked up by hash
+	if strings.HasPrefix(tokenString, model.APIKeyPrefix) {
+		return s.validateAPIKey(ctx, tokenString)
+	}
+
 	// Validate the token using JWT service
 	claims, err := s.jwtService.ValidateToken(tokenString)
 	if err != nil {
+		s.auditValidationFailure(ctx, "", "", "invalid token")
 		return &model.ValidateResponse{
 			Valid: false,
 		}, nil // Return valid=false, but no error (token is just invalid)
 	}
 
+	// Reject tokens whose session was revoked (logout or refresh token reuse)
+	// Tokens without a session ID were issued before refresh tokens existed
+	if claims.SessionID == "" {
+		s.auditValidationFailure(ctx, claims.UserID, claims.Email, "token without session")
+		return &model.ValidateResponse{
+			Valid: false,
+		}, nil
+	}
+	active, err := s.refreshTokenRepo.IsFamilyActive(ctx, claims.SessionID)
+	if err != nil {
+		return nil, err
+	}
+	if !active {
+		s.auditValidationFailure(ctx, claims.UserID, claims.Email, "session revoked")
+		return &model.ValidateResponse{
+			Valid: false,
+		}, nil
+	}
+
+	// Failing to record activity shouldn't fail the request
+	if err := s.sessionRepo.TouchLastSeen(ctx, claims.SessionID, "", ""); err != nil {
+		log.Printf("Failed to record session activity for %s: %v", claims.SessionID, err)
+	}
+
 	// Optionally, verify user still exists in database
 	// This ensures the user wasn't deleted after token was issued
 	user, err := s.userRepo.FindByID(ctx, claims.UserID)
@@ -162,15 +506,663 @@ func (s *AuthService) ValidateToken(ctx context.Context, tokenString string) (*m
 		}, nil
 	}
 	if user == nil {
+		s.auditValidationFailure(ctx, claims.UserID, claims.Email, "user not found")
 		return &model.ValidateResponse{
 			Valid: false,
 		}, nil
 	}
 
+	if s.settings.AuditSuccessfulValidations {
+		s.audit(ctx, model.AuthEventTokenValidate, user.ID, user.Email, model.AuthOutcomeSuccess, "")
+	}
+
 	// Token is valid!
+	// Email and role come from the database, so changes apply immediately
 	return &model.ValidateResponse{
-		Valid:  true,
-		UserID: claims.UserID,
-		Email:  claims.Email,
+		Valid:     true,
+		UserID:    claims.UserID,
+		Email:     user.Email,
+		Role:      user.Role,
+		SessionID: claims.SessionID,
+	}, nil
+}
+
+// ListUsers returns a page of users (admin only - enforced by the route)
+func (s *AuthService) ListUsers(ctx context.Context, filters *model.ListUsersRequest) (*model.ListUsersResponse, error) {
+	// Apply pagination defaults
+	if filters.Limit <= 0 {
+		filters.Limit = 20
+	}
+	if filters.Limit > 100 {
+		filters.Limit = 100
+	}
+	if filters.Page <= 0 {
+		filters.Page = 1
+	}
+
+	users, total, err := s.userRepo.List(ctx, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	pages := (total + filters.Limit - 1) / filters.Limit // Ceiling division
+
+	return &model.ListUsersResponse{
+		Users: users,
+		Total: total,
+		Page:  filters.Page,
+		Limit: filters.Limit,
+		Pages: pages,
 	}, nil
 }
+
+// GetUser returns a single user by ID (admin only - enforced by the route)
+func (s *AuthService) GetUser(ctx context.Context, userID string) (*model.User, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, errors.New("user not found")
+	}
+
+	return user, nil
+}
+
+// JWKS returns the public keys that verify access tokens
+func (s *AuthService) JWKS() *model.JWKS {
+	return s.jwtService.JWKS()
+}
+
+// Refresh exchanges a refresh token for a new access token and refresh token
+// Refresh tokens are single-use (rotation):
+// 1. Look up the token by its hash
+// 2. If it was already used, someone replayed it - revoke the whole family
+// 3. Otherwise revoke it and issue a replacement in the same family
+func (s *AuthService) Refresh(ctx context.Context, req *model.RefreshRequest) (*model.AuthResponse, error) {
+	stored, err := s.refreshTokenRepo.FindByHash(ctx, hashToken(req.RefreshToken))
+	if err != nil {
+		return nil, err
+	}
+	if stored == nil {
+		err := errors.New("invalid refresh token")
+		s.auditFailure(ctx, model.AuthEventTokenRefresh, "", "", err)
+		return nil, err
+	}
+
+	// Reuse detection: a revoked token should never be presented again
+	// Either the legitimate client or an attacker holds a stolen copy,
+	// and we can't tell which - so we end the session for both
+	if stored.IsRevoked() {
+		log.Printf("Refresh token reuse detected for user %s (family %s) - revoking family", stored.UserID, stored.FamilyID)
+		if err := s.refreshTokenRepo.RevokeFamily(ctx, stored.FamilyID); err != nil {
+			return nil, err
+		}
+		err := errors.New("refresh token reuse detected")
+		s.auditFailure(ctx, model.AuthEventTokenRefresh, stored.UserID, "", err)
+		return nil, err
+	}
+
+	if stored.IsExpired() {
+		s.audit(ctx, model.AuthEventTokenRefresh, stored.UserID, "", model.AuthOutcomeFailure, "refresh token expired")
+		return nil, errors.New("invalid refresh token")
+	}
+
+	// Make sure the user still exists
+	user, err := s.userRepo.FindByID(ctx, stored.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		s.audit(ctx, model.AuthEventTokenRefresh, stored.UserID, "", model.AuthOutcomeFailure, "user not found")
+		return nil, errors.New("invalid refresh token")
+	}
+
+	// Issue a replacement token in the same family
+	plainToken, newToken, err := s.newRefreshToken(user.ID, stored.FamilyID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.refreshTokenRepo.Rotate(ctx, stored.ID, newToken)
+	if err != nil {
+		if errors.Is(err, repository.ErrRefreshTokenAlreadyUsed) {
+			// Lost a race with another refresh using the same token - treat as reuse
+			log.Printf("Concurrent refresh token reuse for user %s (family %s) - revoking family", stored.UserID, stored.FamilyID)
+			if err := s.refreshTokenRepo.RevokeFamily(ctx, stored.FamilyID); err != nil {
+				return nil, err
+			}
+			err := errors.New("refresh token reuse detected")
+			s.auditFailure(ctx, model.AuthEventTokenRefresh, user.ID, user.Email, err)
+			return nil, err
+		}
+		return nil, err
+	}
+	s.audit(ctx, model.AuthEventTokenRefresh, user.ID, user.Email, model.AuthOutcomeSuccess, "")
+
+	// The client refreshes directly, so this is the device's current address
+	info := requestInfoFrom(ctx)
+	if err := s.sessionRepo.TouchLastSeen(ctx, stored.FamilyID, info.userAgent, info.clientIP); err != nil {
+		log.Printf("Failed to record session activity for %s: %v", stored.FamilyID, err)
+	}
+
+	return s.buildAuthResponse(user, stored.FamilyID, plainToken)
+}
+
+// Logout revokes the session (refresh token family) the given token belongs to
+// Access tokens issued for that session stop validating immediately
+func (s *AuthService) Logout(ctx context.Context, req *model.LogoutRequest) error {
+	stored, err := s.refreshTokenRepo.FindByHash(ctx, hashToken(req.RefreshToken))
+	if err != nil {
+		return err
+	}
+	if stored == nil {
+		err := errors.New("invalid refresh token")
+		s.auditFailure(ctx, model.AuthEventLogout, "", "", err)
+		return err
+	}
+
+	if err := s.refreshTokenRepo.RevokeFamily(ctx, stored.FamilyID); err != nil {
+		return err
+	}
+	s.audit(ctx, model.AuthEventLogout, stored.UserID, "", model.AuthOutcomeSuccess, "")
+
+	return nil
+}
+
+// ForgotPassword starts the password reset flow for an email address
+// It always succeeds from the caller's point of view - we never reveal
+// whether an account exists for the email (prevents account enumeration)
+// If the user exists, a single-use reset token is stored (hashed) and a
+// user.password_reset_requested event carrying the reset link is published
+func (s *AuthService) ForgotPassword(ctx context.Context, req *model.ForgotPasswordRequest) error {
+	user, err := s.userRepo.FindByEmail(ctx, req.Email)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		// Unknown email - pretend everything went fine
+		return nil
+	}
+
+	plainToken, err := generateSecureToken()
+	if err != nil {
+		return err
+	}
+
+	resetToken := model.NewPasswordResetToken(user.ID, hashToken(plainToken), s.settings.PasswordResetExpiration)
+	if err := s.userRepo.CreatePasswordResetToken(ctx, resetToken); err != nil {
+		return err
+	}
+
+	// The reset link is only ever delivered by email (through notification-service)
+	if s.eventPublisher != nil {
+		event := &Event{
+			EventType: "user.password_reset_requested",
+			UserID:    user.ID,
+			UserEmail: user.Email,
+			Timestamp: time.Now(),
+			Data: map[string]interface{}{
+				"user_id":    user.ID,
+				"email":      user.Email,
+				"name":       user.Name,
+				"reset_url":  buildLink(s.settings.PasswordResetURL, plainToken),
+				"expires_at": resetToken.ExpiresAt.Format(time.RFC3339),
+			},
+		}
+		s.eventPublisher.PublishEventAsync(ctx, event)
+	} else {
+		log.Printf("WARNING: Event publisher not configured - password reset email for user %s will not be sent", user.ID)
+	}
+
+	return nil
+}
+
+// ResetPassword completes the password reset flow
+// Steps:
+// 1. Look up the reset token by its hash and check it is unused and unexpired
+// 2. Hash the new password and store it (consuming the token)
+// 3. Revoke every refresh token of the user, signing out all sessions
+func (s *AuthService) ResetPassword(ctx context.Context, req *model.ResetPasswordRequest) error {
+	resetToken, err := s.userRepo.FindPasswordResetToken(ctx, hashToken(req.Token))
+	if err != nil {
+		return err
+	}
+	if resetToken == nil || !resetToken.IsUsable() {
+		err := errors.New("invalid or expired reset token")
+		s.auditFailure(ctx, model.AuthEventPasswordReset, "", "", err)
+		return err
+	}
+
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	err = s.userRepo.ResetPassword(ctx, resetToken.ID, resetToken.UserID, string(passwordHash))
+	if err != nil {
+		if strings.Contains(err.Error(), "already used") {
+			err := errors.New("invalid or expired reset token")
+			s.auditFailure(ctx, model.AuthEventPasswordReset, resetToken.UserID, "", err)
+			return err
+		}
+		return err
+	}
+	s.audit(ctx, model.AuthEventPasswordReset, resetToken.UserID, "", model.AuthOutcomeSuccess, "")
+
+	// Whoever knew the old password may still hold a session - end them all
+	return s.refreshTokenRepo.RevokeAllForUser(ctx, resetToken.UserID)
+}
+
+// VerifyEmail consumes an email verification token and marks the address verified
+// Tokens from RequestEmailChange also switch the account to the new address
+func (s *AuthService) VerifyEmail(ctx context.Context, token string) error {
+	verificationToken, err := s.userRepo.FindEmailVerificationToken(ctx, hashToken(token))
+	if err != nil {
+		return err
+	}
+	if verificationToken == nil || !verificationToken.IsUsable() {
+		return errors.New("invalid or expired verification token")
+	}
+
+	// Remember the old address for the user.updated event
+	user, err := s.userRepo.FindByID(ctx, verificationToken.UserID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return errors.New("invalid or expired verification token")
+	}
+
+	err = s.userRepo.VerifyEmail(ctx, verificationToken)
+	if err != nil {
+		// Token raced with another request, or the user changed their email since
+		if strings.Contains(err.Error(), "already used") || strings.Contains(err.Error(), "email changed") {
+			return errors.New("invalid or expired verification token")
+		}
+		return err
+	}
+
+	if verificationToken.Purpose == model.EmailTokenPurposeChange {
+		// The security notice goes to the old address (see publishUserUpdated)
+		previousEmail := user.Email
+		user.Email = verificationToken.Email
+		s.publishUserUpdated(ctx, user, []string{"email"}, previousEmail)
+	}
+
+	return nil
+}
+
+// ResendVerification sends a new verification link to an unverified account
+// Like ForgotPassword, it never reveals whether an account exists
+func (s *AuthService) ResendVerification(ctx context.Context, req *model.ResendVerificationRequest) error {
+	user, err := s.userRepo.FindByEmail(ctx, req.Email)
+	if err != nil {
+		return err
+	}
+	if user == nil || user.IsEmailVerified() {
+		// Nothing to do - pretend everything went fine
+		return nil
+	}
+
+	verificationToken, err := s.createEmailVerificationToken(ctx, user)
+	if err != nil {
+		return err
+	}
+
+	s.publishVerificationRequested(ctx, user, verificationToken)
+	return nil
+}
+
+// GetProfile returns the user's own account information
+func (s *AuthService) GetProfile(ctx context.Context, userID string) (*model.ProfileResponse, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, errors.New("user not found")
+	}
+
+	return model.NewProfileResponse(user), nil
+}
+
+// UpdateProfile changes the fields present in the request (currently only the name)
+func (s *AuthService) UpdateProfile(ctx context.Context, userID string, req *model.UpdateProfileRequest) (*model.ProfileResponse, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, errors.New("user not found")
+	}
+
+	var changed []string
+
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name == "" {
+			return nil, errors.New("name cannot be empty")
+		}
+		if name != user.Name {
+			if err := s.userRepo.UpdateName(ctx, userID, name); err != nil {
+				return nil, err
+			}
+			user.Name = name
+			changed = append(changed, "name")
+		}
+	}
+
+	if len(changed) > 0 {
+		user.UpdatedAt = time.Now()
+		s.publishUserUpdated(ctx, user, changed, "")
+	}
+
+	return model.NewProfileResponse(user), nil
+}
+
+// ChangePassword sets a new password after checking the current one
+// Wrong current passwords count as failed logins (someone may be using a stolen token)
+// Every session is ended and a new one is started for the caller
+func (s *AuthService) ChangePassword(ctx context.Context, userID string, req *model.ChangePasswordRequest, clientIP string) (*model.AuthResponse, error) {
+	user, err := s.checkCurrentPassword(ctx, userID, req.CurrentPassword, clientIP, model.AuthEventPasswordChange)
+	if err != nil {
+		return nil, err
+	}
+
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.userRepo.UpdatePassword(ctx, user.ID, string(passwordHash)); err != nil {
+		return nil, err
+	}
+
+	// Sign out everywhere else - whoever knew the old password may hold a session
+	if err := s.refreshTokenRepo.RevokeAllForUser(ctx, user.ID); err != nil {
+		return nil, err
+	}
+
+	s.publishUserUpdated(ctx, user, []string{"password"}, "")
+	s.audit(ctx, model.AuthEventPasswordChange, user.ID, user.Email, model.AuthOutcomeSuccess, "")
+
+	// Start a new session (refresh token family) and issue tokens
+	return s.startSession(ctx, user)
+}
+
+// RequestEmailChange sends a verification link to a new email address
+// The account keeps its current email until the link is used (see VerifyEmail),
+// so a typo or an address the user doesn't own can't lock them out
+func (s *AuthService) RequestEmailChange(ctx context.Context, userID, sessionID string, req *model.ChangeEmailRequest, clientIP string) error {
+	user, err := s.reauthenticate(ctx, userID, sessionID, req.CurrentPassword, clientIP, model.AuthEventEmailChange)
+	if err != nil {
+		return err
+	}
+
+	newEmail := strings.TrimSpace(req.NewEmail)
+	if strings.EqualFold(newEmail, user.Email) {
+		return errors.New("new email is the same as the current email")
+	}
+
+	existingUser, err := s.userRepo.FindByEmail(ctx, newEmail)
+	if err != nil {
+		return err
+	}
+	if existingUser != nil {
+		return errors.New("email already in use")
+	}
+
+	plainToken, err := generateSecureToken()
+	if err != nil {
+		return err
+	}
+
+	token := model.NewEmailChangeToken(user.ID, newEmail, hashToken(plainToken), s.settings.EmailVerificationExpiration)
+	if err := s.userRepo.CreateEmailVerificationToken(ctx, token); err != nil {
+		return err
+	}
+
+	// The link goes to the new address - that's what proves the user owns it
+	pending := *user
+	pending.Email = newEmail
+	s.publishVerificationRequested(ctx, &pending, plainToken)
+	s.audit(ctx, model.AuthEventEmailChange, user.ID, user.Email, model.AuthOutcomeSuccess, "")
+
+	return nil
+}
+
+// DeleteAccount deletes the signed-in user's account after re-authenticating them
+// The account is soft-deleted right away and every session ends.
+// A user.deleted event tells the other services to hide the user's data and
+// erase it once the grace period is over (AccountPurger does the same here).
+func (s *AuthService) DeleteAccount(ctx context.Context, userID, sessionID string, req *model.DeleteAccountRequest, clientIP string) (*model.DeleteAccountResponse, error) {
+	user, err := s.reauthenticate(ctx, userID, sessionID, req.CurrentPassword, clientIP, model.AuthEventAccountDelete)
+	if err != nil {
+		return nil, err
+	}
+
+	deletedAt := time.Now()
+	if err := s.userRepo.SoftDelete(ctx, user.ID, deletedAt); err != nil {
+		return nil, err
+	}
+
+	// Refresh tokens of a deleted user must not start new sessions
+	if err := s.refreshTokenRepo.RevokeAllForUser(ctx, user.ID); err != nil {
+		return nil, err
+	}
+
+	s.audit(ctx, model.AuthEventAccountDelete, user.ID, user.Email, model.AuthOutcomeSuccess, "")
+
+	purgeAfter := deletedAt.Add(s.settings.AccountDeletionGracePeriod)
+	log.Printf("Account %s deleted, data will be purged after %s", user.ID, purgeAfter.Format(time.RFC3339))
+
+	// Published again by AccountPurger before the account is purged,
+	// so a lost event only delays the erasure
+	if s.eventPublisher != nil {
+		s.eventPublisher.PublishEventAsync(ctx, newUserDeletedEvent(user, deletedAt, purgeAfter))
+	}
+
+	return &model.DeleteAccountResponse{
+		Message:    "Account deleted",
+		PurgeAfter: purgeAfter,
+	}, nil
+}
+
+// reauthenticate checks it is really the user asking for a sensitive change
+// The password is checked like checkCurrentPassword. Only users without one may
+// leave it out: their session must have started within reauthWindow instead -
+// users created by an identity provider login never learn the random password
+// they were given, so signing in again is how they confirm it is them
+func (s *AuthService) reauthenticate(ctx context.Context, userID, sessionID, password, clientIP, eventType string) (*model.User, error) {
+	if password != "" {
+		return s.checkCurrentPassword(ctx, userID, password, clientIP, eventType)
+	}
+
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, errors.New("user not found")
+	}
+	if user.HasPassword {
+		return s.checkCurrentPassword(ctx, userID, password, clientIP, eventType)
+	}
+
+	// API keys have no session and always need the password
+	var session *model.Session
+	if sessionID != "" {
+		session, err = s.sessionRepo.FindActiveByID(ctx, sessionID, userID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if session == nil || time.Since(session.CreatedAt) > reauthWindow {
+		err := errors.New("recent sign-in required")
+		s.auditFailure(ctx, eventType, userID, user.Email, err)
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// checkCurrentPassword re-authenticates a signed-in user before a sensitive change
+// Failures are throttled like logins and audited as eventType
+func (s *AuthService) checkCurrentPassword(ctx context.Context, userID, password, clientIP, eventType string) (*model.User, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, errors.New("user not found")
+	}
+
+	if err := s.throttler.Check(ctx, user.Email, clientIP); err != nil {
+		s.auditFailure(ctx, eventType, user.ID, user.Email, err)
+		return nil, err
+	}
+
+	err = bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password))
+	if err != nil {
+		failure := errors.New("invalid current password")
+		s.auditFailure(ctx, eventType, user.ID, user.Email, failure)
+		return nil, s.loginFailed(ctx, user, user.Email, clientIP, failure)
+	}
+
+	return user, nil
+}
+
+// publishUserUpdated publishes a user.updated event so notification-service
+// can tell the user their account changed
+// changed lists the changed fields ("name", "email", "password")
+func (s *AuthService) publishUserUpdated(ctx context.Context, user *model.User, changed []string, previousEmail string) {
+	if s.eventPublisher == nil {
+		return
+	}
+
+	data := map[string]interface{}{
+		"user_id":        user.ID,
+		"email":          user.Email,
+		"name":           user.Name,
+		"changed_fields": changed,
+	}
+	// An email change is announced to the old address: its owner has to hear
+	// about it even if someone else took over the account and changed it
+	recipient := user.Email
+	if previousEmail != "" {
+		data["previous_email"] = previousEmail
+		recipient = previousEmail
+	}
+
+	event := &Event{
+		EventType: "user.updated",
+		UserID:    user.ID,
+		UserEmail: recipient,
+		Timestamp: time.Now(),
+		Data:      data,
+	}
+	s.eventPublisher.PublishEventAsync(ctx, event)
+}
+
+// createEmailVerificationToken stores a new verification token for the user's
+// current email and returns the plain token (only ever sent by email)
+func (s *AuthService) createEmailVerificationToken(ctx context.Context, user *model.User) (string, error) {
+	plainToken, err := generateSecureToken()
+	if err != nil {
+		return "", err
+	}
+
+	token := model.NewEmailVerificationToken(user.ID, user.Email, hashToken(plainToken), s.settings.EmailVerificationExpiration)
+	if err := s.userRepo.CreateEmailVerificationToken(ctx, token); err != nil {
+		return "", err
+	}
+
+	return plainToken, nil
+}
+
+// publishVerificationRequested publishes a user.email_verification_requested event
+// so notification-service emails the verification link
+func (s *AuthService) publishVerificationRequested(ctx context.Context, user *model.User, verificationToken string) {
+	if s.eventPublisher == nil {
+		log.Printf("WARNING: Event publisher not configured - verification email for user %s will not be sent", user.ID)
+		return
+	}
+
+	event := &Event{
+		EventType: "user.email_verification_requested",
+		UserID:    user.ID,
+		UserEmail: user.Email,
+		Timestamp: time.Now(),
+		Data: map[string]interface{}{
+			"user_id":            user.ID,
+			"email":              user.Email,
+			"name":               user.Name,
+			"verification_token": verificationToken,
+			"verification_url":   buildLink(s.settings.EmailVerificationURL, verificationToken),
+		},
+	}
+	s.eventPublisher.PublishEventAsync(ctx, event)
+}
+
+// startSession creates a new refresh token family for a user and issues tokens
+// The session record remembers the device it was started from
+func (s *AuthService) startSession(ctx context.Context, user *model.User) (*model.AuthResponse, error) {
+	familyID := uuid.New().String()
+
+	plainToken, refreshToken, err := s.newRefreshToken(user.ID, familyID)
+	if err != nil {
+		return nil, err
+	}
+
+	info := requestInfoFrom(ctx)
+	if err := s.sessionRepo.Create(ctx, model.NewSession(familyID, user.ID, info.userAgent, info.clientIP), refreshToken); err != nil {
+		return nil, err
+	}
+
+	return s.buildAuthResponse(user, familyID, plainToken)
+}
+
+// newRefreshToken generates a random refresh token
+// Returns the plain token (sent to the client once) and the record to store
+func (s *AuthService) newRefreshToken(userID, familyID string) (string, *model.RefreshToken, error) {
+	plainToken, err := generateSecureToken()
+	if err != nil {
+		return "", nil, err
+	}
+
+	return plainToken, model.NewRefreshToken(userID, familyID, hashToken(plainToken), s.settings.RefreshTokenExpiration), nil
+}
+
+// buildAuthResponse generates an access token for the session and builds the response
+func (s *AuthService) buildAuthResponse(user *model.User, sessionID, refreshToken string) (*model.AuthResponse, error) {
+	// Generate JWT access token
+	token, err := s.jwtService.GenerateToken(user.ID, user.Email, user.Role, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.AuthResponse{
+		UserID:        user.ID,
+		Email:         user.Email,
+		Name:          user.Name,
+		EmailVerified: user.IsEmailVerified(),
+		Role:          user.Role,
+		Token:         token,
+		ExpiresIn:     int64(s.jwtService.TokenExpiration().Seconds()),
+		RefreshToken:  refreshToken,
+	}, nil
+}
+
+// buildLink appends a token as the "token" query parameter of a base URL
+func buildLink(baseURL, token string) string {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		// Fall back to simple concatenation if the configured URL is malformed
+		return baseURL + "?token=" + url.QueryEscape(token)
+	}
+	q := u.Query()
+	q.Set("token", token)
+	u.RawQuery = q.Encode()
+	return u.String()
+}
@@ -2,6 +2,7 @@ package service
 
 import (
 	"errors"
+	"expense-tracker/auth-service/internal/model"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -11,11 +12,12 @@ import (
 // JWT (JSON Web Token) is a way to securely transmit information between parties
 // It consists of three parts: Header.Payload.Signature
 type JWTService struct {
-	// secretKey is used to sign and verify tokens
-	// NEVER expose this key! It should come from environment variables
-	secretKey []byte
+	// keys holds the asymmetric keys used to sign and verify tokens
+	// Tokens are signed with the private half of the active key; anyone with
+	// the public keys (GET /.well-known/jwks.json) can verify them
+	keys *KeySet
 
-	// tokenExpiration is how long tokens are valid (e.g., 24 hours)
+	// tokenExpiration is how long access tokens are valid (e.g., 15 minutes)
 	tokenExpiration time.Duration
 }
 
@@ -24,29 +26,48 @@ type JWTService struct {
 type Claims struct {
 	UserID string `json:"user_id"`
 	Email  string `json:"email"`
+	// Role is the user's role ("user" or "admin") when the token was issued
+	Role string `json:"role,omitempty"`
+	// SessionID is the refresh token family the access token was issued for
+	// It lets us reject access tokens whose session was revoked (logout, reuse detection)
+	SessionID string `json:"sid,omitempty"`
 	jwt.RegisteredClaims
 }
 
 // NewJWTService creates a new JWT service
-// secretKey: the secret used to sign tokens (from environment variable)
-// tokenExpiration: how long tokens are valid (e.g., 24 * time.Hour)
-func NewJWTService(secretKey string, tokenExpiration time.Duration) *JWTService {
+// keys: the signing keys (loaded from JWT_KEYS_DIR)
+// tokenExpiration: how long tokens are valid (e.g., 15 * time.Minute)
+func NewJWTService(keys *KeySet, tokenExpiration time.Duration) *JWTService {
 	return &JWTService{
-		secretKey:       []byte(secretKey), // Convert string to []byte for signing
+		keys:            keys,
 		tokenExpiration: tokenExpiration,
 	}
 }
 
+// JWKS returns the public keys that verify our tokens
+func (s *JWTService) JWKS() *model.JWKS {
+	return s.keys.JWKS()
+}
+
+// TokenExpiration returns how long generated access tokens are valid
+func (s *JWTService) TokenExpiration() time.Duration {
+	return s.tokenExpiration
+}
+
 // GenerateToken creates a new JWT token for a user
 // This token will be sent to the client and used for authenticated requests
-func (s *JWTService) GenerateToken(userID, email string) (string, error) {
+// role is copied into the token so other services can authorize locally
+// sessionID ties the token to the refresh token family it was issued for
+func (s *JWTService) GenerateToken(userID, email, role, sessionID string) (string, error) {
 	// Create the expiration time
 	expirationTime := time.Now().Add(s.tokenExpiration)
 
 	// Create the claims (the data in the token)
 	claims := &Claims{
-		UserID: userID,
-		Email:  email,
+		UserID:    userID,
+		Email:     email,
+		Role:      role,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
 			// ExpiresAt: when the token expires
 			ExpiresAt: jwt.NewNumericDate(expirationTime),
@@ -57,34 +78,80 @@ func (s *JWTService) GenerateToken(userID, email string) (string, error) {
 		},
 	}
 
-	// Create the token with the signing method and claims
-	// HS256 is a symmetric algorithm (uses the same secret to sign and verify)
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-
-	// Sign the token with our secret key
+	// Sign the token with the active key
 	// This creates the signature part of the JWT
-	tokenString, err := token.SignedString(s.secretKey)
-	if err != nil {
-		return "", err
+	return s.sign(claims)
+}
+
+// mfaTokenAudience marks MFA challenge tokens (see GenerateMFAToken)
+// Access tokens have no audience, so the two can never be mixed up
+const mfaTokenAudience = "mfa"
+
+// GenerateMFAToken creates a short-lived MFA challenge token
+// Login returns it instead of an access token when the user has MFA enabled;
+// the client exchanges it (plus a TOTP or recovery code) for real tokens.
+// It proves the password step succeeded but grants no access on its own.
+func (s *JWTService) GenerateMFAToken(userID, email string, expiration time.Duration) (string, error) {
+	now := time.Now()
+
+	claims := &Claims{
+		UserID: userID,
+		Email:  email,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(now.Add(expiration)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			Issuer:    "auth-service",
+			// Audience restricts where the token is accepted
+			Audience: jwt.ClaimStrings{mfaTokenAudience},
+		},
 	}
 
-	return tokenString, nil
+	return s.sign(claims)
 }
 
 // ValidateToken checks if a token is valid and extracts the claims
 // Returns the claims if valid, or an error if invalid
 func (s *JWTService) ValidateToken(tokenString string) (*Claims, error) {
+	claims, err := s.parseToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+
+	// MFA challenge tokens must never work as access tokens
+	if len(claims.Audience) > 0 {
+		return nil, errors.New("invalid token")
+	}
+
+	return claims, nil
+}
+
+// ValidateMFAToken checks an MFA challenge token and extracts the claims
+func (s *JWTService) ValidateMFAToken(tokenString string) (*Claims, error) {
+	return s.parseToken(tokenString, jwt.WithAudience(mfaTokenAudience))
+}
+
+// parseToken verifies the signature and expiration of a token and extracts the claims
+// Extra parser options (e.g. required audience) can be passed in
+func (s *JWTService) parseToken(tokenString string, opts ...jwt.ParserOption) (*Claims, error) {
 	// Parse the token
 	// The function validates the signature and expiration automatically
+	// Only our key algorithms are accepted (prevents "none"/HS256 confusion attacks)
+	opts = append(opts, jwt.WithValidMethods(s.keys.Algorithms()))
+
 	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		// Verify the signing method
-		// This prevents algorithm confusion attacks
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+		// The "kid" header tells us which key signed the token
+		kid, _ := token.Header["kid"].(string)
+		key := s.keys.Get(kid)
+		if key == nil {
+			return nil, errors.New("unknown signing key")
+		}
+		// The algorithm must match the key type
+		if token.Method.Alg() != key.Method.Alg() {
 			return nil, errors.New("unexpected signing method")
 		}
-		// Return the secret key for verification
-		return s.secretKey, nil
-	})
+		// Return the public key for verification
+		return key.PublicKey, nil
+	}, opts...)
 
 	if err != nil {
 		return nil, err
@@ -98,3 +165,14 @@ func (s *JWTService) ValidateToken(tokenString string) (*Claims, error) {
 
 	return claims, nil
 }
+
+// sign creates a token signed with the active key
+// The key ID goes in the "kid" header so verifiers know which public key to use
+func (s *JWTService) sign(claims *Claims) (string, error) {
+	key := s.keys.Active()
+
+	token := jwt.NewWithClaims(key.Method, claims)
+	token.Header["kid"] = key.ID
+
+	return token.SignedString(key.PrivateKey)
+}
@@ -0,0 +1,171 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"expense-tracker/auth-service/internal/model"
+	"expense-tracker/auth-service/internal/repository"
+	"strings"
+	"time"
+)
+
+// maxThrottleSubjectLength is the size of login_throttles.subject (VARCHAR(255))
+const maxThrottleSubjectLength = 255
+
+// LoginThrottledError is returned when too many logins failed recently
+// The handler turns it into 429 Too Many Requests with a Retry-After header
+type LoginThrottledError struct {
+	// RetryAfter is how long the caller has to wait
+	RetryAfter time.Duration
+}
+
+func (e *LoginThrottledError) Error() string {
+	return "too many failed login attempts"
+}
+
+// ThrottleSettings holds the brute force protection limits
+type ThrottleSettings struct {
+	// MaxAttemptsPerAccount is how many failed logins lock an account (0 = no limit)
+	MaxAttemptsPerAccount int
+
+	// MaxAttemptsPerIP is how many failed logins lock a client IP (0 = no limit)
+	MaxAttemptsPerIP int
+
+	// LockoutDuration is how long the first lockout lasts
+	// Every further failure after a lockout doubles it (exponential backoff)
+	LockoutDuration time.Duration
+
+	// MaxLockoutDuration caps the exponential backoff
+	MaxLockoutDuration time.Duration
+
+	// AttemptWindow is how long counters are kept without new failures
+	// After a quiet AttemptWindow (counted from the end of a lockout) they start over
+	AttemptWindow time.Duration
+}
+
+// LoginThrottler counts failed logins per account and per client IP
+// and locks them out temporarily when they cross the limit
+type LoginThrottler struct {
+	repo     repository.LoginThrottleRepository
+	settings ThrottleSettings
+}
+
+// NewLoginThrottler creates a new login throttler
+func NewLoginThrottler(repo repository.LoginThrottleRepository, settings ThrottleSettings) *LoginThrottler {
+	return &LoginThrottler{
+		repo:     repo,
+		settings: settings,
+	}
+}
+
+// Check returns a *LoginThrottledError if the account or the client IP is locked
+// Call it before checking the password so locked accounts can't keep guessing
+func (t *LoginThrottler) Check(ctx context.Context, email, clientIP string) error {
+	now := time.Now()
+	var retryAfter time.Duration
+
+	for _, key := range t.keys(email, clientIP) {
+		throttle, err := t.repo.Find(ctx, key.scope, key.subject)
+		if err != nil {
+			return err
+		}
+		if throttle != nil && throttle.IsLocked(now) {
+			// Wait for the longest of the two locks
+			if wait := throttle.LockedUntil.Sub(now); wait > retryAfter {
+				retryAfter = wait
+			}
+		}
+	}
+
+	if retryAfter > 0 {
+		return &LoginThrottledError{RetryAfter: retryAfter}
+	}
+	return nil
+}
+
+// RecordFailure counts a failed login for the account and the client IP
+// Returns when the account lock ends if this failure locked the account
+// (zero time otherwise), so the caller can notify the user
+func (t *LoginThrottler) RecordFailure(ctx context.Context, email, clientIP string) (time.Time, error) {
+	now := time.Now()
+	resetBefore := now.Add(-t.settings.AttemptWindow)
+	var accountLockedUntil time.Time
+
+	for _, key := range t.keys(email, clientIP) {
+		failures, err := t.repo.RecordFailure(ctx, key.scope, key.subject, resetBefore)
+		if err != nil {
+			return time.Time{}, err
+		}
+
+		if failures < key.limit {
+			continue
+		}
+
+		until := now.Add(t.lockoutDuration(failures - key.limit))
+		if err := t.repo.Lock(ctx, key.scope, key.subject, until); err != nil {
+			return time.Time{}, err
+		}
+		if key.scope == model.ThrottleScopeEmail {
+			accountLockedUntil = until
+		}
+	}
+
+	return accountLockedUntil, nil
+}
+
+// RecordSuccess clears the account's failure count after a successful login
+// The IP counter is left alone - logging into your own account shouldn't
+// reset the count of guesses made against other accounts
+func (t *LoginThrottler) RecordSuccess(ctx context.Context, email string) error {
+	return t.repo.Reset(ctx, model.ThrottleScopeEmail, throttleSubject(normalizeEmail(email)))
+}
+
+// lockoutDuration doubles the lockout for every failure past the limit
+// 0 -> LockoutDuration, 1 -> 2x, 2 -> 4x, ... capped at MaxLockoutDuration
+func (t *LoginThrottler) lockoutDuration(failuresPastLimit int) time.Duration {
+	duration := t.settings.LockoutDuration
+	for i := 0; i < failuresPastLimit && duration < t.settings.MaxLockoutDuration; i++ {
+		duration *= 2
+	}
+	if duration > t.settings.MaxLockoutDuration {
+		duration = t.settings.MaxLockoutDuration
+	}
+	return duration
+}
+
+// throttleKey is one counter checked for a login attempt
+type throttleKey struct {
+	scope   string
+	subject string
+	limit   int
+}
+
+// keys returns the counters that apply to a login attempt
+// Scopes without a limit (or without a subject) are skipped
+func (t *LoginThrottler) keys(email, clientIP string) []throttleKey {
+	var keys []throttleKey
+	if email = normalizeEmail(email); email != "" && t.settings.MaxAttemptsPerAccount > 0 {
+		keys = append(keys, throttleKey{model.ThrottleScopeEmail, throttleSubject(email), t.settings.MaxAttemptsPerAccount})
+	}
+	if clientIP != "" && t.settings.MaxAttemptsPerIP > 0 {
+		keys = append(keys, throttleKey{model.ThrottleScopeIP, throttleSubject(clientIP), t.settings.MaxAttemptsPerIP})
+	}
+	return keys
+}
+
+// throttleSubject returns the subject a counter is stored under
+// Subjects too long for the column (e.g. a made-up 10 KB "email") are hashed,
+// so they still get their own counter instead of failing the login with an error
+func throttleSubject(subject string) string {
+	if len(subject) <= maxThrottleSubjectLength {
+		return subject
+	}
+	sum := sha256.Sum256([]byte(subject))
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// normalizeEmail makes "User@Example.com " and "user@example.com" count as the same account
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
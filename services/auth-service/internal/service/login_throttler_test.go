@@ -0,0 +1,80 @@
+package service
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLockoutDuration(t *testing.T) {
+	throttler := &LoginThrottler{settings: ThrottleSettings{
+		LockoutDuration:    15 * time.Minute,
+		MaxLockoutDuration: 2 * time.Hour,
+	}}
+
+	tests := []struct {
+		failuresPastLimit int
+		want              time.Duration
+	}{
+		{0, 15 * time.Minute},
+		{1, 30 * time.Minute},
+		{2, time.Hour},
+		{3, 2 * time.Hour},
+		{4, 2 * time.Hour},
+		{100, 2 * time.Hour},
+	}
+
+	for _, tt := range tests {
+		if got := throttler.lockoutDuration(tt.failuresPastLimit); got != tt.want {
+			t.Errorf("lockoutDuration(%d) = %v, want %v", tt.failuresPastLimit, got, tt.want)
+		}
+	}
+}
+
+func TestThrottleSubject(t *testing.T) {
+	long := strings.Repeat("a", 10000) + "@example.com"
+
+	tests := []struct {
+		name    string
+		subject string
+		want    string
+	}{
+		{"email", "user@example.com", "user@example.com"},
+		{"ip", "203.0.113.7", "203.0.113.7"},
+		{"column size", strings.Repeat("a", maxThrottleSubjectLength), strings.Repeat("a", maxThrottleSubjectLength)},
+	}
+	for _, tt := range tests {
+		if got := throttleSubject(tt.subject); got != tt.want {
+			t.Errorf("%s: throttleSubject = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+
+	hashed := throttleSubject(long)
+	if len(hashed) > maxThrottleSubjectLength || !strings.HasPrefix(hashed, "sha256:") {
+		t.Errorf("throttleSubject of a long subject = %q, want a sha256 hash", hashed)
+	}
+	if throttleSubject(long) != hashed {
+		t.Error("throttleSubject of a long subject is not stable")
+	}
+	if throttleSubject(long+"x") == hashed {
+		t.Error("different long subjects share a counter")
+	}
+}
+
+func TestThrottleKeys(t *testing.T) {
+	throttler := &LoginThrottler{settings: ThrottleSettings{MaxAttemptsPerAccount: 5, MaxAttemptsPerIP: 50}}
+
+	keys := throttler.keys(" User@Example.com ", "203.0.113.7")
+	if len(keys) != 2 || keys[0].subject != "user@example.com" || keys[1].subject != "203.0.113.7" {
+		t.Errorf("keys = %+v, want the normalized email and the IP", keys)
+	}
+
+	if keys := throttler.keys("", ""); len(keys) != 0 {
+		t.Errorf("keys without email and IP = %+v, want none", keys)
+	}
+
+	throttler.settings.MaxAttemptsPerIP = 0
+	if keys := throttler.keys("user@example.com", "203.0.113.7"); len(keys) != 1 {
+		t.Errorf("keys without an IP limit = %+v, want only the email", keys)
+	}
+}
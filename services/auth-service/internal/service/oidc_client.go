@@ -0,0 +1,351 @@
+package service
+
+import (
+	"context"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"expense-tracker/auth-service/internal/model"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// minProviderKeysRefetchInterval limits key set refreshes triggered by unknown key IDs
+const minProviderKeysRefetchInterval = time.Minute
+
+// OIDCSettings configures the OpenID Connect identity provider
+type OIDCSettings struct {
+	// IssuerURL is the provider's issuer (e.g., "https://accounts.google.com")
+	// The discovery document is read from IssuerURL + "/.well-known/openid-configuration"
+	IssuerURL string
+
+	// ClientID and ClientSecret are the credentials of this app at the provider
+	// ClientSecret may be empty for public clients (PKCE only)
+	ClientID     string
+	ClientSecret string
+
+	// RedirectURL is where the provider sends the user back (GET /auth/oidc/callback)
+	// Must be registered at the provider
+	RedirectURL string
+
+	// Scopes requested from the provider - must include "openid" and "email"
+	Scopes []string
+}
+
+// OIDCUserInfo is the identity the provider vouched for in the ID token
+type OIDCUserInfo struct {
+	Issuer        string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+// OIDCClient talks to an OpenID Connect provider: it builds the authorization
+// URL, exchanges the code for tokens and verifies the ID token
+type OIDCClient struct {
+	settings   OIDCSettings
+	httpClient *http.Client
+
+	// mu protects the discovery document and the provider's keys
+	mu        sync.Mutex
+	discovery *oidcDiscovery
+	keys      map[string]*rsa.PublicKey
+	lastFetch time.Time // Last key set fetch
+}
+
+// oidcDiscovery is the part of the discovery document we use
+type oidcDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// oidcIDTokenClaims are the ID token claims we check or use
+type oidcIDTokenClaims struct {
+	jwt.RegisteredClaims
+	Nonce           string `json:"nonce"`
+	AuthorizedParty string `json:"azp"`
+	Email           string `json:"email"`
+	// Some providers send email_verified as the string "true"
+	EmailVerified interface{} `json:"email_verified"`
+	Name          string      `json:"name"`
+}
+
+// NewOIDCClient creates a new OpenID Connect client
+// The discovery document is fetched on first use, so the service starts
+// even if the provider is down
+func NewOIDCClient(settings OIDCSettings) *OIDCClient {
+	settings.IssuerURL = strings.TrimSuffix(settings.IssuerURL, "/")
+	return &OIDCClient{
+		settings: settings,
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+		keys: make(map[string]*rsa.PublicKey),
+	}
+}
+
+// AuthorizationURL returns the provider URL the user is redirected to
+// codeChallenge is the S256 PKCE challenge of the code verifier
+func (c *OIDCClient) AuthorizationURL(ctx context.Context, state, nonce, codeChallenge string) (string, error) {
+	discovery, err := c.getDiscovery(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	u, err := url.Parse(discovery.AuthorizationEndpoint)
+	if err != nil {
+		return "", fmt.Errorf("identity provider unavailable: invalid authorization endpoint: %w", err)
+	}
+
+	q := u.Query()
+	q.Set("response_type", "code")
+	q.Set("client_id", c.settings.ClientID)
+	q.Set("redirect_uri", c.settings.RedirectURL)
+	q.Set("scope", strings.Join(c.settings.Scopes, " "))
+	q.Set("state", state)
+	q.Set("nonce", nonce)
+	q.Set("code_challenge", codeChallenge)
+	q.Set("code_challenge_method", "S256")
+	u.RawQuery = q.Encode()
+
+	return u.String(), nil
+}
+
+// Exchange redeems an authorization code and returns the verified identity
+// codeVerifier proves we started the login (PKCE); nonce must match the ID token
+func (c *OIDCClient) Exchange(ctx context.Context, code, codeVerifier, nonce string) (*OIDCUserInfo, error) {
+	discovery, err := c.getDiscovery(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", c.settings.RedirectURL)
+	form.Set("code_verifier", codeVerifier)
+	if c.settings.ClientSecret == "" {
+		// Public client - identified by client_id only
+		form.Set("client_id", c.settings.ClientID)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", discovery.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if c.settings.ClientSecret != "" {
+		// client_secret_basic - credentials are form-encoded first (RFC 6749 section 2.3.1)
+		req.SetBasicAuth(url.QueryEscape(c.settings.ClientID), url.QueryEscape(c.settings.ClientSecret))
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("identity provider unavailable: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 500 {
+		return nil, fmt.Errorf("identity provider unavailable: token endpoint returned status %d", resp.StatusCode)
+	}
+	if resp.StatusCode != http.StatusOK {
+		// invalid_grant and friends: bad, expired or reused code
+		return nil, fmt.Errorf("authorization code rejected by identity provider (status %d)", resp.StatusCode)
+	}
+
+	var tokens struct {
+		IDToken string `json:"id_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tokens); err != nil {
+		return nil, fmt.Errorf("identity provider unavailable: invalid token response: %w", err)
+	}
+	if tokens.IDToken == "" {
+		return nil, errors.New("identity provider unavailable: token response has no ID token")
+	}
+
+	return c.verifyIDToken(ctx, discovery, tokens.IDToken, nonce)
+}
+
+// verifyIDToken checks the ID token's signature and claims
+func (c *OIDCClient) verifyIDToken(ctx context.Context, discovery *oidcDiscovery, idToken, nonce string) (*OIDCUserInfo, error) {
+	var claims oidcIDTokenClaims
+	_, err := jwt.ParseWithClaims(idToken, &claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return c.key(ctx, discovery, kid)
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg()}),
+		jwt.WithIssuer(discovery.Issuer),
+		jwt.WithAudience(c.settings.ClientID),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ID token: %w", err)
+	}
+
+	// The nonce ties the ID token to this login (no replayed tokens)
+	if claims.Nonce == "" || claims.Nonce != nonce {
+		return nil, errors.New("invalid ID token: nonce mismatch")
+	}
+	// A token issued to several audiences must name us as the authorized party
+	if (len(claims.Audience) > 1 || claims.AuthorizedParty != "") && claims.AuthorizedParty != c.settings.ClientID {
+		return nil, errors.New("invalid ID token: unexpected authorized party")
+	}
+	if claims.Subject == "" {
+		return nil, errors.New("invalid ID token: missing subject")
+	}
+
+	emailVerified := false
+	switch v := claims.EmailVerified.(type) {
+	case bool:
+		emailVerified = v
+	case string:
+		emailVerified = v == "true"
+	}
+
+	return &OIDCUserInfo{
+		Issuer:        claims.Issuer,
+		Subject:       claims.Subject,
+		Email:         claims.Email,
+		EmailVerified: emailVerified,
+		Name:          claims.Name,
+	}, nil
+}
+
+// getDiscovery returns the discovery document, fetching it the first time
+// A failed fetch is retried on the next login
+func (c *OIDCClient) getDiscovery(ctx context.Context) (*oidcDiscovery, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.discovery != nil {
+		return c.discovery, nil
+	}
+
+	var discovery oidcDiscovery
+	if err := c.getJSON(ctx, c.settings.IssuerURL+"/.well-known/openid-configuration", &discovery); err != nil {
+		return nil, err
+	}
+
+	// The document must belong to the issuer we were configured with
+	if strings.TrimSuffix(discovery.Issuer, "/") != c.settings.IssuerURL {
+		return nil, fmt.Errorf("identity provider unavailable: discovery issuer %q does not match %q", discovery.Issuer, c.settings.IssuerURL)
+	}
+	if discovery.AuthorizationEndpoint == "" || discovery.TokenEndpoint == "" || discovery.JWKSURI == "" {
+		return nil, errors.New("identity provider unavailable: incomplete discovery document")
+	}
+
+	c.discovery = &discovery
+	return c.discovery, nil
+}
+
+// key returns the provider's public key with the given key ID
+// Unknown key IDs trigger a (rate-limited) refetch, so rotated keys are picked up
+func (c *OIDCClient) key(ctx context.Context, discovery *oidcDiscovery, kid string) (*rsa.PublicKey, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if key := c.lookupKey(kid); key != nil {
+		return key, nil
+	}
+
+	if time.Since(c.lastFetch) < minProviderKeysRefetchInterval {
+		return nil, errors.New("unknown signing key")
+	}
+	c.lastFetch = time.Now()
+
+	var body struct {
+		Keys []model.JWK `json:"keys"`
+	}
+	if err := c.getJSON(ctx, discovery.JWKSURI, &body); err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]*rsa.PublicKey, len(body.Keys))
+	for _, k := range body.Keys {
+		key, err := parseProviderJWK(k)
+		if err != nil {
+			continue // Skip keys we can't use (encryption keys, other algorithms)
+		}
+		keys[k.KeyID] = key
+	}
+	c.keys = keys
+
+	if key := c.lookupKey(kid); key != nil {
+		return key, nil
+	}
+	return nil, errors.New("unknown signing key")
+}
+
+// lookupKey finds a cached key - mu must be held
+// Tokens without a "kid" are accepted only if the provider has a single key
+func (c *OIDCClient) lookupKey(kid string) *rsa.PublicKey {
+	if kid == "" && len(c.keys) == 1 {
+		for _, key := range c.keys {
+			return key
+		}
+	}
+	return c.keys[kid]
+}
+
+// getJSON fetches a provider document
+func (c *OIDCClient) getJSON(ctx context.Context, url string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("identity provider unavailable: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("identity provider unavailable: %s returned status %d", url, resp.StatusCode)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("identity provider unavailable: invalid response from %s: %w", url, err)
+	}
+	return nil
+}
+
+// parseProviderJWK converts an RSA signing key from the provider's JWKS
+func parseProviderJWK(k model.JWK) (*rsa.PublicKey, error) {
+	if k.KeyType != "RSA" || (k.Algorithm != "" && k.Algorithm != "RS256") || (k.Use != "" && k.Use != "sig") {
+		return nil, fmt.Errorf("unsupported key type %q / algorithm %q", k.KeyType, k.Algorithm)
+	}
+
+	n, err := base64.RawURLEncoding.DecodeString(k.N)
+	if err != nil {
+		return nil, fmt.Errorf("invalid modulus: %w", err)
+	}
+	e, err := base64.RawURLEncoding.DecodeString(k.E)
+	if err != nil {
+		return nil, fmt.Errorf("invalid exponent: %w", err)
+	}
+
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(n),
+		E: int(new(big.Int).SetBytes(e).Int64()),
+	}, nil
+}
+
+// pkceChallenge returns the S256 code challenge for a PKCE code verifier
+func pkceChallenge(codeVerifier string) string {
+	sum := sha256.Sum256([]byte(codeVerifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
@@ -0,0 +1,190 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"expense-tracker/auth-service/internal/model"
+	"log"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// oidcLoginExpiration is how long the user has to sign in at the provider
+const oidcLoginExpiration = 10 * time.Minute
+
+// SetOIDCClient enables OpenID Connect login (optional)
+func (s *AuthService) SetOIDCClient(client *OIDCClient) {
+	s.oidcClient = client
+}
+
+// OIDCEnabled reports whether OpenID Connect login is configured
+func (s *AuthService) OIDCEnabled() bool {
+	return s.oidcClient != nil
+}
+
+// StartOIDCLogin begins an authorization code + PKCE login
+// Returns the provider URL to redirect to and the state, which the handler
+// also stores in a cookie so the callback can check it came from this browser
+func (s *AuthService) StartOIDCLogin(ctx context.Context) (string, string, error) {
+	if s.oidcClient == nil {
+		return "", "", errors.New("OpenID Connect login not configured")
+	}
+
+	state, err := generateSecureToken()
+	if err != nil {
+		return "", "", err
+	}
+	nonce, err := generateSecureToken()
+	if err != nil {
+		return "", "", err
+	}
+	codeVerifier, err := generateSecureToken()
+	if err != nil {
+		return "", "", err
+	}
+
+	// Only the hash of the state is stored, like every other token
+	loginState := model.NewOIDCLoginState(hashToken(state), nonce, codeVerifier, oidcLoginExpiration)
+	if err := s.oidcRepo.CreateLoginState(ctx, loginState); err != nil {
+		return "", "", err
+	}
+
+	authURL, err := s.oidcClient.AuthorizationURL(ctx, state, nonce, pkceChallenge(codeVerifier))
+	if err != nil {
+		return "", "", err
+	}
+
+	return authURL, state, nil
+}
+
+// CompleteOIDCLogin finishes a login when the provider redirects back
+// Steps:
+//  1. Consume the login state (single use, must not be expired)
+//  2. Exchange the code for an ID token and verify it
+//  3. Find the user linked to the provider account, or link one by verified email
+//     (creating the user if nobody has that email yet). Existing accounts are only
+//     linked if they verified the email too - otherwise whoever registered the
+//     address first (without owning it) would share the account with its owner
+//  4. Issue tokens - or an MFA challenge, like a password login
+func (s *AuthService) CompleteOIDCLogin(ctx context.Context, state, code string) (*model.AuthResponse, error) {
+	if s.oidcClient == nil {
+		return nil, errors.New("OpenID Connect login not configured")
+	}
+
+	loginState, err := s.oidcRepo.ConsumeLoginState(ctx, hashToken(state))
+	if err != nil {
+		return nil, err
+	}
+	if loginState == nil || loginState.IsExpired() {
+		err := errors.New("invalid or expired login state")
+		s.auditFailure(ctx, model.AuthEventOIDCLogin, "", "", err)
+		return nil, err
+	}
+
+	info, err := s.oidcClient.Exchange(ctx, code, loginState.CodeVerifier, loginState.Nonce)
+	if err != nil {
+		s.auditFailure(ctx, model.AuthEventOIDCLogin, "", "", err)
+		return nil, err
+	}
+
+	// Returning users are found by provider account, even if their email changed since
+	identity, err := s.oidcRepo.FindIdentity(ctx, info.Issuer, info.Subject)
+	if err != nil {
+		return nil, err
+	}
+	if identity != nil {
+		user, err := s.userRepo.FindByID(ctx, identity.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if user == nil {
+			// The linked account was deleted
+			err := errors.New("account not found")
+			s.auditFailure(ctx, model.AuthEventOIDCLogin, identity.UserID, info.Email, err)
+			return nil, err
+		}
+		return s.finishLogin(ctx, user, model.AuthEventOIDCLogin)
+	}
+
+	// Linking by email is only safe if the provider checked the address
+	if info.Email == "" || !info.EmailVerified {
+		err := errors.New("email address not verified by identity provider")
+		s.auditFailure(ctx, model.AuthEventOIDCLogin, "", info.Email, err)
+		return nil, err
+	}
+
+	user, err := s.userRepo.FindByEmail(ctx, info.Email)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		user, err = s.createOIDCUser(ctx, info)
+		if err != nil {
+			return nil, err
+		}
+	} else if !user.IsEmailVerified() {
+		// The account may have been registered by someone who doesn't own the
+		// address - its password, sessions and API keys would keep working
+		err := errors.New("account email not verified")
+		s.auditFailure(ctx, model.AuthEventOIDCLogin, user.ID, info.Email, err)
+		return nil, err
+	}
+
+	if err := s.oidcRepo.LinkIdentity(ctx, model.NewOIDCIdentity(info.Issuer, info.Subject, user.ID, info.Email)); err != nil {
+		return nil, err
+	}
+	log.Printf("Linked %s account %s to user %s", info.Issuer, info.Subject, user.ID)
+
+	// LinkIdentity marked a new user's address verified
+	if user.EmailVerifiedAt == nil {
+		now := time.Now()
+		user.EmailVerifiedAt = &now
+	}
+
+	return s.finishLogin(ctx, user, model.AuthEventOIDCLogin)
+}
+
+// createOIDCUser registers a user who signed in with the provider for the first time
+// They get a random password they don't know - "forgot password" sets a real one.
+// Until then sensitive changes accept a recent sign-in instead (see reauthenticate)
+func (s *AuthService) createOIDCUser(ctx context.Context, info *OIDCUserInfo) (*model.User, error) {
+	password, err := generateSecureToken()
+	if err != nil {
+		return nil, err
+	}
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+
+	name := info.Name
+	if name == "" {
+		name = info.Email
+	}
+
+	user := model.NewUser(info.Email, string(passwordHash), name)
+	user.HasPassword = false
+	if err := s.userRepo.Create(ctx, user); err != nil {
+		return nil, err
+	}
+	s.audit(ctx, model.AuthEventRegister, user.ID, user.Email, model.AuthOutcomeSuccess, "via "+info.Issuer)
+
+	// Welcome email - without a verification link, the provider verified the address
+	if s.eventPublisher != nil {
+		event := &Event{
+			EventType: "user.registered",
+			UserID:    user.ID,
+			UserEmail: user.Email,
+			Timestamp: time.Now(),
+			Data: map[string]interface{}{
+				"user_id": user.ID,
+				"email":   user.Email,
+				"name":    user.Name,
+			},
+		}
+		s.eventPublisher.PublishEventAsync(ctx, event)
+	}
+
+	return user, nil
+}
@@ -0,0 +1,26 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+)
+
+// generateSecureToken creates a random, URL-safe token
+// 32 bytes of randomness (256 bits) makes tokens impossible to guess
+func generateSecureToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashToken returns the hex-encoded SHA-256 hash of a token
+// We store only hashes so a database leak doesn't expose usable tokens
+// SHA-256 (not bcrypt) is fine here because the tokens are long and random
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
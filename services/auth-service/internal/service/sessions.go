@@ -0,0 +1,49 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"expense-tracker/auth-service/internal/model"
+
+	"github.com/google/uuid"
+)
+
+// ListSessions returns the user's active sessions (one per signed-in device)
+// currentSessionID is the session of the caller's token, marked as current
+func (s *AuthService) ListSessions(ctx context.Context, userID, currentSessionID string) (*model.ListSessionsResponse, error) {
+	sessions, err := s.sessionRepo.ListActiveByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, session := range sessions {
+		session.Current = session.ID == currentSessionID
+	}
+
+	return &model.ListSessionsResponse{Sessions: sessions}, nil
+}
+
+// RevokeSession signs the user out on one device
+// Its refresh tokens are revoked, so access tokens carrying its "sid" stop
+// validating immediately and it can't be refreshed
+func (s *AuthService) RevokeSession(ctx context.Context, userID, sessionID string) error {
+	// Not a UUID can't be a session - avoids a database error
+	if _, err := uuid.Parse(sessionID); err != nil {
+		return errors.New("session not found")
+	}
+
+	session, err := s.sessionRepo.FindActiveByID(ctx, sessionID, userID)
+	if err != nil {
+		return err
+	}
+	if session == nil {
+		return errors.New("session not found")
+	}
+
+	if err := s.refreshTokenRepo.RevokeFamily(ctx, session.ID); err != nil {
+		return err
+	}
+	s.audit(ctx, model.AuthEventSessionRevoke, userID, "", model.AuthOutcomeSuccess, "session "+session.ID)
+
+	return nil
+}
@@ -0,0 +1,239 @@
+package service
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"expense-tracker/auth-service/internal/model"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// SigningKey is one key used to sign or verify JWTs
+// Asymmetric keys let other services verify tokens with only the public half
+type SigningKey struct {
+	// ID is the key ID ("kid") written to the token header and the JWKS
+	ID string
+
+	// Method is the JWT algorithm for this key type (RS256 for RSA, EdDSA for Ed25519)
+	Method jwt.SigningMethod
+
+	// PrivateKey signs tokens - nil for verify-only (retired) keys
+	PrivateKey crypto.Signer
+
+	// PublicKey verifies tokens and is published in the JWKS
+	PublicKey crypto.PublicKey
+}
+
+// KeySet holds every key that tokens may be signed with
+// Exactly one key (the active key) signs new tokens; all keys verify.
+//
+// Key rotation:
+// 1. Add the new key - it is published in the JWKS but doesn't sign yet
+// 2. Once downstream caches have picked it up, make it the active key
+// 3. After the longest token lifetime has passed, remove the old key
+type KeySet struct {
+	keys   map[string]*SigningKey
+	active *SigningKey
+}
+
+// minRSAKeyBits is the smallest RSA key we accept (NIST recommendation)
+const minRSAKeyBits = 2048
+
+// LoadKeySet reads PEM keys from a directory
+// Each file "<kid>.pem" holds one key; the file name (without .pem) is its key ID.
+// Private keys (PKCS#8 or PKCS#1) can sign, public keys (PKIX) only verify.
+// activeKeyID selects the signing key - it may be empty if there is only one private key.
+func LoadKeySet(dir, activeKeyID string) (*KeySet, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no *.pem keys found in %s", dir)
+	}
+	sort.Strings(files)
+
+	set := &KeySet{keys: make(map[string]*SigningKey)}
+	var signers []string
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		kid := strings.TrimSuffix(filepath.Base(file), ".pem")
+		key, err := parseSigningKey(kid, data)
+		if err != nil {
+			return nil, fmt.Errorf("key %s: %w", file, err)
+		}
+
+		set.keys[kid] = key
+		if key.PrivateKey != nil {
+			signers = append(signers, kid)
+		}
+	}
+
+	// Pick the active key
+	if activeKeyID == "" {
+		if len(signers) != 1 {
+			return nil, fmt.Errorf("found %d private keys in %s - set the active key ID", len(signers), dir)
+		}
+		activeKeyID = signers[0]
+	}
+	active, ok := set.keys[activeKeyID]
+	if !ok {
+		return nil, fmt.Errorf("active key %q not found in %s", activeKeyID, dir)
+	}
+	if active.PrivateKey == nil {
+		return nil, fmt.Errorf("active key %q is a public key - it can't sign", activeKeyID)
+	}
+	set.active = active
+
+	return set, nil
+}
+
+// NewEphemeralKeySet generates a throwaway RSA key that lives only in memory
+// Only for local development: tokens stop validating on restart and
+// replicas can't verify each other's tokens
+func NewEphemeralKeySet() (*KeySet, error) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, minRSAKeyBits)
+	if err != nil {
+		return nil, err
+	}
+
+	key := &SigningKey{
+		ID:         "ephemeral-" + uuid.New().String(),
+		Method:     jwt.SigningMethodRS256,
+		PrivateKey: privateKey,
+		PublicKey:  privateKey.Public(),
+	}
+
+	return &KeySet{
+		keys:   map[string]*SigningKey{key.ID: key},
+		active: key,
+	}, nil
+}
+
+// Active returns the key that signs new tokens
+func (ks *KeySet) Active() *SigningKey {
+	return ks.active
+}
+
+// Get returns the key with the given ID, or nil if there is none
+func (ks *KeySet) Get(kid string) *SigningKey {
+	return ks.keys[kid]
+}
+
+// Algorithms returns the JWT algorithms of all keys (for parser validation)
+func (ks *KeySet) Algorithms() []string {
+	seen := make(map[string]bool)
+	var algs []string
+	for _, key := range ks.keys {
+		alg := key.Method.Alg()
+		if !seen[alg] {
+			seen[alg] = true
+			algs = append(algs, alg)
+		}
+	}
+	sort.Strings(algs)
+	return algs
+}
+
+// JWKS returns the public keys as a JSON Web Key Set (RFC 7517)
+func (ks *KeySet) JWKS() *model.JWKS {
+	// Sort by key ID so the output is stable
+	ids := make([]string, 0, len(ks.keys))
+	for kid := range ks.keys {
+		ids = append(ids, kid)
+	}
+	sort.Strings(ids)
+
+	jwks := &model.JWKS{Keys: make([]model.JWK, 0, len(ids))}
+	for _, kid := range ids {
+		key := ks.keys[kid]
+		jwk := model.JWK{
+			KeyID:     key.ID,
+			Use:       "sig",
+			Algorithm: key.Method.Alg(),
+		}
+
+		switch pub := key.PublicKey.(type) {
+		case *rsa.PublicKey:
+			jwk.KeyType = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.KeyType = "OKP"
+			jwk.Curve = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		}
+
+		jwks.Keys = append(jwks.Keys, jwk)
+	}
+
+	return jwks
+}
+
+// parseSigningKey decodes one PEM block into a SigningKey
+func parseSigningKey(kid string, data []byte) (*SigningKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM data found")
+	}
+
+	var parsed interface{}
+	var err error
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block type %q", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	key := &SigningKey{ID: kid}
+	switch k := parsed.(type) {
+	case *rsa.PrivateKey:
+		key.PrivateKey = k
+		key.PublicKey = k.Public()
+	case ed25519.PrivateKey:
+		key.PrivateKey = k
+		key.PublicKey = k.Public()
+	case *rsa.PublicKey, ed25519.PublicKey:
+		key.PublicKey = k
+	default:
+		return nil, fmt.Errorf("unsupported key type %T (use RSA or Ed25519)", parsed)
+	}
+
+	// The key type decides the algorithm
+	switch pub := key.PublicKey.(type) {
+	case *rsa.PublicKey:
+		if pub.N.BitLen() < minRSAKeyBits {
+			return nil, fmt.Errorf("RSA key is %d bits, need at least %d", pub.N.BitLen(), minRSAKeyBits)
+		}
+		key.Method = jwt.SigningMethodRS256
+	case ed25519.PublicKey:
+		key.Method = jwt.SigningMethodEdDSA
+	}
+
+	return key, nil
+}
@@ -0,0 +1,122 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// TOTP (Time-based One-Time Password, RFC 6238) parameters
+// These are the defaults every authenticator app (Google Authenticator, Authy, ...) supports
+const (
+	totpDigits = 6                // Length of a code
+	totpPeriod = 30 * time.Second // How long a code is valid
+	totpSkew   = 1                // Accept codes one period before/after (clock drift)
+)
+
+// totpEncoding is unpadded base32 - the format authenticator apps expect for secrets
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// generateTOTPSecret creates a new random TOTP secret (base32 encoded)
+// 20 bytes (160 bits) is the key length recommended by RFC 4226 for HMAC-SHA1
+func generateTOTPSecret() (string, error) {
+	b := make([]byte, 20)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return totpEncoding.EncodeToString(b), nil
+}
+
+// totpCode computes the code for a secret and time step (RFC 4226 HOTP)
+func totpCode(secret []byte, step uint64) string {
+	// HMAC-SHA1 of the big-endian step counter
+	var counter [8]byte
+	binary.BigEndian.PutUint64(counter[:], step)
+	mac := hmac.New(sha1.New, secret)
+	mac.Write(counter[:])
+	sum := mac.Sum(nil)
+
+	// Dynamic truncation: the low 4 bits of the last byte pick an offset
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	return fmt.Sprintf("%0*d", totpDigits, value%1000000)
+}
+
+// validateTOTP checks a code against a base32 secret at time t
+// Returns the matched time step so callers can reject reuse of the same code
+func validateTOTP(secret, code string, t time.Time) (int64, bool) {
+	key, err := totpEncoding.DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		return 0, false
+	}
+
+	code = strings.TrimSpace(code)
+	if len(code) != totpDigits {
+		return 0, false
+	}
+
+	current := t.Unix() / int64(totpPeriod/time.Second)
+	for i := -totpSkew; i <= totpSkew; i++ {
+		step := current + int64(i)
+		expected := totpCode(key, uint64(step))
+		// Constant-time comparison prevents timing attacks
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+
+	return 0, false
+}
+
+// totpURI builds the otpauth:// URI that authenticator apps import (usually as a QR code)
+// Format: otpauth://totp/Issuer:account?secret=...&issuer=...
+func totpURI(issuer, account, secret string) string {
+	label := url.PathEscape(issuer + ":" + account)
+
+	q := url.Values{}
+	q.Set("secret", secret)
+	q.Set("issuer", issuer)
+	q.Set("algorithm", "SHA1")
+	q.Set("digits", fmt.Sprintf("%d", totpDigits))
+	q.Set("period", fmt.Sprintf("%d", int(totpPeriod/time.Second)))
+
+	// Some authenticator apps show "+" literally, so encode spaces as %20
+	return "otpauth://totp/" + label + "?" + strings.ReplaceAll(q.Encode(), "+", "%20")
+}
+
+// recoveryCodeAlphabet has 32 characters (so byte%32 is unbiased)
+// and leaves out look-alikes: no "0", "i", "l" or "o"
+const recoveryCodeAlphabet = "abcdefghjkmnpqrstuvwxyz123456789"
+
+// generateRecoveryCode creates a one-time recovery code like "k7m2p-x9q4t"
+func generateRecoveryCode() (string, error) {
+	b := make([]byte, 10)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	for i, v := range b {
+		if i == 5 {
+			sb.WriteByte('-')
+		}
+		sb.WriteByte(recoveryCodeAlphabet[int(v)%len(recoveryCodeAlphabet)])
+	}
+	return sb.String(), nil
+}
+
+// normalizeRecoveryCode makes recovery code input forgiving (case, dashes, spaces)
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	code = strings.ReplaceAll(code, "-", "")
+	code = strings.ReplaceAll(code, " ", "")
+	return code
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfcSecret is the SHA-1 key of the RFC 6238 test vectors ("12345678901234567890")
+var rfcSecret = totpEncoding.EncodeToString([]byte("12345678901234567890"))
+
+func TestTOTPCode(t *testing.T) {
+	// RFC 6238 appendix B (SHA-1); the RFC uses 8 digits, these are the last 6
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+
+	for _, tt := range tests {
+		step := uint64(tt.unix / int64(totpPeriod/time.Second))
+		if got := totpCode([]byte("12345678901234567890"), step); got != tt.want {
+			t.Errorf("totpCode at %d = %q, want %q", tt.unix, got, tt.want)
+		}
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	now := time.Unix(1111111111, 0) // Code 050471, step 37037037
+	code := "050471"
+
+	tests := []struct {
+		name     string
+		secret   string
+		code     string
+		at       time.Time
+		wantOK   bool
+		wantStep int64
+	}{
+		{"current period", rfcSecret, code, now, true, 37037037},
+		{"lowercase secret", strings.ToLower(rfcSecret), code, now, true, 37037037},
+		{"surrounding spaces", rfcSecret, " " + code + " ", now, true, 37037037},
+		{"clock one period behind", rfcSecret, code, now.Add(-totpPeriod), true, 37037037},
+		{"clock one period ahead", rfcSecret, code, now.Add(totpPeriod), true, 37037037},
+		{"clock two periods behind", rfcSecret, code, now.Add(-2 * totpPeriod), false, 0},
+		{"clock two periods ahead", rfcSecret, code, now.Add(2 * totpPeriod), false, 0},
+		{"wrong code", rfcSecret, "123456", now, false, 0},
+		{"too short", rfcSecret, "05047", now, false, 0},
+		{"too long", rfcSecret, "0504710", now, false, 0},
+		{"empty", rfcSecret, "", now, false, 0},
+		{"invalid secret", "not base32!", code, now, false, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, ok := validateTOTP(tt.secret, tt.code, tt.at)
+			if ok != tt.wantOK || step != tt.wantStep {
+				t.Errorf("validateTOTP = (%d, %v), want (%d, %v)", step, ok, tt.wantStep, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestGenerateTOTPSecret(t *testing.T) {
+	secret, err := generateTOTPSecret()
+	if err != nil {
+		t.Fatalf("generateTOTPSecret: %v", err)
+	}
+
+	key, err := totpEncoding.DecodeString(secret)
+	if err != nil {
+		t.Fatalf("secret %q is not unpadded base32: %v", secret, err)
+	}
+	if len(key) != 20 {
+		t.Errorf("key length = %d, want 20", len(key))
+	}
+
+	// A code generated from the secret must validate
+	now := time.Now()
+	code := totpCode(key, uint64(now.Unix()/int64(totpPeriod/time.Second)))
+	if _, ok := validateTOTP(secret, code, now); !ok {
+		t.Errorf("code %q for a generated secret did not validate", code)
+	}
+}
+
+func TestTOTPURI(t *testing.T) {
+	got := totpURI("Expense Tracker", "user@example.com", "JBSWY3DPEHPK3PXP")
+	want := "otpauth://totp/Expense%20Tracker:user@example.com?algorithm=SHA1&digits=6&issuer=Expense%20Tracker&period=30&secret=JBSWY3DPEHPK3PXP"
+	if got != want {
+		t.Errorf("totpURI = %q, want %q", got, want)
+	}
+}
+
+func TestRecoveryCodes(t *testing.T) {
+	code, err := generateRecoveryCode()
+	if err != nil {
+		t.Fatalf("generateRecoveryCode: %v", err)
+	}
+	if len(code) != 11 || code[5] != '-' {
+		t.Fatalf("recovery code %q is not in the form xxxxx-xxxxx", code)
+	}
+	for _, c := range strings.ReplaceAll(code, "-", "") {
+		if !strings.ContainsRune(recoveryCodeAlphabet, c) {
+			t.Errorf("recovery code %q contains %q", code, c)
+		}
+	}
+
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"k7m2p-x9q4t", "k7m2px9q4t"},
+		{"K7M2P-X9Q4T", "k7m2px9q4t"},
+		{" k7m2p x9q4t ", "k7m2px9q4t"},
+		{"k7m2px9q4t", "k7m2px9q4t"},
+	}
+	for _, tt := range tests {
+		if got := normalizeRecoveryCode(tt.input); got != tt.want {
+			t.Errorf("normalizeRecoveryCode(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
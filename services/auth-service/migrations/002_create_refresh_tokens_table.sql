@@ -0,0 +1,41 @@
+-- Migration: Create refresh_tokens table
+-- Stores rotating refresh tokens used to renew short-lived access tokens
+-- Run this script after 001_create_users_table.sql
+
+-- Create the refresh_tokens table
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+    -- UUID primary key
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+
+    -- Owner of the token
+    user_id UUID NOT NULL REFERENCES users(id),
+
+    -- Family ID groups all tokens issued from a single login
+    -- Every rotation keeps the same family ID, so the whole chain can be revoked at once
+    family_id UUID NOT NULL,
+
+    -- SHA-256 hash of the token (hex encoded)
+    -- We never store the plain token, just like passwords
+    token_hash VARCHAR(64) UNIQUE NOT NULL,
+
+    -- When the token stops being accepted
+    expires_at TIMESTAMP NOT NULL,
+
+    -- Timestamps for auditing
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    -- Set when the token is rotated, the user logs out, or reuse is detected
+    revoked_at TIMESTAMP NULL,
+
+    -- ID of the token that replaced this one during rotation (NULL if not rotated)
+    replaced_by UUID NULL
+);
+
+-- Index on family_id (for revoking a whole family and checking session state)
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_family_id ON refresh_tokens(family_id);
+
+-- Index on user_id (for revoking all of a user's tokens)
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_user_id ON refresh_tokens(user_id);
+
+-- Add a comment to the table (documentation)
+COMMENT ON TABLE refresh_tokens IS 'Stores hashed, rotating refresh tokens grouped into families per login';
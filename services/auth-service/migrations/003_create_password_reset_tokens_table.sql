@@ -0,0 +1,31 @@
+-- Migration: Create password_reset_tokens table
+-- Stores hashed, single-use tokens for the "forgot password" flow
+-- Run this script after 002_create_refresh_tokens_table.sql
+
+-- Create the password_reset_tokens table
+CREATE TABLE IF NOT EXISTS password_reset_tokens (
+    -- UUID primary key
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+
+    -- User who requested the reset
+    user_id UUID NOT NULL REFERENCES users(id),
+
+    -- SHA-256 hash of the token (hex encoded) - the plain token is only sent by email
+    token_hash VARCHAR(64) UNIQUE NOT NULL,
+
+    -- Reset tokens are short-lived
+    expires_at TIMESTAMP NOT NULL,
+
+    -- Timestamps for auditing
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    -- Set when the token is used (or invalidated by another reset)
+    -- NULL means the token can still be used
+    used_at TIMESTAMP NULL
+);
+
+-- Index on user_id (for invalidating a user's outstanding tokens)
+CREATE INDEX IF NOT EXISTS idx_password_reset_tokens_user_id ON password_reset_tokens(user_id) WHERE used_at IS NULL;
+
+-- Add a comment to the table (documentation)
+COMMENT ON TABLE password_reset_tokens IS 'Stores hashed, expiring, single-use password reset tokens';
@@ -0,0 +1,42 @@
+-- Migration: Add email verification
+-- Adds a verified flag to users and a table of emailed verification tokens
+-- Run this script after 003_create_password_reset_tokens_table.sql
+
+-- When the user proved they own their email address
+-- NULL means the address has not been verified yet
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email_verified_at TIMESTAMP NULL;
+
+-- Note: accounts created before this migration start out unverified.
+-- To grandfather them in, run once manually:
+--   UPDATE users SET email_verified_at = created_at WHERE email_verified_at IS NULL;
+
+-- Create the email_verification_tokens table
+CREATE TABLE IF NOT EXISTS email_verification_tokens (
+    -- UUID primary key
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+
+    -- User who must verify the address
+    user_id UUID NOT NULL REFERENCES users(id),
+
+    -- The address being verified
+    -- A token only verifies the address it was sent to (matters if the email changes)
+    email VARCHAR(255) NOT NULL,
+
+    -- SHA-256 hash of the token (hex encoded) - the plain token is only sent by email
+    token_hash VARCHAR(64) UNIQUE NOT NULL,
+
+    -- When the token stops being accepted
+    expires_at TIMESTAMP NOT NULL,
+
+    -- Timestamps for auditing
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    -- Set when the token is used - NULL means still usable
+    used_at TIMESTAMP NULL
+);
+
+-- Index on user_id (for looking up a user's outstanding tokens)
+CREATE INDEX IF NOT EXISTS idx_email_verification_tokens_user_id ON email_verification_tokens(user_id) WHERE used_at IS NULL;
+
+-- Add a comment to the table (documentation)
+COMMENT ON TABLE email_verification_tokens IS 'Stores hashed, expiring, single-use email verification tokens';
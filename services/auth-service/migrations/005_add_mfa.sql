@@ -0,0 +1,37 @@
+-- Migration: Add TOTP two-factor authentication
+-- Adds the TOTP secret to users and a table of one-time recovery codes
+-- Run this script after 004_add_email_verification.sql
+
+-- Base32 TOTP secret shared with the user's authenticator app
+-- Set by enrollment; MFA is only enforced once mfa_enabled_at is set too
+ALTER TABLE users ADD COLUMN IF NOT EXISTS mfa_secret VARCHAR(64) NULL;
+
+-- When the user confirmed enrollment with a valid code - NULL means MFA is off
+ALTER TABLE users ADD COLUMN IF NOT EXISTS mfa_enabled_at TIMESTAMP NULL;
+
+-- Last TOTP time step that was accepted - a code can't be used twice
+ALTER TABLE users ADD COLUMN IF NOT EXISTS mfa_last_used_step BIGINT NULL;
+
+-- Create the mfa_recovery_codes table
+CREATE TABLE IF NOT EXISTS mfa_recovery_codes (
+    -- UUID primary key
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+
+    -- User the code belongs to
+    user_id UUID NOT NULL REFERENCES users(id),
+
+    -- SHA-256 hash of the normalized code (hex encoded) - the plain code is shown once
+    code_hash VARCHAR(64) NOT NULL,
+
+    -- Timestamps for auditing
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    -- Set when the code is used - NULL means still usable
+    used_at TIMESTAMP NULL
+);
+
+-- Index on user_id + code_hash (for checking a code at login)
+CREATE INDEX IF NOT EXISTS idx_mfa_recovery_codes_user_id ON mfa_recovery_codes(user_id, code_hash);
+
+-- Add a comment to the table (documentation)
+COMMENT ON TABLE mfa_recovery_codes IS 'Stores hashed, single-use MFA recovery codes';
@@ -0,0 +1,17 @@
+-- Migration: Add user roles
+-- Adds a role column to users for role-based access control
+-- Run this script after 005_add_mfa.sql
+
+-- Role of the user: 'user' (default) or 'admin' (support staff)
+-- The role is copied into access tokens as the "role" claim
+ALTER TABLE users ADD COLUMN IF NOT EXISTS role VARCHAR(20) NOT NULL DEFAULT 'user';
+
+-- Only allow known roles
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_role_check;
+ALTER TABLE users ADD CONSTRAINT users_role_check CHECK (role IN ('user', 'admin'));
+
+-- Index on role (for listing admins)
+CREATE INDEX IF NOT EXISTS idx_users_role ON users(role) WHERE deleted_at IS NULL;
+
+-- Note: there is no API to grant the admin role. Promote support staff manually:
+--   UPDATE users SET role = 'admin' WHERE email = 'support@example.com';
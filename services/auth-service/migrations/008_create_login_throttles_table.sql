@@ -0,0 +1,30 @@
+-- Migration: Create login_throttles table
+-- Counts failed login attempts per account (email) and per client IP
+-- Used to slow down and temporarily lock out password guessing
+-- Run this script after 007_create_admin_audit_log_table.sql
+
+-- Create the login_throttles table
+CREATE TABLE IF NOT EXISTS login_throttles (
+    -- What is being counted: 'email' (one account) or 'ip' (one client)
+    scope VARCHAR(10) NOT NULL,
+
+    -- The normalized email address or the client IP
+    subject VARCHAR(255) NOT NULL,
+
+    -- Consecutive failed attempts (reset after a successful login or a quiet period)
+    failed_count INT NOT NULL DEFAULT 0,
+
+    -- When the last failed attempt happened
+    last_failed_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    -- Logins are refused until this time (NULL = not locked)
+    locked_until TIMESTAMP NULL,
+
+    PRIMARY KEY (scope, subject)
+);
+
+-- Index on last_failed_at (for cleaning up old rows)
+CREATE INDEX IF NOT EXISTS idx_login_throttles_last_failed ON login_throttles(last_failed_at);
+
+-- Add a comment to the table (documentation)
+COMMENT ON TABLE login_throttles IS 'Failed login counters and temporary lockouts';
@@ -0,0 +1,11 @@
+-- Migration: Add email change
+-- Email verification tokens can now also confirm a change to a new address
+-- Run this script after 008_create_login_throttles_table.sql
+
+-- What the token is for:
+--   'verify' - confirms the user's current address (registration, resend)
+--   'change' - confirms a new address; the user's email only changes once it is used
+ALTER TABLE email_verification_tokens ADD COLUMN IF NOT EXISTS purpose VARCHAR(20) NOT NULL DEFAULT 'verify';
+
+-- Only allow known purposes
+ALTER TABLE email_verification_tokens ADD CONSTRAINT email_verification_tokens_purpose_check CHECK (purpose IN ('verify', 'change'));
@@ -0,0 +1,7 @@
+-- Migration: Support account deletion
+-- Deleted accounts are soft-deleted (deleted_at) and purged after a grace period
+-- Run this script after 009_add_email_change.sql
+
+-- Index on deleted_at (for the purger finding accounts whose grace period ended)
+-- Only deleted users are indexed - the active ones never match
+CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users(deleted_at) WHERE deleted_at IS NOT NULL;
@@ -0,0 +1,42 @@
+-- Migration: Create api_keys table
+-- Stores personal API keys that scripts use instead of a login
+-- Run this script after 010_add_account_deletion.sql
+
+-- Create the api_keys table
+CREATE TABLE IF NOT EXISTS api_keys (
+    -- UUID primary key
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+
+    -- Owner of the key - requests made with it act as this user
+    user_id UUID NOT NULL REFERENCES users(id),
+
+    -- Label chosen by the user (e.g. "Monthly import script")
+    name VARCHAR(100) NOT NULL,
+
+    -- First characters of the key, shown in lists so users can tell keys apart
+    prefix VARCHAR(20) NOT NULL,
+
+    -- SHA-256 hash of the key (hex encoded) - the plain key is only shown once
+    key_hash VARCHAR(64) UNIQUE NOT NULL,
+
+    -- Scopes the key is limited to (e.g. {expenses:read}) - empty means no limit
+    scopes TEXT[] NOT NULL DEFAULT '{}',
+
+    -- When the key stops being accepted - NULL means never
+    expires_at TIMESTAMP NULL,
+
+    -- When the key was last used (updated at most once a minute)
+    last_used_at TIMESTAMP NULL,
+
+    -- Timestamps for auditing
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    -- Set when the user revokes the key
+    revoked_at TIMESTAMP NULL
+);
+
+-- Index on user_id (for listing a user's keys)
+CREATE INDEX IF NOT EXISTS idx_api_keys_user_id ON api_keys(user_id) WHERE revoked_at IS NULL;
+
+-- Add a comment to the table (documentation)
+COMMENT ON TABLE api_keys IS 'Stores hashed personal API keys with optional scopes and expiry';
@@ -0,0 +1,48 @@
+-- Migration: Create OpenID Connect tables
+-- Supports signing in with an external identity provider (authorization code + PKCE)
+-- Run this script after 011_create_api_keys_table.sql
+
+-- Pending logins: created when the user is sent to the provider, consumed by the callback
+-- Stored in the database (not memory) so the callback can land on any replica
+CREATE TABLE IF NOT EXISTS oidc_login_states (
+    -- SHA-256 hash of the state parameter (hex encoded)
+    state_hash VARCHAR(64) PRIMARY KEY,
+
+    -- Nonce the ID token must carry (prevents replayed ID tokens)
+    nonce VARCHAR(100) NOT NULL,
+
+    -- PKCE code verifier sent with the code exchange
+    code_verifier VARCHAR(100) NOT NULL,
+
+    -- When the login must be completed by
+    expires_at TIMESTAMP NOT NULL,
+
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- Index on expires_at (for cleaning up abandoned logins)
+CREATE INDEX IF NOT EXISTS idx_oidc_login_states_expires_at ON oidc_login_states(expires_at);
+
+-- Users created by a login with the provider get a random password they never
+-- learn: has_password is false until they set one with "forgot password"
+ALTER TABLE users ADD COLUMN IF NOT EXISTS has_password BOOLEAN NOT NULL DEFAULT TRUE;
+
+-- Provider accounts linked to users
+-- A provider account is identified by issuer + subject ("sub" claim), not by email
+CREATE TABLE IF NOT EXISTS oidc_identities (
+    issuer VARCHAR(255) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+
+    -- User the provider account signs in as
+    user_id UUID NOT NULL REFERENCES users(id),
+
+    -- Verified email the provider reported when the account was linked
+    email VARCHAR(255) NOT NULL,
+
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (issuer, subject)
+);
+
+-- Index on user_id (for deleting a user's identities)
+CREATE INDEX IF NOT EXISTS idx_oidc_identities_user_id ON oidc_identities(user_id);
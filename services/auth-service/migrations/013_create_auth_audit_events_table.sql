@@ -0,0 +1,52 @@
+-- Migration: Create auth_audit_events table
+-- Security audit log: logins, failed logins, registrations, token validations, ...
+-- Run this script after 012_create_oidc_tables.sql
+
+-- Create the auth_audit_events table
+CREATE TABLE IF NOT EXISTS auth_audit_events (
+    -- UUID primary key
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+
+    -- What happened (e.g. "login", "token_refresh", "password_reset")
+    event_type VARCHAR(50) NOT NULL,
+
+    -- User the event is about - NULL when unknown (e.g. login with an unregistered email)
+    -- No foreign key: events are kept after the user is purged
+    user_id UUID NULL,
+
+    -- Email the event is about (the attempted email for failed logins)
+    email VARCHAR(255) NULL,
+
+    -- Who made the request
+    client_ip VARCHAR(100) NOT NULL,
+    user_agent TEXT NOT NULL,
+
+    -- "success", "failure" or "mfa_required"
+    outcome VARCHAR(20) NOT NULL,
+
+    -- Why it failed (e.g. "invalid password") or extra detail (e.g. which API key)
+    reason VARCHAR(255) NULL,
+
+    -- When it happened
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- Index on user_id (a user's own history, newest first)
+CREATE INDEX IF NOT EXISTS idx_auth_audit_events_user ON auth_audit_events(user_id, created_at);
+
+-- Index on created_at (admin queries over a time range)
+CREATE INDEX IF NOT EXISTS idx_auth_audit_events_created_at ON auth_audit_events(created_at);
+
+-- Index on client_ip (everything done from one address)
+CREATE INDEX IF NOT EXISTS idx_auth_audit_events_client_ip ON auth_audit_events(client_ip, created_at);
+
+-- Append-only: updates and deletes are silently ignored
+-- Exception: purging an account pseudonymizes its events (email, IP and user agent
+-- are cleared) in a transaction that sets auth_audit.erasure (see PurgeDeleted)
+CREATE OR REPLACE RULE auth_audit_events_no_update AS ON UPDATE TO auth_audit_events
+    WHERE current_setting('auth_audit.erasure', true) IS DISTINCT FROM 'on'
+    DO INSTEAD NOTHING;
+CREATE OR REPLACE RULE auth_audit_events_no_delete AS ON DELETE TO auth_audit_events DO INSTEAD NOTHING;
+
+-- Add a comment to the table (documentation)
+COMMENT ON TABLE auth_audit_events IS 'Append-only security audit log of authentication events';
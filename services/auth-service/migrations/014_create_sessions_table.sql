@@ -0,0 +1,39 @@
+-- Migration: Create sessions table
+-- One row per login, so users can see where they are signed in and sign out remotely
+-- Run this script after 013_create_auth_audit_events_table.sql
+
+-- Create the sessions table
+CREATE TABLE IF NOT EXISTS sessions (
+    -- Session ID - the same as the refresh token family_id and the "sid" claim of access tokens
+    -- Whether the session is active is decided by its refresh tokens (see IsFamilyActive)
+    id UUID PRIMARY KEY,
+
+    -- Owner of the session
+    user_id UUID NOT NULL REFERENCES users(id),
+
+    -- Device the session was started / last refreshed from
+    -- client_ip is always a parsed address (forwarding headers are only read from trusted proxies)
+    user_agent TEXT NOT NULL DEFAULT '',
+    client_ip VARCHAR(100) NOT NULL DEFAULT '',
+
+    -- When the user logged in
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    -- Last time the session was used (refresh or token validation, at most once a minute)
+    last_seen_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- Index on user_id (for listing a user's sessions)
+CREATE INDEX IF NOT EXISTS idx_sessions_user_id ON sessions(user_id);
+
+-- Sessions started before this migration: one row per active refresh token family
+-- Device details are unknown for them
+INSERT INTO sessions (id, user_id, created_at, last_seen_at)
+SELECT family_id, user_id, MIN(created_at), MAX(created_at)
+FROM refresh_tokens
+WHERE revoked_at IS NULL AND expires_at > NOW()
+GROUP BY family_id, user_id
+ON CONFLICT (id) DO NOTHING;
+
+-- Add a comment to the table (documentation)
+COMMENT ON TABLE sessions IS 'Device details of each login (refresh token family)';
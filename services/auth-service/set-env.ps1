@@ -13,9 +13,13 @@ $env:DB_PASSWORD = "CHANGE_ME_DB_PASSWORD"
 $env:DB_NAME = "auth_db"
 
 # JWT Configuration
-# Generate a strong secret: openssl rand -base64 32
-$env:JWT_SECRET = "CHANGE_ME_JWT_SECRET"
-$env:JWT_EXPIRATION_HOURS = "24"
+# Directory of "<kid>.pem" signing keys - generate one with:
+#   openssl genpkey -algorithm RSA -pkeyopt rsa_keygen_bits:2048 -out keys/2026-01.pem
+# Leave empty to use a throwaway key (local development only)
+$env:JWT_KEYS_DIR = "./keys"
+$env:JWT_ACTIVE_KEY_ID = ""
+$env:JWT_EXPIRATION_MINUTES = "15"
+$env:REFRESH_TOKEN_EXPIRATION_DAYS = "30"
 
 # AWS SNS configuration for event publishing
 $env:AWS_REGION = "us-east-1"
@@ -10,9 +10,13 @@ export DB_PASSWORD="Adminpass123"
 export DB_NAME="auth_db"
 
 # JWT Configuration
-# Generate a strong secret: openssl rand -base64 32
-export JWT_SECRET="your-super-secret-jwt-key-change-this-in-production"
-export JWT_EXPIRATION_HOURS="24"
+# Directory of "<kid>.pem" signing keys - generate one with:
+#   openssl genpkey -algorithm RSA -pkeyopt rsa_keygen_bits:2048 -out keys/2026-01.pem
+# Leave empty to use a throwaway key (local development only)
+export JWT_KEYS_DIR="./keys"
+export JWT_ACTIVE_KEY_ID=""
+export JWT_EXPIRATION_MINUTES="15"
+export REFRESH_TOKEN_EXPIRATION_DAYS="30"
 
 # Server Configuration
 export SERVER_PORT="8080"
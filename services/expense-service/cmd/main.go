@@ -9,6 +9,9 @@ import (
 	"expense-tracker/expense-service/internal/middleware"
 	"expense-tracker/expense-service/internal/repository"
 	"expense-tracker/expense-service/internal/service"
+	"expense-tracker/shared/auth"
+	"expense-tracker/shared/clientip"
+	"expense-tracker/shared/events"
 	"log"
 	"net/http"
 	"os"
@@ -43,12 +46,63 @@ func main() {
 
 	// Initialize repository (data access layer)
 	expenseRepo := repository.NewPostgresExpenseRepository(dbPool)
+	adminAuditRepo := repository.NewPostgresAdminAuditRepository(dbPool)
+	userErasureRepo := repository.NewPostgresUserErasureRepository(dbPool)
+	recurringRepo := repository.NewPostgresRecurringExpenseRepository(dbPool)
+	budgetRepo := repository.NewPostgresBudgetRepository(dbPool)
+	userSettingsRepo := repository.NewPostgresUserSettingsRepository(dbPool)
+	exchangeRateRepo := repository.NewPostgresExchangeRateRepository(dbPool)
+	categoryRepo := repository.NewPostgresCategoryRepository(dbPool)
+
+	// Context for background work (cancelled on shutdown)
+	bgCtx, bgCancel := context.WithCancel(context.Background())
+	defer bgCancel()
 
 	// Initialize auth client (for token validation via auth-service)
-	authClient := service.NewAuthClient(cfg.AuthServiceURL)
+	authClient := auth.NewAuthClient(cfg.AuthServiceURL)
+
+	// Pick how tokens are validated
+	// remote: every request calls auth-service /auth/validate (sees logouts immediately)
+	// local: verify signatures with auth-service's cached public keys (no call per request)
+	var tokenValidator auth.TokenValidator = authClient
+	if cfg.AuthValidationMode == "local" {
+		jwksClient := auth.NewJWKSClient(cfg.AuthServiceURL, cfg.JWKSRefreshInterval)
+		if err := jwksClient.Refresh(bgCtx); err != nil {
+			log.Printf("Warning: Failed to fetch signing keys: %v (will retry)", err)
+		}
+		go jwksClient.Run(bgCtx)
+
+		jwtService := auth.NewJWTService(jwksClient)
+		var jwtValidator auth.TokenValidator = jwtService
+		if cfg.AuthRemoteFallback {
+			jwtValidator = auth.NewFallbackValidator(jwtService, authClient)
+		}
+		// API keys aren't JWTs - they always go to auth-service
+		tokenValidator = auth.NewAPIKeyRouter(jwtValidator, authClient)
+		log.Printf("Validating tokens locally (remote fallback: %v)", cfg.AuthRemoteFallback)
+	} else {
+		log.Println("Validating tokens via auth-service")
+	}
+
+	// Base currencies and conversion (rates fetched from EXCHANGE_RATES_URL or loaded by admins)
+	currencyService := service.NewCurrencyService(userSettingsRepo, exchangeRateRepo, cfg.DefaultCurrency)
+
+	// Per-user category catalogs that categories are validated against
+	categoryService := service.NewCategoryService(categoryRepo)
 
 	// Initialize expense service (business logic layer)
-	expenseService := service.NewExpenseService(expenseRepo)
+	expenseService := service.NewExpenseService(expenseRepo, currencyService, categoryService)
+	importService := service.NewExpenseImportService(expenseRepo, expenseService)
+	exportService := service.NewExpenseExportService(expenseRepo, expenseService)
+	batchService := service.NewExpenseBatchService(expenseRepo, expenseService)
+	recurringService := service.NewRecurringExpenseService(recurringRepo, expenseRepo, currencyService, categoryService, cfg.RecurringCheckInterval)
+
+	// Budget alerts for new and changed expenses
+	budgetService := service.NewBudgetService(budgetRepo, currencyService, categoryService, cfg.BudgetAlertThresholds)
+	expenseService.SetBudgetService(budgetService)
+	importService.SetBudgetService(budgetService)
+	batchService.SetBudgetService(budgetService)
+	recurringService.SetBudgetService(budgetService)
 
 	// Initialize event publisher (optional - for notifications)
 	if cfg.ExpenseEventsTopicARN != "" && cfg.AWSAccessKeyID != "" && cfg.AWSSecretKey != "" {
@@ -65,6 +119,10 @@ func main() {
 			log.Printf("ERROR: Failed to initialize event publisher: %v (events will not be published)", err)
 		} else {
 			expenseService.SetEventPublisher(eventPublisher)
+			importService.SetEventPublisher(eventPublisher)
+			batchService.SetEventPublisher(eventPublisher)
+			recurringService.SetEventPublisher(eventPublisher)
+			budgetService.SetEventPublisher(eventPublisher)
 			log.Println("✓ Event publisher initialized successfully!")
 		}
 	} else {
@@ -81,16 +139,62 @@ func main() {
 		log.Println("  Events will not be published until configuration is complete.")
 	}
 
+	// Erase the expenses of deleted accounts (user.deleted events from auth-service)
+	// Run purges erasures whose grace period is over; the consumer schedules new ones
+	erasureService := service.NewUserErasureService(userErasureRepo, cfg.ErasureCheckInterval)
+	go erasureService.Run(bgCtx)
+	if cfg.UserEventsQueueURL != "" && cfg.AWSAccessKeyID != "" && cfg.AWSSecretKey != "" {
+		sqsConsumer, err := events.NewSQSConsumer(cfg.AWSRegion, cfg.AWSAccessKeyID, cfg.AWSSecretKey)
+		if err != nil {
+			log.Printf("Warning: Failed to initialize SQS consumer: %v (deleted accounts will not be erased)", err)
+		} else {
+			go sqsConsumer.ConsumeMessages(bgCtx, cfg.UserEventsQueueURL, erasureService.HandleEvent)
+			log.Println("Consuming user events for account erasure")
+		}
+	} else {
+		log.Println("WARNING: USER_EVENTS_QUEUE_URL not set - expenses of deleted accounts will not be erased")
+	}
+
+	// Create the expenses of recurring expenses when they are due
+	go recurringService.Run(bgCtx)
+
+	// Keep exchange rates current
+	if cfg.ExchangeRatesURL != "" {
+		fetcher := service.NewExchangeRateFetcher(exchangeRateRepo, cfg.ExchangeRatesURL, cfg.ExchangeRatesRefreshInterval)
+		go fetcher.Run(bgCtx)
+		log.Printf("Fetching exchange rates from %s every %s", cfg.ExchangeRatesURL, cfg.ExchangeRatesRefreshInterval)
+	} else {
+		log.Println("WARNING: EXCHANGE_RATES_URL not set - load exchange rates with PUT /expenses/admin/exchange-rates or conversions fail")
+	}
+
 	// Initialize handlers (HTTP layer)
 	expenseHandler := handler.NewExpenseHandler(expenseService)
+	importHandler := handler.NewExpenseImportHandler(importService)
+	exportHandler := handler.NewExpenseExportHandler(exportService)
+	batchHandler := handler.NewExpenseBatchHandler(batchService)
+	recurringHandler := handler.NewRecurringExpenseHandler(recurringService)
+	budgetHandler := handler.NewBudgetHandler(budgetService)
+	settingsHandler := handler.NewSettingsHandler(currencyService)
+	categoryHandler := handler.NewCategoryHandler(categoryService)
+	adminHandler := handler.NewAdminHandler(expenseService, currencyService)
 
 	// Initialize middleware
-	authMiddleware := middleware.NewAuthMiddleware(authClient) // For token validation via auth-service
-	loggingMiddleware := middleware.NewLoggingMiddleware()     // For request/response logging
+	authMiddleware := middleware.NewAuthMiddleware(tokenValidator)   // For token validation
+	auditMiddleware := middleware.NewAuditMiddleware(adminAuditRepo) // For admin endpoints
+	loggingMiddleware := middleware.NewLoggingMiddleware()           // For request/response logging
+
+	// Works out the client IP (X-Forwarded-For only from TRUSTED_PROXIES)
+	ipResolver, err := clientip.NewResolver(cfg.TrustedProxies)
+	if err != nil {
+		log.Fatalf("Invalid TRUSTED_PROXIES: %v", err)
+	}
 
 	// Setup HTTP router
 	router := mux.NewRouter()
 
+	// Resolve the client IP before anything uses it
+	router.Use(ipResolver.Middleware)
+
 	// Add logging middleware FIRST (so it logs all requests)
 	router.Use(loggingMiddleware.LogRequest)
 
@@ -119,10 +223,38 @@ func main() {
 	router.HandleFunc("/expenses", authMiddleware.RequireAuth(expenseHandler.CreateExpense)).Methods("POST")
 	router.HandleFunc("/expenses", authMiddleware.RequireAuth(expenseHandler.ListExpenses)).Methods("GET")
 	router.HandleFunc("/expenses/summary", authMiddleware.RequireAuth(expenseHandler.GetSummary)).Methods("GET")
+	router.HandleFunc("/expenses/export", authMiddleware.RequireAuth(exportHandler.ExportExpenses)).Methods("GET")
+	router.HandleFunc("/expenses/import", authMiddleware.RequireAuth(importHandler.ImportExpenses)).Methods("POST")
+	router.HandleFunc("/expenses/batch", authMiddleware.RequireAuth(batchHandler.ApplyBatch)).Methods("POST")
+	router.HandleFunc("/expenses/tags", authMiddleware.RequireAuth(expenseHandler.ListTags)).Methods("GET")
+	router.HandleFunc("/expenses/settings", authMiddleware.RequireAuth(settingsHandler.GetSettings)).Methods("GET")
+	router.HandleFunc("/expenses/settings", authMiddleware.RequireAuth(settingsHandler.UpdateSettings)).Methods("PUT")
+	router.HandleFunc("/expenses/categories", authMiddleware.RequireAuth(categoryHandler.ListCategories)).Methods("GET")
+	router.HandleFunc("/expenses/categories", authMiddleware.RequireAuth(categoryHandler.CreateCategory)).Methods("POST")
+	router.HandleFunc("/expenses/categories/{id}", authMiddleware.RequireAuth(categoryHandler.UpdateCategory)).Methods("PUT")
+	router.HandleFunc("/expenses/categories/{id}", authMiddleware.RequireAuth(categoryHandler.DeleteCategory)).Methods("DELETE")
+	router.HandleFunc("/expenses/categories/{id}/merge", authMiddleware.RequireAuth(categoryHandler.MergeCategory)).Methods("POST")
+	router.HandleFunc("/expenses/recurring", authMiddleware.RequireAuth(recurringHandler.CreateRecurringExpense)).Methods("POST")
+	router.HandleFunc("/expenses/recurring", authMiddleware.RequireAuth(recurringHandler.ListRecurringExpenses)).Methods("GET")
+	router.HandleFunc("/expenses/recurring/{id}", authMiddleware.RequireAuth(recurringHandler.GetRecurringExpense)).Methods("GET")
+	router.HandleFunc("/expenses/recurring/{id}", authMiddleware.RequireAuth(recurringHandler.UpdateRecurringExpense)).Methods("PUT")
+	router.HandleFunc("/expenses/recurring/{id}", authMiddleware.RequireAuth(recurringHandler.DeleteRecurringExpense)).Methods("DELETE")
 	router.HandleFunc("/expenses/{id}", authMiddleware.RequireAuth(expenseHandler.GetExpense)).Methods("GET")
 	router.HandleFunc("/expenses/{id}", authMiddleware.RequireAuth(expenseHandler.UpdateExpense)).Methods("PUT")
 	router.HandleFunc("/expenses/{id}", authMiddleware.RequireAuth(expenseHandler.DeleteExpense)).Methods("DELETE")
 
+	// Budgets (/budgets/status before /budgets/{id}, see above)
+	router.HandleFunc("/budgets", authMiddleware.RequireAuth(budgetHandler.CreateBudget)).Methods("POST")
+	router.HandleFunc("/budgets", authMiddleware.RequireAuth(budgetHandler.ListBudgets)).Methods("GET")
+	router.HandleFunc("/budgets/status", authMiddleware.RequireAuth(budgetHandler.GetStatus)).Methods("GET")
+	router.HandleFunc("/budgets/{id}", authMiddleware.RequireAuth(budgetHandler.GetBudget)).Methods("GET")
+	router.HandleFunc("/budgets/{id}", authMiddleware.RequireAuth(budgetHandler.UpdateBudget)).Methods("PUT")
+	router.HandleFunc("/budgets/{id}", authMiddleware.RequireAuth(budgetHandler.DeleteBudget)).Methods("DELETE")
+
+	// Admin routes (require the admin role, every request is audited)
+	router.HandleFunc("/expenses/admin/users/{userId}/expenses", authMiddleware.RequireRole(middleware.RoleAdmin, auditMiddleware.Audit("expenses.list", adminHandler.ListUserExpenses))).Methods("GET")
+	router.HandleFunc("/expenses/admin/exchange-rates", authMiddleware.RequireRole(middleware.RoleAdmin, auditMiddleware.Audit("exchange_rates.update", adminHandler.SetExchangeRates))).Methods("PUT")
+
 	// Create HTTP server
 	server := &http.Server{
 		Addr:         ":" + cfg.ServerPort,
@@ -1,9 +1,13 @@
 package config
 
 import (
+	"expense-tracker/shared/money"
 	"fmt"
 	"os"
+	"sort"
 	"strconv"
+	"strings"
+	"time"
 )
 
 // Config holds all application configuration
@@ -20,14 +24,38 @@ type Config struct {
 	// AUTH_SERVICE_URL is the base URL of auth-service for token validation
 	AuthServiceURL string
 
+	// Token validation configuration
+	AuthValidationMode  string        // "remote" (call /auth/validate) or "local" (verify with JWKS)
+	AuthRemoteFallback  bool          // In local mode, use remote validation while keys are unavailable
+	JWKSRefreshInterval time.Duration // How often cached signing keys are refreshed
+
 	// AWS SNS configuration for event publishing
 	AWSRegion             string
 	AWSAccessKeyID        string
 	AWSSecretKey          string
 	ExpenseEventsTopicARN string
 
+	// AWS SQS configuration for user events from auth-service (account deletion)
+	UserEventsQueueURL   string
+	ErasureCheckInterval time.Duration // How often due erasures are looked for
+
+	// Recurring expenses
+	RecurringCheckInterval time.Duration // How often due occurrences are looked for
+
+	// Budgets
+	BudgetAlertThresholds []int // Default percentages of a budget that trigger an alert
+
+	// Currencies
+	DefaultCurrency              string        // Base currency of users who haven't chosen one (ISO 4217)
+	ExchangeRatesURL             string        // Rates API exchange rates are fetched from (empty: admins load them)
+	ExchangeRatesRefreshInterval time.Duration // How often exchange rates are fetched
+
 	// Server configuration
 	ServerPort string
+
+	// TrustedProxies are the load balancers/proxies (CIDRs or IPs) whose
+	// X-Forwarded-For header is believed - for the admin audit log
+	TrustedProxies []string
 }
 
 // Load reads configuration from environment variables
@@ -48,6 +76,16 @@ func Load() (*Config, error) {
 		return nil, fmt.Errorf("AUTH_SERVICE_URL environment variable is required")
 	}
 
+	// Token validation (default: remote, i.e. call auth-service for every request)
+	// "local" verifies tokens with auth-service's public keys (GET /.well-known/jwks.json)
+	cfg.AuthValidationMode = getEnv("AUTH_VALIDATION_MODE", "remote")
+	if cfg.AuthValidationMode != "remote" && cfg.AuthValidationMode != "local" {
+		return nil, fmt.Errorf("AUTH_VALIDATION_MODE must be \"remote\" or \"local\", got %q", cfg.AuthValidationMode)
+	}
+	cfg.AuthRemoteFallback = getEnvAsBool("AUTH_REMOTE_FALLBACK", true)
+	jwksRefreshMinutes := getEnvAsInt("JWKS_REFRESH_INTERVAL_MINUTES", 5)
+	cfg.JWKSRefreshInterval = time.Duration(jwksRefreshMinutes) * time.Minute
+
 	// AWS SNS configuration (optional - for event publishing)
 	cfg.AWSRegion = getEnv("AWS_REGION", "us-east-1")
 	cfg.AWSAccessKeyID = getEnv("AWS_ACCESS_KEY_ID", "")
@@ -55,9 +93,42 @@ func Load() (*Config, error) {
 	cfg.ExpenseEventsTopicARN = getEnv("EXPENSE_EVENTS_TOPIC_ARN", "")
 	// Note: AWS credentials and topic ARN are optional - events won't be published if not configured
 
+	// User events queue (optional - deleted accounts' data is not erased if not configured)
+	cfg.UserEventsQueueURL = getEnv("USER_EVENTS_QUEUE_URL", "")
+	erasureCheckMinutes := getEnvAsInt("ERASURE_CHECK_INTERVAL_MINUTES", 60)
+	cfg.ErasureCheckInterval = time.Duration(erasureCheckMinutes) * time.Minute
+
+	// Recurring expenses (occurrences are dates, so checking hourly is plenty)
+	recurringCheckMinutes := getEnvAsInt("RECURRING_CHECK_INTERVAL_MINUTES", 60)
+	cfg.RecurringCheckInterval = time.Duration(recurringCheckMinutes) * time.Minute
+
+	// Budget alerts (default: at 80% and 100% of the limit)
+	thresholds, err := getEnvAsIntList("BUDGET_ALERT_THRESHOLDS", []int{80, 100})
+	if err != nil {
+		return nil, fmt.Errorf("BUDGET_ALERT_THRESHOLDS must be a comma-separated list of percentages: %w", err)
+	}
+	sort.Ints(thresholds)
+	cfg.BudgetAlertThresholds = thresholds
+
+	// Default base currency (existing expenses were entered in US dollars)
+	defaultCurrency, ok := money.NormalizeCurrency(getEnv("DEFAULT_CURRENCY", "USD"))
+	if !ok {
+		return nil, fmt.Errorf("DEFAULT_CURRENCY must be an ISO 4217 currency code, got %q", defaultCurrency)
+	}
+	cfg.DefaultCurrency = defaultCurrency
+
+	// Exchange rates (published once a day, so fetching daily is enough)
+	cfg.ExchangeRatesURL = getEnv("EXCHANGE_RATES_URL", "")
+	exchangeRatesRefreshHours := getEnvAsInt("EXCHANGE_RATES_REFRESH_HOURS", 24)
+	cfg.ExchangeRatesRefreshInterval = time.Duration(exchangeRatesRefreshHours) * time.Hour
+
 	// Server port (default: 8081 to avoid conflict with auth-service on 8080)
 	cfg.ServerPort = getEnv("SERVER_PORT", "8081")
 
+	// Trusted proxies (default: none - X-Forwarded-For is ignored)
+	// Comma-separated, e.g. "10.0.0.0/16" for a load balancer inside the VPC
+	cfg.TrustedProxies = strings.Split(getEnv("TRUSTED_PROXIES", ""), ",")
+
 	return cfg, nil
 }
 
@@ -83,6 +154,38 @@ func getEnvAsInt(key string, defaultValue int) int {
 	return value
 }
 
+// getEnvAsIntList reads an environment variable as a comma-separated list of positive integers
+func getEnvAsIntList(key string, defaultValue []int) ([]int, error) {
+	valueStr := os.Getenv(key)
+	if valueStr == "" {
+		return defaultValue, nil
+	}
+
+	var values []int
+	for _, part := range strings.Split(valueStr, ",") {
+		value, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || value <= 0 {
+			return nil, fmt.Errorf("invalid value %q", part)
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+// getEnvAsBool reads an environment variable as a boolean
+// strconv.ParseBool accepts 1, t, T, TRUE, true, True, 0, f, F, FALSE, false, False
+func getEnvAsBool(key string, defaultValue bool) bool {
+	valueStr := os.Getenv(key)
+	if valueStr == "" {
+		return defaultValue
+	}
+	value, err := strconv.ParseBool(valueStr)
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
 // GetDatabaseURL constructs a PostgreSQL connection string
 // Uses sslmode=require for AWS RDS
 func (c *Config) GetDatabaseURL() string {
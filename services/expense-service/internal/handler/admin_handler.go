@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"encoding/json"
+	"expense-tracker/expense-service/internal/model"
+	"expense-tracker/expense-service/internal/service"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// AdminHandler handles HTTP requests for admin-only endpoints
+// Routes must be wrapped in RequireRole(middleware.RoleAdmin, ...) and Audit(...)
+type AdminHandler struct {
+	expenseService  *service.ExpenseService
+	currencyService *service.CurrencyService
+}
+
+// NewAdminHandler creates a new admin handler
+func NewAdminHandler(expenseService *service.ExpenseService, currencyService *service.CurrencyService) *AdminHandler {
+	return &AdminHandler{
+		expenseService:  expenseService,
+		currencyService: currencyService,
+	}
+}
+
+// ListUserExpenses handles listing another user's expenses
+// GET /expenses/admin/users/{userId}/expenses?category=Food&page=1&limit=20
+// Accepts the same filters as GET /expenses
+func (h *AdminHandler) ListUserExpenses(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID := mux.Vars(r)["userId"]
+	if userID == "" {
+		respondWithError(w, http.StatusBadRequest, "User ID is required")
+		return
+	}
+	if _, err := uuid.Parse(userID); err != nil {
+		respondWithError(w, http.StatusBadRequest, "User ID must be a valid UUID")
+		return
+	}
+
+	filters := parseListExpensesRequest(r)
+
+	resp, err := h.expenseService.ListExpenses(r.Context(), userID, filters)
+	if err != nil {
+		if service.IsValidationError(err) {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if strings.Contains(err.Error(), "exchange rate") {
+			respondWithError(w, http.StatusUnprocessableEntity, err.Error())
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Failed to list expenses")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+// SetExchangeRates handles loading exchange rates used for currency conversion
+// PUT /expenses/admin/exchange-rates
+// Body: {"rates": [{"base_currency": "USD", "quote_currency": "EUR", "date": "2024-01-15", "rate": 0.92}]}
+func (h *AdminHandler) SetExchangeRates(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req model.SetExchangeRatesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	saved, err := h.currencyService.SetExchangeRates(r.Context(), &req)
+	if err != nil {
+		if strings.Contains(err.Error(), "required") || strings.Contains(err.Error(), "format") || strings.Contains(err.Error(), "must") {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Failed to save exchange rates")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]int{
+		"saved": saved,
+	})
+}
@@ -0,0 +1,234 @@
+package handler
+
+import (
+	"encoding/json"
+	"expense-tracker/expense-service/internal/middleware"
+	"expense-tracker/expense-service/internal/model"
+	"expense-tracker/expense-service/internal/service"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// BudgetHandler handles HTTP requests for budgets
+type BudgetHandler struct {
+	budgetService *service.BudgetService
+}
+
+// NewBudgetHandler creates a new budget handler
+func NewBudgetHandler(budgetService *service.BudgetService) *BudgetHandler {
+	return &BudgetHandler{
+		budgetService: budgetService,
+	}
+}
+
+// CreateBudget handles budget creation
+// POST /budgets
+func (h *BudgetHandler) CreateBudget(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID := middleware.GetUserID(r.Context())
+	if userID == "" {
+		respondWithError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	var req model.CreateBudgetRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	resp, err := h.budgetService.CreateBudget(r.Context(), userID, &req)
+	if err != nil {
+		if strings.Contains(err.Error(), "already exists") {
+			respondWithError(w, http.StatusConflict, err.Error())
+			return
+		}
+		if isBudgetValidationError(err) {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Failed to create budget")
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, resp)
+}
+
+// ListBudgets handles listing the user's budgets
+// GET /budgets
+func (h *BudgetHandler) ListBudgets(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID := middleware.GetUserID(r.Context())
+	if userID == "" {
+		respondWithError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	resp, err := h.budgetService.ListBudgets(r.Context(), userID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to list budgets")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+// GetStatus handles showing spent vs limit for the user's budgets
+// GET /budgets/status?date=2024-01-15
+func (h *BudgetHandler) GetStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID := middleware.GetUserID(r.Context())
+	if userID == "" {
+		respondWithError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	resp, err := h.budgetService.GetBudgetStatus(r.Context(), userID, r.URL.Query().Get("date"))
+	if err != nil {
+		if strings.Contains(err.Error(), "format") {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if strings.Contains(err.Error(), "exchange rate") {
+			respondWithError(w, http.StatusUnprocessableEntity, err.Error())
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Failed to get budget status")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+// GetBudget handles getting a single budget
+// GET /budgets/:id
+func (h *BudgetHandler) GetBudget(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID := middleware.GetUserID(r.Context())
+	if userID == "" {
+		respondWithError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	budgetID := mux.Vars(r)["id"]
+	if budgetID == "" {
+		respondWithError(w, http.StatusBadRequest, "Budget ID is required")
+		return
+	}
+
+	resp, err := h.budgetService.GetBudget(r.Context(), budgetID, userID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			respondWithError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Failed to get budget")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+// UpdateBudget handles budget updates
+// PUT /budgets/:id
+func (h *BudgetHandler) UpdateBudget(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID := middleware.GetUserID(r.Context())
+	if userID == "" {
+		respondWithError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	budgetID := mux.Vars(r)["id"]
+	if budgetID == "" {
+		respondWithError(w, http.StatusBadRequest, "Budget ID is required")
+		return
+	}
+
+	var req model.UpdateBudgetRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	resp, err := h.budgetService.UpdateBudget(r.Context(), budgetID, userID, &req)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			respondWithError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		if isBudgetValidationError(err) {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Failed to update budget")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+// DeleteBudget handles budget deletion
+// DELETE /budgets/:id
+func (h *BudgetHandler) DeleteBudget(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID := middleware.GetUserID(r.Context())
+	if userID == "" {
+		respondWithError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	budgetID := mux.Vars(r)["id"]
+	if budgetID == "" {
+		respondWithError(w, http.StatusBadRequest, "Budget ID is required")
+		return
+	}
+
+	if err := h.budgetService.DeleteBudget(r.Context(), budgetID, userID); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			respondWithError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Failed to delete budget")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]string{
+		"message": "Budget deleted successfully",
+	})
+}
+
+// isBudgetValidationError reports whether err is a validation error from
+// the budget service (answered with 400)
+func isBudgetValidationError(err error) bool {
+	msg := err.Error()
+	return strings.Contains(msg, "format") ||
+		strings.Contains(msg, "must") ||
+		strings.Contains(msg, "cannot")
+}
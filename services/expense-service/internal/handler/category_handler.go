@@ -0,0 +1,190 @@
+package handler
+
+import (
+	"encoding/json"
+	"expense-tracker/expense-service/internal/middleware"
+	"expense-tracker/expense-service/internal/model"
+	"expense-tracker/expense-service/internal/service"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// CategoryHandler handles HTTP requests for a user's category catalog
+type CategoryHandler struct {
+	categoryService *service.CategoryService
+}
+
+// NewCategoryHandler creates a new category handler
+func NewCategoryHandler(categoryService *service.CategoryService) *CategoryHandler {
+	return &CategoryHandler{
+		categoryService: categoryService,
+	}
+}
+
+// ListCategories handles listing the user's categories
+// GET /expenses/categories
+func (h *CategoryHandler) ListCategories(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID := middleware.GetUserID(r.Context())
+	if userID == "" {
+		respondWithError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	resp, err := h.categoryService.ListCategories(r.Context(), userID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to list categories")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+// CreateCategory handles adding a category
+// POST /expenses/categories
+func (h *CategoryHandler) CreateCategory(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID := middleware.GetUserID(r.Context())
+	if userID == "" {
+		respondWithError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	var req model.CreateCategoryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	resp, err := h.categoryService.CreateCategory(r.Context(), userID, &req)
+	if err != nil {
+		respondWithCategoryError(w, err, "Failed to create category")
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, resp)
+}
+
+// UpdateCategory handles changing (and renaming) a category
+// PUT /expenses/categories/:id
+func (h *CategoryHandler) UpdateCategory(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID := middleware.GetUserID(r.Context())
+	if userID == "" {
+		respondWithError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	categoryID := mux.Vars(r)["id"]
+	if categoryID == "" {
+		respondWithError(w, http.StatusBadRequest, "Category ID is required")
+		return
+	}
+
+	var req model.UpdateCategoryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	resp, err := h.categoryService.UpdateCategory(r.Context(), categoryID, userID, &req)
+	if err != nil {
+		respondWithCategoryError(w, err, "Failed to update category")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+// MergeCategory handles merging a category into another one
+// POST /expenses/categories/:id/merge
+func (h *CategoryHandler) MergeCategory(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID := middleware.GetUserID(r.Context())
+	if userID == "" {
+		respondWithError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	categoryID := mux.Vars(r)["id"]
+	if categoryID == "" {
+		respondWithError(w, http.StatusBadRequest, "Category ID is required")
+		return
+	}
+
+	var req model.MergeCategoryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	resp, err := h.categoryService.MergeCategory(r.Context(), categoryID, userID, &req)
+	if err != nil {
+		respondWithCategoryError(w, err, "Failed to merge categories")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+// DeleteCategory handles deleting an unused category
+// DELETE /expenses/categories/:id
+func (h *CategoryHandler) DeleteCategory(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID := middleware.GetUserID(r.Context())
+	if userID == "" {
+		respondWithError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	categoryID := mux.Vars(r)["id"]
+	if categoryID == "" {
+		respondWithError(w, http.StatusBadRequest, "Category ID is required")
+		return
+	}
+
+	if err := h.categoryService.DeleteCategory(r.Context(), categoryID, userID); err != nil {
+		respondWithCategoryError(w, err, "Failed to delete category")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]string{
+		"message": "Category deleted successfully",
+	})
+}
+
+// respondWithCategoryError maps category service errors to status codes
+func respondWithCategoryError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case strings.Contains(err.Error(), "not found"):
+		respondWithError(w, http.StatusNotFound, err.Error())
+	case strings.Contains(err.Error(), "already exists"):
+		respondWithError(w, http.StatusConflict, err.Error())
+	case service.IsValidationError(err):
+		respondWithError(w, http.StatusBadRequest, err.Error())
+	default:
+		respondWithError(w, http.StatusInternalServerError, fallback)
+	}
+}
@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"encoding/json"
+	"expense-tracker/expense-service/internal/middleware"
+	"expense-tracker/expense-service/internal/model"
+	"expense-tracker/expense-service/internal/service"
+	"net/http"
+)
+
+// maxBatchBodySize is the largest batch request body (1 MB)
+const maxBatchBodySize = 1 << 20
+
+// ExpenseBatchHandler handles batches of expense creates, updates and deletes
+type ExpenseBatchHandler struct {
+	batchService *service.ExpenseBatchService
+}
+
+// NewExpenseBatchHandler creates a new expense batch handler
+func NewExpenseBatchHandler(batchService *service.ExpenseBatchService) *ExpenseBatchHandler {
+	return &ExpenseBatchHandler{
+		batchService: batchService,
+	}
+}
+
+// ApplyBatch handles a batch of expense operations
+// POST /expenses/batch
+// Body: {"mode": "best_effort", "operations": [{"op": "create", "expense": {...}},
+// {"op": "update", "id": "...", "expense": {...}}, {"op": "delete", "id": "..."}]}
+//
+// Answers 200 with a result per operation, or 422 (with the same results) if
+// operations failed and nothing was saved
+func (h *ExpenseBatchHandler) ApplyBatch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID := middleware.GetUserID(r.Context())
+	if userID == "" {
+		respondWithError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	// Decode JSON request body (max 1MB)
+	var req model.ExpenseBatchRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBatchBodySize)).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	resp, err := h.batchService.ApplyBatch(r.Context(), userID, &req)
+	if err != nil {
+		if service.IsValidationError(err) {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Failed to apply batch")
+		return
+	}
+
+	status := http.StatusOK
+	if !resp.Committed && resp.Failed > 0 {
+		status = http.StatusUnprocessableEntity
+	}
+	respondWithJSON(w, status, resp)
+}
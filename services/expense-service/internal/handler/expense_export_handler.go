@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"expense-tracker/expense-service/internal/middleware"
+	"expense-tracker/expense-service/internal/model"
+	"expense-tracker/expense-service/internal/service"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// exportWriteTimeout is how long streaming an export may take
+// (longer than the server's write timeout, which is meant for JSON responses)
+// Expenses are read in short batches, so a slow download doesn't hold a database
+// connection for this long
+const exportWriteTimeout = 5 * time.Minute
+
+// ExpenseExportHandler handles exporting expenses as files
+type ExpenseExportHandler struct {
+	exportService *service.ExpenseExportService
+}
+
+// NewExpenseExportHandler creates a new expense export handler
+func NewExpenseExportHandler(exportService *service.ExpenseExportService) *ExpenseExportHandler {
+	return &ExpenseExportHandler{
+		exportService: exportService,
+	}
+}
+
+// ExportExpenses handles downloading the user's expenses as a file
+// GET /expenses/export?format=csv|xlsx|pdf&category=Food&start_date=2024-01-01&end_date=2024-03-31&tag=work
+func (h *ExpenseExportHandler) ExportExpenses(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID := middleware.GetUserID(r.Context())
+	if userID == "" {
+		respondWithError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	// Same filters as listing (pagination is ignored)
+	req := &model.ExportExpensesRequest{
+		Format:  r.URL.Query().Get("format"),
+		Filters: parseListExpensesRequest(r),
+	}
+
+	export, err := h.exportService.ExportExpenses(r.Context(), userID, req)
+	if err != nil {
+		if service.IsValidationError(err) {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if strings.Contains(err.Error(), "exchange rate") {
+			respondWithError(w, http.StatusUnprocessableEntity, err.Error())
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Failed to export expenses")
+		return
+	}
+
+	// Large exports take longer than the server's write timeout
+	if err := http.NewResponseController(w).SetWriteDeadline(time.Now().Add(exportWriteTimeout)); err != nil {
+		log.Printf("Warning: failed to extend write deadline for export: %v", err)
+	}
+
+	w.Header().Set("Content-Type", export.ContentType)
+	w.Header().Set("Content-Disposition", `attachment; filename="`+export.FileName+`"`)
+	w.WriteHeader(http.StatusOK)
+
+	// The status is sent - a failure now can only cut the file short
+	if err := export.Stream(r.Context(), w); err != nil {
+		log.Printf("Error streaming expense export for user %s: %v", userID, err)
+	}
+}
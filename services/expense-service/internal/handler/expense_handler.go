@@ -49,7 +49,7 @@ func (h *ExpenseHandler) CreateExpense(w http.ResponseWriter, r *http.Request) {
 	// Call the expense service
 	resp, err := h.expenseService.CreateExpense(r.Context(), userID, &req)
 	if err != nil {
-		if strings.Contains(err.Error(), "required") || strings.Contains(err.Error(), "format") {
+		if service.IsValidationError(err) {
 			respondWithError(w, http.StatusBadRequest, err.Error())
 			return
 		}
@@ -99,7 +99,7 @@ func (h *ExpenseHandler) GetExpense(w http.ResponseWriter, r *http.Request) {
 }
 
 // ListExpenses handles listing expenses with filters and pagination
-// GET /expenses?category=Food&start_date=2024-01-01&end_date=2024-01-31&page=1&limit=20
+// GET /expenses?category=Food&start_date=2024-01-01&end_date=2024-01-31&tag=travel&tag=work&tag_match=all&q=taxi&min_amount=10&max_amount=50&sort=amount&order=asc&cursor=xxx&count=false&limit=20&convert=true
 func (h *ExpenseHandler) ListExpenses(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -113,11 +113,44 @@ func (h *ExpenseHandler) ListExpenses(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	filters := parseListExpensesRequest(r)
+
+	// Call the expense service
+	resp, err := h.expenseService.ListExpenses(r.Context(), userID, filters)
+	if err != nil {
+		if service.IsValidationError(err) {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if strings.Contains(err.Error(), "exchange rate") {
+			respondWithError(w, http.StatusUnprocessableEntity, err.Error())
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Failed to list expenses")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+// parseListExpensesRequest reads the list filters and pagination from the query string
+// Shared by ListExpenses and the admin endpoint for viewing a user's expenses
+func parseListExpensesRequest(r *http.Request) *model.ListExpensesRequest {
 	// Parse query parameters
 	filters := &model.ListExpensesRequest{
 		Category:  r.URL.Query().Get("category"),
 		StartDate: r.URL.Query().Get("start_date"),
 		EndDate:   r.URL.Query().Get("end_date"),
+		Tags:      r.URL.Query()["tag"],
+		TagMatch:  r.URL.Query().Get("tag_match"),
+		Query:     r.URL.Query().Get("q"),
+		MinAmount: r.URL.Query().Get("min_amount"),
+		MaxAmount: r.URL.Query().Get("max_amount"),
+		Sort:      r.URL.Query().Get("sort"),
+		Order:     r.URL.Query().Get("order"),
+		Cursor:    r.URL.Query().Get("cursor"),
+		SkipCount: r.URL.Query().Get("count") == "false",
+		Convert:   r.URL.Query().Get("convert") == "true",
 	}
 
 	// Parse pagination parameters
@@ -146,18 +179,7 @@ func (h *ExpenseHandler) ListExpenses(w http.ResponseWriter, r *http.Request) {
 		filters.Limit = 20
 	}
 
-	// Call the expense service
-	resp, err := h.expenseService.ListExpenses(r.Context(), userID, filters)
-	if err != nil {
-		if strings.Contains(err.Error(), "format") {
-			respondWithError(w, http.StatusBadRequest, err.Error())
-			return
-		}
-		respondWithError(w, http.StatusInternalServerError, "Failed to list expenses")
-		return
-	}
-
-	respondWithJSON(w, http.StatusOK, resp)
+	return filters
 }
 
 // UpdateExpense handles expense updates
@@ -198,7 +220,7 @@ func (h *ExpenseHandler) UpdateExpense(w http.ResponseWriter, r *http.Request) {
 			respondWithError(w, http.StatusNotFound, err.Error())
 			return
 		}
-		if strings.Contains(err.Error(), "required") || strings.Contains(err.Error(), "format") {
+		if service.IsValidationError(err) {
 			respondWithError(w, http.StatusBadRequest, err.Error())
 			return
 		}
@@ -283,6 +305,10 @@ func (h *ExpenseHandler) GetSummary(w http.ResponseWriter, r *http.Request) {
 			respondWithError(w, http.StatusBadRequest, err.Error())
 			return
 		}
+		if strings.Contains(err.Error(), "exchange rate") {
+			respondWithError(w, http.StatusUnprocessableEntity, err.Error())
+			return
+		}
 		respondWithError(w, http.StatusInternalServerError, "Failed to get expense summary")
 		return
 	}
@@ -290,6 +316,31 @@ func (h *ExpenseHandler) GetSummary(w http.ResponseWriter, r *http.Request) {
 	respondWithJSON(w, http.StatusOK, resp)
 }
 
+// ListTags handles listing the user's tags with usage counts
+// GET /expenses/tags
+func (h *ExpenseHandler) ListTags(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Get user_id from context
+	userID := middleware.GetUserID(r.Context())
+	if userID == "" {
+		respondWithError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	// Call the expense service
+	resp, err := h.expenseService.ListTags(r.Context(), userID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to list tags")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
 // Health handles health check requests
 // GET /health
 func (h *ExpenseHandler) Health(w http.ResponseWriter, r *http.Request) {
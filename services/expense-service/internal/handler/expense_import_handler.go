@@ -0,0 +1,118 @@
+package handler
+
+import (
+	"encoding/json"
+	"expense-tracker/expense-service/internal/middleware"
+	"expense-tracker/expense-service/internal/model"
+	"expense-tracker/expense-service/internal/service"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+)
+
+// maxImportFileSize is the largest file that can be imported (10 MB)
+const maxImportFileSize = 10 << 20
+
+// ExpenseImportHandler handles importing expenses from bank statements and CSV files
+type ExpenseImportHandler struct {
+	importService *service.ExpenseImportService
+}
+
+// NewExpenseImportHandler creates a new expense import handler
+func NewExpenseImportHandler(importService *service.ExpenseImportService) *ExpenseImportHandler {
+	return &ExpenseImportHandler{
+		importService: importService,
+	}
+}
+
+// ImportExpenses handles importing a CSV file or OFX/QFX statement
+// POST /expenses/import (multipart/form-data)
+//
+// Form fields:
+//   - file: the CSV, OFX or QFX file (required)
+//   - format: csv or ofx (optional, default: from the file extension)
+//   - mapping: the CSV columns as JSON, e.g. {"date":"Date","amount":"Amount","description":"Payee"}
+//   - currency: currency of rows without one (optional)
+//   - category: category of rows without one
+//   - tag: tag added to every expense (optional, repeat for several tags)
+//   - dry_run: true only validates and reports duplicates
+func (h *ExpenseImportHandler) ImportExpenses(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID := middleware.GetUserID(r.Context())
+	if userID == "" {
+		respondWithError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	// Parse multipart form (max 10MB)
+	if err := r.ParseMultipartForm(maxImportFileSize); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Failed to parse multipart form")
+		return
+	}
+
+	file, fileHeader, err := r.FormFile("file")
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "file is required")
+		return
+	}
+	defer file.Close()
+
+	if fileHeader.Size > maxImportFileSize {
+		respondWithError(w, http.StatusBadRequest, "file must be at most 10 MB")
+		return
+	}
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to read file")
+		return
+	}
+
+	req := &model.ImportExpensesRequest{
+		Format:   r.FormValue("format"),
+		Data:     data,
+		Currency: r.FormValue("currency"),
+		Category: r.FormValue("category"),
+		Tags:     r.MultipartForm.Value["tag"],
+		DryRun:   r.FormValue("dry_run") == "true",
+	}
+
+	// Default format from the file extension
+	if req.Format == "" {
+		switch strings.ToLower(filepath.Ext(fileHeader.Filename)) {
+		case ".csv":
+			req.Format = model.ImportFormatCSV
+		case ".ofx", ".qfx":
+			req.Format = model.ImportFormatOFX
+		}
+	}
+
+	if mapping := r.FormValue("mapping"); mapping != "" {
+		req.Mapping = &model.CSVColumnMapping{}
+		if err := json.Unmarshal([]byte(mapping), req.Mapping); err != nil {
+			respondWithError(w, http.StatusBadRequest, "mapping must be a JSON object")
+			return
+		}
+	}
+
+	resp, err := h.importService.ImportExpenses(r.Context(), userID, req)
+	if err != nil {
+		if service.IsValidationError(err) {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Failed to import expenses")
+		return
+	}
+
+	status := http.StatusOK
+	if !resp.DryRun && resp.Imported > 0 {
+		status = http.StatusCreated
+	}
+	respondWithJSON(w, status, resp)
+}
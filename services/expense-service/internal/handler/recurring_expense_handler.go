@@ -0,0 +1,200 @@
+package handler
+
+import (
+	"encoding/json"
+	"expense-tracker/expense-service/internal/middleware"
+	"expense-tracker/expense-service/internal/model"
+	"expense-tracker/expense-service/internal/service"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// RecurringExpenseHandler handles HTTP requests for recurring expenses
+type RecurringExpenseHandler struct {
+	recurringService *service.RecurringExpenseService
+}
+
+// NewRecurringExpenseHandler creates a new recurring expense handler
+func NewRecurringExpenseHandler(recurringService *service.RecurringExpenseService) *RecurringExpenseHandler {
+	return &RecurringExpenseHandler{
+		recurringService: recurringService,
+	}
+}
+
+// CreateRecurringExpense handles recurring expense creation
+// POST /expenses/recurring
+func (h *RecurringExpenseHandler) CreateRecurringExpense(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID := middleware.GetUserID(r.Context())
+	if userID == "" {
+		respondWithError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	var req model.CreateRecurringExpenseRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	resp, err := h.recurringService.CreateRecurringExpense(r.Context(), userID, &req)
+	if err != nil {
+		if isRecurringValidationError(err) {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Failed to create recurring expense")
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, resp)
+}
+
+// ListRecurringExpenses handles listing the user's recurring expenses
+// GET /expenses/recurring
+func (h *RecurringExpenseHandler) ListRecurringExpenses(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID := middleware.GetUserID(r.Context())
+	if userID == "" {
+		respondWithError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	resp, err := h.recurringService.ListRecurringExpenses(r.Context(), userID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to list recurring expenses")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+// GetRecurringExpense handles getting a single recurring expense
+// GET /expenses/recurring/:id
+func (h *RecurringExpenseHandler) GetRecurringExpense(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID := middleware.GetUserID(r.Context())
+	if userID == "" {
+		respondWithError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	ruleID := mux.Vars(r)["id"]
+	if ruleID == "" {
+		respondWithError(w, http.StatusBadRequest, "Recurring expense ID is required")
+		return
+	}
+
+	resp, err := h.recurringService.GetRecurringExpense(r.Context(), ruleID, userID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			respondWithError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Failed to get recurring expense")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+// UpdateRecurringExpense handles recurring expense updates
+// PUT /expenses/recurring/:id
+func (h *RecurringExpenseHandler) UpdateRecurringExpense(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID := middleware.GetUserID(r.Context())
+	if userID == "" {
+		respondWithError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	ruleID := mux.Vars(r)["id"]
+	if ruleID == "" {
+		respondWithError(w, http.StatusBadRequest, "Recurring expense ID is required")
+		return
+	}
+
+	var req model.UpdateRecurringExpenseRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	resp, err := h.recurringService.UpdateRecurringExpense(r.Context(), ruleID, userID, &req)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			respondWithError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		if isRecurringValidationError(err) {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Failed to update recurring expense")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+// DeleteRecurringExpense handles recurring expense deletion
+// DELETE /expenses/recurring/:id
+func (h *RecurringExpenseHandler) DeleteRecurringExpense(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID := middleware.GetUserID(r.Context())
+	if userID == "" {
+		respondWithError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	ruleID := mux.Vars(r)["id"]
+	if ruleID == "" {
+		respondWithError(w, http.StatusBadRequest, "Recurring expense ID is required")
+		return
+	}
+
+	if err := h.recurringService.DeleteRecurringExpense(r.Context(), ruleID, userID); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			respondWithError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Failed to delete recurring expense")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]string{
+		"message": "Recurring expense deleted successfully",
+	})
+}
+
+// isRecurringValidationError reports whether err is a validation error from
+// the recurring expense service (answered with 400)
+func isRecurringValidationError(err error) bool {
+	msg := err.Error()
+	return strings.Contains(msg, "required") ||
+		strings.Contains(msg, "format") ||
+		strings.Contains(msg, "must") ||
+		strings.Contains(msg, "cannot")
+}
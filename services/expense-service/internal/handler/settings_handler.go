@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"encoding/json"
+	"expense-tracker/expense-service/internal/middleware"
+	"expense-tracker/expense-service/internal/model"
+	"expense-tracker/expense-service/internal/service"
+	"net/http"
+	"strings"
+)
+
+// SettingsHandler handles HTTP requests for a user's expense settings
+type SettingsHandler struct {
+	currencyService *service.CurrencyService
+}
+
+// NewSettingsHandler creates a new settings handler
+func NewSettingsHandler(currencyService *service.CurrencyService) *SettingsHandler {
+	return &SettingsHandler{
+		currencyService: currencyService,
+	}
+}
+
+// GetSettings handles getting the user's settings
+// GET /expenses/settings
+func (h *SettingsHandler) GetSettings(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID := middleware.GetUserID(r.Context())
+	if userID == "" {
+		respondWithError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	resp, err := h.currencyService.GetSettings(r.Context(), userID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to get settings")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+// UpdateSettings handles changing the user's base currency
+// PUT /expenses/settings
+func (h *SettingsHandler) UpdateSettings(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID := middleware.GetUserID(r.Context())
+	if userID == "" {
+		respondWithError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	var req model.UpdateUserSettingsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	resp, err := h.currencyService.UpdateSettings(r.Context(), userID, &req)
+	if err != nil {
+		if strings.Contains(err.Error(), "must") {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Failed to update settings")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,137 @@
+package model
+
+import (
+	"expense-tracker/shared/money"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Budget periods
+const (
+	BudgetPeriodMonthly = "monthly" // Every calendar month
+	BudgetPeriodCustom  = "custom"  // From StartDate to EndDate
+)
+
+// Budget is a spending limit for a category (or all categories) over a period
+type Budget struct {
+	ID     string `json:"id" db:"id"`
+	UserID string `json:"user_id" db:"user_id"`
+
+	// Category the budget limits - nil for an overall budget
+	Category *string `json:"category,omitempty" db:"category"`
+
+	// Amount is the spending limit for one period, in Currency
+	Amount money.Money `json:"amount" db:"amount"`
+
+	// Currency of the amount - spending in other currencies is converted into it
+	// Stored, so changing the base currency doesn't change what the limit means
+	Currency string `json:"currency" db:"currency"`
+
+	// Period is monthly or custom
+	Period string `json:"period" db:"period"`
+
+	// StartDate and EndDate are the custom period (nil for monthly budgets)
+	StartDate *time.Time `json:"start_date,omitempty" db:"start_date"`
+	EndDate   *time.Time `json:"end_date,omitempty" db:"end_date"`
+
+	// AlertThresholds are the percentages of Amount that trigger an alert
+	// Empty uses the service default
+	AlertThresholds []int `json:"alert_thresholds,omitempty" db:"alert_thresholds"`
+
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// NewBudget creates a new Budget with generated ID and timestamps
+func NewBudget(userID string, category *string, amount money.Money, currency, period string, startDate, endDate *time.Time, alertThresholds []int) *Budget {
+	now := time.Now()
+	return &Budget{
+		ID:              uuid.New().String(),
+		UserID:          userID,
+		Category:        category,
+		Amount:          amount,
+		Currency:        currency,
+		Period:          period,
+		StartDate:       startDate,
+		EndDate:         endDate,
+		AlertThresholds: alertThresholds,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+}
+
+// PeriodContaining returns the budget period the given date falls in
+// ok is false if the date is outside a custom budget's period
+func (b *Budget) PeriodContaining(date time.Time) (start, end time.Time, ok bool) {
+	if b.Period == BudgetPeriodCustom {
+		if date.Before(*b.StartDate) || date.After(*b.EndDate) {
+			return time.Time{}, time.Time{}, false
+		}
+		return *b.StartDate, *b.EndDate, true
+	}
+
+	// Monthly: the calendar month of the date
+	start = time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, time.UTC)
+	end = start.AddDate(0, 1, -1)
+	return start, end, true
+}
+
+// CreateBudgetRequest represents the data sent when creating a budget
+type CreateBudgetRequest struct {
+	// Category to limit - omit (or leave empty) for an overall budget
+	Category string `json:"category,omitempty"`
+
+	// Amount is the spending limit for one period
+	Amount string `json:"amount"`
+
+	// Currency of the amount (default: the user's base currency)
+	Currency string `json:"currency,omitempty"`
+
+	// Period is monthly (default) or custom
+	Period string `json:"period,omitempty"`
+
+	// StartDate and EndDate (format: YYYY-MM-DD) are required for custom budgets
+	StartDate string `json:"start_date,omitempty"`
+	EndDate   string `json:"end_date,omitempty"`
+
+	// AlertThresholds are percentages of the amount that trigger an alert (default: service setting)
+	AlertThresholds []int `json:"alert_thresholds,omitempty"`
+}
+
+// UpdateBudgetRequest represents the data sent when updating a budget
+// All fields are optional; the category and period can't be changed
+type UpdateBudgetRequest struct {
+	Amount          *string `json:"amount,omitempty"`
+	Currency        *string `json:"currency,omitempty"` // Empty: the user's base currency
+	StartDate       *string `json:"start_date,omitempty"`
+	EndDate         *string `json:"end_date,omitempty"`
+	AlertThresholds *[]int  `json:"alert_thresholds,omitempty"`
+}
+
+// ListBudgetsResponse contains a user's budgets
+type ListBudgetsResponse struct {
+	Budgets []*Budget `json:"budgets"`
+	Total   int       `json:"total"`
+}
+
+// BudgetStatus is how much of a budget was spent in a period
+type BudgetStatus struct {
+	BudgetID    string      `json:"budget_id"`
+	Category    *string     `json:"category,omitempty"` // nil for an overall budget
+	Period      string      `json:"period"`
+	Currency    string      `json:"currency"` // The budget's currency
+	PeriodStart time.Time   `json:"period_start"`
+	PeriodEnd   time.Time   `json:"period_end"`
+	Limit       money.Money `json:"limit"`
+	Spent       money.Money `json:"spent"`
+	Remaining   money.Money `json:"remaining"`    // Negative once the budget is exceeded
+	PercentUsed float64     `json:"percent_used"` // Spent as a percentage of the limit
+	Exceeded    bool        `json:"exceeded"`
+}
+
+// BudgetStatusResponse contains the status of the user's budgets on a date
+type BudgetStatusResponse struct {
+	Date    time.Time      `json:"date"`
+	Budgets []BudgetStatus `json:"budgets"`
+}
@@ -0,0 +1,98 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Category is an entry of a user's category catalog
+// Expenses, recurring expenses and budgets store the category's name
+type Category struct {
+	ID     string `json:"id" db:"id"`
+	UserID string `json:"user_id" db:"user_id"`
+	Name   string `json:"name" db:"name"`
+
+	// Display metadata for clients
+	Color *string `json:"color,omitempty" db:"color"` // Hex color like #FF7043
+	Icon  *string `json:"icon,omitempty" db:"icon"`   // Icon name like "utensils"
+
+	// ParentID is the category this one is a subcategory of (nil for top-level categories)
+	// Subcategories can't have subcategories of their own
+	ParentID *string `json:"parent_id,omitempty" db:"parent_id"`
+
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// NewCategory creates a new Category with generated ID and timestamps
+func NewCategory(userID, name string, color, icon, parentID *string) *Category {
+	now := time.Now()
+	return &Category{
+		ID:        uuid.New().String(),
+		UserID:    userID,
+		Name:      name,
+		Color:     color,
+		Icon:      icon,
+		ParentID:  parentID,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// DefaultCategory is a category every user starts with
+type DefaultCategory struct {
+	Name  string
+	Color string
+	Icon  string
+}
+
+// DefaultCategories seed a user's catalog the first time it is used
+// Categories the user's existing expenses already have are added as well
+var DefaultCategories = []DefaultCategory{
+	{Name: "Food", Color: "#FF7043", Icon: "utensils"},
+	{Name: "Transport", Color: "#42A5F5", Icon: "car"},
+	{Name: "Housing", Color: "#8D6E63", Icon: "home"},
+	{Name: "Utilities", Color: "#FFCA28", Icon: "bolt"},
+	{Name: "Health", Color: "#EF5350", Icon: "heart"},
+	{Name: "Entertainment", Color: "#AB47BC", Icon: "film"},
+	{Name: "Shopping", Color: "#EC407A", Icon: "shopping-bag"},
+	{Name: "Travel", Color: "#26A69A", Icon: "plane"},
+	{Name: "Education", Color: "#5C6BC0", Icon: "book"},
+	{Name: "Other", Color: "#78909C", Icon: "tag"},
+}
+
+// CreateCategoryRequest represents the data sent when creating a category
+type CreateCategoryRequest struct {
+	Name     string `json:"name"`
+	Color    string `json:"color,omitempty"`
+	Icon     string `json:"icon,omitempty"`
+	ParentID string `json:"parent_id,omitempty"`
+}
+
+// UpdateCategoryRequest represents the data sent when updating a category
+// All fields are optional; an empty color, icon or parent_id removes it.
+// A new name is written to all expenses, recurring expenses and budgets with the old one
+type UpdateCategoryRequest struct {
+	Name     *string `json:"name,omitempty"`
+	Color    *string `json:"color,omitempty"`
+	Icon     *string `json:"icon,omitempty"`
+	ParentID *string `json:"parent_id,omitempty"`
+}
+
+// MergeCategoryRequest represents the category another one is merged into
+type MergeCategoryRequest struct {
+	TargetID string `json:"target_id"`
+}
+
+// MergeCategoryResponse contains the category that remains after a merge
+type MergeCategoryResponse struct {
+	Category        *Category `json:"category"`
+	ExpensesUpdated int64     `json:"expenses_updated"`
+}
+
+// ListCategoriesResponse contains a user's categories
+type ListCategoriesResponse struct {
+	Categories []*Category `json:"categories"`
+	Total      int         `json:"total"`
+}
@@ -0,0 +1,65 @@
+package model
+
+import (
+	"expense-tracker/shared/money"
+	"time"
+)
+
+// UserSettings holds a user's expense preferences
+type UserSettings struct {
+	UserID string `json:"user_id" db:"user_id"`
+
+	// BaseCurrency is the currency totals are converted into, and the
+	// default for new expenses and budgets
+	BaseCurrency string `json:"base_currency" db:"base_currency"`
+
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// NewUserSettings creates new UserSettings with timestamps
+func NewUserSettings(userID, baseCurrency string) *UserSettings {
+	now := time.Now()
+	return &UserSettings{
+		UserID:       userID,
+		BaseCurrency: baseCurrency,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+}
+
+// UpdateUserSettingsRequest represents the data sent when changing settings
+type UpdateUserSettingsRequest struct {
+	BaseCurrency string `json:"base_currency"`
+}
+
+// ExchangeRate says one unit of BaseCurrency was worth Rate units of QuoteCurrency on Date
+// (e.g. base USD, quote EUR, rate 0.92)
+type ExchangeRate struct {
+	BaseCurrency  string     `json:"base_currency" db:"base_currency"`
+	QuoteCurrency string     `json:"quote_currency" db:"quote_currency"`
+	Date          time.Time  `json:"date" db:"rate_date"`
+	Rate          money.Rate `json:"rate" db:"rate"`
+}
+
+// SetExchangeRatesRequest represents rates uploaded by an admin
+// Dates use the format YYYY-MM-DD; existing rates for the same pair and date are replaced
+type SetExchangeRatesRequest struct {
+	Rates []struct {
+		BaseCurrency  string     `json:"base_currency"`
+		QuoteCurrency string     `json:"quote_currency"`
+		Date          string     `json:"date"`
+		Rate          money.Rate `json:"rate"`
+	} `json:"rates"`
+}
+
+// AmountGroup is the sum of a user's expenses in one currency on one date
+// (and one category, for summaries). Totals in other currencies are converted
+// group by group, with the exchange rate of the group's date
+type AmountGroup struct {
+	Category string
+	Currency string
+	Date     time.Time
+	Total    money.Money
+	Count    int
+}
@@ -1,45 +1,75 @@
 package model
 
-import "time"
+import (
+	"expense-tracker/shared/money"
+	"expense-tracker/shared/pagination"
+	"time"
+)
 
 // DTOs (Data Transfer Objects) - used for API requests/responses
 
 // CreateExpenseRequest represents the data sent when creating an expense
 type CreateExpenseRequest struct {
-	// Amount must be a positive decimal number
+	// Amount must be a positive decimal number with at most the currency's
+	// decimal places (e.g. "12.34" for USD, "1200" for JPY)
 	Amount string `json:"amount" binding:"required"`
 
+	// Currency is an ISO 4217 code (optional, default: the user's base currency)
+	Currency string `json:"currency,omitempty"`
+
 	// Description of the expense
 	Description string `json:"description" binding:"required"`
 
 	// Category for the expense (e.g., "Food", "Transport", "Entertainment")
 	Category string `json:"category" binding:"required"`
 
+	// Tags are optional free-form labels (e.g. ["client-acme", "reimbursable"])
+	Tags []string `json:"tags,omitempty"`
+
 	// ExpenseDate is when the expense occurred (format: YYYY-MM-DD)
 	ExpenseDate string `json:"expense_date" binding:"required"`
 }
 
 // UpdateExpenseRequest represents the data sent when updating an expense
-// All fields are optional for partial updates
+// All fields are optional for partial updates - tags replace all existing tags
+// (an empty list removes them)
 type UpdateExpenseRequest struct {
-	Amount      *string `json:"amount,omitempty"`
-	Description *string `json:"description,omitempty"`
-	Category    *string `json:"category,omitempty"`
-	ExpenseDate *string `json:"expense_date,omitempty"`
+	Amount      *string   `json:"amount,omitempty"`
+	Currency    *string   `json:"currency,omitempty"` // Empty: the user's base currency, like on create
+	Description *string   `json:"description,omitempty"`
+	Category    *string   `json:"category,omitempty"`
+	Tags        *[]string `json:"tags,omitempty"`
+	ExpenseDate *string   `json:"expense_date,omitempty"`
 }
 
 // ExpenseResponse is what we send back after creating/updating/getting an expense
 type ExpenseResponse struct {
-	ID          string    `json:"id"`
-	UserID      string    `json:"user_id"`
-	Amount      string    `json:"amount"`
-	Description string    `json:"description"`
-	Category    string    `json:"category"`
-	ExpenseDate time.Time `json:"expense_date"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID          string      `json:"id"`
+	UserID      string      `json:"user_id"`
+	Amount      money.Money `json:"amount"`
+	Currency    string      `json:"currency"`
+	Description string      `json:"description"`
+	Category    string      `json:"category"`
+	Tags        []string    `json:"tags"`
+	ExpenseDate time.Time   `json:"expense_date"`
+	CreatedAt   time.Time   `json:"created_at"`
+	UpdatedAt   time.Time   `json:"updated_at"`
+
+	// ConvertedAmount is the amount in the user's base currency as of the
+	// expense date (only when listing with convert=true)
+	ConvertedAmount *money.Money `json:"converted_amount,omitempty"`
+
+	// Highlight is the description with the search words wrapped in <mark>
+	// tags, HTML-escaped otherwise (only when searching with q)
+	Highlight string `json:"highlight,omitempty"`
 }
 
+// Tag filter modes
+const (
+	TagMatchAny = "any" // Expenses with at least one of the tags (default)
+	TagMatchAll = "all" // Expenses with every tag
+)
+
 // ListExpensesRequest represents query parameters for listing expenses
 // These come from URL query parameters, not JSON body
 type ListExpensesRequest struct {
@@ -52,33 +82,85 @@ type ListExpensesRequest struct {
 	// EndDate for date range filter (format: YYYY-MM-DD)
 	EndDate string
 
+	// Tags filter (optional) - expenses with any of the tags, or all of them
+	// if TagMatch is "all"
+	Tags     []string
+	TagMatch string
+
+	// Query is a full-text search of description, category and tags (optional)
+	// Web search syntax: "quoted phrase", or, -excluded. Results are sorted by relevance
+	Query string
+
+	// MinAmount and MaxAmount filter by amount, in each expense's own currency (optional)
+	MinAmount string
+	MaxAmount string
+
+	// Sort is date (default), amount, category, created_at or relevance (default with Query)
+	// Order is desc (default) or asc
+	Sort  string
+	Order string
+
+	// Cursor is a next_cursor or prev_cursor of an earlier page (optional)
+	// Replaces Page; PageCursor is the decoded cursor, set by validation
+	Cursor     string
+	PageCursor *pagination.Cursor
+
 	// Page number for pagination (default: 1)
 	Page int
 
 	// Limit is items per page (default: 20, max: 100)
 	Limit int
+
+	// SkipCount skips counting the matching expenses (no total and pages)
+	SkipCount bool
+
+	// Convert adds amounts and a total converted into the user's base currency
+	Convert bool
 }
 
 // ListExpensesResponse contains the list of expenses and pagination info
+// total and pages are left out with count=false, page and pages when paging with cursors
 type ListExpensesResponse struct {
 	Expenses []ExpenseResponse `json:"expenses"`
-	Total    int               `json:"total"` // Total number of expenses (before pagination)
-	Page     int               `json:"page"`  // Current page number
-	Limit    int               `json:"limit"` // Items per page
-	Pages    int               `json:"pages"` // Total number of pages
+	Total    *int              `json:"total,omitempty"` // Total number of expenses (before pagination)
+	Page     int               `json:"page,omitempty"`  // Current page number
+	Limit    int               `json:"limit"`           // Items per page
+	Pages    *int              `json:"pages,omitempty"` // Total number of pages
+
+	// NextCursor and PrevCursor fetch the neighbouring pages (left out if there are none)
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
+
+	// With convert=true: the sum of all matching expenses (not just this page)
+	// in the user's base currency
+	BaseCurrency   string       `json:"base_currency,omitempty"`
+	ConvertedTotal *money.Money `json:"converted_total,omitempty"`
 }
 
 // ExpenseSummaryItem represents a category summary
 type ExpenseSummaryItem struct {
-	Category string `json:"category"`
-	Total    string `json:"total"` // Total amount for this category
-	Count    int    `json:"count"` // Number of expenses in this category
+	Category string      `json:"category"`
+	Total    money.Money `json:"total"` // Total amount for this category
+	Count    int         `json:"count"` // Number of expenses in this category
 }
 
 // ExpenseSummaryResponse contains expense summary grouped by category
+// All totals are in the user's base currency (converted as of each expense's date)
 type ExpenseSummaryResponse struct {
 	StartDate  time.Time            `json:"start_date"`
 	EndDate    time.Time            `json:"end_date"`
-	Total      string               `json:"total"` // Grand total across all categories
+	Currency   string               `json:"currency"`
+	Total      money.Money          `json:"total"` // Grand total across all categories
 	ByCategory []ExpenseSummaryItem `json:"by_category"`
 }
+
+// TagUsage is a tag and how many of the user's expenses have it
+type TagUsage struct {
+	Tag   string `json:"tag"`
+	Count int    `json:"count"`
+}
+
+// ListTagsResponse contains the tags a user has used, most used first
+type ListTagsResponse struct {
+	Tags []TagUsage `json:"tags"`
+}
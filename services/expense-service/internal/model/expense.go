@@ -1,6 +1,7 @@
 package model
 
 import (
+	"expense-tracker/shared/money"
 	"time"
 
 	"github.com/google/uuid"
@@ -17,9 +18,12 @@ type Expense struct {
 	// We don't have a foreign key constraint since it's in a different database
 	UserID string `json:"user_id" db:"user_id"`
 
-	// Amount is the expense amount (using string for JSON, will parse to decimal)
-	// DECIMAL(10,2) in database for precise currency handling
-	Amount string `json:"amount" db:"amount"`
+	// Amount is the expense amount, with the currency's decimal places
+	// DECIMAL(11,3) in database for precise currency handling
+	Amount money.Money `json:"amount" db:"amount"`
+
+	// Currency is the ISO 4217 code of the amount (e.g. "USD", "EUR", "INR")
+	Currency string `json:"currency" db:"currency"`
 
 	// Description describes what the expense was for
 	Description string `json:"description" db:"description"`
@@ -27,6 +31,10 @@ type Expense struct {
 	// Category categorizes the expense (e.g., "Food", "Transport", "Entertainment")
 	Category string `json:"category" db:"category"`
 
+	// Tags are free-form lower-case labels (e.g. "client-acme", "reimbursable")
+	// Stored in the expense_tags table
+	Tags []string `json:"tags" db:"-"`
+
 	// ExpenseDate is when the expense occurred
 	// DATE type in database
 	ExpenseDate time.Time `json:"expense_date" db:"expense_date"`
@@ -40,17 +48,23 @@ type Expense struct {
 	// DeletedAt is for soft deletes (nullable)
 	// If nil, expense is active. If set, expense is deleted.
 	DeletedAt *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
+
+	// Highlight is the description with the search words marked, when
+	// listing with a search query (not stored)
+	Highlight string `json:"-" db:"-"`
 }
 
 // NewExpense creates a new Expense with generated ID and timestamps
-func NewExpense(userID, amount, description, category string, expenseDate time.Time) *Expense {
+func NewExpense(userID string, amount money.Money, currency, description, category string, expenseDate time.Time) *Expense {
 	now := time.Now()
 	return &Expense{
 		ID:          uuid.New().String(),
 		UserID:      userID,
 		Amount:      amount,
+		Currency:    currency,
 		Description: description,
 		Category:    category,
+		Tags:        []string{},
 		ExpenseDate: expenseDate,
 		CreatedAt:   now,
 		UpdatedAt:   now,
@@ -0,0 +1,73 @@
+package model
+
+import "encoding/json"
+
+// Batch operations
+const (
+	BatchOpCreate = "create"
+	BatchOpUpdate = "update"
+	BatchOpDelete = "delete"
+)
+
+// Batch modes
+const (
+	BatchModeAllOrNothing = "all_or_nothing" // Nothing is saved if any operation fails (default)
+	BatchModeBestEffort   = "best_effort"    // Operations that succeed are saved, the others are not
+)
+
+// Statuses of batch operations
+const (
+	BatchOpCreated    = "created"
+	BatchOpUpdated    = "updated"
+	BatchOpDeleted    = "deleted"
+	BatchOpFailed     = "failed"      // Failed validation or saving - not applied
+	BatchOpNotApplied = "not_applied" // Valid, but another operation failed (all_or_nothing)
+)
+
+// ExpenseBatchRequest is a batch of expense creates, updates and deletes
+// (e.g. edits a mobile app made offline), applied in order in one transaction
+type ExpenseBatchRequest struct {
+	// Mode is all_or_nothing (default) or best_effort
+	Mode string `json:"mode,omitempty"`
+
+	Operations []ExpenseBatchOperation `json:"operations"`
+}
+
+// ExpenseBatchOperation is one operation of a batch
+type ExpenseBatchOperation struct {
+	// Op is create, update or delete
+	Op string `json:"op"`
+
+	// ID of the expense to update or delete
+	// Optional for create: a client-generated UUID, so that a retried batch
+	// fails with "expense already exists" instead of creating the expense twice
+	ID string `json:"id,omitempty"`
+
+	// Expense is a CreateExpenseRequest (create) or UpdateExpenseRequest (update)
+	Expense json.RawMessage `json:"expense,omitempty"`
+}
+
+// ExpenseBatchResult is the outcome of one operation, at the operation's index
+type ExpenseBatchResult struct {
+	Index   int              `json:"index"`
+	Op      string           `json:"op"`
+	ID      string           `json:"id,omitempty"`
+	Status  string           `json:"status"`
+	Error   string           `json:"error,omitempty"`
+	Expense *ExpenseResponse `json:"expense,omitempty"` // The created or updated expense
+}
+
+// ExpenseBatchResponse reports the outcome of a batch
+type ExpenseBatchResponse struct {
+	Mode      string               `json:"mode"`
+	Committed bool                 `json:"committed"` // Whether anything was saved
+	Succeeded int                  `json:"succeeded"`
+	Failed    int                  `json:"failed"`
+	Results   []ExpenseBatchResult `json:"results"`
+}
+
+// ExpenseChange is a prepared write of a batch, for the repository
+type ExpenseChange struct {
+	Op      string   // create, update or delete
+	Expense *Expense // For delete only ID and UserID are used
+}
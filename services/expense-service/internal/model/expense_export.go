@@ -0,0 +1,18 @@
+package model
+
+// Export formats
+const (
+	ExportFormatCSV  = "csv"
+	ExportFormatXLSX = "xlsx" // Excel workbook
+	ExportFormatPDF  = "pdf"  // Printable report with a summary by category
+)
+
+// ExportExpensesRequest represents query parameters for exporting expenses
+type ExportExpensesRequest struct {
+	// Format is csv, xlsx or pdf
+	Format string
+
+	// Filters are the same as for listing - pagination is ignored, every
+	// matching expense is exported
+	Filters *ListExpensesRequest
+}
@@ -0,0 +1,114 @@
+package model
+
+import (
+	"expense-tracker/shared/money"
+	"strings"
+	"time"
+)
+
+// Statement import formats
+const (
+	ImportFormatCSV = "csv" // Any CSV export, read with a CSVColumnMapping
+	ImportFormatOFX = "ofx" // OFX/QFX bank statements (SGML 1.x and XML 2.x)
+)
+
+// Statuses of imported rows
+const (
+	ImportRowImported  = "imported"  // Saved as an expense
+	ImportRowAccepted  = "accepted"  // Would be saved (dry run)
+	ImportRowDuplicate = "duplicate" // Matches an existing expense - not saved
+	ImportRowInvalid   = "invalid"   // Failed validation - not saved
+	ImportRowSkipped   = "skipped"   // Not spending (a deposit or refund) - not saved
+)
+
+// CSVColumnMapping says which columns of a CSV file hold the expense fields
+// Columns are header names of the file's first line (matched ignoring case)
+type CSVColumnMapping struct {
+	// Required columns
+	Date        string `json:"date"`
+	Amount      string `json:"amount"`
+	Description string `json:"description"`
+
+	// Optional columns - rows without them use the category and currency of the import
+	Category string `json:"category,omitempty"`
+	Currency string `json:"currency,omitempty"`
+
+	// DateFormat of the date column: YYYY-MM-DD (default), YYYY/MM/DD, DD/MM/YYYY,
+	// MM/DD/YYYY, DD.MM.YYYY, DD-MM-YYYY or MM-DD-YYYY
+	DateFormat string `json:"date_format,omitempty"`
+
+	// Delimiter between columns (default: ",")
+	Delimiter string `json:"delimiter,omitempty"`
+
+	// DecimalComma reads amounts like "1.234,56" (default: "1,234.56")
+	DecimalComma bool `json:"decimal_comma,omitempty"`
+
+	// NegativeExpenses is for bank exports where spending is negative: negative
+	// amounts are imported (as positive expenses) and positive ones skipped
+	NegativeExpenses bool `json:"negative_expenses,omitempty"`
+}
+
+// ImportExpensesRequest is a bank statement or CSV file to import
+// Sent as multipart/form-data (see ExpenseImportHandler)
+type ImportExpensesRequest struct {
+	// Format is csv or ofx (QFX files are OFX)
+	Format string
+
+	// Data is the uploaded file
+	Data []byte
+
+	// Mapping of the CSV columns (required for csv)
+	Mapping *CSVColumnMapping
+
+	// Currency of rows without one (optional, default: the OFX statement's
+	// currency, then the user's base currency)
+	Currency string
+
+	// Category of rows without one (bank statements have none)
+	Category string
+
+	// Tags added to every imported expense (e.g. ["imported"])
+	Tags []string
+
+	// DryRun validates and checks for duplicates without saving anything
+	DryRun bool
+}
+
+// ImportRowResult is the outcome of one row of an import
+type ImportRowResult struct {
+	// Row is the line of the CSV file (the header is line 1), or the
+	// number of the transaction in an OFX statement (starting at 1)
+	Row    int    `json:"row"`
+	Status string `json:"status"`
+
+	// Error says why an invalid or skipped row was not imported
+	Error string `json:"error,omitempty"`
+
+	// DuplicateOf is the ID of the existing expense a duplicate matches
+	DuplicateOf string `json:"duplicate_of,omitempty"`
+
+	// Expense is the expense that was (or with a dry run, would be) created
+	Expense *ExpenseResponse `json:"expense,omitempty"`
+}
+
+// ImportExpensesResponse summarizes an import
+type ImportExpensesResponse struct {
+	DryRun bool `json:"dry_run"`
+
+	// Row counts (Imported is how many would be imported for a dry run)
+	Total      int `json:"total"`
+	Imported   int `json:"imported"`
+	Duplicates int `json:"duplicates"`
+	Invalid    int `json:"invalid"`
+	Skipped    int `json:"skipped"`
+
+	Rows []ImportRowResult `json:"rows"`
+}
+
+// ExpenseFingerprint identifies an expense for duplicate detection on import:
+// the same date, amount and description (ignoring case and spacing) are
+// considered the same expense
+func ExpenseFingerprint(expenseDate time.Time, amount money.Money, description string) string {
+	description = strings.ToLower(strings.Join(strings.Fields(description), " "))
+	return expenseDate.Format("2006-01-02") + "|" + amount.String() + "|" + description
+}
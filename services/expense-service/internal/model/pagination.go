@@ -0,0 +1,30 @@
+package model
+
+import "expense-tracker/shared/pagination"
+
+// Sort fields of expense listings
+const (
+	SortDate      = "date" // Expense date, newest created first on the same day (default)
+	SortAmount    = "amount"
+	SortCategory  = "category"
+	SortCreatedAt = "created_at"
+	SortRelevance = "relevance" // Search rank, only with a search query (default when searching)
+)
+
+// Sort orders
+const (
+	SortAsc  = "asc"
+	SortDesc = "desc" // Default
+)
+
+// ExpensePage is a page of a user's expenses
+type ExpensePage struct {
+	Expenses []*Expense
+
+	// Total is the number of matching expenses (nil if not counted)
+	Total *int
+
+	// NextCursor and PrevCursor point to the neighbouring pages (nil if there are none)
+	NextCursor *pagination.Cursor
+	PrevCursor *pagination.Cursor
+}
@@ -0,0 +1,188 @@
+package model
+
+import (
+	"expense-tracker/shared/money"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Recurring expense frequencies
+const (
+	FrequencyDaily   = "daily"
+	FrequencyWeekly  = "weekly"
+	FrequencyMonthly = "monthly"
+	FrequencyYearly  = "yearly"
+)
+
+// RecurringExpense is a rule for an expense that repeats (rent, subscriptions, utilities)
+// The scheduler creates an expense for every occurrence once its date is reached
+type RecurringExpense struct {
+	ID     string `json:"id" db:"id"`
+	UserID string `json:"user_id" db:"user_id"`
+
+	// UserEmail is where expense.created notifications for the occurrences go
+	// Stored because there is no request (and no token) when the scheduler runs
+	UserEmail string `json:"-" db:"user_email"`
+
+	// What every created expense gets
+	Amount      money.Money `json:"amount" db:"amount"`
+	Currency    string      `json:"currency" db:"currency"`
+	Description string      `json:"description" db:"description"`
+	Category    string      `json:"category" db:"category"`
+
+	// Frequency is daily, weekly, monthly or yearly
+	Frequency string `json:"frequency" db:"frequency"`
+
+	// DayOfMonth is the day monthly rules fall on (1-31)
+	// Months without that day use their last day (31 -> Feb 28)
+	DayOfMonth *int `json:"day_of_month,omitempty" db:"day_of_month"`
+
+	// StartDate is the first day an occurrence can fall on
+	// Weekly rules repeat on its weekday, yearly rules on its month and day
+	StartDate time.Time `json:"start_date" db:"start_date"`
+
+	// EndDate is the last day an occurrence can fall on (nil = no end)
+	EndDate *time.Time `json:"end_date,omitempty" db:"end_date"`
+
+	// LastOccurrence is the date of the last occurrence an expense was created for
+	LastOccurrence *time.Time `json:"last_occurrence_date,omitempty" db:"last_occurrence_date"`
+
+	// NextOccurrence is the date of the next occurrence (nil once the rule has ended)
+	NextOccurrence *time.Time `json:"next_occurrence_date,omitempty" db:"next_occurrence_date"`
+
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at" db:"updated_at"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
+}
+
+// NewRecurringExpense creates a new RecurringExpense with generated ID and timestamps
+// The next occurrence is scheduled from the start date
+func NewRecurringExpense(userID, userEmail string, amount money.Money, currency, description, category, frequency string, dayOfMonth *int, startDate time.Time, endDate *time.Time) *RecurringExpense {
+	now := time.Now()
+	rule := &RecurringExpense{
+		ID:          uuid.New().String(),
+		UserID:      userID,
+		UserEmail:   userEmail,
+		Amount:      amount,
+		Currency:    currency,
+		Description: description,
+		Category:    category,
+		Frequency:   frequency,
+		DayOfMonth:  dayOfMonth,
+		StartDate:   startDate,
+		EndDate:     endDate,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	rule.ScheduleNext()
+	return rule
+}
+
+// ScheduleNext sets NextOccurrence to the first occurrence after the last one
+// (or on/after the start date if there was none yet), or nil if that is past the end date
+// Called after an occurrence was created and whenever the schedule changes
+func (r *RecurringExpense) ScheduleNext() {
+	from := r.StartDate
+	if r.LastOccurrence != nil {
+		if dayAfter := r.LastOccurrence.AddDate(0, 0, 1); dayAfter.After(from) {
+			from = dayAfter
+		}
+	}
+
+	next := r.OccurrenceOnOrAfter(from)
+	if r.EndDate != nil && next.After(*r.EndDate) {
+		r.NextOccurrence = nil
+		return
+	}
+	r.NextOccurrence = &next
+}
+
+// OccurrenceOnOrAfter returns the first date on or after the given one that the rule falls on
+// date must not be before the start date
+func (r *RecurringExpense) OccurrenceOnOrAfter(date time.Time) time.Time {
+	switch r.Frequency {
+	case FrequencyWeekly:
+		// Same weekday as the start date
+		days := int(date.Sub(r.StartDate).Hours() / 24)
+		weeks := (days + 6) / 7 // Ceiling division
+		return r.StartDate.AddDate(0, 0, weeks*7)
+
+	case FrequencyMonthly:
+		day := r.StartDate.Day()
+		if r.DayOfMonth != nil {
+			day = *r.DayOfMonth
+		}
+		candidate := clampedDate(date.Year(), date.Month(), day)
+		if candidate.Before(date) {
+			candidate = clampedDate(date.Year(), date.Month()+1, day)
+		}
+		return candidate
+
+	case FrequencyYearly:
+		candidate := clampedDate(date.Year(), r.StartDate.Month(), r.StartDate.Day())
+		if candidate.Before(date) {
+			candidate = clampedDate(date.Year()+1, r.StartDate.Month(), r.StartDate.Day())
+		}
+		return candidate
+
+	default: // FrequencyDaily
+		return date
+	}
+}
+
+// NewOccurrence creates the expense for the occurrence on the given date
+// Its ID is derived from the rule and the date, so creating the same
+// occurrence twice (retries, several replicas) fails instead of duplicating it
+func (r *RecurringExpense) NewOccurrence(date time.Time) *Expense {
+	expense := NewExpense(r.UserID, r.Amount, r.Currency, r.Description, r.Category, date)
+	expense.ID = uuid.NewSHA1(uuid.NameSpaceURL, []byte("recurring-expense:"+r.ID+":"+date.Format("2006-01-02"))).String()
+	return expense
+}
+
+// clampedDate returns the given day of the month, or the month's last day if it is shorter
+// month may be 13 (January of the next year), like time.Date
+func clampedDate(year int, month time.Month, day int) time.Time {
+	lastDay := time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	if day > lastDay {
+		day = lastDay
+	}
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// CreateRecurringExpenseRequest represents the data sent when creating a recurring expense
+type CreateRecurringExpenseRequest struct {
+	Amount      string `json:"amount"`
+	Currency    string `json:"currency,omitempty"` // Default: the user's base currency
+	Description string `json:"description"`
+	Category    string `json:"category"`
+
+	// Frequency is daily, weekly, monthly or yearly
+	Frequency string `json:"frequency"`
+
+	// DayOfMonth is only allowed for monthly rules (default: the start date's day)
+	DayOfMonth *int `json:"day_of_month,omitempty"`
+
+	// StartDate and EndDate use the format YYYY-MM-DD (EndDate optional)
+	StartDate string  `json:"start_date"`
+	EndDate   *string `json:"end_date,omitempty"`
+}
+
+// UpdateRecurringExpenseRequest represents the data sent when updating a recurring expense
+// All fields are optional for partial updates - an empty end_date removes the end date
+type UpdateRecurringExpenseRequest struct {
+	Amount      *string `json:"amount,omitempty"`
+	Currency    *string `json:"currency,omitempty"` // "" switches to the base currency
+	Description *string `json:"description,omitempty"`
+	Category    *string `json:"category,omitempty"`
+	Frequency   *string `json:"frequency,omitempty"`
+	DayOfMonth  *int    `json:"day_of_month,omitempty"`
+	StartDate   *string `json:"start_date,omitempty"`
+	EndDate     *string `json:"end_date,omitempty"`
+}
+
+// ListRecurringExpensesResponse contains a user's recurring expenses
+type ListRecurringExpensesResponse struct {
+	RecurringExpenses []*RecurringExpense `json:"recurring_expenses"`
+	Total             int                 `json:"total"`
+}
@@ -0,0 +1,97 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+// date parses a YYYY-MM-DD date for the tests
+func date(t *testing.T, value string) time.Time {
+	t.Helper()
+	parsed, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		t.Fatalf("invalid test date %q: %v", value, err)
+	}
+	return parsed
+}
+
+func TestOccurrenceOnOrAfter(t *testing.T) {
+	day := func(d int) *int { return &d }
+
+	tests := []struct {
+		name       string
+		frequency  string
+		dayOfMonth *int
+		start      string
+		from       string
+		want       string
+	}{
+		{"daily", FrequencyDaily, nil, "2024-01-10", "2024-03-05", "2024-03-05"},
+		{"weekly on the start day", FrequencyWeekly, nil, "2024-01-10", "2024-01-10", "2024-01-10"},
+		{"weekly next week", FrequencyWeekly, nil, "2024-01-10", "2024-01-11", "2024-01-17"},
+		{"weekly exactly a week later", FrequencyWeekly, nil, "2024-01-10", "2024-01-17", "2024-01-17"},
+		{"weekly across a year", FrequencyWeekly, nil, "2024-12-30", "2025-01-01", "2025-01-06"},
+		{"monthly same month", FrequencyMonthly, nil, "2024-01-15", "2024-03-10", "2024-03-15"},
+		{"monthly next month", FrequencyMonthly, nil, "2024-01-15", "2024-03-16", "2024-04-15"},
+		{"monthly on the day", FrequencyMonthly, nil, "2024-01-15", "2024-03-15", "2024-03-15"},
+		{"monthly 31st in february", FrequencyMonthly, nil, "2024-01-31", "2024-02-01", "2024-02-29"},
+		{"monthly 31st in a short year", FrequencyMonthly, nil, "2023-01-31", "2023-02-01", "2023-02-28"},
+		{"monthly 31st in april", FrequencyMonthly, nil, "2024-01-31", "2024-04-01", "2024-04-30"},
+		{"monthly day of month", FrequencyMonthly, day(1), "2024-01-15", "2024-01-15", "2024-02-01"},
+		{"monthly december to january", FrequencyMonthly, nil, "2024-01-20", "2024-12-21", "2025-01-20"},
+		{"yearly same year", FrequencyYearly, nil, "2020-06-01", "2024-05-31", "2024-06-01"},
+		{"yearly next year", FrequencyYearly, nil, "2020-06-01", "2024-06-02", "2025-06-01"},
+		{"yearly leap day", FrequencyYearly, nil, "2024-02-29", "2025-01-01", "2025-02-28"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := &RecurringExpense{Frequency: tt.frequency, DayOfMonth: tt.dayOfMonth, StartDate: date(t, tt.start)}
+			got := rule.OccurrenceOnOrAfter(date(t, tt.from))
+			if want := date(t, tt.want); !got.Equal(want) {
+				t.Errorf("OccurrenceOnOrAfter(%s) = %s, want %s", tt.from, got.Format("2006-01-02"), tt.want)
+			}
+		})
+	}
+}
+
+func TestScheduleNext(t *testing.T) {
+	lastDay := date(t, "2024-03-31")
+	last := date(t, "2024-02-29")
+
+	tests := []struct {
+		name string
+		rule RecurringExpense
+		want string // Empty: the rule has ended
+	}{
+		{"first occurrence", RecurringExpense{Frequency: FrequencyMonthly, StartDate: date(t, "2024-01-31")}, "2024-01-31"},
+		{"after the last one", RecurringExpense{Frequency: FrequencyMonthly, StartDate: date(t, "2024-01-31"), LastOccurrence: &last}, "2024-03-31"},
+		{"on the end date", RecurringExpense{Frequency: FrequencyMonthly, StartDate: date(t, "2024-01-31"), LastOccurrence: &last, EndDate: &lastDay}, "2024-03-31"},
+		{"past the end date", RecurringExpense{Frequency: FrequencyDaily, StartDate: date(t, "2024-03-01"), LastOccurrence: &lastDay, EndDate: &lastDay}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := tt.rule
+			rule.ScheduleNext()
+			switch {
+			case tt.want == "" && rule.NextOccurrence != nil:
+				t.Errorf("NextOccurrence = %s, want none", rule.NextOccurrence.Format("2006-01-02"))
+			case tt.want != "" && (rule.NextOccurrence == nil || !rule.NextOccurrence.Equal(date(t, tt.want))):
+				t.Errorf("NextOccurrence = %v, want %s", rule.NextOccurrence, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewOccurrenceIDIsStable(t *testing.T) {
+	rule := &RecurringExpense{ID: "3f1c2b9e-0000-4000-8000-000000000001", UserID: "user", Currency: "EUR", Category: "Rent"}
+	first := rule.NewOccurrence(date(t, "2024-03-01"))
+
+	if again := rule.NewOccurrence(date(t, "2024-03-01")); again.ID != first.ID {
+		t.Errorf("same occurrence got IDs %s and %s", first.ID, again.ID)
+	}
+	if next := rule.NewOccurrence(date(t, "2024-04-01")); next.ID == first.ID {
+		t.Error("different occurrences share an ID")
+	}
+}
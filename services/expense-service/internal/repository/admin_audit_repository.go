@@ -0,0 +1,12 @@
+package repository
+
+import (
+	"context"
+	"expense-tracker/expense-service/internal/model"
+)
+
+// AdminAuditRepository defines the interface for the admin audit trail
+type AdminAuditRepository interface {
+	// Record stores one audit entry
+	Record(ctx context.Context, entry *model.AdminAuditEntry) error
+}
@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"context"
+	"expense-tracker/expense-service/internal/model"
+	"time"
+)
+
+// BudgetRepository defines the interface for budget data operations
+type BudgetRepository interface {
+	// Create inserts a new budget
+	// Fails with "already exists" if the user has a monthly budget for the category
+	Create(ctx context.Context, budget *model.Budget) error
+
+	// FindByID finds a budget by ID and user ID
+	// Returns nil if it doesn't exist or belongs to another user
+	FindByID(ctx context.Context, id, userID string) (*model.Budget, error)
+
+	// FindByUserID finds all budgets of a user, overall budgets first
+	FindByUserID(ctx context.Context, userID string) ([]*model.Budget, error)
+
+	// Update updates the budget's amount, dates and thresholds
+	// Verifies ownership through userID
+	Update(ctx context.Context, budget *model.Budget) error
+
+	// Delete deletes a budget and its alerts
+	// Verifies ownership through userID
+	Delete(ctx context.Context, id, userID string) error
+
+	// GetSpent sums the user's expenses from start to end (inclusive) by currency and date
+	// category nil sums all categories
+	GetSpent(ctx context.Context, userID string, category *string, start, end time.Time) ([]model.AmountGroup, error)
+
+	// RecordAlert records that a threshold was crossed in the period starting on periodStart
+	// Returns false if it was already recorded (the alert was sent before)
+	RecordAlert(ctx context.Context, budgetID string, periodStart time.Time, threshold int) (bool, error)
+}
@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+	"expense-tracker/expense-service/internal/model"
+)
+
+// CategoryRepository defines the interface for category catalog operations
+// Category names are matched ignoring case
+type CategoryRepository interface {
+	// Create inserts a new category
+	// Fails with "already exists" if the user has a category with the name
+	Create(ctx context.Context, category *model.Category) error
+
+	// CreateDefaults seeds the catalog of a user without categories with the defaults
+	// and the categories their expenses, recurring expenses and budgets already use,
+	// whose spellings are unified ("food" and "Food" become one)
+	// Does nothing if the user has categories
+	CreateDefaults(ctx context.Context, userID string, defaults []model.DefaultCategory) error
+
+	// FindByID finds a category by ID and user ID
+	// Returns nil if it doesn't exist or belongs to another user
+	FindByID(ctx context.Context, id, userID string) (*model.Category, error)
+
+	// FindByName finds a user's category by name
+	// Returns nil if the user has no such category
+	FindByName(ctx context.Context, userID, name string) (*model.Category, error)
+
+	// FindByUserID finds all categories of a user, sorted by name
+	FindByUserID(ctx context.Context, userID string) ([]*model.Category, error)
+
+	// HasSubcategories reports whether a category is the parent of other categories
+	HasSubcategories(ctx context.Context, id string) (bool, error)
+
+	// CountUsage counts the expenses, recurring expenses and budgets with the category name
+	CountUsage(ctx context.Context, userID, name string) (int, error)
+
+	// Update updates a category in one transaction with renaming the expenses,
+	// recurring expenses and budgets that have its stored name in any spelling
+	// Without renamed the stored name is kept (category.Name is set to it)
+	// Verifies ownership through userID
+	Update(ctx context.Context, category *model.Category, renamed bool) error
+
+	// Merge moves the expenses, recurring expenses, budgets and subcategories of
+	// source to target and deletes source, in one transaction
+	// Both are read again with their rows locked (source and target are updated)
+	// Returns how many expenses were moved
+	Merge(ctx context.Context, source, target *model.Category) (int64, error)
+
+	// Delete deletes a category; its subcategories become top-level
+	// Verifies ownership through userID
+	Delete(ctx context.Context, id, userID string) error
+}
@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"context"
+	"expense-tracker/expense-service/internal/model"
+	"time"
+)
+
+// ExchangeRateRepository defines the interface for stored exchange rates
+type ExchangeRateRepository interface {
+	// Save inserts rates, replacing existing ones for the same pair and date
+	Save(ctx context.Context, rates []model.ExchangeRate) error
+
+	// FindHistory finds, for every currency pair involving one of the given
+	// currencies, the latest rate on or before from and every rate after it up to to,
+	// oldest first - enough to know the rate of each pair on any day from..to
+	FindHistory(ctx context.Context, currencies []string, from, to time.Time) ([]model.ExchangeRate, error)
+}
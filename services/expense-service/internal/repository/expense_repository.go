@@ -2,23 +2,44 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"expense-tracker/expense-service/internal/model"
+	"time"
+)
+
+// Errors of single expenses (batches report them per change)
+var (
+	ErrExpenseNotFound = errors.New("expense not found or access denied")
+	ErrExpenseExists   = errors.New("expense already exists")
+
+	// ErrEarlierChangeFailed skips a change of a batch that was prepared on top of a
+	// change of the same expense that failed
+	ErrEarlierChangeFailed = errors.New("expense not saved because an earlier operation on it failed")
 )
 
 // ExpenseRepository defines the interface for expense data operations
 // This follows the repository pattern for clean architecture
 type ExpenseRepository interface {
 	// Create inserts a new expense into the database
+	// Fails with "expense already exists" if the ID is taken
 	Create(ctx context.Context, expense *model.Expense) error
 
+	// CreateBatch inserts several expenses in one transaction - if one fails, none is created
+	CreateBatch(ctx context.Context, expenses []*model.Expense) error
+
 	// FindByID finds an expense by ID and user ID
 	// This ensures users can only access their own expenses
 	FindByID(ctx context.Context, id, userID string) (*model.Expense, error)
 
-	// FindByUserID finds all expenses for a user with optional filters and pagination
-	// Filters: category, startDate, endDate
-	// Pagination: page, limit
-	FindByUserID(ctx context.Context, userID string, filters *model.ListExpensesRequest) ([]*model.Expense, int, error)
+	// FindByUserID finds a page of a user's expenses with optional filters and sorting
+	// Filters: category, startDate, endDate, tags, query, amount range
+	// Pagination: cursor or page, limit; the total is not counted with SkipCount
+	FindByUserID(ctx context.Context, userID string, filters *model.ListExpensesRequest) (*model.ExpensePage, error)
+
+	// StreamByUserID calls fn for every expense matching the filters (same order as
+	// FindByUserID, ignoring pagination) - stops at the first error fn returns
+	// No database connection is held while fn runs
+	StreamByUserID(ctx context.Context, userID string, filters *model.ListExpensesRequest, fn func(*model.Expense) error) error
 
 	// Update updates an existing expense
 	// Verifies ownership through userID
@@ -28,7 +49,30 @@ type ExpenseRepository interface {
 	// Verifies ownership through userID
 	Delete(ctx context.Context, id, userID string) error
 
-	// GetTotalByCategory gets expense totals grouped by category
-	// Used for summary/aggregation queries
-	GetTotalByCategory(ctx context.Context, userID string, startDate, endDate *string) ([]model.ExpenseSummaryItem, string, error)
+	// ApplyChanges writes a batch of creates, updates and deletes in one transaction
+	// Returns each change's error (nil if applied). With atomic, nothing is saved
+	// if a change fails; otherwise only the failing changes are left out, along with
+	// the later changes of the same expenses (ErrEarlierChangeFailed)
+	ApplyChanges(ctx context.Context, changes []*model.ExpenseChange, atomic bool) ([]error, error)
+
+	// FindFingerprints finds the fingerprints (date, amount and description) of a
+	// user's expenses between two dates, with the IDs of the expenses that have each
+	// Used to detect duplicates when importing bank statements
+	FindFingerprints(ctx context.Context, userID string, startDate, endDate time.Time) (map[string][]string, error)
+
+	// GetTags counts how many of the user's expenses have each tag (most used first)
+	GetTags(ctx context.Context, userID string) ([]model.TagUsage, error)
+
+	// GetTotalByCategory gets the totals of the expenses matching the list filters
+	// grouped by category, currency and date (ignores pagination)
+	// Used for summary/aggregation queries (totals are converted per currency and date)
+	GetTotalByCategory(ctx context.Context, userID string, filters *model.ListExpensesRequest) ([]model.AmountGroup, error)
+
+	// HasNonLatin1Text reports whether a description or category of the expenses
+	// matching the list filters has characters outside Latin-1 (PDF exports can't show them)
+	HasNonLatin1Text(ctx context.Context, userID string, filters *model.ListExpensesRequest) (bool, error)
+
+	// GetAmountGroups gets the totals of the expenses matching the list filters
+	// grouped by currency and date (ignores pagination)
+	GetAmountGroups(ctx context.Context, userID string, filters *model.ListExpensesRequest) ([]model.AmountGroup, error)
 }
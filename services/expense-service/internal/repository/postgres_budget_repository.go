@@ -0,0 +1,251 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"expense-tracker/expense-service/internal/model"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// budgetColumns is the column list scanBudget expects
+const budgetColumns = `id, user_id, category, amount, currency, period, start_date, end_date, alert_thresholds, created_at, updated_at`
+
+// monthlyBudgetIndex allows one monthly budget per category (see migrations/005_create_budgets_tables.sql)
+const monthlyBudgetIndex = "idx_budgets_user_monthly_category"
+
+// PostgresBudgetRepository implements BudgetRepository using PostgreSQL
+type PostgresBudgetRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresBudgetRepository creates a new PostgreSQL budget repository
+func NewPostgresBudgetRepository(pool *pgxpool.Pool) BudgetRepository {
+	return &PostgresBudgetRepository{
+		pool: pool,
+	}
+}
+
+// Create inserts a new budget
+func (r *PostgresBudgetRepository) Create(ctx context.Context, budget *model.Budget) error {
+	query := `
+		INSERT INTO budgets (id, user_id, category, amount, currency, period, start_date, end_date, alert_thresholds, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`
+
+	_, err := r.pool.Exec(ctx, query,
+		budget.ID,
+		budget.UserID,
+		budget.Category,
+		budget.Amount,
+		budget.Currency,
+		budget.Period,
+		budget.StartDate,
+		budget.EndDate,
+		budget.AlertThresholds,
+		budget.CreatedAt,
+		budget.UpdatedAt,
+	)
+
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == monthlyBudgetIndex { // unique_violation
+			return fmt.Errorf("a monthly budget for this category already exists")
+		}
+		return err
+	}
+
+	return nil
+}
+
+// FindByID finds a budget by ID and user ID
+func (r *PostgresBudgetRepository) FindByID(ctx context.Context, id, userID string) (*model.Budget, error) {
+	query := `SELECT ` + budgetColumns + ` FROM budgets WHERE id = $1 AND user_id = $2`
+
+	budget, err := scanBudget(r.pool.QueryRow(ctx, query, id, userID))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil // Budget not found
+		}
+		return nil, err
+	}
+
+	return budget, nil
+}
+
+// FindByUserID finds all budgets of a user
+func (r *PostgresBudgetRepository) FindByUserID(ctx context.Context, userID string) ([]*model.Budget, error) {
+	query := `
+		SELECT ` + budgetColumns + `
+		FROM budgets
+		WHERE user_id = $1
+		ORDER BY category NULLS FIRST, period, start_date
+	`
+
+	return r.queryBudgets(ctx, query, userID)
+}
+
+// Update updates the budget's amount, currency, dates and thresholds
+func (r *PostgresBudgetRepository) Update(ctx context.Context, budget *model.Budget) error {
+	query := `
+		UPDATE budgets
+		SET amount = $1,
+		    currency = $2,
+		    start_date = $3,
+		    end_date = $4,
+		    alert_thresholds = $5,
+		    updated_at = $6
+		WHERE id = $7 AND user_id = $8
+	`
+
+	result, err := r.pool.Exec(ctx, query,
+		budget.Amount,
+		budget.Currency,
+		budget.StartDate,
+		budget.EndDate,
+		budget.AlertThresholds,
+		budget.UpdatedAt,
+		budget.ID,
+		budget.UserID,
+	)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("budget not found or access denied")
+	}
+
+	return nil
+}
+
+// Delete deletes a budget (its alerts are deleted by ON DELETE CASCADE)
+func (r *PostgresBudgetRepository) Delete(ctx context.Context, id, userID string) error {
+	result, err := r.pool.Exec(ctx, `DELETE FROM budgets WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("budget not found or access denied")
+	}
+
+	return nil
+}
+
+// GetSpent sums the user's (not deleted) expenses in the period by currency and date
+func (r *PostgresBudgetRepository) GetSpent(ctx context.Context, userID string, category *string, start, end time.Time) ([]model.AmountGroup, error) {
+	whereClause := "user_id = $1 AND deleted_at IS NULL AND expense_date >= $2 AND expense_date <= $3"
+	args := []interface{}{userID, start, end}
+
+	if category != nil {
+		whereClause += " AND category = $4"
+		args = append(args, *category)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT currency, expense_date, SUM(amount), COUNT(*)
+		FROM expenses
+		WHERE %s
+		GROUP BY currency, expense_date
+	`, whereClause)
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	groups := []model.AmountGroup{}
+	for rows.Next() {
+		var group model.AmountGroup
+		if err := rows.Scan(&group.Currency, &group.Date, &group.Total, &group.Count); err != nil {
+			return nil, err
+		}
+		groups = append(groups, group)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return groups, nil
+}
+
+// RecordAlert records a crossed threshold
+// ON CONFLICT DO NOTHING makes concurrent requests crossing the same threshold send one alert
+func (r *PostgresBudgetRepository) RecordAlert(ctx context.Context, budgetID string, periodStart time.Time, threshold int) (bool, error) {
+	result, err := r.pool.Exec(ctx, `
+		INSERT INTO budget_alerts (budget_id, period_start, threshold, created_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (budget_id, period_start, threshold) DO NOTHING
+	`, budgetID, periodStart, threshold, time.Now())
+	if err != nil {
+		return false, err
+	}
+
+	return result.RowsAffected() > 0, nil
+}
+
+// queryBudgets runs a query selecting budgetColumns
+func (r *PostgresBudgetRepository) queryBudgets(ctx context.Context, query string, args ...interface{}) ([]*model.Budget, error) {
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	budgets := []*model.Budget{}
+	for rows.Next() {
+		budget, err := scanBudget(rows)
+		if err != nil {
+			return nil, err
+		}
+		budgets = append(budgets, budget)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return budgets, nil
+}
+
+// scanBudget scans a row of budgetColumns
+func scanBudget(row pgx.Row) (*model.Budget, error) {
+	var budget model.Budget
+	var category sql.NullString
+	var startDate, endDate sql.NullTime
+
+	err := row.Scan(
+		&budget.ID,
+		&budget.UserID,
+		&category,
+		&budget.Amount,
+		&budget.Currency,
+		&budget.Period,
+		&startDate,
+		&endDate,
+		&budget.AlertThresholds,
+		&budget.CreatedAt,
+		&budget.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if category.Valid {
+		budget.Category = &category.String
+	}
+	if startDate.Valid {
+		budget.StartDate = &startDate.Time
+	}
+	if endDate.Valid {
+		budget.EndDate = &endDate.Time
+	}
+
+	return &budget, nil
+}
@@ -0,0 +1,447 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"expense-tracker/expense-service/internal/model"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// categoryColumns are the columns scanCategory reads
+const categoryColumns = `id, user_id, name, color, icon, parent_id, created_at, updated_at`
+
+// PostgresCategoryRepository implements CategoryRepository using PostgreSQL
+type PostgresCategoryRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresCategoryRepository creates a new PostgreSQL category repository
+func NewPostgresCategoryRepository(pool *pgxpool.Pool) CategoryRepository {
+	return &PostgresCategoryRepository{
+		pool: pool,
+	}
+}
+
+// Create inserts a new category into the database
+func (r *PostgresCategoryRepository) Create(ctx context.Context, category *model.Category) error {
+	query := `
+		INSERT INTO categories (id, user_id, name, color, icon, parent_id, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+
+	_, err := r.pool.Exec(ctx, query,
+		category.ID,
+		category.UserID,
+		category.Name,
+		category.Color,
+		category.Icon,
+		category.ParentID,
+		category.CreatedAt,
+		category.UpdatedAt,
+	)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" { // unique_violation
+			return fmt.Errorf("category already exists")
+		}
+		return err
+	}
+
+	return nil
+}
+
+// CreateDefaults seeds the catalog of a user without categories
+// Names already in use win over a default that only differs in case ("food" over "Food"),
+// and other spellings of a seeded name are changed to it in the same transaction
+func (r *PostgresCategoryRepository) CreateDefaults(ctx context.Context, userID string, defaults []model.DefaultCategory) error {
+	names := make([]string, len(defaults))
+	colors := make([]string, len(defaults))
+	icons := make([]string, len(defaults))
+	for i, category := range defaults {
+		names[i] = category.Name
+		colors[i] = category.Color
+		icons[i] = category.Icon
+	}
+
+	// ON CONFLICT covers two requests seeding the same catalog at once
+	query := `
+		INSERT INTO categories (user_id, name, color, icon)
+		SELECT $1, name, color, icon
+		FROM (
+			SELECT DISTINCT ON (LOWER(c.name)) c.name, d.color, d.icon
+			FROM (
+				SELECT category AS name, 0 AS source FROM expenses WHERE user_id = $1 AND deleted_at IS NULL
+				UNION ALL
+				SELECT category, 0 FROM recurring_expenses WHERE user_id = $1 AND deleted_at IS NULL
+				UNION ALL
+				SELECT category, 0 FROM budgets WHERE user_id = $1 AND category IS NOT NULL
+				UNION ALL
+				SELECT name, 1 FROM unnest($2::text[]) AS name
+			) c
+			LEFT JOIN unnest($2::text[], $3::text[], $4::text[]) AS d(name, color, icon) ON LOWER(d.name) = LOWER(c.name)
+			ORDER BY LOWER(c.name), c.source
+		) seed
+		WHERE NOT EXISTS (SELECT 1 FROM categories WHERE user_id = $1)
+		ON CONFLICT DO NOTHING
+	`
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	// Rollback is a no-op if the transaction was committed
+	defer tx.Rollback(ctx)
+
+	result, err := tx.Exec(ctx, query, userID, names, colors, icons)
+	if err != nil {
+		return err
+	}
+
+	// Expenses of "food" and "Food" would otherwise be totalled and filtered apart
+	if result.RowsAffected() > 0 {
+		if err := normalizeCategoryNames(ctx, tx, userID); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// FindByID finds a category by ID and user ID
+func (r *PostgresCategoryRepository) FindByID(ctx context.Context, id, userID string) (*model.Category, error) {
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM categories
+		WHERE id = $1 AND user_id = $2
+	`, categoryColumns)
+
+	category, err := scanCategory(r.pool.QueryRow(ctx, query, id, userID))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil // Category not found
+		}
+		return nil, err
+	}
+
+	return category, nil
+}
+
+// FindByName finds a user's category by name, ignoring case
+func (r *PostgresCategoryRepository) FindByName(ctx context.Context, userID, name string) (*model.Category, error) {
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM categories
+		WHERE user_id = $1 AND LOWER(name) = LOWER($2)
+	`, categoryColumns)
+
+	category, err := scanCategory(r.pool.QueryRow(ctx, query, userID, name))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil // Category not found
+		}
+		return nil, err
+	}
+
+	return category, nil
+}
+
+// FindByUserID finds all categories of a user
+func (r *PostgresCategoryRepository) FindByUserID(ctx context.Context, userID string) ([]*model.Category, error) {
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM categories
+		WHERE user_id = $1
+		ORDER BY LOWER(name)
+	`, categoryColumns)
+
+	rows, err := r.pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	categories := []*model.Category{}
+	for rows.Next() {
+		category, err := scanCategory(rows)
+		if err != nil {
+			return nil, err
+		}
+		categories = append(categories, category)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return categories, nil
+}
+
+// HasSubcategories reports whether a category is the parent of other categories
+func (r *PostgresCategoryRepository) HasSubcategories(ctx context.Context, id string) (bool, error) {
+	var exists bool
+	err := r.pool.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM categories WHERE parent_id = $1)`, id).Scan(&exists)
+	return exists, err
+}
+
+// CountUsage counts the expenses, recurring expenses and budgets with the category name
+func (r *PostgresCategoryRepository) CountUsage(ctx context.Context, userID, name string) (int, error) {
+	query := `
+		SELECT
+			(SELECT COUNT(*) FROM expenses WHERE user_id = $1 AND LOWER(category) = LOWER($2) AND deleted_at IS NULL) +
+			(SELECT COUNT(*) FROM recurring_expenses WHERE user_id = $1 AND LOWER(category) = LOWER($2) AND deleted_at IS NULL) +
+			(SELECT COUNT(*) FROM budgets WHERE user_id = $1 AND LOWER(category) = LOWER($2))
+	`
+
+	var count int
+	err := r.pool.QueryRow(ctx, query, userID, name).Scan(&count)
+	return count, err
+}
+
+// Update updates a category and renames its expenses, recurring expenses and budgets
+func (r *PostgresCategoryRepository) Update(ctx context.Context, category *model.Category, renamed bool) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	// Rollback is a no-op if the transaction was committed
+	defer tx.Rollback(ctx)
+
+	// The name may have changed since category was read - expenses are renamed
+	// from the current one, which stays locked until the commit
+	current, err := lockCategories(ctx, tx, category.UserID, category.ID)
+	if err != nil {
+		return err
+	}
+	previousName := current[0].Name
+	if !renamed {
+		category.Name = previousName
+	}
+
+	query := `
+		UPDATE categories
+		SET name = $1, color = $2, icon = $3, parent_id = $4, updated_at = $5
+		WHERE id = $6 AND user_id = $7
+	`
+
+	result, err := tx.Exec(ctx, query,
+		category.Name,
+		category.Color,
+		category.Icon,
+		category.ParentID,
+		category.UpdatedAt,
+		category.ID,
+		category.UserID,
+	)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" { // unique_violation
+			return fmt.Errorf("category already exists")
+		}
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("category not found or access denied")
+	}
+
+	// Also without a new name, so other spellings ("food" for "Food") are fixed
+	if _, err := renameCategory(ctx, tx, category.UserID, previousName, category.Name, category.UpdatedAt); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// Merge moves everything of source to target and deletes source
+func (r *PostgresCategoryRepository) Merge(ctx context.Context, source, target *model.Category) (int64, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	// Rollback is a no-op if the transaction was committed
+	defer tx.Rollback(ctx)
+
+	// Both may have been renamed or moved since they were read
+	current, err := lockCategories(ctx, tx, source.UserID, source.ID, target.ID)
+	if err != nil {
+		return 0, err
+	}
+	*source, *target = *current[0], *current[1]
+	if target.ParentID != nil && *target.ParentID == source.ID {
+		return 0, fmt.Errorf("category cannot be merged into its own subcategory")
+	}
+
+	expensesUpdated, err := renameCategory(ctx, tx, source.UserID, source.Name, target.Name, time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	// Subcategories move to the target, or next to it if it is a subcategory itself (one level deep)
+	parentID := target.ID
+	if target.ParentID != nil {
+		parentID = *target.ParentID
+	}
+	_, err = tx.Exec(ctx, `UPDATE categories SET parent_id = $1, updated_at = NOW() WHERE parent_id = $2`, parentID, source.ID)
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := tx.Exec(ctx, `DELETE FROM categories WHERE id = $1 AND user_id = $2`, source.ID, source.UserID)
+	if err != nil {
+		return 0, err
+	}
+
+	if result.RowsAffected() == 0 {
+		return 0, fmt.Errorf("category not found or access denied")
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+
+	return expensesUpdated, nil
+}
+
+// lockCategories reads a user's categories by ID (in the given order) and locks
+// them until the transaction ends, so they can't be renamed or deleted meanwhile
+func lockCategories(ctx context.Context, tx pgx.Tx, userID string, ids ...string) ([]*model.Category, error) {
+	// Locked in ID order, so two requests locking the same categories can't deadlock
+	rows, err := tx.Query(ctx, fmt.Sprintf(`
+		SELECT %s
+		FROM categories
+		WHERE id = ANY($1) AND user_id = $2
+		ORDER BY id
+		FOR UPDATE
+	`, categoryColumns), ids, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	byID := make(map[string]*model.Category)
+	for rows.Next() {
+		category, err := scanCategory(rows)
+		if err != nil {
+			return nil, err
+		}
+		byID[category.ID] = category
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	categories := make([]*model.Category, len(ids))
+	for i, id := range ids {
+		if categories[i] = byID[id]; categories[i] == nil {
+			return nil, fmt.Errorf("category not found or access denied")
+		}
+	}
+	return categories, nil
+}
+
+// Delete deletes a category
+func (r *PostgresCategoryRepository) Delete(ctx context.Context, id, userID string) error {
+	result, err := r.pool.Exec(ctx, `DELETE FROM categories WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("category not found or access denied")
+	}
+
+	return nil
+}
+
+// renameCategory changes the category of a user's expenses (including soft-deleted ones),
+// recurring expenses and budgets from one name (ignoring case) to another
+// Rows that already have the new name are left alone. Returns how many expenses were changed
+func renameCategory(ctx context.Context, tx pgx.Tx, userID, from, to string, updatedAt time.Time) (int64, error) {
+	result, err := tx.Exec(ctx, `
+		UPDATE expenses SET category = $1, updated_at = $2
+		WHERE user_id = $3 AND LOWER(category) = LOWER($4) AND category <> $1
+	`, to, updatedAt, userID, from)
+	if err != nil {
+		return 0, err
+	}
+	expensesUpdated := result.RowsAffected()
+
+	_, err = tx.Exec(ctx, `
+		UPDATE recurring_expenses SET category = $1, updated_at = $2
+		WHERE user_id = $3 AND LOWER(category) = LOWER($4) AND category <> $1
+	`, to, updatedAt, userID, from)
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE budgets SET category = $1, updated_at = $2
+		WHERE user_id = $3 AND LOWER(category) = LOWER($4) AND category <> $1
+	`, to, updatedAt, userID, from)
+	if err != nil {
+		// Only one monthly budget per category
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == monthlyBudgetIndex { // unique_violation
+			return 0, fmt.Errorf("a monthly budget for category %s already exists", to)
+		}
+		return 0, err
+	}
+
+	return expensesUpdated, nil
+}
+
+// normalizeCategoryNames changes the categories of a user's expenses (including
+// soft-deleted ones), recurring expenses and budgets to the spelling of their catalog
+// category ("food" -> "Food"). Search words don't change, they are lower-cased anyway
+func normalizeCategoryNames(ctx context.Context, tx pgx.Tx, userID string) error {
+	for _, table := range []string{"expenses", "recurring_expenses", "budgets"} {
+		_, err := tx.Exec(ctx, fmt.Sprintf(`
+			UPDATE %s t SET category = c.name, updated_at = NOW()
+			FROM categories c
+			WHERE t.user_id = $1 AND c.user_id = $1
+			  AND LOWER(t.category) = LOWER(c.name) AND t.category <> c.name
+		`, table), userID)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// scanCategory scans a row of categoryColumns
+func scanCategory(row pgx.Row) (*model.Category, error) {
+	var category model.Category
+	var color, icon, parentID sql.NullString
+
+	err := row.Scan(
+		&category.ID,
+		&category.UserID,
+		&category.Name,
+		&color,
+		&icon,
+		&parentID,
+		&category.CreatedAt,
+		&category.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if color.Valid {
+		category.Color = &color.String
+	}
+	if icon.Valid {
+		category.Icon = &icon.String
+	}
+	if parentID.Valid {
+		category.ParentID = &parentID.String
+	}
+
+	return &category, nil
+}
@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+	"expense-tracker/expense-service/internal/model"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgresExchangeRateRepository implements ExchangeRateRepository using PostgreSQL
+type PostgresExchangeRateRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresExchangeRateRepository creates a new PostgreSQL exchange rate repository
+func NewPostgresExchangeRateRepository(pool *pgxpool.Pool) ExchangeRateRepository {
+	return &PostgresExchangeRateRepository{
+		pool: pool,
+	}
+}
+
+// Save inserts rates in one transaction
+func (r *PostgresExchangeRateRepository) Save(ctx context.Context, rates []model.ExchangeRate) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	// Rollback is a no-op if the transaction was committed
+	defer tx.Rollback(ctx)
+
+	now := time.Now()
+	for _, rate := range rates {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO exchange_rates (base_currency, quote_currency, rate_date, rate, created_at)
+			VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (base_currency, quote_currency, rate_date) DO UPDATE
+			SET rate = EXCLUDED.rate,
+			    created_at = EXCLUDED.created_at
+		`, rate.BaseCurrency, rate.QuoteCurrency, rate.Date, rate.Rate, now)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// FindHistory finds the rates of every pair involving the currencies needed for from..to
+// in one query: DISTINCT ON keeps the newest rate per pair on or before from,
+// the second part adds the rates after it
+func (r *PostgresExchangeRateRepository) FindHistory(ctx context.Context, currencies []string, from, to time.Time) ([]model.ExchangeRate, error) {
+	rows, err := r.pool.Query(ctx, `
+		(
+			SELECT DISTINCT ON (base_currency, quote_currency) base_currency, quote_currency, rate_date, rate
+			FROM exchange_rates
+			WHERE (base_currency = ANY($1) OR quote_currency = ANY($1)) AND rate_date <= $2
+			ORDER BY base_currency, quote_currency, rate_date DESC
+		)
+		UNION ALL
+		(
+			SELECT base_currency, quote_currency, rate_date, rate
+			FROM exchange_rates
+			WHERE (base_currency = ANY($1) OR quote_currency = ANY($1)) AND rate_date > $2 AND rate_date <= $3
+		)
+		ORDER BY rate_date
+	`, currencies, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rates := []model.ExchangeRate{}
+	for rows.Next() {
+		var rate model.ExchangeRate
+		if err := rows.Scan(&rate.BaseCurrency, &rate.QuoteCurrency, &rate.Date, &rate.Rate); err != nil {
+			return nil, err
+		}
+		rates = append(rates, rate)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return rates, nil
+}
@@ -3,14 +3,27 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"expense-tracker/expense-service/internal/model"
+	"expense-tracker/shared/money"
+	"expense-tracker/shared/pagination"
 	"fmt"
+	"slices"
 	"time"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// expenseColumns are the columns scanExpense reads (tags are aggregated from expense_tags)
+const expenseColumns = `id, user_id, amount, currency, description, category, expense_date, created_at, updated_at, deleted_at,
+	COALESCE((SELECT array_agg(t.tag ORDER BY t.tag) FROM expense_tags t WHERE t.expense_id = expenses.id), '{}')`
+
+// expenseHeadline is the description of a matching expense with the search
+// words marked (ts_headline never escapes, see service.highlightHTML)
+const expenseHeadline = `ts_headline('english', description, websearch_to_tsquery('english', $%d), 'StartSel=<mark>, StopSel=</mark>, HighlightAll=true')`
+
 // PostgresExpenseRepository implements ExpenseRepository using PostgreSQL
 type PostgresExpenseRepository struct {
 	pool *pgxpool.Pool
@@ -23,51 +36,50 @@ func NewPostgresExpenseRepository(pool *pgxpool.Pool) ExpenseRepository {
 	}
 }
 
-// Create inserts a new expense into the database
+// Create inserts a new expense and its tags into the database in one transaction
 func (r *PostgresExpenseRepository) Create(ctx context.Context, expense *model.Expense) error {
-	query := `
-		INSERT INTO expenses (id, user_id, amount, description, category, expense_date, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-	`
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	// Rollback is a no-op if the transaction was committed
+	defer tx.Rollback(ctx)
 
-	_, err := r.pool.Exec(ctx, query,
-		expense.ID,
-		expense.UserID,
-		expense.Amount,
-		expense.Description,
-		expense.Category,
-		expense.ExpenseDate,
-		expense.CreatedAt,
-		expense.UpdatedAt,
-	)
+	if err := insertExpense(ctx, tx, expense); err != nil {
+		return err
+	}
 
-	return err
+	return tx.Commit(ctx)
+}
+
+// CreateBatch inserts expenses and their tags in one transaction - all or none
+func (r *PostgresExpenseRepository) CreateBatch(ctx context.Context, expenses []*model.Expense) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	// Rollback is a no-op if the transaction was committed
+	defer tx.Rollback(ctx)
+
+	for _, expense := range expenses {
+		if err := insertExpense(ctx, tx, expense); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
 }
 
 // FindByID finds an expense by ID and user ID
 // This ensures ownership - users can only access their own expenses
 func (r *PostgresExpenseRepository) FindByID(ctx context.Context, id, userID string) (*model.Expense, error) {
-	query := `
-		SELECT id, user_id, amount, description, category, expense_date, created_at, updated_at, deleted_at
+	query := fmt.Sprintf(`
+		SELECT %s
 		FROM expenses
 		WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL
-	`
-
-	var expense model.Expense
-	var deletedAt sql.NullTime
-
-	err := r.pool.QueryRow(ctx, query, id, userID).Scan(
-		&expense.ID,
-		&expense.UserID,
-		&expense.Amount,
-		&expense.Description,
-		&expense.Category,
-		&expense.ExpenseDate,
-		&expense.CreatedAt,
-		&expense.UpdatedAt,
-		&deletedAt,
-	)
+	`, expenseColumns)
 
+	expense, err := scanExpense(r.pool.QueryRow(ctx, query, id, userID))
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			return nil, nil // Expense not found
@@ -75,46 +87,27 @@ func (r *PostgresExpenseRepository) FindByID(ctx context.Context, id, userID str
 		return nil, err
 	}
 
-	if deletedAt.Valid {
-		expense.DeletedAt = &deletedAt.Time
-	}
-
-	return &expense, nil
+	return expense, nil
 }
 
-// FindByUserID finds all expenses for a user with optional filters and pagination
-func (r *PostgresExpenseRepository) FindByUserID(ctx context.Context, userID string, filters *model.ListExpensesRequest) ([]*model.Expense, int, error) {
-	// Build WHERE clause dynamically based on filters
-	whereClause := "user_id = $1 AND deleted_at IS NULL"
-	args := []interface{}{userID}
-	argIndex := 2
-
-	// Add category filter
-	if filters.Category != "" {
-		whereClause += fmt.Sprintf(" AND category = $%d", argIndex)
-		args = append(args, filters.Category)
-		argIndex++
-	}
-
-	// Add date range filters
-	if filters.StartDate != "" {
-		whereClause += fmt.Sprintf(" AND expense_date >= $%d", argIndex)
-		args = append(args, filters.StartDate)
-		argIndex++
-	}
-
-	if filters.EndDate != "" {
-		whereClause += fmt.Sprintf(" AND expense_date <= $%d", argIndex)
-		args = append(args, filters.EndDate)
-		argIndex++
-	}
+// FindByUserID finds a page of a user's expenses with optional filters and sorting
+//
+// Pages are found by keyset: with a cursor, the query continues after (or before)
+// the cursor's sort key values instead of skipping rows with OFFSET. One row more
+// than the limit is read to know whether there is another page
+func (r *PostgresExpenseRepository) FindByUserID(ctx context.Context, userID string, filters *model.ListExpensesRequest) (*model.ExpensePage, error) {
+	whereClause, args := expenseFilterClause(userID, filters)
+	page := &model.ExpensePage{}
 
 	// Get total count (for pagination)
-	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM expenses WHERE %s", whereClause)
-	var total int
-	err := r.pool.QueryRow(ctx, countQuery, args...).Scan(&total)
-	if err != nil {
-		return nil, 0, err
+	if !filters.SkipCount {
+		countQuery := fmt.Sprintf("SELECT COUNT(*) FROM expenses WHERE %s", whereClause)
+		var total int
+		err := r.pool.QueryRow(ctx, countQuery, args...).Scan(&total)
+		if err != nil {
+			return nil, err
+		}
+		page.Total = &total
 	}
 
 	// Apply pagination
@@ -126,78 +119,277 @@ func (r *PostgresExpenseRepository) FindByUserID(ctx context.Context, userID str
 		limit = 100 // Max
 	}
 
-	page := filters.Page
-	if page <= 0 {
-		page = 1
+	offset := 0
+	cursor := filters.PageCursor
+	if cursor == nil && filters.Page > 1 {
+		offset = (filters.Page - 1) * limit
+	}
+
+	// Searches mark the search words in the description
+	columns := expenseColumns + ", ''"
+	if filters.Query != "" {
+		columns = expenseColumns + ", " + fmt.Sprintf(expenseHeadline, len(args)+1)
+		args = append(args, filters.Query)
+	}
+
+	var keys []pagination.SortKey
+	keys, args = expenseSortKeys(filters, args)
+	for _, key := range keys {
+		columns += ", " + key.Expr + "::text"
+	}
+
+	// A page before the cursor is read backwards from the cursor, then flipped
+	desc := filters.Order != model.SortAsc
+	backward := cursor != nil && cursor.Before
+	if cursor != nil {
+		if len(cursor.Values) != len(keys) {
+			return nil, errors.New("cursor must be a next_cursor or prev_cursor value")
+		}
+		var condition string
+		condition, args = pagination.After(keys, cursor.Values, desc != backward, args)
+		whereClause += " AND " + condition
 	}
-	offset := (page - 1) * limit
 
 	// Build SELECT query with pagination
 	query := fmt.Sprintf(`
-		SELECT id, user_id, amount, description, category, expense_date, created_at, updated_at, deleted_at
+		SELECT %s
 		FROM expenses
 		WHERE %s
-		ORDER BY expense_date DESC, created_at DESC
+		ORDER BY %s
 		LIMIT $%d OFFSET $%d
-	`, whereClause, argIndex, argIndex+1)
+	`, columns, whereClause, pagination.OrderBy(keys, desc != backward), len(args)+1, len(args)+2)
 
-	args = append(args, limit, offset)
+	args = append(args, limit+1, offset)
 
 	rows, err := r.pool.Query(ctx, query, args...)
 	if err != nil {
-		return nil, 0, err
+		return nil, err
 	}
 	defer rows.Close()
 
 	var expenses []*model.Expense
+	var values [][]string // Sort key values of each expense
 	for rows.Next() {
-		var expense model.Expense
-		var deletedAt sql.NullTime
-
-		err := rows.Scan(
-			&expense.ID,
-			&expense.UserID,
-			&expense.Amount,
-			&expense.Description,
-			&expense.Category,
-			&expense.ExpenseDate,
-			&expense.CreatedAt,
-			&expense.UpdatedAt,
-			&deletedAt,
-		)
+		var highlight string
+		keyValues := make([]string, len(keys))
+		extra := []interface{}{&highlight}
+		for i := range keyValues {
+			extra = append(extra, &keyValues[i])
+		}
+
+		expense, err := scanExpense(rows, extra...)
+		if err != nil {
+			return nil, err
+		}
+		expense.Highlight = highlight
+		expenses = append(expenses, expense)
+		values = append(values, keyValues)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	more := len(expenses) > limit
+	if more {
+		expenses = expenses[:limit]
+		values = values[:limit]
+	}
+	if backward {
+		slices.Reverse(expenses)
+		slices.Reverse(values)
+	}
+	page.Expenses = expenses
+
+	page.NextCursor, page.PrevCursor = pagination.PageCursors(cursor, filters.Sort, filters.Order, values, more, offset)
+	return page, nil
+}
+
+// streamBatchSize is how many expenses StreamByUserID reads per query
+const streamBatchSize = 500
+
+// StreamByUserID calls fn for every expense of a user matching the list filters,
+// in listing order, without pagination
+// Expenses are read in batches by keyset (like pages after a cursor), and fn only
+// sees a batch once it was read: a slow client downloading an export doesn't hold
+// a database connection, and any number of expenses can be exported
+func (r *PostgresExpenseRepository) StreamByUserID(ctx context.Context, userID string, filters *model.ListExpensesRequest, fn func(*model.Expense) error) error {
+	filterClause, filterArgs := expenseFilterClause(userID, filters)
+
+	var keys []pagination.SortKey
+	keys, filterArgs = expenseSortKeys(filters, filterArgs)
+	desc := filters.Order != model.SortAsc
+
+	columns := expenseColumns
+	for _, key := range keys {
+		columns += ", " + key.Expr + "::text"
+	}
+
+	var after []string // Sort key values of the last expense read
+	for {
+		whereClause := filterClause
+		args := append([]interface{}{}, filterArgs...)
+		if after != nil {
+			var condition string
+			condition, args = pagination.After(keys, after, desc, args)
+			whereClause += " AND " + condition
+		}
+
+		query := fmt.Sprintf(`
+			SELECT %s
+			FROM expenses
+			WHERE %s
+			ORDER BY %s
+			LIMIT $%d
+		`, columns, whereClause, pagination.OrderBy(keys, desc), len(args)+1)
+		args = append(args, streamBatchSize)
+
+		batch, last, err := r.queryExpenseBatch(ctx, query, len(keys), args...)
 		if err != nil {
-			return nil, 0, err
+			return err
 		}
 
-		if deletedAt.Valid {
-			expense.DeletedAt = &deletedAt.Time
+		for _, expense := range batch {
+			if err := fn(expense); err != nil {
+				return err
+			}
 		}
 
-		expenses = append(expenses, &expense)
+		if len(batch) < streamBatchSize {
+			return nil
+		}
+		after = last
 	}
+}
 
-	if err = rows.Err(); err != nil {
-		return nil, 0, err
+// queryExpenseBatch reads the expenses of a query selecting expenseColumns followed
+// by the sort key values, and returns the sort key values of the last one
+func (r *PostgresExpenseRepository) queryExpenseBatch(ctx context.Context, query string, keyCount int, args ...interface{}) ([]*model.Expense, []string, error) {
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	var expenses []*model.Expense
+	var last []string
+	for rows.Next() {
+		keyValues := make([]string, keyCount)
+		extra := make([]interface{}, keyCount)
+		for i := range keyValues {
+			extra[i] = &keyValues[i]
+		}
+
+		expense, err := scanExpense(rows, extra...)
+		if err != nil {
+			return nil, nil, err
+		}
+		expenses = append(expenses, expense)
+		last = keyValues
 	}
 
-	return expenses, total, nil
+	return expenses, last, rows.Err()
 }
 
-// Update updates an existing expense
+// Update updates an existing expense and replaces its tags in one transaction
 func (r *PostgresExpenseRepository) Update(ctx context.Context, expense *model.Expense) error {
-	// Update only non-nil fields
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	// Rollback is a no-op if the transaction was committed
+	defer tx.Rollback(ctx)
+
+	if err := updateExpense(ctx, tx, expense); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// Delete soft deletes an expense
+func (r *PostgresExpenseRepository) Delete(ctx context.Context, id, userID string) error {
+	return deleteExpense(ctx, r.pool, id, userID)
+}
+
+// ApplyChanges writes a batch of creates, updates and deletes in order, in one transaction
+// Returns the error of each change (nil if it was applied). With atomic, the first
+// failing change rolls back the whole transaction and the rest are not tried; otherwise
+// each change runs in a savepoint, so a failing one is rolled back on its own
+func (r *PostgresExpenseRepository) ApplyChanges(ctx context.Context, changes []*model.ExpenseChange, atomic bool) ([]error, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// Rollback is a no-op if the transaction was committed
+	defer tx.Rollback(ctx)
+
+	errs := make([]error, len(changes))
+	failed := make(map[string]bool) // Expenses with a failed change
+	for i, change := range changes {
+		if atomic {
+			if errs[i] = applyChange(ctx, tx, change); errs[i] != nil {
+				return errs, nil
+			}
+			continue
+		}
+
+		// Later changes of an expense were prepared from the failed one
+		if failed[change.Expense.ID] {
+			errs[i] = ErrEarlierChangeFailed
+			continue
+		}
+
+		// A failed statement aborts the transaction - unless it is rolled back to a savepoint
+		savepoint, err := tx.Begin(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if errs[i] = applyChange(ctx, savepoint, change); errs[i] != nil {
+			if err := savepoint.Rollback(ctx); err != nil {
+				return nil, err
+			}
+			failed[change.Expense.ID] = true
+			continue
+		}
+		if err := savepoint.Commit(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	return errs, tx.Commit(ctx)
+}
+
+// applyChange writes one change of a batch
+func applyChange(ctx context.Context, tx pgx.Tx, change *model.ExpenseChange) error {
+	switch change.Op {
+	case model.BatchOpCreate:
+		return insertExpense(ctx, tx, change.Expense)
+	case model.BatchOpUpdate:
+		return updateExpense(ctx, tx, change.Expense)
+	case model.BatchOpDelete:
+		return deleteExpense(ctx, tx, change.Expense.ID, change.Expense.UserID)
+	default:
+		return fmt.Errorf("unknown change %q", change.Op)
+	}
+}
+
+// updateExpense updates an expense and replaces its tags
+func updateExpense(ctx context.Context, tx pgx.Tx, expense *model.Expense) error {
 	query := `
 		UPDATE expenses
 		SET amount = $1,
-		    description = $2,
-		    category = $3,
-		    expense_date = $4,
-		    updated_at = $5
-		WHERE id = $6 AND user_id = $7 AND deleted_at IS NULL
+		    currency = $2,
+		    description = $3,
+		    category = $4,
+		    expense_date = $5,
+		    updated_at = $6
+		WHERE id = $7 AND user_id = $8 AND deleted_at IS NULL
 	`
 
-	result, err := r.pool.Exec(ctx, query,
+	result, err := tx.Exec(ctx, query,
 		expense.Amount,
+		expense.Currency,
 		expense.Description,
 		expense.Category,
 		expense.ExpenseDate,
@@ -212,14 +404,24 @@ func (r *PostgresExpenseRepository) Update(ctx context.Context, expense *model.E
 
 	// Check if any row was updated
 	if result.RowsAffected() == 0 {
-		return fmt.Errorf("expense not found or access denied")
+		return ErrExpenseNotFound
 	}
 
-	return nil
+	_, err = tx.Exec(ctx, `DELETE FROM expense_tags WHERE expense_id = $1`, expense.ID)
+	if err != nil {
+		return err
+	}
+
+	return insertExpenseTags(ctx, tx, expense)
 }
 
-// Delete soft deletes an expense
-func (r *PostgresExpenseRepository) Delete(ctx context.Context, id, userID string) error {
+// execer runs a statement on the pool or in a transaction
+type execer interface {
+	Exec(ctx context.Context, sql string, arguments ...interface{}) (pgconn.CommandTag, error)
+}
+
+// deleteExpense soft deletes an expense
+func deleteExpense(ctx context.Context, db execer, id, userID string) error {
 	query := `
 		UPDATE expenses
 		SET deleted_at = $1
@@ -227,75 +429,316 @@ func (r *PostgresExpenseRepository) Delete(ctx context.Context, id, userID strin
 	`
 
 	now := time.Now()
-	result, err := r.pool.Exec(ctx, query, now, id, userID)
+	result, err := db.Exec(ctx, query, now, id, userID)
 
 	if err != nil {
 		return err
 	}
 
 	if result.RowsAffected() == 0 {
-		return fmt.Errorf("expense not found or access denied")
+		return ErrExpenseNotFound
 	}
 
 	return nil
 }
 
-// GetTotalByCategory gets expense totals grouped by category
-func (r *PostgresExpenseRepository) GetTotalByCategory(ctx context.Context, userID string, startDate, endDate *string) ([]model.ExpenseSummaryItem, string, error) {
-	whereClause := "user_id = $1 AND deleted_at IS NULL"
-	args := []interface{}{userID}
-	argIndex := 2
+// GetTags counts how many of the user's (not deleted) expenses have each tag
+func (r *PostgresExpenseRepository) GetTags(ctx context.Context, userID string) ([]model.TagUsage, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT t.tag, COUNT(*)
+		FROM expense_tags t
+		JOIN expenses e ON e.id = t.expense_id
+		WHERE t.user_id = $1 AND e.deleted_at IS NULL
+		GROUP BY t.tag
+		ORDER BY COUNT(*) DESC, t.tag
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
 
-	if startDate != nil && *startDate != "" {
-		whereClause += fmt.Sprintf(" AND expense_date >= $%d", argIndex)
-		args = append(args, *startDate)
-		argIndex++
+	tags := []model.TagUsage{}
+	for rows.Next() {
+		var usage model.TagUsage
+		if err := rows.Scan(&usage.Tag, &usage.Count); err != nil {
+			return nil, err
+		}
+		tags = append(tags, usage)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
 	}
 
-	if endDate != nil && *endDate != "" {
-		whereClause += fmt.Sprintf(" AND expense_date <= $%d", argIndex)
-		args = append(args, *endDate)
-		argIndex++
+	return tags, nil
+}
+
+// FindFingerprints finds the fingerprints (see model.ExpenseFingerprint) of a
+// user's expenses between two dates (inclusive)
+func (r *PostgresExpenseRepository) FindFingerprints(ctx context.Context, userID string, startDate, endDate time.Time) (map[string][]string, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, amount, currency, description, expense_date
+		FROM expenses
+		WHERE user_id = $1 AND deleted_at IS NULL AND expense_date BETWEEN $2 AND $3
+		ORDER BY created_at
+	`, userID, startDate, endDate)
+	if err != nil {
+		return nil, err
 	}
+	defer rows.Close()
+
+	fingerprints := make(map[string][]string)
+	for rows.Next() {
+		var id, currency, description string
+		var amount money.Money
+		var expenseDate time.Time
+		if err := rows.Scan(&id, &amount, &currency, &description, &expenseDate); err != nil {
+			return nil, err
+		}
+		// Rounded like scanExpense, so the amount reads as when it was created
+		fingerprint := model.ExpenseFingerprint(expenseDate, amount.Round(money.CurrencyScale(currency)), description)
+		fingerprints[fingerprint] = append(fingerprints[fingerprint], id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return fingerprints, nil
+}
+
+// GetTotalByCategory gets the totals of the expenses matching the list filters
+// grouped by category, currency and date
+func (r *PostgresExpenseRepository) GetTotalByCategory(ctx context.Context, userID string, filters *model.ListExpensesRequest) ([]model.AmountGroup, error) {
+	whereClause, args := expenseFilterClause(userID, filters)
 
-	// Get totals by category
 	query := fmt.Sprintf(`
-		SELECT category, SUM(amount) as total, COUNT(*) as count
+		SELECT category, currency, expense_date, SUM(amount), COUNT(*)
 		FROM expenses
 		WHERE %s
-		GROUP BY category
-		ORDER BY total DESC
+		GROUP BY category, currency, expense_date
+	`, whereClause)
+
+	return r.queryAmountGroups(ctx, query, args...)
+}
+
+// HasNonLatin1Text reports whether a matching expense's description or category
+// has a character above U+00FF
+func (r *PostgresExpenseRepository) HasNonLatin1Text(ctx context.Context, userID string, filters *model.ListExpensesRequest) (bool, error) {
+	whereClause, args := expenseFilterClause(userID, filters)
+
+	query := fmt.Sprintf(`
+		SELECT EXISTS (
+			SELECT 1 FROM expenses
+			WHERE %s AND (description || category) ~ '[^\u0001-\u00ff]'
+		)
 	`, whereClause)
 
+	var found bool
+	err := r.pool.QueryRow(ctx, query, args...).Scan(&found)
+	return found, err
+}
+
+// GetAmountGroups gets the totals of the expenses matching the list filters
+// grouped by currency and date (ignores pagination)
+func (r *PostgresExpenseRepository) GetAmountGroups(ctx context.Context, userID string, filters *model.ListExpensesRequest) ([]model.AmountGroup, error) {
+	whereClause, args := expenseFilterClause(userID, filters)
+
+	query := fmt.Sprintf(`
+		SELECT '', currency, expense_date, SUM(amount), COUNT(*)
+		FROM expenses
+		WHERE %s
+		GROUP BY currency, expense_date
+	`, whereClause)
+
+	return r.queryAmountGroups(ctx, query, args...)
+}
+
+// queryAmountGroups runs a query selecting category, currency, date, sum and count
+func (r *PostgresExpenseRepository) queryAmountGroups(ctx context.Context, query string, args ...interface{}) ([]model.AmountGroup, error) {
 	rows, err := r.pool.Query(ctx, query, args...)
 	if err != nil {
-		return nil, "", err
+		return nil, err
 	}
 	defer rows.Close()
 
-	var items []model.ExpenseSummaryItem
-	var grandTotal float64
-
+	groups := []model.AmountGroup{}
 	for rows.Next() {
-		var item model.ExpenseSummaryItem
-		var total float64
+		var group model.AmountGroup
+		if err := rows.Scan(&group.Category, &group.Currency, &group.Date, &group.Total, &group.Count); err != nil {
+			return nil, err
+		}
+		groups = append(groups, group)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
 
-		err := rows.Scan(&item.Category, &total, &item.Count)
-		if err != nil {
-			return nil, "", err
+	return groups, nil
+}
+
+// expenseFilterClause builds the WHERE clause (and its arguments) for a user's
+// expenses matching the list filters
+func expenseFilterClause(userID string, filters *model.ListExpensesRequest) (string, []interface{}) {
+	// Build WHERE clause dynamically based on filters
+	whereClause := "user_id = $1 AND deleted_at IS NULL"
+	args := []interface{}{userID}
+	argIndex := 2
+
+	// Add category filter
+	if filters.Category != "" {
+		whereClause += fmt.Sprintf(" AND category = $%d", argIndex)
+		args = append(args, filters.Category)
+		argIndex++
+	}
+
+	// Add date range filters
+	if filters.StartDate != "" {
+		whereClause += fmt.Sprintf(" AND expense_date >= $%d", argIndex)
+		args = append(args, filters.StartDate)
+		argIndex++
+	}
+
+	if filters.EndDate != "" {
+		whereClause += fmt.Sprintf(" AND expense_date <= $%d", argIndex)
+		args = append(args, filters.EndDate)
+		argIndex++
+	}
+
+	// Add amount range filters (in each expense's own currency)
+	if filters.MinAmount != "" {
+		whereClause += fmt.Sprintf(" AND amount >= $%d", argIndex)
+		args = append(args, filters.MinAmount)
+		argIndex++
+	}
+
+	if filters.MaxAmount != "" {
+		whereClause += fmt.Sprintf(" AND amount <= $%d", argIndex)
+		args = append(args, filters.MaxAmount)
+		argIndex++
+	}
+
+	// Add full-text search of description, category and tags
+	if filters.Query != "" {
+		whereClause += fmt.Sprintf(" AND search_vector @@ websearch_to_tsquery('english', $%d)", argIndex)
+		args = append(args, filters.Query)
+		argIndex++
+	}
+
+	// Add tag filter - any of the tags, or all of them (tags are distinct)
+	if len(filters.Tags) > 0 {
+		tagQuery := fmt.Sprintf("SELECT expense_id FROM expense_tags WHERE user_id = $1 AND tag = ANY($%d)", argIndex)
+		args = append(args, filters.Tags)
+		argIndex++
+		if filters.TagMatch == model.TagMatchAll {
+			tagQuery += fmt.Sprintf(" GROUP BY expense_id HAVING COUNT(*) = $%d", argIndex)
+			args = append(args, len(filters.Tags))
 		}
+		whereClause += fmt.Sprintf(" AND id IN (%s)", tagQuery)
+	}
 
-		// Format total as string with 2 decimal places
-		item.Total = fmt.Sprintf("%.2f", total)
-		grandTotal += total
+	return whereClause, args
+}
+
+// ExpenseCursorTypes returns the types of the values of a cursor for an expense
+// sorting (see pagination.DecodeCursor)
+func ExpenseCursorTypes(sort string) []pagination.KeyType {
+	keys, _ := expenseSortKeys(&model.ListExpensesRequest{Sort: sort}, nil)
+	return pagination.Types(keys)
+}
 
-		items = append(items, item)
+// expenseSortKeys returns the keys expenses are sorted by (see model.ListExpensesRequest.Sort)
+// The last key is always the ID, so that the order is unique and a cursor points to one row
+// Sorting by relevance adds the search query to args
+func expenseSortKeys(filters *model.ListExpensesRequest, args []interface{}) ([]pagination.SortKey, []interface{}) {
+	id := pagination.SortKey{Expr: "id", Type: pagination.UUID}
+	createdAt := pagination.SortKey{Expr: "created_at", Type: pagination.Timestamp}
+
+	switch filters.Sort {
+	case model.SortAmount:
+		return []pagination.SortKey{{Expr: "amount", Type: pagination.Numeric}, id}, args
+	case model.SortCategory:
+		return []pagination.SortKey{{Expr: "category", Type: pagination.Text}, id}, args
+	case model.SortCreatedAt:
+		return []pagination.SortKey{createdAt, id}, args
+	case model.SortRelevance:
+		rank := pagination.SortKey{Expr: fmt.Sprintf("ts_rank(search_vector, websearch_to_tsquery('english', $%d))", len(args)+1), Type: pagination.Real}
+		return []pagination.SortKey{rank, {Expr: "expense_date", Type: pagination.Date}, createdAt, id}, append(args, filters.Query)
+	default:
+		return []pagination.SortKey{{Expr: "expense_date", Type: pagination.Date}, createdAt, id}, args
 	}
+}
 
-	if err = rows.Err(); err != nil {
-		return nil, "", err
+// scanExpense scans a row of expenseColumns, followed by any extra columns
+func scanExpense(row pgx.Row, extra ...interface{}) (*model.Expense, error) {
+	var expense model.Expense
+	var deletedAt sql.NullTime
+
+	dest := []interface{}{
+		&expense.ID,
+		&expense.UserID,
+		&expense.Amount,
+		&expense.Currency,
+		&expense.Description,
+		&expense.Category,
+		&expense.ExpenseDate,
+		&expense.CreatedAt,
+		&expense.UpdatedAt,
+		&deletedAt,
+		&expense.Tags,
+	}
+	err := row.Scan(append(dest, extra...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	if deletedAt.Valid {
+		expense.DeletedAt = &deletedAt.Time
+	}
+	// The column has three decimals - use the currency's
+	expense.Amount = expense.Amount.Round(money.CurrencyScale(expense.Currency))
+
+	return &expense, nil
+}
+
+// insertExpense inserts an expense and its tags
+func insertExpense(ctx context.Context, tx pgx.Tx, expense *model.Expense) error {
+	query := `
+		INSERT INTO expenses (id, user_id, amount, currency, description, category, expense_date, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+
+	_, err := tx.Exec(ctx, query,
+		expense.ID,
+		expense.UserID,
+		expense.Amount,
+		expense.Currency,
+		expense.Description,
+		expense.Category,
+		expense.ExpenseDate,
+		expense.CreatedAt,
+		expense.UpdatedAt,
+	)
+
+	if err != nil {
+		// The ID is taken - occurrences of recurring expenses have fixed IDs
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" { // unique_violation
+			return ErrExpenseExists
+		}
+		return err
 	}
 
-	grandTotalStr := fmt.Sprintf("%.2f", grandTotal)
-	return items, grandTotalStr, nil
+	return insertExpenseTags(ctx, tx, expense)
+}
+
+// insertExpenseTags inserts the tags of an expense
+func insertExpenseTags(ctx context.Context, tx pgx.Tx, expense *model.Expense) error {
+	if len(expense.Tags) == 0 {
+		return nil
+	}
+
+	_, err := tx.Exec(ctx, `
+		INSERT INTO expense_tags (expense_id, user_id, tag)
+		SELECT $1, $2, unnest($3::text[])
+	`, expense.ID, expense.UserID, expense.Tags)
+	return err
 }
@@ -0,0 +1,259 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"expense-tracker/expense-service/internal/model"
+	"expense-tracker/shared/money"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// recurringExpenseColumns is the column list scanRecurringExpense expects
+const recurringExpenseColumns = `id, user_id, user_email, amount, currency, description, category, frequency, day_of_month,
+	start_date, end_date, last_occurrence_date, next_occurrence_date, created_at, updated_at, deleted_at`
+
+// PostgresRecurringExpenseRepository implements RecurringExpenseRepository using PostgreSQL
+type PostgresRecurringExpenseRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresRecurringExpenseRepository creates a new PostgreSQL recurring expense repository
+func NewPostgresRecurringExpenseRepository(pool *pgxpool.Pool) RecurringExpenseRepository {
+	return &PostgresRecurringExpenseRepository{
+		pool: pool,
+	}
+}
+
+// Create inserts a new recurring expense
+func (r *PostgresRecurringExpenseRepository) Create(ctx context.Context, rule *model.RecurringExpense) error {
+	query := `
+		INSERT INTO recurring_expenses (id, user_id, user_email, amount, currency, description, category, frequency, day_of_month,
+			start_date, end_date, next_occurrence_date, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+	`
+
+	_, err := r.pool.Exec(ctx, query,
+		rule.ID,
+		rule.UserID,
+		rule.UserEmail,
+		rule.Amount,
+		rule.Currency,
+		rule.Description,
+		rule.Category,
+		rule.Frequency,
+		rule.DayOfMonth,
+		rule.StartDate,
+		rule.EndDate,
+		rule.NextOccurrence,
+		rule.CreatedAt,
+		rule.UpdatedAt,
+	)
+
+	return err
+}
+
+// FindByID finds a recurring expense by ID and user ID
+func (r *PostgresRecurringExpenseRepository) FindByID(ctx context.Context, id, userID string) (*model.RecurringExpense, error) {
+	query := `
+		SELECT ` + recurringExpenseColumns + `
+		FROM recurring_expenses
+		WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL
+	`
+
+	rule, err := scanRecurringExpense(r.pool.QueryRow(ctx, query, id, userID))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil // Recurring expense not found
+		}
+		return nil, err
+	}
+
+	return rule, nil
+}
+
+// FindByUserID finds all recurring expenses of a user
+func (r *PostgresRecurringExpenseRepository) FindByUserID(ctx context.Context, userID string) ([]*model.RecurringExpense, error) {
+	query := `
+		SELECT ` + recurringExpenseColumns + `
+		FROM recurring_expenses
+		WHERE user_id = $1 AND deleted_at IS NULL
+		ORDER BY created_at
+	`
+
+	return r.queryRecurringExpenses(ctx, query, userID)
+}
+
+// Update updates the rule's fields and next occurrence
+// The last occurrence is only ever written by Advance
+func (r *PostgresRecurringExpenseRepository) Update(ctx context.Context, rule *model.RecurringExpense) error {
+	query := `
+		UPDATE recurring_expenses
+		SET user_email = $1,
+		    amount = $2,
+		    currency = $3,
+		    description = $4,
+		    category = $5,
+		    frequency = $6,
+		    day_of_month = $7,
+		    start_date = $8,
+		    end_date = $9,
+		    next_occurrence_date = $10,
+		    updated_at = $11
+		WHERE id = $12 AND user_id = $13 AND deleted_at IS NULL
+	`
+
+	result, err := r.pool.Exec(ctx, query,
+		rule.UserEmail,
+		rule.Amount,
+		rule.Currency,
+		rule.Description,
+		rule.Category,
+		rule.Frequency,
+		rule.DayOfMonth,
+		rule.StartDate,
+		rule.EndDate,
+		rule.NextOccurrence,
+		rule.UpdatedAt,
+		rule.ID,
+		rule.UserID,
+	)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("recurring expense not found or access denied")
+	}
+
+	return nil
+}
+
+// Delete soft deletes a recurring expense
+func (r *PostgresRecurringExpenseRepository) Delete(ctx context.Context, id, userID string) error {
+	query := `
+		UPDATE recurring_expenses
+		SET deleted_at = $1
+		WHERE id = $2 AND user_id = $3 AND deleted_at IS NULL
+	`
+
+	result, err := r.pool.Exec(ctx, query, time.Now(), id, userID)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("recurring expense not found or access denied")
+	}
+
+	return nil
+}
+
+// FindDue finds rules with a due occurrence, most overdue first
+func (r *PostgresRecurringExpenseRepository) FindDue(ctx context.Context, onOrBefore time.Time, limit int) ([]*model.RecurringExpense, error) {
+	query := `
+		SELECT ` + recurringExpenseColumns + `
+		FROM recurring_expenses
+		WHERE deleted_at IS NULL AND next_occurrence_date <= $1
+		ORDER BY next_occurrence_date
+		LIMIT $2
+	`
+
+	return r.queryRecurringExpenses(ctx, query, onOrBefore, limit)
+}
+
+// Advance stores the new last and next occurrence
+// The WHERE on the previous next occurrence makes concurrent schedulers
+// (several replicas) and rule updates safe - only one of them wins
+func (r *PostgresRecurringExpenseRepository) Advance(ctx context.Context, rule *model.RecurringExpense, previousNext time.Time) (bool, error) {
+	query := `
+		UPDATE recurring_expenses
+		SET last_occurrence_date = $1,
+		    next_occurrence_date = $2
+		WHERE id = $3 AND next_occurrence_date = $4 AND deleted_at IS NULL
+	`
+
+	result, err := r.pool.Exec(ctx, query, rule.LastOccurrence, rule.NextOccurrence, rule.ID, previousNext)
+	if err != nil {
+		return false, err
+	}
+
+	return result.RowsAffected() > 0, nil
+}
+
+// queryRecurringExpenses runs a query selecting recurringExpenseColumns
+func (r *PostgresRecurringExpenseRepository) queryRecurringExpenses(ctx context.Context, query string, args ...interface{}) ([]*model.RecurringExpense, error) {
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rules := []*model.RecurringExpense{}
+	for rows.Next() {
+		rule, err := scanRecurringExpense(rows)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return rules, nil
+}
+
+// scanRecurringExpense scans a row of recurringExpenseColumns
+func scanRecurringExpense(row pgx.Row) (*model.RecurringExpense, error) {
+	var rule model.RecurringExpense
+	var dayOfMonth sql.NullInt16
+	var endDate, lastOccurrence, nextOccurrence, deletedAt sql.NullTime
+
+	err := row.Scan(
+		&rule.ID,
+		&rule.UserID,
+		&rule.UserEmail,
+		&rule.Amount,
+		&rule.Currency,
+		&rule.Description,
+		&rule.Category,
+		&rule.Frequency,
+		&dayOfMonth,
+		&rule.StartDate,
+		&endDate,
+		&lastOccurrence,
+		&nextOccurrence,
+		&rule.CreatedAt,
+		&rule.UpdatedAt,
+		&deletedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// The column has three decimals - use the currency's
+	rule.Amount = rule.Amount.Round(money.CurrencyScale(rule.Currency))
+
+	if dayOfMonth.Valid {
+		day := int(dayOfMonth.Int16)
+		rule.DayOfMonth = &day
+	}
+	if endDate.Valid {
+		rule.EndDate = &endDate.Time
+	}
+	if lastOccurrence.Valid {
+		rule.LastOccurrence = &lastOccurrence.Time
+	}
+	if nextOccurrence.Valid {
+		rule.NextOccurrence = &nextOccurrence.Time
+	}
+	if deletedAt.Valid {
+		rule.DeletedAt = &deletedAt.Time
+	}
+
+	return &rule, nil
+}
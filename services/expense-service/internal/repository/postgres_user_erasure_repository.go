@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"expense-tracker/expense-service/internal/model"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgresUserErasureRepository implements UserErasureRepository using PostgreSQL
+type PostgresUserErasureRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresUserErasureRepository creates a new PostgreSQL user erasure repository
+func NewPostgresUserErasureRepository(pool *pgxpool.Pool) UserErasureRepository {
+	return &PostgresUserErasureRepository{
+		pool: pool,
+	}
+}
+
+// Schedule records an erasure and soft-deletes the user's expenses and
+// recurring expenses in one transaction
+func (r *PostgresUserErasureRepository) Schedule(ctx context.Context, erasure *model.UserErasure) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	// Rollback is a no-op if the transaction was committed
+	defer tx.Rollback(ctx)
+
+	// ON CONFLICT DO NOTHING makes redelivered events harmless
+	_, err = tx.Exec(ctx, `
+		INSERT INTO user_erasures (user_id, requested_at, purge_after)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id) DO NOTHING
+	`, erasure.UserID, erasure.RequestedAt, erasure.PurgeAfter)
+	if err != nil {
+		return err
+	}
+
+	// Hide the expenses until they are erased
+	_, err = tx.Exec(ctx, `
+		UPDATE expenses
+		SET deleted_at = $1
+		WHERE user_id = $2 AND deleted_at IS NULL
+	`, erasure.RequestedAt, erasure.UserID)
+	if err != nil {
+		return err
+	}
+
+	// Stop creating expenses from their recurring expenses
+	_, err = tx.Exec(ctx, `
+		UPDATE recurring_expenses
+		SET deleted_at = $1
+		WHERE user_id = $2 AND deleted_at IS NULL
+	`, erasure.RequestedAt, erasure.UserID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// FindDue finds pending erasures, oldest first
+func (r *PostgresUserErasureRepository) FindDue(ctx context.Context, before time.Time, limit int) ([]*model.UserErasure, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT user_id, requested_at, purge_after, purged_at
+		FROM user_erasures
+		WHERE purged_at IS NULL AND purge_after <= $1
+		ORDER BY purge_after
+		LIMIT $2
+	`, before, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	erasures := []*model.UserErasure{}
+	for rows.Next() {
+		var erasure model.UserErasure
+		var purgedAt sql.NullTime
+
+		if err := rows.Scan(&erasure.UserID, &erasure.RequestedAt, &erasure.PurgeAfter, &purgedAt); err != nil {
+			return nil, err
+		}
+		if purgedAt.Valid {
+			erasure.PurgedAt = &purgedAt.Time
+		}
+
+		erasures = append(erasures, &erasure)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return erasures, nil
+}
+
+// Purge hard-deletes every expense, recurring expense, budget, category and setting of the user (including
+// ones created or soft-deleted earlier) and marks the erasure as done in one transaction
+func (r *PostgresUserErasureRepository) Purge(ctx context.Context, userID string) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	// Rollback is a no-op if the transaction was committed
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `DELETE FROM expenses WHERE user_id = $1`, userID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `DELETE FROM recurring_expenses WHERE user_id = $1`, userID)
+	if err != nil {
+		return err
+	}
+
+	// Their alerts are deleted by ON DELETE CASCADE
+	_, err = tx.Exec(ctx, `DELETE FROM budgets WHERE user_id = $1`, userID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `DELETE FROM categories WHERE user_id = $1`, userID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `DELETE FROM user_settings WHERE user_id = $1`, userID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE user_erasures
+		SET purged_at = $1
+		WHERE user_id = $2
+	`, time.Now(), userID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
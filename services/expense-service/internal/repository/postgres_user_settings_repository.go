@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"context"
+	"expense-tracker/expense-service/internal/model"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgresUserSettingsRepository implements UserSettingsRepository using PostgreSQL
+type PostgresUserSettingsRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresUserSettingsRepository creates a new PostgreSQL user settings repository
+func NewPostgresUserSettingsRepository(pool *pgxpool.Pool) UserSettingsRepository {
+	return &PostgresUserSettingsRepository{
+		pool: pool,
+	}
+}
+
+// FindByUserID finds a user's settings
+func (r *PostgresUserSettingsRepository) FindByUserID(ctx context.Context, userID string) (*model.UserSettings, error) {
+	query := `
+		SELECT user_id, base_currency, created_at, updated_at
+		FROM user_settings
+		WHERE user_id = $1
+	`
+
+	var settings model.UserSettings
+	err := r.pool.QueryRow(ctx, query, userID).Scan(
+		&settings.UserID,
+		&settings.BaseCurrency,
+		&settings.CreatedAt,
+		&settings.UpdatedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil // Defaults apply
+		}
+		return nil, err
+	}
+
+	return &settings, nil
+}
+
+// Upsert creates or replaces a user's settings (created_at is kept)
+func (r *PostgresUserSettingsRepository) Upsert(ctx context.Context, settings *model.UserSettings) error {
+	query := `
+		INSERT INTO user_settings (user_id, base_currency, created_at, updated_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (user_id) DO UPDATE
+		SET base_currency = EXCLUDED.base_currency,
+		    updated_at = EXCLUDED.updated_at
+	`
+
+	_, err := r.pool.Exec(ctx, query, settings.UserID, settings.BaseCurrency, settings.CreatedAt, settings.UpdatedAt)
+	return err
+}
@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"context"
+	"expense-tracker/expense-service/internal/model"
+	"time"
+)
+
+// RecurringExpenseRepository defines the interface for recurring expense rules
+type RecurringExpenseRepository interface {
+	// Create inserts a new recurring expense
+	Create(ctx context.Context, rule *model.RecurringExpense) error
+
+	// FindByID finds a recurring expense by ID and user ID
+	// Returns nil if it doesn't exist, is deleted or belongs to another user
+	FindByID(ctx context.Context, id, userID string) (*model.RecurringExpense, error)
+
+	// FindByUserID finds all recurring expenses of a user, oldest first
+	FindByUserID(ctx context.Context, userID string) ([]*model.RecurringExpense, error)
+
+	// Update updates the rule's fields and next occurrence
+	// Verifies ownership through userID
+	Update(ctx context.Context, rule *model.RecurringExpense) error
+
+	// Delete soft deletes a recurring expense (expenses already created are kept)
+	// Verifies ownership through userID
+	Delete(ctx context.Context, id, userID string) error
+
+	// FindDue finds rules whose next occurrence is on or before the given date
+	FindDue(ctx context.Context, onOrBefore time.Time, limit int) ([]*model.RecurringExpense, error)
+
+	// Advance stores the rule's new last and next occurrence if its next
+	// occurrence is still the given one (i.e. nobody advanced or rescheduled it meanwhile)
+	// Returns false if it wasn't
+	Advance(ctx context.Context, rule *model.RecurringExpense, previousNext time.Time) (bool, error)
+}
@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"context"
+	"expense-tracker/expense-service/internal/model"
+	"time"
+)
+
+// UserErasureRepository defines the interface for erasing deleted users' data
+type UserErasureRepository interface {
+	// Schedule records an erasure and hides the user's expenses and recurring expenses (soft delete)
+	// Scheduling a user twice keeps the first erasure (events can be delivered more than once)
+	Schedule(ctx context.Context, erasure *model.UserErasure) error
+
+	// FindDue finds pending erasures whose grace period ended before the given time
+	FindDue(ctx context.Context, before time.Time, limit int) ([]*model.UserErasure, error)
+
+	// Purge permanently deletes the user's expenses, recurring expenses, budgets and settings and marks the erasure as done
+	Purge(ctx context.Context, userID string) error
+}
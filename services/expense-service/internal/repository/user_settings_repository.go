@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"context"
+	"expense-tracker/expense-service/internal/model"
+)
+
+// UserSettingsRepository defines the interface for user settings
+type UserSettingsRepository interface {
+	// FindByUserID finds a user's settings
+	// Returns nil if the user never changed them (defaults apply)
+	FindByUserID(ctx context.Context, userID string) (*model.UserSettings, error)
+
+	// Upsert creates or replaces a user's settings
+	Upsert(ctx context.Context, settings *model.UserSettings) error
+}
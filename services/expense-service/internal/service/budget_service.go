@@ -0,0 +1,454 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"expense-tracker/expense-service/internal/model"
+	"expense-tracker/expense-service/internal/repository"
+	"expense-tracker/shared/money"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// BudgetService manages budgets and alerts users when their spending crosses
+// a threshold (budget.threshold_crossed events)
+// Every budget has its own currency (the user's base currency by default);
+// spending in other currencies is converted into it as of each expense's date
+type BudgetService struct {
+	repo              repository.BudgetRepository
+	currencyService   *CurrencyService
+	categoryService   *CategoryService
+	defaultThresholds []int           // Alert percentages for budgets without their own
+	eventPublisher    *EventPublisher // Optional - can be nil if not configured
+}
+
+// NewBudgetService creates a new budget service
+func NewBudgetService(repo repository.BudgetRepository, currencyService *CurrencyService, categoryService *CategoryService, defaultThresholds []int) *BudgetService {
+	return &BudgetService{
+		repo:              repo,
+		currencyService:   currencyService,
+		categoryService:   categoryService,
+		defaultThresholds: defaultThresholds,
+	}
+}
+
+// SetEventPublisher sets the event publisher (optional)
+// Without it no threshold alerts are sent
+func (s *BudgetService) SetEventPublisher(publisher *EventPublisher) {
+	s.eventPublisher = publisher
+}
+
+// CreateBudget creates a new budget for a user
+// An empty category creates an overall budget; the period defaults to monthly
+func (s *BudgetService) CreateBudget(ctx context.Context, userID string, req *model.CreateBudgetRequest) (*model.Budget, error) {
+	// Validate currency (default: the user's base currency)
+	currency, err := s.currencyService.ExpenseCurrency(ctx, userID, req.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	amount, err := money.ParseAmount(req.Amount, currency)
+	if err != nil {
+		return nil, err
+	}
+
+	thresholds, err := normalizeThresholds(req.AlertThresholds)
+	if err != nil {
+		return nil, err
+	}
+
+	period := req.Period
+	if period == "" {
+		period = model.BudgetPeriodMonthly
+	}
+
+	var startDate, endDate *time.Time
+	switch period {
+	case model.BudgetPeriodMonthly:
+		if req.StartDate != "" || req.EndDate != "" {
+			return nil, errors.New("start_date and end_date must only be set for custom budgets")
+		}
+	case model.BudgetPeriodCustom:
+		start, err := time.Parse("2006-01-02", req.StartDate)
+		if err != nil {
+			return nil, errors.New("start_date must be in YYYY-MM-DD format")
+		}
+		end, err := time.Parse("2006-01-02", req.EndDate)
+		if err != nil {
+			return nil, errors.New("end_date must be in YYYY-MM-DD format")
+		}
+		if end.Before(start) {
+			return nil, errors.New("end_date cannot be before start_date")
+		}
+		startDate, endDate = &start, &end
+	default:
+		return nil, errors.New("period must be monthly or custom")
+	}
+
+	// A category budget needs a category of the user's catalog
+	var category *string
+	if req.Category != "" {
+		name, err := s.categoryService.ResolveCategory(ctx, userID, req.Category)
+		if err != nil {
+			return nil, err
+		}
+		category = &name
+	}
+
+	budget := model.NewBudget(userID, category, amount, currency, period, startDate, endDate, thresholds)
+	if err := s.repo.Create(ctx, budget); err != nil {
+		return nil, err
+	}
+
+	return budget, nil
+}
+
+// GetBudget retrieves a single budget by ID
+// Verifies ownership (user can only access their own budgets)
+func (s *BudgetService) GetBudget(ctx context.Context, budgetID, userID string) (*model.Budget, error) {
+	budget, err := s.repo.FindByID(ctx, budgetID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if budget == nil {
+		return nil, errors.New("budget not found")
+	}
+
+	roundAmounts(budget)
+
+	return budget, nil
+}
+
+// ListBudgets retrieves all budgets of a user
+func (s *BudgetService) ListBudgets(ctx context.Context, userID string) (*model.ListBudgetsResponse, error) {
+	budgets, err := s.repo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	roundAmounts(budgets...)
+
+	return &model.ListBudgetsResponse{
+		Budgets: budgets,
+		Total:   len(budgets),
+	}, nil
+}
+
+// UpdateBudget updates a budget's amount, currency, custom period or alert thresholds
+func (s *BudgetService) UpdateBudget(ctx context.Context, budgetID, userID string, req *model.UpdateBudgetRequest) (*model.Budget, error) {
+	budget, err := s.repo.FindByID(ctx, budgetID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if budget == nil {
+		return nil, errors.New("budget not found")
+	}
+
+	if req.Currency != nil {
+		currency, err := s.currencyService.ExpenseCurrency(ctx, userID, *req.Currency)
+		if err != nil {
+			return nil, err
+		}
+		budget.Currency = currency
+	}
+	// The amount is checked again when only the currency changes
+	if req.Amount != nil || req.Currency != nil {
+		amountStr := budget.Amount.String()
+		if req.Amount != nil {
+			amountStr = *req.Amount
+		}
+		amount, err := money.ParseAmount(amountStr, budget.Currency)
+		if err != nil {
+			return nil, err
+		}
+		budget.Amount = amount
+	}
+
+	if req.StartDate != nil || req.EndDate != nil {
+		if budget.Period != model.BudgetPeriodCustom {
+			return nil, errors.New("start_date and end_date must only be set for custom budgets")
+		}
+		if req.StartDate != nil {
+			start, err := time.Parse("2006-01-02", *req.StartDate)
+			if err != nil {
+				return nil, errors.New("start_date must be in YYYY-MM-DD format")
+			}
+			budget.StartDate = &start
+		}
+		if req.EndDate != nil {
+			end, err := time.Parse("2006-01-02", *req.EndDate)
+			if err != nil {
+				return nil, errors.New("end_date must be in YYYY-MM-DD format")
+			}
+			budget.EndDate = &end
+		}
+		if budget.EndDate.Before(*budget.StartDate) {
+			return nil, errors.New("end_date cannot be before start_date")
+		}
+	}
+
+	if req.AlertThresholds != nil {
+		thresholds, err := normalizeThresholds(*req.AlertThresholds)
+		if err != nil {
+			return nil, err
+		}
+		budget.AlertThresholds = thresholds
+	}
+
+	budget.UpdatedAt = time.Now()
+
+	if err := s.repo.Update(ctx, budget); err != nil {
+		return nil, err
+	}
+
+	roundAmounts(budget)
+
+	return budget, nil
+}
+
+// DeleteBudget deletes a budget
+func (s *BudgetService) DeleteBudget(ctx context.Context, budgetID, userID string) error {
+	budget, err := s.repo.FindByID(ctx, budgetID, userID)
+	if err != nil {
+		return err
+	}
+
+	if budget == nil {
+		return errors.New("budget not found")
+	}
+
+	return s.repo.Delete(ctx, budgetID, userID)
+}
+
+// GetBudgetStatus shows how much of each budget was spent in the period containing date
+// date is YYYY-MM-DD (default: today); custom budgets whose period doesn't contain it are left out
+func (s *BudgetService) GetBudgetStatus(ctx context.Context, userID, date string) (*model.BudgetStatusResponse, error) {
+	day := today()
+	if date != "" {
+		parsed, err := time.Parse("2006-01-02", date)
+		if err != nil {
+			return nil, errors.New("date must be in YYYY-MM-DD format")
+		}
+		day = parsed
+	}
+
+	budgets, err := s.repo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := []model.BudgetStatus{}
+	for _, budget := range budgets {
+		start, end, ok := budget.PeriodContaining(day)
+		if !ok {
+			continue
+		}
+
+		status, err := s.status(ctx, budget, start, end)
+		if err != nil {
+			return nil, err
+		}
+		statuses = append(statuses, *status)
+	}
+
+	return &model.BudgetStatusResponse{
+		Date:    day,
+		Budgets: statuses,
+	}, nil
+}
+
+// CheckThresholds sends a budget.threshold_crossed alert for every budget the
+// expenses count towards whose spending has now crossed one of its thresholds
+// Called after expenses were created or updated. Every budget period is checked
+// once, however many of the expenses fall in it. Each threshold is alerted once
+// per budget period; when several are crossed at once, only the highest is sent.
+// Failures are only logged - they must not fail saving the expenses
+func (s *BudgetService) CheckThresholds(ctx context.Context, userID, userEmail string, expenses ...*model.Expense) {
+	s.checkThresholds(ctx, userID, userEmail, expenses, time.Time{})
+}
+
+// CheckCurrentThresholds is CheckThresholds for imported expenses: budget periods
+// that ended before today are left out, so importing an old statement doesn't
+// alert about months that are over
+func (s *BudgetService) CheckCurrentThresholds(ctx context.Context, userID, userEmail string, expenses []*model.Expense) {
+	s.checkThresholds(ctx, userID, userEmail, expenses, today())
+}
+
+// checkThresholds checks the budget periods the expenses fall in that end on or after from
+func (s *BudgetService) checkThresholds(ctx context.Context, userID, userEmail string, expenses []*model.Expense, from time.Time) {
+	if s.eventPublisher == nil || len(expenses) == 0 {
+		return
+	}
+
+	budgets, err := s.repo.FindByUserID(ctx, userID)
+	if err != nil {
+		log.Printf("Warning: failed to check budgets of user %s: %v", userID, err)
+		return
+	}
+
+	// The budget periods to check, each with the last expense that falls in it
+	type budgetPeriod struct {
+		key        string // Budget ID and period start
+		budget     *model.Budget
+		start, end time.Time
+	}
+	var periods []budgetPeriod
+	last := make(map[string]*model.Expense)
+	for _, expense := range expenses {
+		for _, budget := range budgets {
+			if budget.Category != nil && !strings.EqualFold(*budget.Category, expense.Category) {
+				continue
+			}
+			start, end, ok := budget.PeriodContaining(expense.ExpenseDate)
+			if !ok || end.Before(from) {
+				continue
+			}
+			key := budget.ID + " " + start.Format("2006-01-02")
+			if _, seen := last[key]; !seen {
+				periods = append(periods, budgetPeriod{key: key, budget: budget, start: start, end: end})
+			}
+			last[key] = expense
+		}
+	}
+
+	for _, period := range periods {
+		budget, start, end := period.budget, period.start, period.end
+		status, err := s.status(ctx, budget, start, end)
+		if err != nil {
+			// Usually a missing exchange rate - no alert can be sent until it is loaded
+			log.Printf("ERROR: budget %s not checked, its alerts are not sent: %v", budget.ID, err)
+			continue
+		}
+
+		// Record every crossed threshold, alert about the highest new one
+		crossed := 0
+		for _, threshold := range s.thresholdsFor(budget) {
+			if status.PercentUsed < float64(threshold) {
+				break
+			}
+			recorded, err := s.repo.RecordAlert(ctx, budget.ID, start, threshold)
+			if err != nil {
+				log.Printf("Warning: failed to record alert for budget %s: %v", budget.ID, err)
+				break
+			}
+			if recorded {
+				crossed = threshold
+			}
+		}
+
+		if crossed > 0 {
+			s.publishThresholdCrossed(ctx, userID, userEmail, last[period.key], status, crossed)
+		}
+	}
+}
+
+// roundAmounts gives budget amounts the decimal places of their currency
+// (the column has three)
+func roundAmounts(budgets ...*model.Budget) {
+	for _, budget := range budgets {
+		budget.Amount = budget.Amount.Round(money.CurrencyScale(budget.Currency))
+	}
+}
+
+// status computes how much of the budget was spent from start to end,
+// in the budget's currency
+func (s *BudgetService) status(ctx context.Context, budget *model.Budget, start, end time.Time) (*model.BudgetStatus, error) {
+	groups, err := s.repo.GetSpent(ctx, budget.UserID, budget.Category, start, end)
+	if err != nil {
+		return nil, err
+	}
+	conv := s.currencyService.newConverter(budget.Currency)
+	converted, err := conv.convertGroups(ctx, groups)
+	if err != nil {
+		return nil, err
+	}
+
+	spent := conv.zero()
+	for _, value := range converted {
+		spent = spent.Add(value)
+	}
+
+	limit := budget.Amount.Round(money.CurrencyScale(conv.to))
+	percentUsed := 0.0
+	if limit.Sign() > 0 {
+		percentUsed = math.Round(spent.Float64()/limit.Float64()*1000) / 10 // One decimal place
+	}
+
+	return &model.BudgetStatus{
+		BudgetID:    budget.ID,
+		Category:    budget.Category,
+		Period:      budget.Period,
+		Currency:    conv.to,
+		PeriodStart: start,
+		PeriodEnd:   end,
+		Limit:       limit,
+		Spent:       spent,
+		Remaining:   limit.Sub(spent),
+		PercentUsed: percentUsed,
+		Exceeded:    spent.Cmp(limit) > 0,
+	}, nil
+}
+
+// thresholdsFor returns the budget's alert thresholds, lowest first
+func (s *BudgetService) thresholdsFor(budget *model.Budget) []int {
+	if len(budget.AlertThresholds) > 0 {
+		return budget.AlertThresholds
+	}
+	return s.defaultThresholds
+}
+
+// publishThresholdCrossed publishes a budget.threshold_crossed event
+func (s *BudgetService) publishThresholdCrossed(ctx context.Context, userID, userEmail string, expense *model.Expense, status *model.BudgetStatus, threshold int) {
+	category := ""
+	if status.Category != nil {
+		category = *status.Category
+	}
+
+	event := &Event{
+		EventType: "budget.threshold_crossed",
+		UserID:    userID,
+		UserEmail: userEmail,
+		Timestamp: time.Now(),
+		Data: map[string]interface{}{
+			"budget_id":    status.BudgetID,
+			"category":     category, // Empty for an overall budget
+			"threshold":    threshold,
+			"limit":        status.Limit.String(),
+			"currency":     status.Currency,
+			"spent":        status.Spent.String(),
+			"percent_used": status.PercentUsed,
+			"period_start": status.PeriodStart.Format("2006-01-02"),
+			"period_end":   status.PeriodEnd.Format("2006-01-02"),
+			"expense_id":   expense.ID,
+		},
+	}
+	log.Printf("Publishing budget.threshold_crossed event for budget %s (%d%%, user: %s)", status.BudgetID, threshold, userID)
+	s.eventPublisher.PublishEventAsync(ctx, event)
+}
+
+// normalizeThresholds validates alert thresholds and sorts them, dropping duplicates
+func normalizeThresholds(thresholds []int) ([]int, error) {
+	if len(thresholds) == 0 {
+		return nil, nil
+	}
+
+	normalized := make([]int, 0, len(thresholds))
+	seen := make(map[int]bool)
+	for _, threshold := range thresholds {
+		if threshold < 1 || threshold > 1000 {
+			return nil, errors.New("alert_thresholds must be percentages between 1 and 1000")
+		}
+		if !seen[threshold] {
+			seen[threshold] = true
+			normalized = append(normalized, threshold)
+		}
+	}
+	sort.Ints(normalized)
+
+	return normalized, nil
+}
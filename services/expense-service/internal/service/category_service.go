@@ -0,0 +1,307 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"expense-tracker/expense-service/internal/model"
+	"expense-tracker/expense-service/internal/repository"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Category field limits (the columns' lengths)
+const (
+	maxCategoryNameLength = 50
+	maxCategoryIconLength = 50
+)
+
+// categoryColorPattern matches hex colors like #FF7043
+var categoryColorPattern = regexp.MustCompile(`^#[0-9A-Fa-f]{6}$`)
+
+// CategoryService manages users' category catalogs
+// Every user starts with the default categories (plus the ones their expenses already have);
+// expenses, recurring expenses and budgets must use a category of the catalog
+type CategoryService struct {
+	repo repository.CategoryRepository
+}
+
+// NewCategoryService creates a new category service
+func NewCategoryService(repo repository.CategoryRepository) *CategoryService {
+	return &CategoryService{
+		repo: repo,
+	}
+}
+
+// ListCategories retrieves all categories of a user, seeding the catalog on first use
+func (s *CategoryService) ListCategories(ctx context.Context, userID string) (*model.ListCategoriesResponse, error) {
+	categories, err := s.repo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(categories) == 0 {
+		if err := s.repo.CreateDefaults(ctx, userID, model.DefaultCategories); err != nil {
+			return nil, err
+		}
+		categories, err = s.repo.FindByUserID(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &model.ListCategoriesResponse{
+		Categories: categories,
+		Total:      len(categories),
+	}, nil
+}
+
+// CreateCategory adds a category to a user's catalog
+func (s *CategoryService) CreateCategory(ctx context.Context, userID string, req *model.CreateCategoryRequest) (*model.Category, error) {
+	// Seed the defaults first, so the user's first own category doesn't replace them
+	if err := s.repo.CreateDefaults(ctx, userID, model.DefaultCategories); err != nil {
+		return nil, err
+	}
+
+	name, err := normalizeCategoryName(req.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	color, err := normalizeCategoryColor(req.Color)
+	if err != nil {
+		return nil, err
+	}
+
+	icon, err := normalizeCategoryIcon(req.Icon)
+	if err != nil {
+		return nil, err
+	}
+
+	category := model.NewCategory(userID, name, color, icon, nil)
+	if req.ParentID != "" {
+		if err := s.setParent(ctx, category, req.ParentID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.repo.Create(ctx, category); err != nil {
+		return nil, err
+	}
+
+	return category, nil
+}
+
+// UpdateCategory changes a category
+// Renaming it renames the category of all expenses, recurring expenses and budgets that have it
+func (s *CategoryService) UpdateCategory(ctx context.Context, categoryID, userID string, req *model.UpdateCategoryRequest) (*model.Category, error) {
+	category, err := s.repo.FindByID(ctx, categoryID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if category == nil {
+		return nil, errors.New("category not found")
+	}
+
+	// Update fields if provided
+	if req.Name != nil {
+		name, err := normalizeCategoryName(*req.Name)
+		if err != nil {
+			return nil, err
+		}
+		category.Name = name
+	}
+
+	if req.Color != nil {
+		color, err := normalizeCategoryColor(*req.Color)
+		if err != nil {
+			return nil, err
+		}
+		category.Color = color
+	}
+
+	if req.Icon != nil {
+		icon, err := normalizeCategoryIcon(*req.Icon)
+		if err != nil {
+			return nil, err
+		}
+		category.Icon = icon
+	}
+
+	if req.ParentID != nil {
+		if *req.ParentID == "" {
+			category.ParentID = nil
+		} else if err := s.setParent(ctx, category, *req.ParentID); err != nil {
+			return nil, err
+		}
+	}
+
+	category.UpdatedAt = time.Now()
+
+	if err := s.repo.Update(ctx, category, req.Name != nil); err != nil {
+		return nil, err
+	}
+
+	return category, nil
+}
+
+// MergeCategory merges a category into another one: its expenses, recurring expenses,
+// budgets and subcategories move to the target, then it is deleted
+func (s *CategoryService) MergeCategory(ctx context.Context, categoryID, userID string, req *model.MergeCategoryRequest) (*model.MergeCategoryResponse, error) {
+	if req.TargetID == "" {
+		return nil, errors.New("target_id is required")
+	}
+	if req.TargetID == categoryID {
+		return nil, errors.New("category cannot be merged into itself")
+	}
+
+	source, err := s.repo.FindByID(ctx, categoryID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if source == nil {
+		return nil, errors.New("category not found")
+	}
+
+	target, err := s.repo.FindByID(ctx, req.TargetID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if target == nil {
+		return nil, errors.New("target category not found")
+	}
+	if target.ParentID != nil && *target.ParentID == source.ID {
+		return nil, errors.New("category cannot be merged into its own subcategory")
+	}
+
+	expensesUpdated, err := s.repo.Merge(ctx, source, target)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.MergeCategoryResponse{
+		Category:        target,
+		ExpensesUpdated: expensesUpdated,
+	}, nil
+}
+
+// DeleteCategory deletes a category nothing uses; its subcategories become top-level
+// Categories in use have to be merged into another one instead
+func (s *CategoryService) DeleteCategory(ctx context.Context, categoryID, userID string) error {
+	category, err := s.repo.FindByID(ctx, categoryID, userID)
+	if err != nil {
+		return err
+	}
+
+	if category == nil {
+		return errors.New("category not found")
+	}
+
+	usage, err := s.repo.CountUsage(ctx, userID, category.Name)
+	if err != nil {
+		return err
+	}
+	if usage > 0 {
+		return errors.New("category cannot be deleted while expenses, recurring expenses or budgets use it - merge it into another category instead")
+	}
+
+	return s.repo.Delete(ctx, categoryID, userID)
+}
+
+// ResolveCategory checks that a category is in the user's catalog and returns
+// its name as it is spelled there ("food" -> "Food")
+func (s *CategoryService) ResolveCategory(ctx context.Context, userID, name string) (string, error) {
+	name = strings.TrimSpace(name)
+
+	category, err := s.repo.FindByName(ctx, userID, name)
+	if err != nil {
+		return "", err
+	}
+
+	// The catalog may not be seeded yet
+	if category == nil {
+		if err := s.repo.CreateDefaults(ctx, userID, model.DefaultCategories); err != nil {
+			return "", err
+		}
+		category, err = s.repo.FindByName(ctx, userID, name)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	if category == nil {
+		return "", invalid(fmt.Errorf("category %s must be one of your categories (GET /expenses/categories)", name))
+	}
+
+	return category.Name, nil
+}
+
+// setParent makes category a subcategory of the category with parentID
+// Only top-level categories can be parents, and categories with subcategories can't get a parent
+func (s *CategoryService) setParent(ctx context.Context, category *model.Category, parentID string) error {
+	if parentID == category.ID {
+		return errors.New("category cannot be its own parent")
+	}
+
+	parent, err := s.repo.FindByID(ctx, parentID, category.UserID)
+	if err != nil {
+		return err
+	}
+	if parent == nil {
+		return errors.New("parent_id must be one of your categories")
+	}
+	if parent.ParentID != nil {
+		return errors.New("parent_id must be a top-level category")
+	}
+
+	hasSubcategories, err := s.repo.HasSubcategories(ctx, category.ID)
+	if err != nil {
+		return err
+	}
+	if hasSubcategories {
+		return errors.New("category with subcategories cannot have a parent")
+	}
+
+	category.ParentID = &parent.ID
+	return nil
+}
+
+// normalizeCategoryName trims and checks a category name
+func normalizeCategoryName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", errors.New("name is required")
+	}
+	if utf8.RuneCountInString(name) > maxCategoryNameLength {
+		return "", fmt.Errorf("name must be at most %d characters", maxCategoryNameLength)
+	}
+	return name, nil
+}
+
+// normalizeCategoryColor checks a color - empty means none
+func normalizeCategoryColor(color string) (*string, error) {
+	color = strings.TrimSpace(color)
+	if color == "" {
+		return nil, nil
+	}
+	if !categoryColorPattern.MatchString(color) {
+		return nil, errors.New("color must be a hex color like #FF7043")
+	}
+	color = strings.ToUpper(color)
+	return &color, nil
+}
+
+// normalizeCategoryIcon trims and checks an icon name - empty means none
+func normalizeCategoryIcon(icon string) (*string, error) {
+	icon = strings.TrimSpace(icon)
+	if icon == "" {
+		return nil, nil
+	}
+	if utf8.RuneCountInString(icon) > maxCategoryIconLength {
+		return nil, fmt.Errorf("icon must be at most %d characters", maxCategoryIconLength)
+	}
+	return &icon, nil
+}
@@ -0,0 +1,237 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"expense-tracker/expense-service/internal/model"
+	"expense-tracker/expense-service/internal/repository"
+	"expense-tracker/shared/money"
+	"time"
+)
+
+// CurrencyService manages users' base currencies and converts amounts between currencies
+type CurrencyService struct {
+	settingsRepo    repository.UserSettingsRepository
+	rateRepo        repository.ExchangeRateRepository
+	rateProvider    ExchangeRateProvider
+	defaultCurrency string // Base currency of users who never set one
+}
+
+// NewCurrencyService creates a new currency service
+// Rates come from the database until another provider is set
+func NewCurrencyService(settingsRepo repository.UserSettingsRepository, rateRepo repository.ExchangeRateRepository, defaultCurrency string) *CurrencyService {
+	return &CurrencyService{
+		settingsRepo:    settingsRepo,
+		rateRepo:        rateRepo,
+		rateProvider:    NewDatabaseExchangeRateProvider(rateRepo),
+		defaultCurrency: defaultCurrency,
+	}
+}
+
+// SetExchangeRateProvider replaces where exchange rates come from (optional)
+func (s *CurrencyService) SetExchangeRateProvider(provider ExchangeRateProvider) {
+	s.rateProvider = provider
+}
+
+// GetSettings retrieves a user's settings (the defaults if they never changed them)
+func (s *CurrencyService) GetSettings(ctx context.Context, userID string) (*model.UserSettings, error) {
+	settings, err := s.settingsRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if settings == nil {
+		return &model.UserSettings{
+			UserID:       userID,
+			BaseCurrency: s.defaultCurrency,
+		}, nil
+	}
+
+	return settings, nil
+}
+
+// UpdateSettings changes a user's base currency
+// Existing expenses keep their currency; totals are converted into the new one
+func (s *CurrencyService) UpdateSettings(ctx context.Context, userID string, req *model.UpdateUserSettingsRequest) (*model.UserSettings, error) {
+	baseCurrency, ok := money.NormalizeCurrency(req.BaseCurrency)
+	if !ok {
+		return nil, errors.New("base_currency must be an ISO 4217 currency code")
+	}
+
+	settings := model.NewUserSettings(userID, baseCurrency)
+	existing, err := s.settingsRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		settings.CreatedAt = existing.CreatedAt
+	}
+
+	if err := s.settingsRepo.Upsert(ctx, settings); err != nil {
+		return nil, err
+	}
+
+	return settings, nil
+}
+
+// BaseCurrency returns the currency a user's totals are converted into
+func (s *CurrencyService) BaseCurrency(ctx context.Context, userID string) (string, error) {
+	settings, err := s.GetSettings(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	return settings.BaseCurrency, nil
+}
+
+// ExpenseCurrency validates the currency of an expense, recurring expense or
+// budget, when it is created or changed. An empty code means the user's base currency
+func (s *CurrencyService) ExpenseCurrency(ctx context.Context, userID, code string) (string, error) {
+	if code == "" {
+		return s.BaseCurrency(ctx, userID)
+	}
+
+	currency, ok := money.NormalizeCurrency(code)
+	if !ok {
+		return "", invalid(errors.New("currency must be an ISO 4217 currency code"))
+	}
+	return currency, nil
+}
+
+// SetExchangeRates stores exchange rates (admin only)
+// Returns how many rates were stored
+func (s *CurrencyService) SetExchangeRates(ctx context.Context, req *model.SetExchangeRatesRequest) (int, error) {
+	if len(req.Rates) == 0 {
+		return 0, errors.New("rates are required")
+	}
+
+	rates := make([]model.ExchangeRate, 0, len(req.Rates))
+	for _, r := range req.Rates {
+		base, ok := money.NormalizeCurrency(r.BaseCurrency)
+		if !ok {
+			return 0, errors.New("base_currency must be an ISO 4217 currency code")
+		}
+		quote, ok := money.NormalizeCurrency(r.QuoteCurrency)
+		if !ok {
+			return 0, errors.New("quote_currency must be an ISO 4217 currency code")
+		}
+		if base == quote {
+			return 0, errors.New("base_currency and quote_currency must differ")
+		}
+		date, err := time.Parse("2006-01-02", r.Date)
+		if err != nil {
+			return 0, errors.New("date must be in YYYY-MM-DD format")
+		}
+		if r.Rate.Equal(money.Rate{}) {
+			return 0, errors.New("rate must be a positive number")
+		}
+
+		rates = append(rates, model.ExchangeRate{
+			BaseCurrency:  base,
+			QuoteCurrency: quote,
+			Date:          date,
+			Rate:          r.Rate,
+		})
+	}
+
+	if err := s.rateRepo.Save(ctx, rates); err != nil {
+		return 0, err
+	}
+
+	return len(rates), nil
+}
+
+// converter converts amounts into one currency for the duration of a request
+// It caches the rates it looked up, since many amounts share a currency and date.
+// Rates for many amounts are loaded at once (see load), not one query per amount
+type converter struct {
+	provider ExchangeRateProvider
+	to       string
+	rates    map[string]money.Rate // "<currency> <date>" -> rate
+}
+
+// newConverter creates a converter into the given currency
+func (s *CurrencyService) newConverter(to string) *converter {
+	return &converter{
+		provider: s.rateProvider,
+		to:       to,
+		rates:    make(map[string]money.Rate),
+	}
+}
+
+// rateKey is the cache key of a currency's rate on a date
+func rateKey(currency string, date time.Time) string {
+	return currency + " " + date.Format("2006-01-02")
+}
+
+// load looks up the rates of the given currencies and dates that aren't cached yet,
+// with a single call to the provider
+func (c *converter) load(ctx context.Context, requests []RateRequest) error {
+	missing := []RateRequest{}
+	queued := make(map[string]bool)
+	for _, req := range requests {
+		key := rateKey(req.Currency, req.Date)
+		if req.Currency == c.to || queued[key] {
+			continue
+		}
+		if _, ok := c.rates[key]; ok {
+			continue
+		}
+		queued[key] = true
+		missing = append(missing, req)
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	rates, err := c.provider.Rates(ctx, c.to, missing)
+	if err != nil {
+		return err
+	}
+	for i, req := range missing {
+		c.rates[rateKey(req.Currency, req.Date)] = rates[i]
+	}
+	return nil
+}
+
+// convert converts an amount in currency `from` with the rate of the given date
+// The result has the decimal places of the target currency
+func (c *converter) convert(ctx context.Context, amount money.Money, from string, date time.Time) (money.Money, error) {
+	scale := money.CurrencyScale(c.to)
+	if from == c.to {
+		return amount.Round(scale), nil
+	}
+
+	if err := c.load(ctx, []RateRequest{{Currency: from, Date: date}}); err != nil {
+		return money.Money{}, err
+	}
+	rate := c.rates[rateKey(from, date)]
+
+	return amount.Convert(rate, scale)
+}
+
+// convertGroups converts the totals of amount groups, each with the rate of its date
+// The result is in the same order as groups
+func (c *converter) convertGroups(ctx context.Context, groups []model.AmountGroup) ([]money.Money, error) {
+	requests := make([]RateRequest, len(groups))
+	for i, group := range groups {
+		requests[i] = RateRequest{Currency: group.Currency, Date: group.Date}
+	}
+	if err := c.load(ctx, requests); err != nil {
+		return nil, err
+	}
+
+	converted := make([]money.Money, len(groups))
+	for i, group := range groups {
+		value, err := c.convert(ctx, group.Total, group.Currency, group.Date)
+		if err != nil {
+			return nil, err
+		}
+		converted[i] = value
+	}
+	return converted, nil
+}
+
+// zero returns a zero amount with the decimal places of the target currency
+func (c *converter) zero() money.Money {
+	return money.Money{}.Round(money.CurrencyScale(c.to))
+}
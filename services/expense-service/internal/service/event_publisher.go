@@ -3,9 +3,9 @@ package service
 import (
 	"context"
 	"encoding/json"
+	"expense-tracker/shared/events"
 	"fmt"
 	"log"
-	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	awsconfig "github.com/aws/aws-sdk-go-v2/config"
@@ -40,13 +40,8 @@ func NewEventPublisher(region, accessKeyID, secretKey, topicARN string) (*EventP
 }
 
 // Event represents an event to be published
-type Event struct {
-	EventType string                 `json:"event_type"`
-	UserID    string                 `json:"user_id"`
-	UserEmail string                 `json:"user_email"`
-	Timestamp time.Time              `json:"timestamp"`
-	Data      map[string]interface{} `json:"data"`
-}
+// It is the shared format, also read from the user events queue (see events.SQSConsumer)
+type Event = events.Event
 
 // PublishEvent publishes an event to the SNS topic
 // This is non-blocking - errors are logged but don't affect the main operation
@@ -0,0 +1,141 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"expense-tracker/expense-service/internal/model"
+	"expense-tracker/expense-service/internal/repository"
+	"expense-tracker/shared/money"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+)
+
+// ExchangeRateFetcher downloads the latest exchange rates from a rates API and
+// stores them, so conversions don't depend on an admin loading rates by hand
+// The API must answer like Frankfurter (https://api.frankfurter.app/latest?from=USD):
+//
+//	{"base": "USD", "date": "2024-01-15", "rates": {"EUR": 0.92, "GBP": 0.79}}
+type ExchangeRateFetcher struct {
+	rateRepo   repository.ExchangeRateRepository
+	url        string
+	interval   time.Duration
+	httpClient *http.Client
+}
+
+// ratesResponse is the body returned by the rates API
+type ratesResponse struct {
+	Base  string                `json:"base"`
+	Date  string                `json:"date"`
+	Rates map[string]money.Rate `json:"rates"`
+}
+
+// NewExchangeRateFetcher creates a fetcher that downloads rates from url every interval
+func NewExchangeRateFetcher(rateRepo repository.ExchangeRateRepository, url string, interval time.Duration) *ExchangeRateFetcher {
+	return &ExchangeRateFetcher{
+		rateRepo: rateRepo,
+		url:      url,
+		interval: interval,
+		httpClient: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+// Run fetches rates periodically until ctx is cancelled
+// Start it in a goroutine - it also fetches once right away
+func (f *ExchangeRateFetcher) Run(ctx context.Context) {
+	f.fetchAndLog(ctx)
+
+	ticker := time.NewTicker(f.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			f.fetchAndLog(ctx)
+		}
+	}
+}
+
+// fetchAndLog fetches rates once and logs the outcome
+func (f *ExchangeRateFetcher) fetchAndLog(ctx context.Context) {
+	count, err := f.Fetch(ctx)
+	if err != nil {
+		// Conversions keep using older rates, and fail once there are none
+		log.Printf("ERROR: failed to fetch exchange rates from %s: %v (will retry)", f.url, err)
+		return
+	}
+	log.Printf("Stored %d exchange rates from %s", count, f.url)
+}
+
+// Fetch downloads the latest rates and stores them
+// Returns how many rates were stored; currencies that aren't ISO 4217 codes are skipped
+func (f *ExchangeRateFetcher) Fetch(ctx context.Context) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, f.url, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := f.httpClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return 0, fmt.Errorf("rates API returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var body ratesResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return 0, fmt.Errorf("failed to parse rates: %w", err)
+	}
+
+	rates, err := parseFetchedRates(&body)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := f.rateRepo.Save(ctx, rates); err != nil {
+		return 0, err
+	}
+	return len(rates), nil
+}
+
+// parseFetchedRates turns a rates API response into exchange rates to store
+func parseFetchedRates(body *ratesResponse) ([]model.ExchangeRate, error) {
+	base, ok := money.NormalizeCurrency(body.Base)
+	if !ok {
+		return nil, fmt.Errorf("rates API returned an unknown base currency %q", body.Base)
+	}
+	date, err := time.Parse("2006-01-02", body.Date)
+	if err != nil {
+		return nil, fmt.Errorf("rates API returned an invalid date %q", body.Date)
+	}
+
+	rates := make([]model.ExchangeRate, 0, len(body.Rates))
+	for code, rate := range body.Rates {
+		quote, ok := money.NormalizeCurrency(code)
+		if !ok || quote == base {
+			continue
+		}
+		rates = append(rates, model.ExchangeRate{
+			BaseCurrency:  base,
+			QuoteCurrency: quote,
+			Date:          date,
+			Rate:          rate,
+		})
+	}
+	if len(rates) == 0 {
+		return nil, errors.New("rates API returned no usable rates")
+	}
+
+	return rates, nil
+}
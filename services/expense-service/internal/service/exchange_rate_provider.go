@@ -0,0 +1,163 @@
+package service
+
+import (
+	"context"
+	"expense-tracker/expense-service/internal/model"
+	"expense-tracker/expense-service/internal/repository"
+	"expense-tracker/shared/money"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+)
+
+// ExchangeRateProvider supplies the exchange rates used to convert amounts
+// The default reads the rates stored in the database (loaded by admins or by
+// ExchangeRateFetcher); another source can be plugged in with
+// CurrencyService.SetExchangeRateProvider
+type ExchangeRateProvider interface {
+	// Rates returns, for every request, how many units of `to` one unit of the
+	// requested currency was worth on the requested date (in the same order)
+	// All rates are looked up at once
+	Rates(ctx context.Context, to string, requests []RateRequest) ([]money.Rate, error)
+}
+
+// RateRequest asks for the rate of a currency on a date
+type RateRequest struct {
+	Currency string
+	Date     time.Time
+}
+
+// DatabaseExchangeRateProvider provides the stored exchange rates
+// It uses the latest rate on or before the date, and derives missing pairs
+// from the inverse rate or from the rates of both currencies against a third one
+type DatabaseExchangeRateProvider struct {
+	repo repository.ExchangeRateRepository
+}
+
+// NewDatabaseExchangeRateProvider creates a provider reading stored rates
+func NewDatabaseExchangeRateProvider(repo repository.ExchangeRateRepository) *DatabaseExchangeRateProvider {
+	return &DatabaseExchangeRateProvider{
+		repo: repo,
+	}
+}
+
+// Rates returns the rate into `to` of every requested currency and date
+// The rates of all requested dates are loaded with one query, then each date
+// uses the newest rates on or before it
+func (p *DatabaseExchangeRateProvider) Rates(ctx context.Context, to string, requests []RateRequest) ([]money.Rate, error) {
+	result := make([]money.Rate, len(requests))
+
+	// Only other currencies need stored rates
+	pending := []int{}
+	currencies := []string{to}
+	seen := map[string]bool{to: true}
+	var first, last time.Time
+	for i, req := range requests {
+		if req.Currency == to {
+			result[i] = money.OneRate()
+			continue
+		}
+		pending = append(pending, i)
+		if !seen[req.Currency] {
+			seen[req.Currency] = true
+			currencies = append(currencies, req.Currency)
+		}
+		if first.IsZero() || req.Date.Before(first) {
+			first = req.Date
+		}
+		if req.Date.After(last) {
+			last = req.Date
+		}
+	}
+	if len(pending) == 0 {
+		return result, nil
+	}
+
+	rates, err := p.repo.FindHistory(ctx, currencies, first, last)
+	if err != nil {
+		return nil, err
+	}
+
+	// Walk through the requests by date, keeping the newest rate of every pair
+	sort.SliceStable(pending, func(a, b int) bool {
+		return requests[pending[a]].Date.Before(requests[pending[b]].Date)
+	})
+	// The table of both directions is only rebuilt when a newer rate comes in
+	latest := make(map[[2]string]model.ExchangeRate)
+	var table rateTable
+	next := 0
+	for _, i := range pending {
+		req := requests[i]
+		changed := table == nil
+		for next < len(rates) && !rates[next].Date.After(req.Date) {
+			latest[[2]string{rates[next].BaseCurrency, rates[next].QuoteCurrency}] = rates[next]
+			next++
+			changed = true
+		}
+		if changed {
+			table = newRateTable(latest)
+		}
+
+		value, ok := table.crossRate(req.Currency, to)
+		if !ok {
+			date := req.Date.Format("2006-01-02")
+			// Every conversion needing this rate fails until it is loaded
+			log.Printf("ERROR: no exchange rate from %s to %s on %s - load rates with PUT /expenses/admin/exchange-rates or set EXCHANGE_RATES_URL", req.Currency, to, date)
+			return nil, fmt.Errorf("no exchange rate from %s to %s on %s", req.Currency, to, date)
+		}
+		result[i] = value
+	}
+
+	return result, nil
+}
+
+// rateTable holds how many b one a is worth (rateTable[a][b]), in both
+// directions of every stored pair
+type rateTable map[string]map[string]money.Rate
+
+// newRateTable builds the table of the given rates (one per pair)
+func newRateTable(rates map[[2]string]model.ExchangeRate) rateTable {
+	table := make(rateTable)
+	add := func(a, b string, value money.Rate) {
+		if table[a] == nil {
+			table[a] = make(map[string]money.Rate)
+		}
+		// A stored pair wins over the inverse of the opposite pair
+		if _, ok := table[a][b]; !ok {
+			table[a][b] = value
+		}
+	}
+	for _, r := range rates {
+		add(r.BaseCurrency, r.QuoteCurrency, r.Rate)
+	}
+	for _, r := range rates {
+		add(r.QuoteCurrency, r.BaseCurrency, r.Rate.Inverse())
+	}
+	return table
+}
+
+// crossRate returns how many `to` one `from` is worth: the direct or inverse
+// rate, or a cross rate through a third currency
+func (t rateTable) crossRate(from, to string) (money.Rate, bool) {
+	// Direct (or inverse) rate
+	if value, ok := t[from][to]; ok {
+		return value, true
+	}
+
+	// Cross rate through a third currency (e.g. GBP -> USD -> EUR)
+	// Candidates are tried in alphabetical order, so that the same rates always
+	// convert the same way (map order would pick a different one each time)
+	vias := make([]string, 0, len(t[from]))
+	for via := range t[from] {
+		vias = append(vias, via)
+	}
+	sort.Strings(vias)
+	for _, via := range vias {
+		if viaTo, ok := t[via][to]; ok {
+			return t[from][via].Mul(viaTo), true
+		}
+	}
+
+	return money.Rate{}, false
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"expense-tracker/expense-service/internal/model"
+	"expense-tracker/shared/money"
+	"testing"
+	"time"
+)
+
+// fakeRateRepository returns its rates (ordered by date) from every lookup
+type fakeRateRepository struct {
+	rates   []model.ExchangeRate
+	lookups int
+}
+
+func (r *fakeRateRepository) Save(ctx context.Context, rates []model.ExchangeRate) error {
+	r.rates = append(r.rates, rates...)
+	return nil
+}
+
+func (r *fakeRateRepository) FindHistory(ctx context.Context, currencies []string, from, to time.Time) ([]model.ExchangeRate, error) {
+	r.lookups++
+	return r.rates, nil
+}
+
+func rateOn(t *testing.T, base, quote, day, value string) model.ExchangeRate {
+	t.Helper()
+	date, _ := time.Parse("2006-01-02", day)
+	return model.ExchangeRate{BaseCurrency: base, QuoteCurrency: quote, Date: date, Rate: parseRate(t, value)}
+}
+
+func parseRate(t *testing.T, value string) money.Rate {
+	t.Helper()
+	rate, err := money.ParseRate(value)
+	if err != nil {
+		t.Fatalf("invalid test rate %q: %v", value, err)
+	}
+	return rate
+}
+
+func TestDatabaseExchangeRateProviderRates(t *testing.T) {
+	repo := &fakeRateRepository{rates: []model.ExchangeRate{
+		rateOn(t, "USD", "EUR", "2024-01-01", "0.9"),
+		rateOn(t, "USD", "GBP", "2024-01-01", "0.8"),
+		rateOn(t, "USD", "EUR", "2024-02-01", "0.95"),
+	}}
+	provider := NewDatabaseExchangeRateProvider(repo)
+
+	day := func(value string) time.Time {
+		parsed, _ := time.Parse("2006-01-02", value)
+		return parsed
+	}
+	requests := []RateRequest{
+		{Currency: "USD", Date: day("2024-02-10")},
+		{Currency: "EUR", Date: day("2024-02-10")}, // Newer rate, inverse
+		{Currency: "EUR", Date: day("2024-01-15")}, // Older rate, inverse
+		{Currency: "GBP", Date: day("2024-01-15")},
+	}
+
+	got, err := provider.Rates(context.Background(), "USD", requests)
+	if err != nil {
+		t.Fatalf("Rates() error = %v", err)
+	}
+	want := []money.Rate{money.OneRate(), parseRate(t, "0.95").Inverse(), parseRate(t, "0.9").Inverse(), parseRate(t, "1.25")}
+	for i := range want {
+		if !got[i].Equal(want[i]) {
+			t.Errorf("rate %d = %s, want %s", i, got[i], want[i])
+		}
+	}
+	if repo.lookups != 1 {
+		t.Errorf("rates were looked up %d times, want once", repo.lookups)
+	}
+
+	// GBP -> EUR only exists through USD
+	cross, err := provider.Rates(context.Background(), "EUR", []RateRequest{{Currency: "GBP", Date: day("2024-01-15")}})
+	if err != nil {
+		t.Fatalf("Rates() cross rate error = %v", err)
+	}
+	if want := parseRate(t, "1.125"); !cross[0].Equal(want) {
+		t.Errorf("cross rate = %s, want %s", cross[0], want)
+	}
+
+	// Nothing on or before the date
+	if _, err := provider.Rates(context.Background(), "USD", []RateRequest{{Currency: "EUR", Date: day("2023-12-31")}}); err == nil {
+		t.Error("Rates() before any rate succeeded, want an error")
+	}
+}
+
+func TestCrossRateIsDeterministic(t *testing.T) {
+	// GBP -> JPY through EUR gives 160, through USD 150
+	rates := make(map[[2]string]model.ExchangeRate)
+	for _, r := range []model.ExchangeRate{
+		rateOn(t, "GBP", "USD", "2024-01-01", "1.25"),
+		rateOn(t, "USD", "JPY", "2024-01-01", "120"),
+		rateOn(t, "GBP", "EUR", "2024-01-01", "1.25"),
+		rateOn(t, "EUR", "JPY", "2024-01-01", "128"),
+	} {
+		rates[[2]string{r.BaseCurrency, r.QuoteCurrency}] = r
+	}
+
+	want := parseRate(t, "160") // EUR comes first
+	for i := 0; i < 20; i++ {
+		got, ok := newRateTable(rates).crossRate("GBP", "JPY")
+		if !ok || !got.Equal(want) {
+			t.Fatalf("crossRate() = %s, %v, want %s", got, ok, want)
+		}
+	}
+}
+
+func TestParseFetchedRates(t *testing.T) {
+	rates, err := parseFetchedRates(&ratesResponse{
+		Base:  "usd",
+		Date:  "2024-01-15",
+		Rates: map[string]money.Rate{"EUR": parseRate(t, "0.92"), "XXXX": parseRate(t, "1.5"), "USD": money.OneRate()},
+	})
+	if err != nil {
+		t.Fatalf("parseFetchedRates() error = %v", err)
+	}
+	if len(rates) != 1 || rates[0].BaseCurrency != "USD" || rates[0].QuoteCurrency != "EUR" || !rates[0].Rate.Equal(parseRate(t, "0.92")) {
+		t.Errorf("parseFetchedRates() = %+v, want only USD/EUR 0.92", rates)
+	}
+
+	if _, err := parseFetchedRates(&ratesResponse{Base: "USD", Date: "15/01/2024", Rates: map[string]money.Rate{"EUR": parseRate(t, "0.92")}}); err == nil {
+		t.Error("parseFetchedRates() with an invalid date succeeded")
+	}
+	if _, err := parseFetchedRates(&ratesResponse{Base: "USD", Date: "2024-01-15"}); err == nil {
+		t.Error("parseFetchedRates() without rates succeeded")
+	}
+}
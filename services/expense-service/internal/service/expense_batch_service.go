@@ -0,0 +1,346 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"expense-tracker/expense-service/internal/model"
+	"expense-tracker/expense-service/internal/repository"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MaxBatchOperations is how many operations a batch may have
+const MaxBatchOperations = 100
+
+// ExpenseBatchService applies batches of expense creates, updates and deletes,
+// e.g. the edits a mobile app made while offline
+// Operations are validated like the single-expense endpoints and saved in one
+// transaction; one expense.batch_applied event is published per batch
+type ExpenseBatchService struct {
+	expenseRepo    repository.ExpenseRepository
+	expenseService *ExpenseService // Validates operations like single-expense requests
+	budgetService  *BudgetService  // Optional - budget alerts are not checked if nil
+	eventPublisher *EventPublisher // Optional - can be nil if not configured
+}
+
+// NewExpenseBatchService creates a new expense batch service
+func NewExpenseBatchService(expenseRepo repository.ExpenseRepository, expenseService *ExpenseService) *ExpenseBatchService {
+	return &ExpenseBatchService{
+		expenseRepo:    expenseRepo,
+		expenseService: expenseService,
+	}
+}
+
+// SetBudgetService enables budget threshold alerts for created and updated expenses (optional)
+func (s *ExpenseBatchService) SetBudgetService(budgetService *BudgetService) {
+	s.budgetService = budgetService
+}
+
+// SetEventPublisher sets the event publisher (optional)
+func (s *ExpenseBatchService) SetEventPublisher(publisher *EventPublisher) {
+	s.eventPublisher = publisher
+}
+
+// ApplyBatch applies a batch of operations in order
+// Later operations see the effect of earlier ones (an expense created in the
+// batch can be updated by a later operation). In all_or_nothing mode nothing
+// is saved if any operation fails; in best_effort mode the others are saved
+func (s *ExpenseBatchService) ApplyBatch(ctx context.Context, userID string, req *model.ExpenseBatchRequest) (*model.ExpenseBatchResponse, error) {
+	mode := req.Mode
+	switch mode {
+	case "":
+		mode = model.BatchModeAllOrNothing
+	case model.BatchModeAllOrNothing, model.BatchModeBestEffort:
+	default:
+		return nil, errors.New("mode must be all_or_nothing or best_effort")
+	}
+
+	if len(req.Operations) == 0 {
+		return nil, errors.New("operations is required")
+	}
+	if len(req.Operations) > MaxBatchOperations {
+		return nil, fmt.Errorf("operations must be at most %d", MaxBatchOperations)
+	}
+
+	// Validate every operation before saving any, against the expenses as the
+	// earlier operations leave them (pending). Nothing is read while the batch
+	// is being saved, so the transaction doesn't wait on other connections
+	atomic := mode == model.BatchModeAllOrNothing
+	lookups := s.expenseService.newExpenseLookups(userID)
+	results := make([]model.ExpenseBatchResult, len(req.Operations))
+	pending := make(map[string]*model.Expense) // Expenses changed by the batch (nil if deleted)
+	var changes []*model.ExpenseChange
+	var changeResults []int // Index of each change's result
+	anyInvalid := false
+	for i := range req.Operations {
+		op := &req.Operations[i]
+		results[i] = model.ExpenseBatchResult{Index: i, Op: op.Op, ID: op.ID}
+
+		change, err := s.prepare(ctx, lookups, op, pending)
+		if err != nil {
+			if !isBatchOperationError(err) {
+				return nil, err
+			}
+			results[i].Status = model.BatchOpFailed
+			results[i].Error = err.Error()
+			anyInvalid = true
+			continue
+		}
+
+		results[i].ID = change.Expense.ID
+		recordChange(pending, change)
+		changes = append(changes, change)
+		changeResults = append(changeResults, i)
+	}
+
+	// An all_or_nothing batch with an invalid operation is not saved at all
+	committed := false
+	if len(changes) > 0 && !(atomic && anyInvalid) {
+		errs, err := s.expenseRepo.ApplyChanges(ctx, changes, atomic)
+		if err != nil {
+			return nil, err
+		}
+		committed = true
+		for j, err := range errs {
+			if err == nil {
+				continue
+			}
+			if !isBatchOperationError(err) {
+				log.Printf("Warning: batch operation %d of user %s failed: %v", changeResults[j], userID, err)
+				err = errors.New("failed to save expense")
+			}
+			result := &results[changeResults[j]]
+			result.Status = model.BatchOpFailed
+			result.Error = err.Error()
+			if atomic {
+				committed = false
+			}
+		}
+	}
+
+	resp := &model.ExpenseBatchResponse{Mode: mode, Results: results}
+
+	var saved []*model.ExpenseChange
+	for j, change := range changes {
+		result := &results[changeResults[j]]
+		if result.Status == model.BatchOpFailed {
+			continue
+		}
+		if !committed {
+			result.Status = model.BatchOpNotApplied
+			continue
+		}
+
+		switch change.Op {
+		case model.BatchOpCreate:
+			result.Status = model.BatchOpCreated
+		case model.BatchOpUpdate:
+			result.Status = model.BatchOpUpdated
+		case model.BatchOpDelete:
+			result.Status = model.BatchOpDeleted
+		}
+		if change.Op != model.BatchOpDelete {
+			expense := change.Expense
+			result.Expense = &model.ExpenseResponse{
+				ID:          expense.ID,
+				UserID:      expense.UserID,
+				Amount:      expense.Amount,
+				Currency:    expense.Currency,
+				Description: expense.Description,
+				Category:    expense.Category,
+				Tags:        expense.Tags,
+				ExpenseDate: expense.ExpenseDate,
+				CreatedAt:   expense.CreatedAt,
+				UpdatedAt:   expense.UpdatedAt,
+			}
+		}
+		saved = append(saved, change)
+	}
+
+	for _, result := range results {
+		if result.Status == model.BatchOpFailed {
+			resp.Failed++
+		}
+	}
+	resp.Succeeded = len(saved)
+	resp.Committed = len(saved) > 0
+
+	if resp.Committed {
+		log.Printf("Applied batch of %d operations for user %s (%d failed, mode %s)", len(results), userID, resp.Failed, mode)
+		s.publishBatch(ctx, userID, mode, saved, resp)
+		s.checkBudgets(ctx, userID, saved)
+	}
+
+	return resp, nil
+}
+
+// prepare validates an operation and returns the change to save
+// pending holds the expenses earlier operations of the batch created, updated or
+// deleted; it is left alone (see recordChange)
+// Errors of the operation itself are ValidationErrors
+func (s *ExpenseBatchService) prepare(ctx context.Context, lookups *expenseLookups, op *model.ExpenseBatchOperation, pending map[string]*model.Expense) (*model.ExpenseChange, error) {
+	userID := lookups.userID
+
+	if op.ID != "" {
+		if _, err := uuid.Parse(op.ID); err != nil {
+			return nil, invalid(errors.New("id must be a UUID"))
+		}
+	}
+
+	switch op.Op {
+	case model.BatchOpCreate:
+		var req model.CreateExpenseRequest
+		if err := decodeBatchExpense(op.Expense, &req); err != nil {
+			return nil, err
+		}
+		expense, err := s.expenseService.newExpense(ctx, lookups, &req)
+		if err != nil {
+			return nil, err
+		}
+
+		// A client-generated ID (the database rejects IDs that are taken)
+		if op.ID != "" {
+			if _, ok := pending[op.ID]; ok {
+				return nil, invalid(errors.New("expense already exists"))
+			}
+			expense.ID = op.ID
+		}
+
+		return &model.ExpenseChange{Op: op.Op, Expense: expense}, nil
+
+	case model.BatchOpUpdate:
+		current, err := s.find(ctx, userID, op.ID, pending)
+		if err != nil {
+			return nil, err
+		}
+		var req model.UpdateExpenseRequest
+		if err := decodeBatchExpense(op.Expense, &req); err != nil {
+			return nil, err
+		}
+
+		// Update a copy - an earlier change of the batch may hold the current one
+		expense := *current
+		expense.Tags = append([]string{}, current.Tags...)
+		if err := s.expenseService.applyUpdate(ctx, userID, &expense, &req); err != nil {
+			return nil, err
+		}
+
+		return &model.ExpenseChange{Op: op.Op, Expense: &expense}, nil
+
+	case model.BatchOpDelete:
+		expense, err := s.find(ctx, userID, op.ID, pending)
+		if err != nil {
+			return nil, err
+		}
+
+		return &model.ExpenseChange{Op: op.Op, Expense: &model.Expense{ID: expense.ID, UserID: userID}}, nil
+
+	default:
+		return nil, invalid(errors.New("op must be create, update or delete"))
+	}
+}
+
+// recordChange notes a valid change in pending, for the operations after it
+func recordChange(pending map[string]*model.Expense, change *model.ExpenseChange) {
+	if change.Op == model.BatchOpDelete {
+		pending[change.Expense.ID] = nil
+		return
+	}
+	pending[change.Expense.ID] = change.Expense
+}
+
+// find finds an expense to update or delete, as earlier operations of the batch left it
+func (s *ExpenseBatchService) find(ctx context.Context, userID, id string, pending map[string]*model.Expense) (*model.Expense, error) {
+	if id == "" {
+		return nil, invalid(errors.New("id is required"))
+	}
+
+	expense, ok := pending[id]
+	if !ok {
+		var err error
+		expense, err = s.expenseRepo.FindByID(ctx, id, userID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if expense == nil {
+		return nil, invalid(errors.New("expense not found"))
+	}
+	return expense, nil
+}
+
+// decodeBatchExpense decodes the expense of a create or update operation
+func decodeBatchExpense(data json.RawMessage, v interface{}) error {
+	if len(data) == 0 || string(data) == "null" {
+		return invalid(errors.New("expense is required"))
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return invalid(errors.New("expense must be a valid expense object"))
+	}
+	return nil
+}
+
+// publishBatch publishes one expense.batch_applied event for the whole batch
+// (instead of an event per created or updated expense)
+func (s *ExpenseBatchService) publishBatch(ctx context.Context, userID, mode string, saved []*model.ExpenseChange, resp *model.ExpenseBatchResponse) {
+	if s.eventPublisher == nil {
+		log.Printf("WARNING: Event publisher not configured - expense.batch_applied event will not be published")
+		return
+	}
+
+	counts := make(map[string]int)
+	expenseIDs := make([]string, len(saved))
+	for i, change := range saved {
+		counts[change.Op]++
+		expenseIDs[i] = change.Expense.ID
+	}
+
+	event := &Event{
+		EventType: "expense.batch_applied",
+		UserID:    userID,
+		UserEmail: userEmailFrom(ctx),
+		Timestamp: time.Now(),
+		Data: map[string]interface{}{
+			"mode":        mode,
+			"created":     counts[model.BatchOpCreate],
+			"updated":     counts[model.BatchOpUpdate],
+			"deleted":     counts[model.BatchOpDelete],
+			"failed":      resp.Failed,
+			"expense_ids": expenseIDs,
+		},
+	}
+	s.eventPublisher.PublishEventAsync(ctx, event)
+}
+
+// checkBudgets alerts if created or updated expenses pushed a budget over a
+// threshold, like the single-expense endpoints (each threshold is alerted once per period)
+func (s *ExpenseBatchService) checkBudgets(ctx context.Context, userID string, saved []*model.ExpenseChange) {
+	if s.budgetService == nil {
+		return
+	}
+
+	var expenses []*model.Expense
+	for _, change := range saved {
+		if change.Op != model.BatchOpDelete {
+			expenses = append(expenses, change.Expense)
+		}
+	}
+	if len(expenses) > 0 {
+		s.budgetService.CheckThresholds(ctx, userID, userEmailFrom(ctx), expenses...)
+	}
+}
+
+// isBatchOperationError reports whether err is an error of a single operation
+// (invalid, or its expense doesn't exist or already exists) rather than a failure
+// of the whole batch
+func isBatchOperationError(err error) bool {
+	return isInvalid(err) ||
+		errors.Is(err, repository.ErrExpenseNotFound) ||
+		errors.Is(err, repository.ErrExpenseExists) ||
+		errors.Is(err, repository.ErrEarlierChangeFailed)
+}
@@ -0,0 +1,178 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"expense-tracker/expense-service/internal/model"
+	"expense-tracker/expense-service/internal/repository"
+	"io"
+	"strings"
+	"time"
+)
+
+// ExpenseExportService exports expenses as CSV, Excel or PDF files for accountants
+// Expenses are streamed from the database, so exports have no row limit
+type ExpenseExportService struct {
+	expenseRepo    repository.ExpenseRepository
+	expenseService *ExpenseService // Builds the PDF's summary by category
+}
+
+// NewExpenseExportService creates a new expense export service
+func NewExpenseExportService(expenseRepo repository.ExpenseRepository, expenseService *ExpenseService) *ExpenseExportService {
+	return &ExpenseExportService{
+		expenseRepo:    expenseRepo,
+		expenseService: expenseService,
+	}
+}
+
+// ExpenseExport is a validated export that is ready to be streamed
+type ExpenseExport struct {
+	ContentType string
+	FileName    string
+
+	userID    string
+	filters   *model.ListExpensesRequest
+	repo      repository.ExpenseRepository
+	newWriter func(w io.Writer) expenseWriter
+}
+
+// expenseWriter writes exported expenses in one format
+type expenseWriter interface {
+	// writeExpense writes one expense
+	writeExpense(expense *model.Expense) error
+
+	// close writes whatever comes after the expenses
+	close() error
+}
+
+// ExportExpenses validates an export
+// Everything that can fail with a client error (filters, a missing exchange
+// rate for the PDF summary) is checked here, before anything is written
+func (s *ExpenseExportService) ExportExpenses(ctx context.Context, userID string, req *model.ExportExpensesRequest) (*ExpenseExport, error) {
+	if err := validateListFilters(req.Filters); err != nil {
+		return nil, err
+	}
+
+	export := &ExpenseExport{
+		userID:  userID,
+		filters: req.Filters,
+		repo:    s.expenseRepo,
+	}
+
+	format := strings.ToLower(req.Format)
+	switch format {
+	case model.ExportFormatCSV, "":
+		format = model.ExportFormatCSV
+		export.ContentType = "text/csv; charset=utf-8"
+		export.newWriter = newCSVExpenseWriter
+	case model.ExportFormatXLSX:
+		export.ContentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+		export.newWriter = newXLSXExpenseWriter
+	case model.ExportFormatPDF:
+		if err := s.checkPDFText(ctx, userID, req.Filters); err != nil {
+			return nil, err
+		}
+		report, err := s.newReport(ctx, userID, req.Filters)
+		if err != nil {
+			return nil, err
+		}
+		export.ContentType = "application/pdf"
+		export.newWriter = func(w io.Writer) expenseWriter {
+			return newPDFExpenseWriter(w, report)
+		}
+	default:
+		return nil, errors.New("format must be csv, xlsx or pdf")
+	}
+
+	export.FileName = "expenses-" + time.Now().Format("2006-01-02") + "." + format
+	return export, nil
+}
+
+// Stream writes the exported file
+// Errors after the first bytes were written can't be reported to the client
+// any more - the file is cut short
+func (e *ExpenseExport) Stream(ctx context.Context, w io.Writer) error {
+	writer := e.newWriter(w)
+
+	if err := e.repo.StreamByUserID(ctx, e.userID, e.filters, writer.writeExpense); err != nil {
+		return err
+	}
+
+	return writer.close()
+}
+
+// checkPDFText rejects PDF exports with text the PDF can't show
+// PDFs use the standard Helvetica font without embedding one, which only has
+// Latin-1 characters - others would be printed as "?"
+func (s *ExpenseExportService) checkPDFText(ctx context.Context, userID string, filters *model.ListExpensesRequest) error {
+	filterText := filters.Category + filters.Query + strings.Join(filters.Tags, "")
+	if !isLatin1(filterText) {
+		return errors.New("pdf exports cannot show characters outside Latin-1 in filters - use csv or xlsx")
+	}
+
+	found, err := s.expenseRepo.HasNonLatin1Text(ctx, userID, filters)
+	if err != nil {
+		return err
+	}
+	if found {
+		return errors.New("pdf exports cannot show characters outside Latin-1 (e.g. Cyrillic, Chinese or emoji) in descriptions or categories - use csv or xlsx")
+	}
+	return nil
+}
+
+// newReport builds the heading and summary of a PDF report
+// The summary totals the same expenses as the export (all its filters),
+// by category in the user's base currency
+func (s *ExpenseExportService) newReport(ctx context.Context, userID string, filters *model.ListExpensesRequest) (*pdfReport, error) {
+	summary, err := s.expenseService.summarizeByCategory(ctx, userID, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	period := "All dates"
+	switch {
+	case filters.StartDate != "" && filters.EndDate != "":
+		period = filters.StartDate + " to " + filters.EndDate
+	case filters.StartDate != "":
+		period = "From " + filters.StartDate
+	case filters.EndDate != "":
+		period = "Until " + filters.EndDate
+	}
+
+	var filterNotes []string
+	if filters.Category != "" {
+		filterNotes = append(filterNotes, "category "+filters.Category)
+	}
+	if len(filters.Tags) > 0 {
+		match := "any of"
+		if filters.TagMatch == model.TagMatchAll {
+			match = "all of"
+		}
+		filterNotes = append(filterNotes, "tags ("+match+") "+strings.Join(filters.Tags, ", "))
+	}
+	if filters.Query != "" {
+		filterNotes = append(filterNotes, "search \""+filters.Query+"\"")
+	}
+	if filters.MinAmount != "" || filters.MaxAmount != "" {
+		filterNotes = append(filterNotes, "amount "+amountRange(filters.MinAmount, filters.MaxAmount))
+	}
+
+	return &pdfReport{
+		period:    period,
+		filters:   strings.Join(filterNotes, "; "),
+		generated: time.Now(),
+		summary:   summary,
+	}, nil
+}
+
+// amountRange describes an amount filter, e.g. "10.00 to 50.00" or "at least 10.00"
+func amountRange(min, max string) string {
+	switch {
+	case min != "" && max != "":
+		return min + " to " + max
+	case min != "":
+		return "at least " + min
+	default:
+		return "at most " + max
+	}
+}
@@ -0,0 +1,239 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"expense-tracker/expense-service/internal/model"
+	"expense-tracker/expense-service/internal/repository"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// MaxImportRows is how many rows an imported file may have
+const MaxImportRows = 5000
+
+// ExpenseImportService imports expenses from bank statements and CSV files
+// Rows are validated like POST /expenses; rows that match an existing expense
+// are left out, and the rest are saved together (all or none)
+type ExpenseImportService struct {
+	expenseRepo    repository.ExpenseRepository
+	expenseService *ExpenseService // Validates rows like created expenses
+	eventPublisher *EventPublisher // Optional - can be nil if not configured
+	budgetService  *BudgetService  // Optional - budget alerts are not checked if nil
+}
+
+// NewExpenseImportService creates a new expense import service
+func NewExpenseImportService(expenseRepo repository.ExpenseRepository, expenseService *ExpenseService) *ExpenseImportService {
+	return &ExpenseImportService{
+		expenseRepo:    expenseRepo,
+		expenseService: expenseService,
+	}
+}
+
+// SetEventPublisher sets the event publisher (optional)
+func (s *ExpenseImportService) SetEventPublisher(publisher *EventPublisher) {
+	s.eventPublisher = publisher
+}
+
+// SetBudgetService enables budget threshold alerts for imported expenses (optional)
+func (s *ExpenseImportService) SetBudgetService(budgetService *BudgetService) {
+	s.budgetService = budgetService
+}
+
+// ImportExpenses imports the rows of a CSV file or OFX statement as expenses
+// With DryRun nothing is saved - the response shows what would be imported
+func (s *ExpenseImportService) ImportExpenses(ctx context.Context, userID string, req *model.ImportExpensesRequest) (*model.ImportExpensesResponse, error) {
+	if len(req.Data) == 0 {
+		return nil, errors.New("file is required")
+	}
+
+	// Read the file
+	var rows []*statementRow
+	currency := req.Currency
+	switch strings.ToLower(req.Format) {
+	case model.ImportFormatCSV:
+		var err error
+		rows, err = parseCSVStatement(req.Data, req.Mapping)
+		if err != nil {
+			return nil, err
+		}
+	case model.ImportFormatOFX, "qfx":
+		var statementCurrency string
+		var err error
+		rows, statementCurrency, err = parseOFXStatement(req.Data)
+		if err != nil {
+			return nil, err
+		}
+		if currency == "" {
+			currency = statementCurrency
+		}
+	case "":
+		return nil, errors.New("format is required")
+	default:
+		return nil, errors.New("format must be csv or ofx")
+	}
+
+	if len(rows) > MaxImportRows {
+		return nil, fmt.Errorf("file must not have more than %d rows", MaxImportRows)
+	}
+
+	// Validate the rows like created expenses
+	// The base currency and categories are looked up once for all rows
+	lookups := s.expenseService.newExpenseLookups(userID)
+	results := make([]model.ImportRowResult, len(rows))
+	expenses := make([]*model.Expense, len(rows))
+	var startDate, endDate time.Time
+	for i, row := range rows {
+		results[i] = model.ImportRowResult{Row: row.row, Status: row.status, Error: row.err}
+		if row.status != "" {
+			continue
+		}
+
+		expenseReq := row.expense
+		if expenseReq.Currency == "" {
+			expenseReq.Currency = currency
+		}
+		if expenseReq.Category == "" {
+			expenseReq.Category = req.Category
+		}
+		expenseReq.Tags = req.Tags
+
+		expense, err := s.expenseService.newExpense(ctx, lookups, &expenseReq)
+		if err != nil {
+			if !isInvalid(err) {
+				return nil, err
+			}
+			results[i].Status = model.ImportRowInvalid
+			results[i].Error = err.Error()
+			continue
+		}
+		expenses[i] = expense
+
+		if startDate.IsZero() || expense.ExpenseDate.Before(startDate) {
+			startDate = expense.ExpenseDate
+		}
+		if endDate.IsZero() || expense.ExpenseDate.After(endDate) {
+			endDate = expense.ExpenseDate
+		}
+	}
+
+	// Leave out rows that match existing expenses
+	// Each existing expense matches one row, so a file with two equal
+	// purchases on one day still imports the second one
+	existing := map[string][]string{}
+	if !startDate.IsZero() {
+		var err error
+		existing, err = s.expenseRepo.FindFingerprints(ctx, userID, startDate, endDate)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var accepted []*model.Expense
+	status := model.ImportRowImported
+	if req.DryRun {
+		status = model.ImportRowAccepted
+	}
+	for i, expense := range expenses {
+		if expense == nil {
+			continue
+		}
+
+		fingerprint := model.ExpenseFingerprint(expense.ExpenseDate, expense.Amount, expense.Description)
+		if ids := existing[fingerprint]; len(ids) > 0 {
+			results[i].Status = model.ImportRowDuplicate
+			results[i].DuplicateOf = ids[0]
+			existing[fingerprint] = ids[1:]
+			continue
+		}
+
+		results[i].Status = status
+		results[i].Expense = &model.ExpenseResponse{
+			ID:          expense.ID,
+			UserID:      expense.UserID,
+			Amount:      expense.Amount,
+			Currency:    expense.Currency,
+			Description: expense.Description,
+			Category:    expense.Category,
+			Tags:        expense.Tags,
+			ExpenseDate: expense.ExpenseDate,
+			CreatedAt:   expense.CreatedAt,
+			UpdatedAt:   expense.UpdatedAt,
+		}
+		accepted = append(accepted, expense)
+	}
+
+	resp := &model.ImportExpensesResponse{
+		DryRun:   req.DryRun,
+		Total:    len(rows),
+		Imported: len(accepted),
+		Rows:     results,
+	}
+	for _, result := range results {
+		switch result.Status {
+		case model.ImportRowDuplicate:
+			resp.Duplicates++
+		case model.ImportRowInvalid:
+			resp.Invalid++
+		case model.ImportRowSkipped:
+			resp.Skipped++
+		}
+	}
+
+	if req.DryRun || len(accepted) == 0 {
+		return resp, nil
+	}
+
+	// Save the accepted rows together
+	if err := s.expenseRepo.CreateBatch(ctx, accepted); err != nil {
+		return nil, err
+	}
+	log.Printf("Imported %d expenses for user %s (%d duplicates, %d invalid, %d skipped)", resp.Imported, userID, resp.Duplicates, resp.Invalid, resp.Skipped)
+
+	s.publishImported(ctx, userID, strings.ToLower(req.Format), accepted, resp)
+
+	// One check for the whole import, after it was saved
+	if s.budgetService != nil {
+		s.budgetService.CheckCurrentThresholds(ctx, userID, userEmailFrom(ctx), accepted)
+	}
+
+	return resp, nil
+}
+
+// publishImported publishes one expense.imported event for the whole import
+// (instead of an expense.created event per row)
+func (s *ExpenseImportService) publishImported(ctx context.Context, userID, format string, expenses []*model.Expense, resp *model.ImportExpensesResponse) {
+	if s.eventPublisher == nil {
+		log.Printf("WARNING: Event publisher not configured - expense.imported event will not be published")
+		return
+	}
+
+	startDate, endDate := expenses[0].ExpenseDate, expenses[0].ExpenseDate
+	for _, expense := range expenses {
+		if expense.ExpenseDate.Before(startDate) {
+			startDate = expense.ExpenseDate
+		}
+		if expense.ExpenseDate.After(endDate) {
+			endDate = expense.ExpenseDate
+		}
+	}
+
+	event := &Event{
+		EventType: "expense.imported",
+		UserID:    userID,
+		UserEmail: userEmailFrom(ctx),
+		Timestamp: time.Now(),
+		Data: map[string]interface{}{
+			"format":     format,
+			"imported":   resp.Imported,
+			"duplicates": resp.Duplicates,
+			"invalid":    resp.Invalid,
+			"skipped":    resp.Skipped,
+			"start_date": startDate.Format("2006-01-02"),
+			"end_date":   endDate.Format("2006-01-02"),
+		},
+	}
+	s.eventPublisher.PublishEventAsync(ctx, event)
+}
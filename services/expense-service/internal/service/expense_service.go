@@ -5,21 +5,41 @@ import (
 	"errors"
 	"expense-tracker/expense-service/internal/model"
 	"expense-tracker/expense-service/internal/repository"
+	"expense-tracker/shared/money"
+	"expense-tracker/shared/pagination"
+	"fmt"
+	"html"
 	"log"
-	"strconv"
+	"sort"
+	"strings"
 	"time"
+	"unicode/utf8"
 )
 
+// Tag limits
+const (
+	maxTagsPerExpense = 20
+	maxTagLength      = 50 // expense_tags.tag is VARCHAR(50)
+)
+
+// maxSearchQueryLength is the longest search query (q) in characters
+const maxSearchQueryLength = 200
+
 // ExpenseService handles expense business logic
 type ExpenseService struct {
-	expenseRepo    repository.ExpenseRepository
-	eventPublisher *EventPublisher // Optional - can be nil if not configured
+	expenseRepo     repository.ExpenseRepository
+	currencyService *CurrencyService
+	categoryService *CategoryService
+	eventPublisher  *EventPublisher // Optional - can be nil if not configured
+	budgetService   *BudgetService  // Optional - budget alerts are not checked if nil
 }
 
 // NewExpenseService creates a new expense service
-func NewExpenseService(expenseRepo repository.ExpenseRepository) *ExpenseService {
+func NewExpenseService(expenseRepo repository.ExpenseRepository, currencyService *CurrencyService, categoryService *CategoryService) *ExpenseService {
 	return &ExpenseService{
-		expenseRepo: expenseRepo,
+		expenseRepo:     expenseRepo,
+		currencyService: currencyService,
+		categoryService: categoryService,
 	}
 }
 
@@ -28,38 +48,18 @@ func (s *ExpenseService) SetEventPublisher(publisher *EventPublisher) {
 	s.eventPublisher = publisher
 }
 
+// SetBudgetService enables budget threshold alerts for created and updated expenses (optional)
+func (s *ExpenseService) SetBudgetService(budgetService *BudgetService) {
+	s.budgetService = budgetService
+}
+
 // CreateExpense creates a new expense for a user
 func (s *ExpenseService) CreateExpense(ctx context.Context, userID string, req *model.CreateExpenseRequest) (*model.ExpenseResponse, error) {
-	// Validate amount
-	amount, err := strconv.ParseFloat(req.Amount, 64)
-	if err != nil || amount <= 0 {
-		return nil, errors.New("amount must be a positive number")
-	}
-
-	// Validate description
-	if req.Description == "" {
-		return nil, errors.New("description is required")
-	}
-
-	// Validate category
-	if req.Category == "" {
-		return nil, errors.New("category is required")
-	}
-
-	// Parse expense date
-	expenseDate, err := time.Parse("2006-01-02", req.ExpenseDate)
+	expense, err := s.newExpense(ctx, s.newExpenseLookups(userID), req)
 	if err != nil {
-		return nil, errors.New("expense_date must be in YYYY-MM-DD format")
-	}
-
-	// Validate date is not in the future (optional business rule)
-	if expenseDate.After(time.Now()) {
-		return nil, errors.New("expense_date cannot be in the future")
+		return nil, err
 	}
 
-	// Create expense
-	expense := model.NewExpense(userID, req.Amount, req.Description, req.Category, expenseDate)
-
 	// Save to database
 	err = s.expenseRepo.Create(ctx, expense)
 	if err != nil {
@@ -83,9 +83,11 @@ func (s *ExpenseService) CreateExpense(ctx context.Context, userID string, req *
 			Timestamp: time.Now(),
 			Data: map[string]interface{}{
 				"expense_id":   expense.ID,
-				"amount":       expense.Amount,
+				"amount":       expense.Amount.String(),
+				"currency":     expense.Currency,
 				"description":  expense.Description,
 				"category":     expense.Category,
+				"tags":         expense.Tags,
 				"expense_date": expense.ExpenseDate.Format("2006-01-02"),
 			},
 		}
@@ -95,19 +97,177 @@ func (s *ExpenseService) CreateExpense(ctx context.Context, userID string, req *
 		log.Printf("WARNING: Event publisher not configured - expense.created event will not be published")
 	}
 
+	// Alert if the expense pushed a budget over a threshold
+	if s.budgetService != nil {
+		s.budgetService.CheckThresholds(ctx, userID, userEmailFrom(ctx), expense)
+	}
+
 	// Return response
 	return &model.ExpenseResponse{
 		ID:          expense.ID,
 		UserID:      expense.UserID,
 		Amount:      expense.Amount,
+		Currency:    expense.Currency,
 		Description: expense.Description,
 		Category:    expense.Category,
+		Tags:        expense.Tags,
 		ExpenseDate: expense.ExpenseDate,
 		CreatedAt:   expense.CreatedAt,
 		UpdatedAt:   expense.UpdatedAt,
 	}, nil
 }
 
+// newExpense validates a create request of the lookups' user and builds the expense
+// Shared by CreateExpense, imports and batches, so their expenses follow the same rules
+func (s *ExpenseService) newExpense(ctx context.Context, lookups *expenseLookups, req *model.CreateExpenseRequest) (*model.Expense, error) {
+	userID := lookups.userID
+
+	// Validate currency (default: the user's base currency)
+	currency, err := lookups.currency(ctx, req.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	// Validate amount (exact decimal with the currency's decimal places)
+	amount, err := money.ParseAmount(req.Amount, currency)
+	if err != nil {
+		return nil, invalid(err)
+	}
+
+	// Validate description
+	if req.Description == "" {
+		return nil, invalid(errors.New("description is required"))
+	}
+
+	// Validate category (must be in the user's catalog)
+	if req.Category == "" {
+		return nil, invalid(errors.New("category is required"))
+	}
+	category, err := lookups.category(ctx, req.Category)
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse expense date
+	expenseDate, err := time.Parse("2006-01-02", req.ExpenseDate)
+	if err != nil {
+		return nil, invalid(errors.New("expense_date must be in YYYY-MM-DD format"))
+	}
+
+	// Validate date is not in the future (optional business rule)
+	if expenseDate.After(time.Now()) {
+		return nil, invalid(errors.New("expense_date cannot be in the future"))
+	}
+
+	// Validate tags (optional)
+	tags, err := normalizeTags(req.Tags)
+	if err != nil {
+		return nil, invalid(err)
+	}
+
+	// Create expense
+	expense := model.NewExpense(userID, amount, currency, req.Description, category, expenseDate)
+	expense.Tags = tags
+
+	return expense, nil
+}
+
+// expenseLookups remembers what validating a user's new expenses looks up - their
+// base currency and catalog categories - so an import or batch of many expenses
+// looks each up once instead of once per expense
+type expenseLookups struct {
+	service      *ExpenseService
+	userID       string
+	baseCurrency string                      // Empty until looked up
+	categories   map[string]resolvedCategory // Keyed by the lower-cased name
+}
+
+// resolvedCategory is the catalog name of a category, or why it isn't one
+type resolvedCategory struct {
+	name string
+	err  error
+}
+
+// newExpenseLookups creates empty lookups for a user's expenses
+func (s *ExpenseService) newExpenseLookups(userID string) *expenseLookups {
+	return &expenseLookups{
+		service:    s,
+		userID:     userID,
+		categories: make(map[string]resolvedCategory),
+	}
+}
+
+// currency validates an expense's currency (default: the user's base currency)
+func (l *expenseLookups) currency(ctx context.Context, code string) (string, error) {
+	if code != "" {
+		return l.service.currencyService.ExpenseCurrency(ctx, l.userID, code)
+	}
+	if l.baseCurrency == "" {
+		base, err := l.service.currencyService.BaseCurrency(ctx, l.userID)
+		if err != nil {
+			return "", err
+		}
+		l.baseCurrency = base
+	}
+	return l.baseCurrency, nil
+}
+
+// category resolves a category name to its spelling in the user's catalog
+// Names that aren't in the catalog are remembered too; database failures aren't
+func (l *expenseLookups) category(ctx context.Context, name string) (string, error) {
+	key := strings.ToLower(strings.TrimSpace(name))
+	if resolved, ok := l.categories[key]; ok {
+		return resolved.name, resolved.err
+	}
+
+	category, err := l.service.categoryService.ResolveCategory(ctx, l.userID, name)
+	if err != nil && !isInvalid(err) {
+		return "", err
+	}
+	l.categories[key] = resolvedCategory{name: category, err: err}
+	return category, err
+}
+
+// ValidationError is an expense request that can't be applied as sent, as opposed
+// to a failure (of the database, ...). Imports and batches report these per row
+// or operation and go on with the rest
+type ValidationError struct {
+	err error
+}
+
+func (e *ValidationError) Error() string {
+	return e.err.Error()
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.err
+}
+
+// invalid marks err as a ValidationError
+func invalid(err error) error {
+	return &ValidationError{err: err}
+}
+
+// isInvalid reports whether err is a ValidationError
+func isInvalid(err error) bool {
+	var validationErr *ValidationError
+	return errors.As(err, &validationErr)
+}
+
+// IsValidationError reports whether err should be answered with 400 rather than 500
+// Besides ValidationErrors, handlers map the plain errors of other validations by
+// their message, so this is only meant for the HTTP layer
+func IsValidationError(err error) bool {
+	if isInvalid(err) {
+		return true
+	}
+	msg := err.Error()
+	return strings.Contains(msg, "required") ||
+		strings.Contains(msg, "format") ||
+		strings.Contains(msg, "must") ||
+		strings.Contains(msg, "cannot")
+}
+
 // GetExpense retrieves a single expense by ID
 // Verifies ownership (user can only access their own expenses)
 func (s *ExpenseService) GetExpense(ctx context.Context, expenseID, userID string) (*model.ExpenseResponse, error) {
@@ -124,8 +284,10 @@ func (s *ExpenseService) GetExpense(ctx context.Context, expenseID, userID strin
 		ID:          expense.ID,
 		UserID:      expense.UserID,
 		Amount:      expense.Amount,
+		Currency:    expense.Currency,
 		Description: expense.Description,
 		Category:    expense.Category,
+		Tags:        expense.Tags,
 		ExpenseDate: expense.ExpenseDate,
 		CreatedAt:   expense.CreatedAt,
 		UpdatedAt:   expense.UpdatedAt,
@@ -134,35 +296,16 @@ func (s *ExpenseService) GetExpense(ctx context.Context, expenseID, userID strin
 
 // ListExpenses retrieves expenses for a user with optional filters and pagination
 func (s *ExpenseService) ListExpenses(ctx context.Context, userID string, filters *model.ListExpensesRequest) (*model.ListExpensesResponse, error) {
-	// Validate date format if provided
-	if filters.StartDate != "" {
-		_, err := time.Parse("2006-01-02", filters.StartDate)
-		if err != nil {
-			return nil, errors.New("start_date must be in YYYY-MM-DD format")
-		}
-	}
-
-	if filters.EndDate != "" {
-		_, err := time.Parse("2006-01-02", filters.EndDate)
-		if err != nil {
-			return nil, errors.New("end_date must be in YYYY-MM-DD format")
-		}
-	}
-
-	// Validate date range
-	if filters.StartDate != "" && filters.EndDate != "" {
-		startDate, _ := time.Parse("2006-01-02", filters.StartDate)
-		endDate, _ := time.Parse("2006-01-02", filters.EndDate)
-		if startDate.After(endDate) {
-			return nil, errors.New("start_date cannot be after end_date")
-		}
+	if err := validateListFilters(filters); err != nil {
+		return nil, err
 	}
 
 	// Get expenses from repository
-	expenses, total, err := s.expenseRepo.FindByUserID(ctx, userID, filters)
+	result, err := s.expenseRepo.FindByUserID(ctx, userID, filters)
 	if err != nil {
 		return nil, err
 	}
+	expenses := result.Expenses
 
 	// Convert to response format
 	expenseResponses := make([]model.ExpenseResponse, len(expenses))
@@ -171,11 +314,14 @@ func (s *ExpenseService) ListExpenses(ctx context.Context, userID string, filter
 			ID:          exp.ID,
 			UserID:      exp.UserID,
 			Amount:      exp.Amount,
+			Currency:    exp.Currency,
 			Description: exp.Description,
 			Category:    exp.Category,
+			Tags:        exp.Tags,
 			ExpenseDate: exp.ExpenseDate,
 			CreatedAt:   exp.CreatedAt,
 			UpdatedAt:   exp.UpdatedAt,
+			Highlight:   highlightHTML(exp.Highlight),
 		}
 	}
 
@@ -188,68 +334,102 @@ func (s *ExpenseService) ListExpenses(ctx context.Context, userID string, filter
 		limit = 100
 	}
 
-	page := filters.Page
-	if page <= 0 {
-		page = 1
+	resp := &model.ListExpensesResponse{
+		Expenses: expenseResponses,
+		Total:    result.Total,
+		Limit:    limit,
 	}
 
-	pages := (total + limit - 1) / limit // Ceiling division
+	// Page numbers only apply without a cursor
+	if filters.PageCursor == nil {
+		resp.Page = filters.Page
+		if resp.Page <= 0 {
+			resp.Page = 1
+		}
+		if result.Total != nil {
+			pages := (*result.Total + limit - 1) / limit // Ceiling division
+			resp.Pages = &pages
+		}
+	}
 
-	return &model.ListExpensesResponse{
-		Expenses: expenseResponses,
-		Total:    total,
-		Page:     page,
-		Limit:    limit,
-		Pages:    pages,
-	}, nil
+	if result.NextCursor != nil {
+		resp.NextCursor = result.NextCursor.Encode()
+	}
+	if result.PrevCursor != nil {
+		resp.PrevCursor = result.PrevCursor.Encode()
+	}
+
+	if filters.Convert {
+		if err := s.addConvertedAmounts(ctx, userID, filters, expenses, resp); err != nil {
+			return nil, err
+		}
+	}
+
+	return resp, nil
 }
 
-// UpdateExpense updates an existing expense
-// Verifies ownership before updating
-func (s *ExpenseService) UpdateExpense(ctx context.Context, expenseID, userID string, req *model.UpdateExpenseRequest) (*model.ExpenseResponse, error) {
-	// Get existing expense (verifies ownership)
-	expense, err := s.expenseRepo.FindByID(ctx, expenseID, userID)
+// addConvertedAmounts adds the amount of every listed expense and the total of
+// all matching expenses in the user's base currency (as of each expense's date)
+func (s *ExpenseService) addConvertedAmounts(ctx context.Context, userID string, filters *model.ListExpensesRequest, expenses []*model.Expense, resp *model.ListExpensesResponse) error {
+	baseCurrency, err := s.currencyService.BaseCurrency(ctx, userID)
 	if err != nil {
-		return nil, err
+		return err
 	}
+	conv := s.currencyService.newConverter(baseCurrency)
 
-	if expense == nil {
-		return nil, errors.New("expense not found")
+	// The rates of the whole page at once
+	requests := make([]RateRequest, len(expenses))
+	for i, exp := range expenses {
+		requests[i] = RateRequest{Currency: exp.Currency, Date: exp.ExpenseDate}
+	}
+	if err := conv.load(ctx, requests); err != nil {
+		return err
 	}
 
-	// Update fields if provided
-	if req.Amount != nil {
-		amount, err := strconv.ParseFloat(*req.Amount, 64)
-		if err != nil || amount <= 0 {
-			return nil, errors.New("amount must be a positive number")
+	for i, exp := range expenses {
+		converted, err := conv.convert(ctx, exp.Amount, exp.Currency, exp.ExpenseDate)
+		if err != nil {
+			return err
 		}
-		expense.Amount = *req.Amount
+		resp.Expenses[i].ConvertedAmount = &converted
 	}
 
-	if req.Description != nil {
-		if *req.Description == "" {
-			return nil, errors.New("description cannot be empty")
-		}
-		expense.Description = *req.Description
+	// The total covers every page, so it is summed in the database per currency and date
+	groups, err := s.expenseRepo.GetAmountGroups(ctx, userID, filters)
+	if err != nil {
+		return err
+	}
+	converted, err := conv.convertGroups(ctx, groups)
+	if err != nil {
+		return err
 	}
 
-	if req.Category != nil {
-		if *req.Category == "" {
-			return nil, errors.New("category cannot be empty")
-		}
-		expense.Category = *req.Category
+	total := conv.zero()
+	for _, value := range converted {
+		total = total.Add(value)
 	}
 
-	if req.ExpenseDate != nil {
-		expenseDate, err := time.Parse("2006-01-02", *req.ExpenseDate)
-		if err != nil {
-			return nil, errors.New("expense_date must be in YYYY-MM-DD format")
-		}
-		expense.ExpenseDate = expenseDate
+	resp.BaseCurrency = baseCurrency
+	resp.ConvertedTotal = &total
+	return nil
+}
+
+// UpdateExpense updates an existing expense
+// Verifies ownership before updating
+func (s *ExpenseService) UpdateExpense(ctx context.Context, expenseID, userID string, req *model.UpdateExpenseRequest) (*model.ExpenseResponse, error) {
+	// Get existing expense (verifies ownership)
+	expense, err := s.expenseRepo.FindByID(ctx, expenseID, userID)
+	if err != nil {
+		return nil, err
 	}
 
-	// Update timestamp
-	expense.UpdatedAt = time.Now()
+	if expense == nil {
+		return nil, errors.New("expense not found")
+	}
+
+	if err := s.applyUpdate(ctx, userID, expense, req); err != nil {
+		return nil, err
+	}
 
 	// Save to database
 	err = s.expenseRepo.Update(ctx, expense)
@@ -274,9 +454,11 @@ func (s *ExpenseService) UpdateExpense(ctx context.Context, expenseID, userID st
 			Timestamp: time.Now(),
 			Data: map[string]interface{}{
 				"expense_id":   expense.ID,
-				"amount":       expense.Amount,
+				"amount":       expense.Amount.String(),
+				"currency":     expense.Currency,
 				"description":  expense.Description,
 				"category":     expense.Category,
+				"tags":         expense.Tags,
 				"expense_date": expense.ExpenseDate.Format("2006-01-02"),
 			},
 		}
@@ -286,18 +468,101 @@ func (s *ExpenseService) UpdateExpense(ctx context.Context, expenseID, userID st
 		log.Printf("WARNING: Event publisher not configured - expense.updated event will not be published")
 	}
 
+	// A higher amount (or a new category or date) can push a budget over a threshold
+	if s.budgetService != nil {
+		s.budgetService.CheckThresholds(ctx, userID, userEmailFrom(ctx), expense)
+	}
+
 	return &model.ExpenseResponse{
 		ID:          expense.ID,
 		UserID:      expense.UserID,
 		Amount:      expense.Amount,
+		Currency:    expense.Currency,
 		Description: expense.Description,
 		Category:    expense.Category,
+		Tags:        expense.Tags,
 		ExpenseDate: expense.ExpenseDate,
 		CreatedAt:   expense.CreatedAt,
 		UpdatedAt:   expense.UpdatedAt,
 	}, nil
 }
 
+// applyUpdate validates an update request and applies it to the expense
+// Shared by UpdateExpense and batches, so batched updates follow the same rules
+func (s *ExpenseService) applyUpdate(ctx context.Context, userID string, expense *model.Expense, req *model.UpdateExpenseRequest) error {
+	// Update fields if provided
+	if req.Currency != nil {
+		currency, err := s.currencyService.ExpenseCurrency(ctx, userID, *req.Currency)
+		if err != nil {
+			return err
+		}
+		expense.Currency = currency
+	}
+
+	// The amount is checked again when only the currency changes (12.50 is no valid JPY amount)
+	if req.Amount != nil || req.Currency != nil {
+		amountStr := expense.Amount.String()
+		if req.Amount != nil {
+			amountStr = *req.Amount
+		}
+		amount, err := money.ParseAmount(amountStr, expense.Currency)
+		if err != nil {
+			return invalid(err)
+		}
+		expense.Amount = amount
+	}
+
+	if req.Description != nil {
+		if *req.Description == "" {
+			return invalid(errors.New("description cannot be empty"))
+		}
+		expense.Description = *req.Description
+	}
+
+	if req.Category != nil {
+		if *req.Category == "" {
+			return invalid(errors.New("category cannot be empty"))
+		}
+		category, err := s.categoryService.ResolveCategory(ctx, userID, *req.Category)
+		if err != nil {
+			return err
+		}
+		expense.Category = category
+	}
+
+	if req.ExpenseDate != nil {
+		expenseDate, err := time.Parse("2006-01-02", *req.ExpenseDate)
+		if err != nil {
+			return invalid(errors.New("expense_date must be in YYYY-MM-DD format"))
+		}
+		expense.ExpenseDate = expenseDate
+	}
+
+	// Tags replace the existing ones
+	if req.Tags != nil {
+		tags, err := normalizeTags(*req.Tags)
+		if err != nil {
+			return invalid(err)
+		}
+		expense.Tags = tags
+	}
+
+	// Update timestamp
+	expense.UpdatedAt = time.Now()
+
+	return nil
+}
+
+// ListTags lists the tags a user has used, with how many expenses have each (most used first)
+func (s *ExpenseService) ListTags(ctx context.Context, userID string) (*model.ListTagsResponse, error) {
+	tags, err := s.expenseRepo.GetTags(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.ListTagsResponse{Tags: tags}, nil
+}
+
 // DeleteExpense soft deletes an expense
 // Verifies ownership before deleting
 func (s *ExpenseService) DeleteExpense(ctx context.Context, expenseID, userID string) error {
@@ -332,8 +597,14 @@ func (s *ExpenseService) GetExpenseSummary(ctx context.Context, userID string, s
 		}
 	}
 
-	// Get summary from repository
-	byCategory, total, err := s.expenseRepo.GetTotalByCategory(ctx, userID, startDate, endDate)
+	filters := &model.ListExpensesRequest{}
+	if startDate != nil {
+		filters.StartDate = *startDate
+	}
+	if endDate != nil {
+		filters.EndDate = *endDate
+	}
+	summary, err := s.summarizeByCategory(ctx, userID, filters)
 	if err != nil {
 		return nil, err
 	}
@@ -355,10 +626,220 @@ func (s *ExpenseService) GetExpenseSummary(ctx context.Context, userID string, s
 		end = time.Now()
 	}
 
+	summary.StartDate = start
+	summary.EndDate = end
+	return summary, nil
+}
+
+// summarizeByCategory totals the expenses matching the (validated) list filters
+// by category, in the user's base currency. Dates of the response are left empty
+func (s *ExpenseService) summarizeByCategory(ctx context.Context, userID string, filters *model.ListExpensesRequest) (*model.ExpenseSummaryResponse, error) {
+	// Get totals per category, currency and date from repository
+	groups, err := s.expenseRepo.GetTotalByCategory(ctx, userID, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	// Convert into the user's base currency and add up per category
+	baseCurrency, err := s.currencyService.BaseCurrency(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	conv := s.currencyService.newConverter(baseCurrency)
+	converted, err := conv.convertGroups(ctx, groups)
+	if err != nil {
+		return nil, err
+	}
+
+	totals := make(map[string]money.Money)
+	counts := make(map[string]int)
+	var categories []string
+	grandTotal := conv.zero()
+	for i, group := range groups {
+		if _, ok := totals[group.Category]; !ok {
+			categories = append(categories, group.Category)
+			totals[group.Category] = conv.zero()
+		}
+		totals[group.Category] = totals[group.Category].Add(converted[i])
+		counts[group.Category] += group.Count
+		grandTotal = grandTotal.Add(converted[i])
+	}
+
+	// Highest total first
+	sort.SliceStable(categories, func(i, j int) bool {
+		return totals[categories[i]].Cmp(totals[categories[j]]) > 0
+	})
+
+	byCategory := make([]model.ExpenseSummaryItem, len(categories))
+	for i, category := range categories {
+		byCategory[i] = model.ExpenseSummaryItem{
+			Category: category,
+			Total:    totals[category],
+			Count:    counts[category],
+		}
+	}
+
 	return &model.ExpenseSummaryResponse{
-		StartDate:  start,
-		EndDate:    end,
-		Total:      total,
+		Currency:   baseCurrency,
+		Total:      grandTotal,
 		ByCategory: byCategory,
 	}, nil
 }
+
+// validateListFilters validates the filters of listing (and exporting) expenses
+// Tags are normalized and the tag match defaults to any
+func validateListFilters(filters *model.ListExpensesRequest) error {
+	// Validate date format if provided
+	if filters.StartDate != "" {
+		_, err := time.Parse("2006-01-02", filters.StartDate)
+		if err != nil {
+			return errors.New("start_date must be in YYYY-MM-DD format")
+		}
+	}
+
+	if filters.EndDate != "" {
+		_, err := time.Parse("2006-01-02", filters.EndDate)
+		if err != nil {
+			return errors.New("end_date must be in YYYY-MM-DD format")
+		}
+	}
+
+	// Validate date range
+	if filters.StartDate != "" && filters.EndDate != "" {
+		startDate, _ := time.Parse("2006-01-02", filters.StartDate)
+		endDate, _ := time.Parse("2006-01-02", filters.EndDate)
+		if startDate.After(endDate) {
+			return errors.New("start_date cannot be after end_date")
+		}
+	}
+
+	// Validate tag filter (default: expenses with any of the tags)
+	tags, err := normalizeTags(filters.Tags)
+	if err != nil {
+		return err
+	}
+	filters.Tags = tags
+
+	switch filters.TagMatch {
+	case "":
+		filters.TagMatch = model.TagMatchAny
+	case model.TagMatchAny, model.TagMatchAll:
+	default:
+		return errors.New("tag_match must be any or all")
+	}
+
+	// Validate search query
+	filters.Query = strings.TrimSpace(filters.Query)
+	if utf8.RuneCountInString(filters.Query) > maxSearchQueryLength {
+		return fmt.Errorf("q must be at most %d characters", maxSearchQueryLength)
+	}
+
+	// Validate amount range
+	var minAmount, maxAmount money.Money
+	if filters.MinAmount != "" {
+		amount, err := money.ParseAmount(filters.MinAmount, "")
+		if err != nil {
+			return errors.New("min_amount must be a positive number like 12.34")
+		}
+		minAmount = amount
+		filters.MinAmount = amount.String()
+	}
+
+	if filters.MaxAmount != "" {
+		amount, err := money.ParseAmount(filters.MaxAmount, "")
+		if err != nil {
+			return errors.New("max_amount must be a positive number like 12.34")
+		}
+		maxAmount = amount
+		filters.MaxAmount = amount.String()
+	}
+
+	if filters.MinAmount != "" && filters.MaxAmount != "" && minAmount.Cmp(maxAmount) > 0 {
+		return errors.New("min_amount cannot be greater than max_amount")
+	}
+
+	// Validate sorting (default: by relevance when searching, else by date, descending)
+	switch filters.Sort {
+	case "":
+		filters.Sort = model.SortDate
+		if filters.Query != "" {
+			filters.Sort = model.SortRelevance
+		}
+	case model.SortDate, model.SortAmount, model.SortCategory, model.SortCreatedAt:
+	case model.SortRelevance:
+		if filters.Query == "" {
+			return errors.New("q is required to sort by relevance")
+		}
+	default:
+		return errors.New("sort must be date, amount, category, created_at or relevance")
+	}
+
+	switch filters.Order {
+	case "":
+		filters.Order = model.SortDesc
+	case model.SortAsc, model.SortDesc:
+	default:
+		return errors.New("order must be asc or desc")
+	}
+
+	// Validate cursor (it only works with the sorting it was created for)
+	if filters.Cursor != "" {
+		cursor, err := pagination.DecodeCursor(filters.Cursor, repository.ExpenseCursorTypes(filters.Sort))
+		if err != nil {
+			return err
+		}
+		if cursor.Sort != filters.Sort || cursor.Order != filters.Order {
+			return errors.New("cursor cannot be used with a different sort or order")
+		}
+		filters.PageCursor = cursor
+	}
+
+	return nil
+}
+
+// highlightHTML escapes a search headline for HTML, keeping only the
+// <mark></mark> tags Postgres put around the search words
+func highlightHTML(headline string) string {
+	if headline == "" {
+		return ""
+	}
+
+	var b strings.Builder
+	for {
+		before, rest, found := strings.Cut(headline, "<mark>")
+		b.WriteString(html.EscapeString(before))
+		if !found {
+			return b.String()
+		}
+		word, after, _ := strings.Cut(rest, "</mark>")
+		b.WriteString("<mark>" + html.EscapeString(word) + "</mark>")
+		headline = after
+	}
+}
+
+// normalizeTags trims and lower-cases tags and removes duplicates
+// ("Travel" and "travel " are the same tag). Never returns nil
+func normalizeTags(tags []string) ([]string, error) {
+	if len(tags) > maxTagsPerExpense {
+		return nil, fmt.Errorf("tags must not have more than %d entries", maxTagsPerExpense)
+	}
+
+	normalized := []string{}
+	seen := make(map[string]bool)
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" {
+			return nil, errors.New("tags cannot be empty")
+		}
+		if utf8.RuneCountInString(tag) > maxTagLength {
+			return nil, fmt.Errorf("tags must be at most %d characters", maxTagLength)
+		}
+		if !seen[tag] {
+			seen[tag] = true
+			normalized = append(normalized, tag)
+		}
+	}
+
+	sort.Strings(normalized)
+	return normalized, nil
+}
@@ -0,0 +1,30 @@
+package service
+
+import "testing"
+
+func TestHighlightHTML(t *testing.T) {
+	tests := []struct {
+		name     string
+		headline string
+		want     string
+	}{
+		{"empty", "", ""},
+		{"no match", "Coffee", "Coffee"},
+		{"match", "Morning <mark>coffee</mark> run", "Morning <mark>coffee</mark> run"},
+		{"several matches", "<mark>Team</mark> <mark>lunch</mark>", "<mark>Team</mark> <mark>lunch</mark>"},
+		{"script in description", `<script>alert("x")</script> <mark>taxi</mark>`, "&lt;script&gt;alert(&#34;x&#34;)&lt;/script&gt; <mark>taxi</mark>"},
+		{"markup inside a match", "<mark><b>bold</b></mark>", "<mark>&lt;b&gt;bold&lt;/b&gt;</mark>"},
+		{"ampersand and quotes", "Tom & Jerry's <mark>diner</mark>", "Tom &amp; Jerry&#39;s <mark>diner</mark>"},
+		// A stop tag outside a match is text
+		{"stray closing tag", "a </mark> b", "a &lt;/mark&gt; b"},
+		{"unclosed match", "<mark>coffee", "<mark>coffee</mark>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := highlightHTML(tt.headline); got != tt.want {
+				t.Errorf("highlightHTML(%q) = %q, want %q", tt.headline, got, tt.want)
+			}
+		})
+	}
+}
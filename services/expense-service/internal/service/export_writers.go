@@ -0,0 +1,224 @@
+package service
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/csv"
+	"encoding/xml"
+	"expense-tracker/expense-service/internal/model"
+	"expense-tracker/shared/money"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// exportColumns are the columns of CSV and Excel exports
+var exportColumns = []string{"Date", "Description", "Category", "Tags", "Amount", "Currency"}
+
+// csvExpenseWriter writes expenses as CSV
+type csvExpenseWriter struct {
+	writer *csv.Writer
+	header bool // Whether the header was written
+}
+
+// newCSVExpenseWriter creates a CSV writer
+func newCSVExpenseWriter(w io.Writer) expenseWriter {
+	return &csvExpenseWriter{writer: csv.NewWriter(w)}
+}
+
+// writeExpense writes an expense as a CSV row
+func (c *csvExpenseWriter) writeExpense(expense *model.Expense) error {
+	if err := c.writeHeader(); err != nil {
+		return err
+	}
+
+	return c.writer.Write([]string{
+		expense.ExpenseDate.Format("2006-01-02"),
+		csvSafe(expense.Description),
+		csvSafe(expense.Category),
+		csvSafe(strings.Join(expense.Tags, ", ")),
+		expense.Amount.String(),
+		expense.Currency,
+	})
+}
+
+// close writes the header (if there were no expenses) and flushes
+func (c *csvExpenseWriter) close() error {
+	if err := c.writeHeader(); err != nil {
+		return err
+	}
+	c.writer.Flush()
+	return c.writer.Error()
+}
+
+// writeHeader writes the header row once
+func (c *csvExpenseWriter) writeHeader() error {
+	if c.header {
+		return nil
+	}
+	c.header = true
+	return c.writer.Write(exportColumns)
+}
+
+// csvSafe keeps spreadsheets from running user text as a formula
+// ("=HYPERLINK(...)" as a description) by prefixing it with a quote
+func csvSafe(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+// XLSX package parts (everything but the sheet, which is streamed)
+const (
+	xlsxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/><Default Extension="xml" ContentType="application/xml"/><Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/><Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/><Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/></Types>`
+
+	xlsxRootRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`
+
+	xlsxWorkbook = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="Expenses" sheetId="1" r:id="rId1"/></sheets></workbook>`
+
+	xlsxWorkbookRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/><Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/></Relationships>`
+
+	// Cell styles: 0 default, 1 date, 2 bold (header), 3-5 amounts with 0, 2 and 3 decimals
+	xlsxStyles = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><numFmts count="2"><numFmt numFmtId="164" formatCode="yyyy-mm-dd"/><numFmt numFmtId="165" formatCode="0.000"/></numFmts><fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts><fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills><borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders><cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs><cellXfs count="6"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/><xf numFmtId="164" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/><xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/><xf numFmtId="1" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/><xf numFmtId="2" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/><xf numFmtId="165" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/></cellXfs><cellStyles count="1"><cellStyle name="Normal" xfId="0" builtinId="0"/></cellStyles></styleSheet>`
+
+	xlsxSheetStart = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><cols><col min="1" max="1" width="12" customWidth="1"/><col min="2" max="2" width="40" customWidth="1"/><col min="3" max="4" width="20" customWidth="1"/><col min="5" max="5" width="14" customWidth="1"/><col min="6" max="6" width="10" customWidth="1"/></cols><sheetData>`
+
+	xlsxSheetEnd = `</sheetData></worksheet>`
+)
+
+// Excel XLSX cell styles (see xlsxStyles)
+const (
+	xlsxStyleDate = 1
+	xlsxStyleBold = 2
+)
+
+// excelEpoch is day 0 of Excel dates
+var excelEpoch = time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+
+// xlsxExpenseWriter writes expenses as an Excel workbook with one sheet
+// An XLSX file is a zip of XML parts; the sheet is streamed row by row into the zip
+type xlsxExpenseWriter struct {
+	zip   *zip.Writer
+	sheet *bufio.Writer
+	row   int // Rows written so far
+	err   error
+}
+
+// newXLSXExpenseWriter creates an Excel writer and writes the parts before the sheet
+func newXLSXExpenseWriter(w io.Writer) expenseWriter {
+	x := &xlsxExpenseWriter{zip: zip.NewWriter(w)}
+
+	for _, part := range []struct{ name, content string }{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRootRels},
+		{"xl/workbook.xml", xlsxWorkbook},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+		{"xl/styles.xml", xlsxStyles},
+	} {
+		if x.err = x.writePart(part.name, part.content); x.err != nil {
+			return x
+		}
+	}
+
+	sheet, err := x.zip.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		x.err = err
+		return x
+	}
+	x.sheet = bufio.NewWriter(sheet)
+	x.sheet.WriteString(xlsxSheetStart)
+
+	x.startRow()
+	for _, column := range exportColumns {
+		x.stringCell(column, xlsxStyleBold)
+	}
+	x.endRow()
+
+	return x
+}
+
+// writeExpense writes an expense as a row
+func (x *xlsxExpenseWriter) writeExpense(expense *model.Expense) error {
+	if x.err != nil {
+		return x.err
+	}
+
+	x.startRow()
+	x.numberCell(fmt.Sprint(int(expense.ExpenseDate.Sub(excelEpoch).Hours()/24)), xlsxStyleDate)
+	x.stringCell(expense.Description, 0)
+	x.stringCell(expense.Category, 0)
+	x.stringCell(strings.Join(expense.Tags, ", "), 0)
+	x.numberCell(expense.Amount.String(), xlsxAmountStyle(expense.Currency))
+	x.stringCell(expense.Currency, 0)
+	x.endRow()
+
+	return x.err
+}
+
+// close ends the sheet and the zip
+func (x *xlsxExpenseWriter) close() error {
+	if x.err != nil {
+		return x.err
+	}
+
+	x.sheet.WriteString(xlsxSheetEnd)
+	if err := x.sheet.Flush(); err != nil {
+		return err
+	}
+	return x.zip.Close()
+}
+
+// writePart adds a complete part to the zip
+func (x *xlsxExpenseWriter) writePart(name, content string) error {
+	part, err := x.zip.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(part, content)
+	return err
+}
+
+// startRow starts the next row
+func (x *xlsxExpenseWriter) startRow() {
+	x.row++
+	fmt.Fprintf(x.sheet, `<row r="%d">`, x.row)
+}
+
+// endRow ends a row (write errors of the buffered sheet surface here)
+func (x *xlsxExpenseWriter) endRow() {
+	if _, err := x.sheet.WriteString(`</row>`); err != nil {
+		x.err = err
+	}
+}
+
+// stringCell writes a text cell (inline, so no shared strings part is needed)
+func (x *xlsxExpenseWriter) stringCell(value string, style int) {
+	fmt.Fprintf(x.sheet, `<c t="inlineStr" s="%d"><is><t xml:space="preserve">`, style)
+	xml.EscapeText(x.sheet, []byte(value))
+	x.sheet.WriteString(`</t></is></c>`)
+}
+
+// numberCell writes a number cell
+func (x *xlsxExpenseWriter) numberCell(value string, style int) {
+	fmt.Fprintf(x.sheet, `<c s="%d"><v>%s</v></c>`, style, value)
+}
+
+// xlsxAmountStyle returns the cell style showing the currency's decimal places
+func xlsxAmountStyle(currency string) int {
+	switch money.CurrencyScale(currency) {
+	case 0:
+		return 3
+	case 3:
+		return 5
+	default:
+		return 4
+	}
+}
@@ -0,0 +1,337 @@
+package service
+
+import (
+	"bytes"
+	"expense-tracker/expense-service/internal/model"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// A4 page layout in points (1/72 inch)
+const (
+	pdfPageWidth  = 595
+	pdfPageHeight = 842
+	pdfMargin     = 50
+	pdfLineHeight = 16
+	pdfFontSize   = 10
+)
+
+// Columns of the expense table (x positions; the amount is right-aligned at pdfAmountRight)
+const (
+	pdfDateX        = pdfMargin
+	pdfDescriptionX = pdfMargin + 70
+	pdfCategoryX    = pdfMargin + 290
+	pdfAmountRight  = pdfPageWidth - pdfMargin - 40
+	pdfCurrencyX    = pdfPageWidth - pdfMargin - 32
+)
+
+// PDF object numbers of the parts written before the pages
+const (
+	pdfCatalogObject  = 1
+	pdfPagesObject    = 2 // Written last, when all pages are known
+	pdfFontObject     = 3
+	pdfBoldFontObject = 4
+	pdfFirstPageObj   = 5
+)
+
+// helveticaWidths are the widths of the printable ASCII characters (32-126)
+// in Helvetica, in 1/1000 of the font size
+var helveticaWidths = [95]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
+
+// pdfReport is the heading and category summary of a PDF export
+type pdfReport struct {
+	period    string // e.g. "2024-01-01 to 2024-03-31"
+	filters   string // Category and tag filters ("" if none)
+	generated time.Time
+	summary   *model.ExpenseSummaryResponse
+}
+
+// pdfExpenseWriter writes expenses as a PDF report: a summary by category
+// followed by a table of the expenses
+//
+// It writes a minimal PDF 1.4 file with the standard Helvetica fonts (no
+// embedded fonts, so text is limited to Latin-1). Each page is written as
+// soon as it is full; the page tree and cross-reference table come last
+type pdfExpenseWriter struct {
+	w       *countingWriter
+	offsets map[int]int64 // Object number -> file offset
+	pages   []int         // Object numbers of the pages
+	nextObj int
+
+	page *bytes.Buffer // Content of the current page
+	y    int           // Baseline of the next line on the current page
+	err  error
+}
+
+// newPDFExpenseWriter creates a PDF writer and writes the report's first page up to the expense table
+func newPDFExpenseWriter(w io.Writer, report *pdfReport) expenseWriter {
+	p := &pdfExpenseWriter{
+		w:       &countingWriter{w: w},
+		offsets: make(map[int]int64),
+		nextObj: pdfFirstPageObj,
+	}
+
+	io.WriteString(p.w, "%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	p.writeObject(pdfCatalogObject, fmt.Sprintf("<< /Type /Catalog /Pages %d 0 R >>", pdfPagesObject))
+	p.writeObject(pdfFontObject, "<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	p.writeObject(pdfBoldFontObject, "<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+
+	p.newPage()
+	p.text(pdfMargin, 16, true, "Expense Report")
+	p.y -= 10
+	p.text(pdfMargin, pdfFontSize, false, "Period: "+report.period)
+	if report.filters != "" {
+		p.text(pdfMargin, pdfFontSize, false, "Filters: "+report.filters)
+	}
+	p.text(pdfMargin, pdfFontSize, false, "Generated: "+report.generated.Format("2006-01-02 15:04 MST"))
+	p.y -= pdfLineHeight
+
+	// Summary by category (in the base currency)
+	summary := report.summary
+	p.text(pdfMargin, 12, true, "Summary by category ("+summary.Currency+")")
+	p.summaryRow(true, "Category", "Expenses", "Total")
+	count := 0
+	for _, item := range summary.ByCategory {
+		p.summaryRow(false, item.Category, fmt.Sprint(item.Count), item.Total.String())
+		count += item.Count
+	}
+	p.summaryRow(true, "Total", fmt.Sprint(count), summary.Total.String())
+	p.y -= pdfLineHeight
+
+	// The table starts on the next page if not even its first row fits
+	if p.y < pdfMargin+3*pdfLineHeight {
+		p.endPage()
+		p.newPage()
+	}
+	p.text(pdfMargin, 12, true, "Expenses")
+	p.tableHeader()
+
+	return p
+}
+
+// writeExpense writes an expense as a table row, starting a new page when the current one is full
+func (p *pdfExpenseWriter) writeExpense(expense *model.Expense) error {
+	if p.err != nil {
+		return p.err
+	}
+
+	if p.pageFull() {
+		p.tableHeader()
+	}
+
+	p.row(false,
+		expense.ExpenseDate.Format("2006-01-02"),
+		expense.Description,
+		expense.Category,
+		expense.Amount.String(),
+		expense.Currency,
+	)
+
+	return p.err
+}
+
+// close writes the last page, the page tree and the cross-reference table
+func (p *pdfExpenseWriter) close() error {
+	if p.err != nil {
+		return p.err
+	}
+	p.endPage()
+
+	kids := make([]string, len(p.pages))
+	for i, page := range p.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", page)
+	}
+	p.writeObject(pdfPagesObject, fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(p.pages)))
+	if p.err != nil {
+		return p.err
+	}
+
+	// Cross-reference table: the offset of every object
+	xref := p.w.n
+	fmt.Fprintf(p.w, "xref\n0 %d\n0000000000 65535 f \n", p.nextObj)
+	for obj := 1; obj < p.nextObj; obj++ {
+		fmt.Fprintf(p.w, "%010d 00000 n \n", p.offsets[obj])
+	}
+	fmt.Fprintf(p.w, "trailer\n<< /Size %d /Root %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", p.nextObj, pdfCatalogObject, xref)
+
+	return p.w.err
+}
+
+// newPage starts a page
+func (p *pdfExpenseWriter) newPage() {
+	p.page = &bytes.Buffer{}
+	p.y = pdfPageHeight - pdfMargin
+}
+
+// endPage writes the current page (its content stream and the page object)
+func (p *pdfExpenseWriter) endPage() {
+	// Page number at the bottom
+	number := fmt.Sprintf("Page %d", len(p.pages)+1)
+	p.y = pdfMargin / 2
+	p.text(pdfPageWidth-pdfMargin-textWidth(number, pdfFontSize), pdfFontSize, false, number)
+
+	contentObj := p.nextObj
+	pageObj := p.nextObj + 1
+	p.nextObj += 2
+
+	p.writeObject(contentObj, fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", p.page.Len(), p.page.Bytes()))
+	p.writeObject(pageObj, fmt.Sprintf(
+		"<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 %d 0 R /F2 %d 0 R >> >> /Contents %d 0 R >>",
+		pdfPagesObject, pdfPageWidth, pdfPageHeight, pdfFontObject, pdfBoldFontObject, contentObj,
+	))
+	p.pages = append(p.pages, pageObj)
+}
+
+// pageFull starts a new page if the current one has no room for another line
+// Reports whether it did
+func (p *pdfExpenseWriter) pageFull() bool {
+	if p.y >= pdfMargin+pdfLineHeight {
+		return false
+	}
+	p.endPage()
+	p.newPage()
+	return true
+}
+
+// summaryRow writes a row of the category summary: category, count and right-aligned total
+func (p *pdfExpenseWriter) summaryRow(bold bool, category, count, total string) {
+	p.pageFull()
+	y := p.y
+	p.cell(pdfDateX, y, bold, truncateText(category, pdfCategoryX-pdfDateX-10))
+	p.cell(pdfCategoryX, y, bold, count)
+	p.cell(pdfAmountRight-textWidth(total, pdfFontSize), y, bold, total)
+	p.y -= pdfLineHeight
+}
+
+// tableHeader writes the header of the expense table
+func (p *pdfExpenseWriter) tableHeader() {
+	p.row(true, "Date", "Description", "Category", "Amount", "")
+}
+
+// row writes a row of the expense table: date, description, category,
+// right-aligned amount and currency
+func (p *pdfExpenseWriter) row(bold bool, date, description, category, amount, currency string) {
+	y := p.y
+	p.cell(pdfDateX, y, bold, date)
+	p.cell(pdfDescriptionX, y, bold, truncateText(description, pdfCategoryX-pdfDescriptionX-10))
+	p.cell(pdfCategoryX, y, bold, truncateText(category, pdfAmountRight-pdfCategoryX-80))
+	p.cell(pdfAmountRight-textWidth(amount, pdfFontSize), y, bold, amount)
+	if currency != "" {
+		p.cell(pdfCurrencyX, y, bold, currency)
+	}
+	p.y -= pdfLineHeight
+}
+
+// text writes a line of text and moves down a line
+func (p *pdfExpenseWriter) text(x, size int, bold bool, s string) {
+	font := "F1"
+	if bold {
+		font = "F2"
+	}
+	fmt.Fprintf(p.page, "BT /%s %d Tf %d %d Td (%s) Tj ET\n", font, size, x, p.y, pdfString(s))
+	p.y -= pdfLineHeight + size - pdfFontSize
+}
+
+// cell writes text at a position without moving down
+func (p *pdfExpenseWriter) cell(x, y int, bold bool, s string) {
+	font := "F1"
+	if bold {
+		font = "F2"
+	}
+	fmt.Fprintf(p.page, "BT /%s %d Tf %d %d Td (%s) Tj ET\n", font, pdfFontSize, x, y, pdfString(s))
+}
+
+// writeObject writes an indirect object and records its offset
+func (p *pdfExpenseWriter) writeObject(obj int, body string) {
+	if p.err != nil {
+		return
+	}
+	p.offsets[obj] = p.w.n
+	fmt.Fprintf(p.w, "%d 0 obj\n%s\nendobj\n", obj, body)
+	p.err = p.w.err
+}
+
+// pdfString encodes text for a PDF string in WinAnsiEncoding
+// Characters outside Latin-1 become "?" - exports with such text are rejected
+// before anything is written (see ExpenseExportService.checkPDFText)
+func pdfString(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r >= 32 && r <= 126:
+			b.WriteRune(r)
+		case r >= 160 && r <= 255:
+			fmt.Fprintf(&b, "\\%03o", r)
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
+
+// isLatin1 reports whether pdfString can encode all of s
+func isLatin1(s string) bool {
+	for _, r := range s {
+		if r > 255 {
+			return false
+		}
+	}
+	return true
+}
+
+// textWidth returns the width of text in Helvetica in points
+func textWidth(s string, size int) int {
+	width := 0
+	for _, r := range s {
+		if r >= 32 && r <= 126 {
+			width += helveticaWidths[r-32]
+		} else {
+			width += 556
+		}
+	}
+	return width * size / 1000
+}
+
+// truncateText shortens text to fit into a width (in points), ending it with "..."
+func truncateText(s string, width int) string {
+	if textWidth(s, pdfFontSize) <= width {
+		return s
+	}
+	for len(s) > 0 && textWidth(s+"...", pdfFontSize) > width {
+		_, size := utf8.DecodeLastRuneInString(s)
+		s = s[:len(s)-size]
+	}
+	return s + "..."
+}
+
+// countingWriter counts the bytes written (for the PDF's object offsets)
+// and keeps the first error
+type countingWriter struct {
+	w   io.Writer
+	n   int64
+	err error
+}
+
+// Write writes to the underlying writer unless an earlier write failed
+func (c *countingWriter) Write(b []byte) (int, error) {
+	if c.err != nil {
+		return 0, c.err
+	}
+	n, err := c.w.Write(b)
+	c.n += int64(n)
+	c.err = err
+	return n, err
+}
@@ -0,0 +1,31 @@
+package service
+
+import "testing"
+
+func TestPDFString(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		want   string
+		latin1 bool
+	}{
+		{"plain", "Coffee 3.50", "Coffee 3.50", true},
+		{"parentheses and backslash", `a (b) \c`, `a \(b\) \\c`, true},
+		{"latin-1 accents", "Café", `Caf\351`, true},
+		{"non-breaking space", "a b", `a\240b`, true},
+		{"control character", "a\tb", "a?b", true},
+		{"cyrillic", "Кофе", "????", false},
+		{"emoji", "Pizza 🍕", "Pizza ?", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := pdfString(tt.input); got != tt.want {
+				t.Errorf("pdfString(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+			if got := isLatin1(tt.input); got != tt.latin1 {
+				t.Errorf("isLatin1(%q) = %v, want %v", tt.input, got, tt.latin1)
+			}
+		})
+	}
+}
@@ -0,0 +1,449 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"expense-tracker/expense-service/internal/model"
+	"expense-tracker/expense-service/internal/repository"
+	"expense-tracker/shared/money"
+	"log"
+	"strings"
+	"time"
+)
+
+// recurringBatchSize is how many due rules are handled per query
+const recurringBatchSize = 100
+
+// maxOccurrencesPerRun is how many occurrences of one rule are created at a time
+// A rule starting in the past gets the rest on the next scheduler runs, so a
+// request never creates hundreds of expenses
+const maxOccurrencesPerRun = 31
+
+// maxRecurringBackfill is how far in the past a rule may start (in years)
+// Every occurrence since the start date is created, so this bounds the backfill
+const maxRecurringBackfill = 1
+
+// RecurringExpenseService manages recurring expense rules and creates
+// the expenses for their occurrences once they are due
+type RecurringExpenseService struct {
+	repo            repository.RecurringExpenseRepository
+	expenseRepo     repository.ExpenseRepository
+	currencyService *CurrencyService
+	categoryService *CategoryService
+	eventPublisher  *EventPublisher // Optional - can be nil if not configured
+	budgetService   *BudgetService  // Optional - budget alerts are not checked if nil
+	interval        time.Duration
+}
+
+// NewRecurringExpenseService creates a new recurring expense service
+// interval is how often the scheduler (Run) looks for due occurrences
+func NewRecurringExpenseService(repo repository.RecurringExpenseRepository, expenseRepo repository.ExpenseRepository, currencyService *CurrencyService, categoryService *CategoryService, interval time.Duration) *RecurringExpenseService {
+	return &RecurringExpenseService{
+		repo:            repo,
+		expenseRepo:     expenseRepo,
+		currencyService: currencyService,
+		categoryService: categoryService,
+		interval:        interval,
+	}
+}
+
+// SetEventPublisher sets the event publisher (optional)
+func (s *RecurringExpenseService) SetEventPublisher(publisher *EventPublisher) {
+	s.eventPublisher = publisher
+}
+
+// SetBudgetService enables budget threshold alerts for created occurrences (optional)
+func (s *RecurringExpenseService) SetBudgetService(budgetService *BudgetService) {
+	s.budgetService = budgetService
+}
+
+// CreateRecurringExpense creates a new recurring expense for a user
+// Occurrences that are already due (start date today or earlier) are created right away
+func (s *RecurringExpenseService) CreateRecurringExpense(ctx context.Context, userID string, req *model.CreateRecurringExpenseRequest) (*model.RecurringExpense, error) {
+	// Validate currency (default: the user's base currency)
+	currency, err := s.currencyService.ExpenseCurrency(ctx, userID, req.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	amount, err := money.ParseAmount(req.Amount, currency)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateRecurringFields(req.Description, req.Category, req.Frequency, req.DayOfMonth); err != nil {
+		return nil, err
+	}
+
+	// The category must be in the user's catalog
+	category, err := s.categoryService.ResolveCategory(ctx, userID, req.Category)
+	if err != nil {
+		return nil, err
+	}
+
+	startDate, err := parseStartDate(req.StartDate)
+	if err != nil {
+		return nil, err
+	}
+
+	var endDate *time.Time
+	if req.EndDate != nil && *req.EndDate != "" {
+		parsed, err := time.Parse("2006-01-02", *req.EndDate)
+		if err != nil {
+			return nil, errors.New("end_date must be in YYYY-MM-DD format")
+		}
+		if parsed.Before(startDate) {
+			return nil, errors.New("end_date cannot be before start_date")
+		}
+		endDate = &parsed
+	}
+
+	rule := model.NewRecurringExpense(userID, userEmailFrom(ctx), amount, currency, req.Description, category,
+		req.Frequency, req.DayOfMonth, startDate, endDate)
+
+	if err := s.repo.Create(ctx, rule); err != nil {
+		return nil, err
+	}
+
+	s.createDueOccurrencesNow(ctx, rule)
+
+	return rule, nil
+}
+
+// GetRecurringExpense retrieves a single recurring expense by ID
+// Verifies ownership (user can only access their own recurring expenses)
+func (s *RecurringExpenseService) GetRecurringExpense(ctx context.Context, ruleID, userID string) (*model.RecurringExpense, error) {
+	rule, err := s.repo.FindByID(ctx, ruleID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if rule == nil {
+		return nil, errors.New("recurring expense not found")
+	}
+
+	return rule, nil
+}
+
+// ListRecurringExpenses retrieves all recurring expenses of a user
+func (s *RecurringExpenseService) ListRecurringExpenses(ctx context.Context, userID string) (*model.ListRecurringExpensesResponse, error) {
+	rules, err := s.repo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.ListRecurringExpensesResponse{
+		RecurringExpenses: rules,
+		Total:             len(rules),
+	}, nil
+}
+
+// UpdateRecurringExpense updates a recurring expense
+// Changes apply to occurrences from the next one on - expenses already created are not touched
+func (s *RecurringExpenseService) UpdateRecurringExpense(ctx context.Context, ruleID, userID string, req *model.UpdateRecurringExpenseRequest) (*model.RecurringExpense, error) {
+	rule, err := s.repo.FindByID(ctx, ruleID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if rule == nil {
+		return nil, errors.New("recurring expense not found")
+	}
+
+	// Update fields if provided
+	if req.Currency != nil {
+		currency, err := s.currencyService.ExpenseCurrency(ctx, userID, *req.Currency)
+		if err != nil {
+			return nil, err
+		}
+		rule.Currency = currency
+	}
+	// The amount is checked again when only the currency changes
+	if req.Amount != nil || req.Currency != nil {
+		amountStr := rule.Amount.String()
+		if req.Amount != nil {
+			amountStr = *req.Amount
+		}
+		amount, err := money.ParseAmount(amountStr, rule.Currency)
+		if err != nil {
+			return nil, err
+		}
+		rule.Amount = amount
+	}
+	if req.Description != nil {
+		rule.Description = *req.Description
+	}
+	if req.Category != nil {
+		rule.Category = *req.Category
+	}
+	if req.Frequency != nil {
+		rule.Frequency = *req.Frequency
+		// A day of the month only makes sense for monthly rules
+		if rule.Frequency != model.FrequencyMonthly && req.DayOfMonth == nil {
+			rule.DayOfMonth = nil
+		}
+	}
+	if req.DayOfMonth != nil {
+		rule.DayOfMonth = req.DayOfMonth
+	}
+
+	if err := validateRecurringFields(rule.Description, rule.Category, rule.Frequency, rule.DayOfMonth); err != nil {
+		return nil, err
+	}
+
+	if req.Category != nil {
+		category, err := s.categoryService.ResolveCategory(ctx, userID, rule.Category)
+		if err != nil {
+			return nil, err
+		}
+		rule.Category = category
+	}
+
+	if req.StartDate != nil {
+		startDate, err := parseStartDate(*req.StartDate)
+		if err != nil {
+			return nil, err
+		}
+		rule.StartDate = startDate
+	}
+
+	if req.EndDate != nil {
+		if *req.EndDate == "" {
+			rule.EndDate = nil
+		} else {
+			endDate, err := time.Parse("2006-01-02", *req.EndDate)
+			if err != nil {
+				return nil, errors.New("end_date must be in YYYY-MM-DD format")
+			}
+			rule.EndDate = &endDate
+		}
+	}
+
+	if rule.EndDate != nil && rule.EndDate.Before(rule.StartDate) {
+		return nil, errors.New("end_date cannot be before start_date")
+	}
+
+	// The schedule may have changed - continue after the last created occurrence
+	rule.ScheduleNext()
+	if email := userEmailFrom(ctx); email != "" {
+		rule.UserEmail = email
+	}
+	rule.UpdatedAt = time.Now()
+
+	if err := s.repo.Update(ctx, rule); err != nil {
+		return nil, err
+	}
+
+	s.createDueOccurrencesNow(ctx, rule)
+
+	return rule, nil
+}
+
+// DeleteRecurringExpense soft deletes a recurring expense
+// No more expenses are created for it; the ones already created are kept
+func (s *RecurringExpenseService) DeleteRecurringExpense(ctx context.Context, ruleID, userID string) error {
+	rule, err := s.repo.FindByID(ctx, ruleID, userID)
+	if err != nil {
+		return err
+	}
+
+	if rule == nil {
+		return errors.New("recurring expense not found")
+	}
+
+	return s.repo.Delete(ctx, ruleID, userID)
+}
+
+// Run creates due occurrences periodically until ctx is cancelled
+// Start it in a goroutine - it also runs once right away, to catch up after downtime
+func (s *RecurringExpenseService) Run(ctx context.Context) {
+	if err := s.CreateDueExpenses(ctx); err != nil {
+		log.Printf("Warning: failed to create recurring expenses: %v (will retry)", err)
+	}
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.CreateDueExpenses(ctx); err != nil {
+				log.Printf("Warning: failed to create recurring expenses: %v (will retry)", err)
+			}
+		}
+	}
+}
+
+// CreateDueExpenses creates the expenses for every occurrence that is due (today or earlier)
+func (s *RecurringExpenseService) CreateDueExpenses(ctx context.Context) error {
+	for {
+		rules, err := s.repo.FindDue(ctx, today(), recurringBatchSize)
+		if err != nil {
+			return err
+		}
+
+		progressed := false
+		for _, rule := range rules {
+			advanced, err := s.createDueOccurrences(ctx, rule)
+			if err != nil {
+				return err
+			}
+			progressed = progressed || advanced
+		}
+
+		// Rules changed meanwhile can still be due - they are picked up on
+		// the next run, so stop instead of fetching them again and again
+		if len(rules) < recurringBatchSize || !progressed {
+			return nil
+		}
+	}
+}
+
+// createDueOccurrencesNow creates a new or changed rule's due occurrences
+// right away instead of on the next scheduler run
+// Failures are only logged: the scheduler retries them
+func (s *RecurringExpenseService) createDueOccurrencesNow(ctx context.Context, rule *model.RecurringExpense) {
+	if _, err := s.createDueOccurrences(ctx, rule); err != nil {
+		log.Printf("Warning: failed to create due occurrences of recurring expense %s: %v (will retry)", rule.ID, err)
+	}
+}
+
+// createDueOccurrences creates an expense for each due occurrence of the rule, oldest first
+// (at most maxOccurrencesPerRun). Returns whether the rule was moved on at all
+//
+// Every occurrence is created exactly once, even if the scheduler runs on
+// several replicas or crashes halfway: the expense ID is derived from the rule
+// and the date, so a second Create fails with "already exists", and Advance
+// only moves the rule on if nobody else did
+//
+// Occurrences dated before the rule was created or last changed are a backfill
+// (the start date is in the past): they are recorded without notifications or
+// budget alerts, which would all arrive at once and describe past spending
+func (s *RecurringExpenseService) createDueOccurrences(ctx context.Context, rule *model.RecurringExpense) (bool, error) {
+	today := today()
+	scheduledOn := dateOf(rule.UpdatedAt)
+	advanced := false
+
+	for created := 0; created < maxOccurrencesPerRun && rule.NextOccurrence != nil && !rule.NextOccurrence.After(today); created++ {
+		occurrence := *rule.NextOccurrence
+
+		expense := rule.NewOccurrence(occurrence)
+		err := s.expenseRepo.Create(ctx, expense)
+		if err != nil && !strings.Contains(err.Error(), "already exists") {
+			return advanced, err
+		}
+		if err == nil {
+			log.Printf("Created expense %s for recurring expense %s (%s)", expense.ID, rule.ID, occurrence.Format("2006-01-02"))
+			if !occurrence.Before(scheduledOn) {
+				s.publishOccurrenceCreated(ctx, rule, expense)
+				if s.budgetService != nil {
+					s.budgetService.CheckThresholds(ctx, rule.UserID, rule.UserEmail, expense)
+				}
+			}
+		}
+
+		rule.LastOccurrence = &occurrence
+		rule.ScheduleNext()
+
+		ok, err := s.repo.Advance(ctx, rule, occurrence)
+		if err != nil {
+			return advanced, err
+		}
+		if !ok {
+			// Deleted, rescheduled or advanced by another replica meanwhile
+			return advanced, nil
+		}
+		advanced = true
+	}
+
+	return advanced, nil
+}
+
+// publishOccurrenceCreated publishes expense.created for an occurrence
+// Same event as POST /expenses, flagged as recurring
+func (s *RecurringExpenseService) publishOccurrenceCreated(ctx context.Context, rule *model.RecurringExpense, expense *model.Expense) {
+	if s.eventPublisher == nil {
+		return
+	}
+
+	event := &Event{
+		EventType: "expense.created",
+		UserID:    expense.UserID,
+		UserEmail: rule.UserEmail,
+		Timestamp: time.Now(),
+		Data: map[string]interface{}{
+			"expense_id":           expense.ID,
+			"amount":               expense.Amount.String(),
+			"currency":             expense.Currency,
+			"description":          expense.Description,
+			"category":             expense.Category,
+			"expense_date":         expense.ExpenseDate.Format("2006-01-02"),
+			"recurring":            true,
+			"recurring_expense_id": rule.ID,
+		},
+	}
+	s.eventPublisher.PublishEventAsync(ctx, event)
+}
+
+// validateRecurringFields validates the fields shared by create and update
+// (the amount is parsed with the currency)
+func validateRecurringFields(description, category, frequency string, dayOfMonth *int) error {
+	if description == "" {
+		return errors.New("description is required")
+	}
+
+	if category == "" {
+		return errors.New("category is required")
+	}
+
+	switch frequency {
+	case model.FrequencyDaily, model.FrequencyWeekly, model.FrequencyMonthly, model.FrequencyYearly:
+	case "":
+		return errors.New("frequency is required")
+	default:
+		return errors.New("frequency must be daily, weekly, monthly or yearly")
+	}
+
+	if dayOfMonth != nil {
+		if frequency != model.FrequencyMonthly {
+			return errors.New("day_of_month must only be set for monthly recurring expenses")
+		}
+		if *dayOfMonth < 1 || *dayOfMonth > 31 {
+			return errors.New("day_of_month must be between 1 and 31")
+		}
+	}
+
+	return nil
+}
+
+// userEmailFrom returns the user's email from the context (set by auth middleware)
+func userEmailFrom(ctx context.Context) string {
+	email, _ := ctx.Value("user_email").(string)
+	return email
+}
+
+// parseStartDate parses a rule's start date (YYYY-MM-DD)
+// It may be at most maxRecurringBackfill years in the past
+func parseStartDate(value string) (time.Time, error) {
+	startDate, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, errors.New("start_date must be in YYYY-MM-DD format")
+	}
+	if startDate.Before(today().AddDate(-maxRecurringBackfill, 0, 0)) {
+		return time.Time{}, errors.New("start_date cannot be more than a year in the past")
+	}
+	return startDate, nil
+}
+
+// today returns today's date (midnight UTC, like dates read from the database)
+func today() time.Time {
+	return dateOf(time.Now())
+}
+
+// dateOf returns the date of t (midnight UTC, like dates read from the database)
+func dateOf(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
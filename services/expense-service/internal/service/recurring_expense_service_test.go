@@ -0,0 +1,29 @@
+package service
+
+import (
+	"testing"
+)
+
+func TestParseStartDate(t *testing.T) {
+	now := today()
+
+	tests := []struct {
+		name    string
+		value   string
+		wantErr bool
+	}{
+		{"today", now.Format("2006-01-02"), false},
+		{"future", now.AddDate(0, 2, 0).Format("2006-01-02"), false},
+		{"a month ago", now.AddDate(0, -1, 0).Format("2006-01-02"), false},
+		{"exactly the limit", now.AddDate(-maxRecurringBackfill, 0, 0).Format("2006-01-02"), false},
+		{"past the limit", now.AddDate(-maxRecurringBackfill, 0, -1).Format("2006-01-02"), true},
+		{"decades ago", "1970-01-01", true},
+		{"wrong format", "01/02/2024", true},
+	}
+
+	for _, tt := range tests {
+		if _, err := parseStartDate(tt.value); (err != nil) != tt.wantErr {
+			t.Errorf("%s: parseStartDate(%q) error = %v, wantErr %v", tt.name, tt.value, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,319 @@
+package service
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"expense-tracker/expense-service/internal/model"
+	"fmt"
+	"html"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// csvDateFormats maps the date formats of a CSV mapping to Go layouts
+// The layouts accept days and months with or without a leading zero
+var csvDateFormats = map[string]string{
+	"YYYY-MM-DD": "2006-1-2",
+	"YYYY/MM/DD": "2006/1/2",
+	"DD/MM/YYYY": "2/1/2006",
+	"MM/DD/YYYY": "1/2/2006",
+	"DD.MM.YYYY": "2.1.2006",
+	"DD-MM-YYYY": "2-1-2006",
+	"MM-DD-YYYY": "1-2-2006",
+}
+
+// statementRow is a transaction read from an imported file
+type statementRow struct {
+	row int
+
+	// Fields as create request values: a plain positive amount, a YYYY-MM-DD date
+	expense model.CreateExpenseRequest
+
+	// Set instead if the row can't be imported (status is invalid or skipped)
+	status string
+	err    string
+}
+
+// invalid marks the row as invalid
+func (r *statementRow) invalid(format string, args ...interface{}) {
+	r.status = model.ImportRowInvalid
+	r.err = fmt.Sprintf(format, args...)
+}
+
+// skip marks the row as not spending
+func (r *statementRow) skip(reason string) {
+	r.status = model.ImportRowSkipped
+	r.err = reason
+}
+
+// parseCSVStatement reads the rows of a CSV file with a header line
+// Errors are about the file as a whole (the header or the mapping); problems
+// with single rows are returned on the rows
+func parseCSVStatement(data []byte, mapping *model.CSVColumnMapping) ([]*statementRow, error) {
+	if mapping == nil {
+		return nil, errors.New("mapping is required for csv imports")
+	}
+
+	dateFormat := "YYYY-MM-DD"
+	if mapping.DateFormat != "" {
+		dateFormat = strings.ToUpper(mapping.DateFormat)
+	}
+	layout, ok := csvDateFormats[dateFormat]
+	if !ok {
+		return nil, errors.New("mapping date_format must be YYYY-MM-DD, YYYY/MM/DD, DD/MM/YYYY, MM/DD/YYYY, DD.MM.YYYY, DD-MM-YYYY or MM-DD-YYYY")
+	}
+
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")))) // Excel writes a BOM
+	reader.FieldsPerRecord = -1                                                              // Checked per row
+	reader.TrimLeadingSpace = true
+	if mapping.Delimiter != "" {
+		delimiter, size := utf8.DecodeRuneInString(mapping.Delimiter)
+		if size != len(mapping.Delimiter) || delimiter == '"' || delimiter == '\r' || delimiter == '\n' {
+			return nil, errors.New("mapping delimiter must be a single character")
+		}
+		reader.Comma = delimiter
+	}
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, errors.New("file cannot be empty")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("file must be a valid CSV file: %v", err)
+	}
+
+	columns := make(map[string]int)
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if _, ok := columns[name]; !ok {
+			columns[name] = i
+		}
+	}
+
+	// column finds a mapped column (-1 for optional columns that aren't mapped)
+	column := func(field, name string, required bool) (int, error) {
+		if name == "" {
+			if required {
+				return -1, fmt.Errorf("mapping %s is required", field)
+			}
+			return -1, nil
+		}
+		index, ok := columns[strings.ToLower(strings.TrimSpace(name))]
+		if !ok {
+			return -1, fmt.Errorf("mapping %s must be a column of the file (%q not found)", field, name)
+		}
+		return index, nil
+	}
+
+	dateCol, err := column("date", mapping.Date, true)
+	if err != nil {
+		return nil, err
+	}
+	amountCol, err := column("amount", mapping.Amount, true)
+	if err != nil {
+		return nil, err
+	}
+	descriptionCol, err := column("description", mapping.Description, true)
+	if err != nil {
+		return nil, err
+	}
+	categoryCol, err := column("category", mapping.Category, false)
+	if err != nil {
+		return nil, err
+	}
+	currencyCol, err := column("currency", mapping.Currency, false)
+	if err != nil {
+		return nil, err
+	}
+
+	// The last column a row needs
+	lastCol := dateCol
+	if amountCol > lastCol {
+		lastCol = amountCol
+	}
+	if descriptionCol > lastCol {
+		lastCol = descriptionCol
+	}
+
+	var rows []*statementRow
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		// A broken quote throws off every row after it
+		if err != nil {
+			return nil, fmt.Errorf("file must be a valid CSV file: %v", err)
+		}
+
+		// Blank lines are skipped by the reader, lines of empty cells aren't
+		if strings.TrimSpace(strings.Join(record, "")) == "" {
+			continue
+		}
+
+		line, _ := reader.FieldPos(0)
+		row := &statementRow{row: line}
+		rows = append(rows, row)
+
+		// field returns a mapped cell ("" for unmapped columns)
+		field := func(index int) string {
+			if index < 0 || index >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[index])
+		}
+
+		if len(record) <= lastCol {
+			row.invalid("row must have %d columns like the header", len(header))
+			continue
+		}
+
+		date, err := time.Parse(layout, field(dateCol))
+		if err != nil {
+			row.invalid("date %q must be in %s format", field(dateCol), dateFormat)
+			continue
+		}
+
+		amount, negative, err := normalizeStatementAmount(field(amountCol), mapping.DecimalComma)
+		if err != nil {
+			row.invalid("%v", err)
+			continue
+		}
+		if negative != mapping.NegativeExpenses {
+			row.skip("not spending (credit or refund)")
+			continue
+		}
+
+		row.expense = model.CreateExpenseRequest{
+			Amount:      amount,
+			Currency:    field(currencyCol),
+			Description: field(descriptionCol),
+			Category:    field(categoryCol),
+			ExpenseDate: date.Format("2006-01-02"),
+		}
+	}
+
+	return rows, nil
+}
+
+// ofxTransactionPattern matches the transactions of an OFX statement
+var ofxTransactionPattern = regexp.MustCompile(`(?is)<STMTTRN>(.*?)</STMTTRN>`)
+
+// ofxElementPattern matches an OFX element and its value
+// SGML (OFX 1.x) elements have no closing tags, so the value ends at the next tag or line
+var ofxElementPattern = regexp.MustCompile(`(?i)<([A-Z0-9.]+)>([^<\r\n]*)`)
+
+// ofxCurrencyPattern matches the default currency of an OFX statement
+var ofxCurrencyPattern = regexp.MustCompile(`(?i)<CURDEF>\s*([A-Z]{3})`)
+
+// parseOFXStatement reads the transactions of an OFX (or QFX) bank or credit card statement
+// Debits (negative amounts) are imported as expenses, credits are skipped.
+// Returns the statement's currency ("" if it has none)
+func parseOFXStatement(data []byte) ([]*statementRow, string, error) {
+	content := string(data)
+	if !strings.Contains(strings.ToUpper(content), "<OFX>") {
+		return nil, "", errors.New("file must be an OFX or QFX statement")
+	}
+
+	currency := ""
+	if match := ofxCurrencyPattern.FindStringSubmatch(content); match != nil {
+		currency = strings.ToUpper(match[1])
+	}
+
+	var rows []*statementRow
+	for i, match := range ofxTransactionPattern.FindAllStringSubmatch(content, -1) {
+		row := &statementRow{row: i + 1}
+		rows = append(rows, row)
+
+		elements := make(map[string]string)
+		for _, element := range ofxElementPattern.FindAllStringSubmatch(match[1], -1) {
+			elements[strings.ToUpper(element[1])] = html.UnescapeString(strings.TrimSpace(element[2]))
+		}
+
+		// Dates are YYYYMMDD, optionally followed by a time and zone
+		posted := elements["DTPOSTED"]
+		if len(posted) < 8 {
+			row.invalid("DTPOSTED %q must be a date like 20240115", posted)
+			continue
+		}
+		date, err := time.Parse("20060102", posted[:8])
+		if err != nil {
+			row.invalid("DTPOSTED %q must be a date like 20240115", posted)
+			continue
+		}
+
+		// Some banks write decimal commas
+		amount, negative, err := normalizeStatementAmount(elements["TRNAMT"], !strings.Contains(elements["TRNAMT"], "."))
+		if err != nil {
+			row.invalid("TRNAMT: %v", err)
+			continue
+		}
+		if !negative {
+			row.skip("not spending (credit or refund)")
+			continue
+		}
+
+		// NAME is the payee, MEMO has details (and is all some banks fill in)
+		description := elements["NAME"]
+		if memo := elements["MEMO"]; description == "" {
+			description = memo
+		} else if memo != "" && !strings.EqualFold(memo, description) {
+			description += " - " + memo
+		}
+
+		row.expense = model.CreateExpenseRequest{
+			Amount:      amount,
+			Description: description,
+			ExpenseDate: date.Format("2006-01-02"),
+		}
+	}
+
+	return rows, currency, nil
+}
+
+// statementAmountPattern matches a plain decimal
+var statementAmountPattern = regexp.MustCompile(`^[0-9]+(\.[0-9]+)?$`)
+
+// normalizeStatementAmount turns an amount from a bank export ("-1,234.56",
+// "(12.50)", "1.234,56" with decimal commas) into a plain positive decimal
+// that money.ParseAmount accepts, and reports whether it was negative
+func normalizeStatementAmount(s string, decimalComma bool) (string, bool, error) {
+	amount := strings.TrimSpace(s)
+	if amount == "" {
+		return "", false, errors.New("amount is required")
+	}
+
+	negative := false
+	switch {
+	case strings.HasPrefix(amount, "(") && strings.HasSuffix(amount, ")"):
+		negative = true
+		amount = amount[1 : len(amount)-1]
+	case strings.HasPrefix(amount, "-"):
+		negative = true
+		amount = amount[1:]
+	case strings.HasSuffix(amount, "-"):
+		negative = true
+		amount = amount[:len(amount)-1]
+	case strings.HasPrefix(amount, "+"):
+		amount = amount[1:]
+	}
+
+	// Drop thousands separators, then use a decimal point
+	thousands, decimal := ",", "."
+	if decimalComma {
+		thousands, decimal = ".", ","
+	}
+	amount = strings.NewReplacer(thousands, "", " ", "", "'", "").Replace(strings.TrimSpace(amount))
+	amount = strings.Replace(amount, decimal, ".", 1)
+
+	// The amount itself is validated like any other (positive, decimal places)
+	if !statementAmountPattern.MatchString(amount) {
+		return "", false, fmt.Errorf("amount %q must be a number like 12.34", s)
+	}
+
+	return amount, negative, nil
+}
@@ -0,0 +1,200 @@
+package service
+
+import (
+	"expense-tracker/expense-service/internal/model"
+	"testing"
+)
+
+func TestNormalizeStatementAmount(t *testing.T) {
+	tests := []struct {
+		name         string
+		input        string
+		decimalComma bool
+		want         string // Empty: the amount is rejected
+		negative     bool
+	}{
+		{"plain", "12.34", false, "12.34", false},
+		{"whole", "12", false, "12", false},
+		{"plus sign", "+12.34", false, "12.34", false},
+		{"minus sign", "-12.34", false, "12.34", true},
+		{"trailing minus", "12.34-", false, "12.34", true},
+		{"parentheses", "(12.50)", false, "12.50", true},
+		{"thousands separator", "-1,234.56", false, "1234.56", true},
+		{"spaces as thousands separator", "1 234.56", false, "1234.56", false},
+		{"apostrophes as thousands separator", "1'234.56", false, "1234.56", false},
+		{"decimal comma", "1.234,56", true, "1234.56", false},
+		{"negative decimal comma", "-12,5", true, "12.5", true},
+		{"surrounding spaces", "  12.34 ", false, "12.34", false},
+		{"empty", "", false, "", false},
+		{"letters", "12.34 USD", false, "", false},
+		{"currency symbol", "$12.34", false, "", false},
+		{"two decimal points", "1.2.3", false, "", false},
+		{"exponent", "1e3", false, "", false},
+		{"sign only", "-", false, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, negative, err := normalizeStatementAmount(tt.input, tt.decimalComma)
+			switch {
+			case tt.want == "" && err == nil:
+				t.Errorf("normalizeStatementAmount(%q) = %q, want an error", tt.input, got)
+			case tt.want != "" && err != nil:
+				t.Errorf("normalizeStatementAmount(%q) error = %v, want %q", tt.input, err, tt.want)
+			case tt.want != "" && (got != tt.want || negative != tt.negative):
+				t.Errorf("normalizeStatementAmount(%q) = %q, %v, want %q, %v", tt.input, got, negative, tt.want, tt.negative)
+			}
+		})
+	}
+}
+
+func TestParseCSVStatement(t *testing.T) {
+	data := "\xef\xbb\xbfBooking Date;Text;Amount;Currency\r\n" +
+		"15.01.2024;Coffee;-3,50;EUR\r\n" +
+		"16.01.2024;Salary;2.500,00;EUR\r\n" +
+		"\r\n" +
+		"17.01.2024;\"Books; used\";-1.234,56;\r\n" +
+		"2024-01-18;Bad date;-1,00;EUR\r\n" +
+		"19.01.2024;Bad amount;abc;EUR\r\n" +
+		"20.01.2024;Short\r\n"
+	mapping := &model.CSVColumnMapping{
+		Date:             "booking date",
+		Amount:           "Amount",
+		Description:      "Text",
+		Currency:         "Currency",
+		DateFormat:       "dd.mm.yyyy",
+		Delimiter:        ";",
+		DecimalComma:     true,
+		NegativeExpenses: true,
+	}
+
+	rows, err := parseCSVStatement([]byte(data), mapping)
+	if err != nil {
+		t.Fatalf("parseCSVStatement() error = %v", err)
+	}
+
+	want := []struct {
+		row    int
+		status string
+		amount string
+		date   string
+	}{
+		{2, "", "3.50", "2024-01-15"},
+		{3, model.ImportRowSkipped, "", ""},
+		{5, "", "1234.56", "2024-01-17"},
+		{6, model.ImportRowInvalid, "", ""},
+		{7, model.ImportRowInvalid, "", ""},
+		{8, model.ImportRowInvalid, "", ""},
+	}
+	if len(rows) != len(want) {
+		t.Fatalf("parseCSVStatement() returned %d rows, want %d", len(rows), len(want))
+	}
+	for i, w := range want {
+		row := rows[i]
+		if row.row != w.row || row.status != w.status {
+			t.Errorf("row %d = line %d, status %q, want line %d, status %q", i, row.row, row.status, w.row, w.status)
+		}
+		if w.status == "" && (row.expense.Amount != w.amount || row.expense.ExpenseDate != w.date) {
+			t.Errorf("row %d = %s on %s, want %s on %s", i, row.expense.Amount, row.expense.ExpenseDate, w.amount, w.date)
+		}
+	}
+	if rows[2].expense.Description != "Books; used" || rows[2].expense.Currency != "" {
+		t.Errorf("quoted row = %+v, want description \"Books; used\" and no currency", rows[2].expense)
+	}
+}
+
+func TestParseCSVStatementErrors(t *testing.T) {
+	mapping := func(date, format, delimiter string) *model.CSVColumnMapping {
+		return &model.CSVColumnMapping{Date: date, Amount: "amount", Description: "text", DateFormat: format, Delimiter: delimiter}
+	}
+
+	tests := []struct {
+		name    string
+		data    string
+		mapping *model.CSVColumnMapping
+	}{
+		{"no mapping", "date,amount,text\n", nil},
+		{"unknown date format", "date,amount,text\n", mapping("date", "YY-MM-DD", "")},
+		{"long delimiter", "date,amount,text\n", mapping("date", "", ";;")},
+		{"quote delimiter", "date,amount,text\n", mapping("date", "", `"`)},
+		{"empty file", "", mapping("date", "", "")},
+		{"missing date mapping", "date,amount,text\n", mapping("", "", "")},
+		{"unknown column", "date,amount,text\n", mapping("day", "", "")},
+		{"broken quote", "date,amount,text\n2024-01-15,1.00,\"open\n", mapping("date", "", "")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := parseCSVStatement([]byte(tt.data), tt.mapping); err == nil {
+				t.Error("parseCSVStatement() succeeded, want an error")
+			}
+		})
+	}
+}
+
+func TestParseOFXStatement(t *testing.T) {
+	// OFX 1.x is SGML: elements have no closing tags
+	data := `OFXHEADER:100
+DATA:OFXSGML
+
+<OFX>
+<BANKMSGSRSV1><STMTTRNRS><STMTRS>
+<CURDEF>eur
+<BANKTRANLIST>
+<STMTTRN>
+<TRNTYPE>DEBIT
+<DTPOSTED>20240115120000[-5:EST]
+<TRNAMT>-42.10
+<NAME>Grocery &amp; Co
+<MEMO>Card payment
+</STMTTRN>
+<STMTTRN>
+<TRNTYPE>CREDIT
+<DTPOSTED>20240116
+<TRNAMT>1000.00
+<NAME>Salary
+</STMTTRN>
+<STMTTRN>
+<TRNTYPE>DEBIT
+<DTPOSTED>20240117
+<TRNAMT>-7,5
+<MEMO>Parking
+</STMTTRN>
+<STMTTRN>
+<TRNTYPE>DEBIT
+<DTPOSTED>2024
+<TRNAMT>-1.00
+</STMTTRN>
+</BANKTRANLIST>
+</STMTRS></STMTTRNRS></BANKMSGSRSV1>
+</OFX>`
+
+	rows, currency, err := parseOFXStatement([]byte(data))
+	if err != nil {
+		t.Fatalf("parseOFXStatement() error = %v", err)
+	}
+	if currency != "EUR" {
+		t.Errorf("currency = %q, want EUR", currency)
+	}
+	if len(rows) != 4 {
+		t.Fatalf("parseOFXStatement() returned %d rows, want 4", len(rows))
+	}
+
+	first := rows[0].expense
+	if rows[0].status != "" || first.Amount != "42.10" || first.ExpenseDate != "2024-01-15" || first.Description != "Grocery & Co - Card payment" {
+		t.Errorf("first row = %q %+v, want 42.10 on 2024-01-15 from Grocery & Co", rows[0].status, first)
+	}
+	if rows[1].status != model.ImportRowSkipped {
+		t.Errorf("credit status = %q, want skipped", rows[1].status)
+	}
+	if third := rows[2].expense; rows[2].status != "" || third.Amount != "7.5" || third.Description != "Parking" {
+		t.Errorf("third row = %q %+v, want 7.5 for Parking", rows[2].status, third)
+	}
+	if rows[3].status != model.ImportRowInvalid {
+		t.Errorf("short date status = %q, want invalid", rows[3].status)
+	}
+
+	if _, _, err := parseOFXStatement([]byte("date,amount\n2024-01-15,1.00\n")); err == nil {
+		t.Error("parseOFXStatement() of a CSV file succeeded, want an error")
+	}
+}
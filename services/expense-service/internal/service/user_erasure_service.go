@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"expense-tracker/expense-service/internal/model"
+	"expense-tracker/expense-service/internal/repository"
+	"fmt"
+	"log"
+	"time"
+)
+
+// erasureBatchSize is how many due erasures are handled per query
+const erasureBatchSize = 100
+
+// UserErasureService erases the expenses of users who deleted their account
+// auth-service publishes user.deleted; the expenses are hidden right away
+// and permanently deleted once the grace period in the event is over
+type UserErasureService struct {
+	repo     repository.UserErasureRepository
+	interval time.Duration
+}
+
+// NewUserErasureService creates a new user erasure service
+func NewUserErasureService(repo repository.UserErasureRepository, interval time.Duration) *UserErasureService {
+	return &UserErasureService{
+		repo:     repo,
+		interval: interval,
+	}
+}
+
+// HandleEvent handles an event from the user events queue (see events.SQSConsumer)
+// Only user.deleted is acted on - other auth events are ignored
+func (s *UserErasureService) HandleEvent(ctx context.Context, event *Event) error {
+	if event.EventType != "user.deleted" {
+		return nil
+	}
+	if event.UserID == "" {
+		return fmt.Errorf("user.deleted event without user ID")
+	}
+
+	// purge_after is an RFC3339 string set by auth-service
+	purgeAfterStr, _ := event.Data["purge_after"].(string)
+	purgeAfter, err := time.Parse(time.RFC3339, purgeAfterStr)
+	if err != nil {
+		return fmt.Errorf("invalid purge_after in user.deleted event: %w", err)
+	}
+
+	if err := s.repo.Schedule(ctx, model.NewUserErasure(event.UserID, purgeAfter)); err != nil {
+		return err
+	}
+
+	log.Printf("Scheduled erasure of user %s after %s", event.UserID, purgeAfter.Format(time.RFC3339))
+	return nil
+}
+
+// Run erases due users periodically until ctx is cancelled
+// Start it in a goroutine
+func (s *UserErasureService) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.PurgeDue(ctx); err != nil {
+				log.Printf("Warning: failed to erase deleted users' expenses: %v (will retry)", err)
+			}
+		}
+	}
+}
+
+// PurgeDue erases every user whose grace period is over
+func (s *UserErasureService) PurgeDue(ctx context.Context) error {
+	for {
+		erasures, err := s.repo.FindDue(ctx, time.Now(), erasureBatchSize)
+		if err != nil {
+			return err
+		}
+
+		for _, erasure := range erasures {
+			if err := s.repo.Purge(ctx, erasure.UserID); err != nil {
+				return err
+			}
+
+			log.Printf("Erased expenses of deleted user %s", erasure.UserID)
+		}
+
+		if len(erasures) < erasureBatchSize {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,54 @@
+-- Migration: Create recurring_expenses table
+-- Rules for expenses that repeat (rent, subscriptions, utilities)
+-- The scheduler in expense-service creates an expense for every due occurrence
+-- Run this script after 003_create_user_erasures_table.sql
+
+-- Create the recurring_expenses table
+CREATE TABLE IF NOT EXISTS recurring_expenses (
+    -- UUID primary key
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+
+    -- User ID from auth-service (UUID, no foreign key since different DB)
+    user_id UUID NOT NULL,
+
+    -- Email of the user, for the expense.created events the scheduler publishes
+    -- (there is no request - and no token - when an occurrence is created)
+    user_email VARCHAR(255) NOT NULL DEFAULT '',
+
+    -- What every created expense gets (same columns as expenses)
+    amount DECIMAL(10, 2) NOT NULL,
+    description VARCHAR(500) NOT NULL,
+    category VARCHAR(50) NOT NULL,
+
+    -- daily, weekly, monthly or yearly
+    frequency VARCHAR(10) NOT NULL CHECK (frequency IN ('daily', 'weekly', 'monthly', 'yearly')),
+
+    -- Day of the month for monthly rules (1-31, clamped to the last day of shorter months)
+    day_of_month SMALLINT NULL CHECK (day_of_month BETWEEN 1 AND 31),
+
+    -- First and (optional) last day occurrences can fall on
+    start_date DATE NOT NULL,
+    end_date DATE NULL,
+
+    -- Date of the last occurrence an expense was created for - NULL until the first
+    last_occurrence_date DATE NULL,
+
+    -- Date of the next occurrence - NULL once the rule has ended
+    next_occurrence_date DATE NULL,
+
+    -- Timestamps for auditing
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    -- Soft delete support (expenses already created are kept)
+    deleted_at TIMESTAMP NULL
+);
+
+-- Index on user_id (for listing a user's rules)
+CREATE INDEX IF NOT EXISTS idx_recurring_expenses_user_id ON recurring_expenses(user_id) WHERE deleted_at IS NULL;
+
+-- Index on next_occurrence_date (for the scheduler finding due rules)
+CREATE INDEX IF NOT EXISTS idx_recurring_expenses_next_occurrence ON recurring_expenses(next_occurrence_date) WHERE deleted_at IS NULL AND next_occurrence_date IS NOT NULL;
+
+-- Add a comment to the table (documentation)
+COMMENT ON TABLE recurring_expenses IS 'Rules for repeating expenses - each occurrence becomes an expense with an ID derived from the rule and date, so it is created exactly once';
@@ -0,0 +1,62 @@
+-- Migration: Create budgets and budget_alerts tables
+-- Spending limits per category (or overall) and the threshold alerts already sent for them
+-- Run this script after 004_create_recurring_expenses_table.sql
+
+-- Create the budgets table
+CREATE TABLE IF NOT EXISTS budgets (
+    -- UUID primary key
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+
+    -- User ID from auth-service (UUID, no foreign key since different DB)
+    user_id UUID NOT NULL,
+
+    -- Category the budget limits - NULL for an overall budget (all categories)
+    category VARCHAR(50) NULL,
+
+    -- The spending limit for one period
+    amount DECIMAL(10, 2) NOT NULL CHECK (amount > 0),
+
+    -- monthly (every calendar month) or custom (start_date to end_date)
+    period VARCHAR(10) NOT NULL CHECK (period IN ('monthly', 'custom')),
+    start_date DATE NULL,
+    end_date DATE NULL,
+
+    -- Percentages of the limit that trigger an alert (e.g. {80,100})
+    -- NULL uses the service default (BUDGET_ALERT_THRESHOLDS)
+    alert_thresholds INTEGER[] NULL,
+
+    -- Timestamps for auditing
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    -- Custom budgets need both dates, monthly budgets none
+    CHECK ((period = 'custom' AND start_date IS NOT NULL AND end_date IS NOT NULL AND start_date <= end_date)
+        OR (period = 'monthly' AND start_date IS NULL AND end_date IS NULL))
+);
+
+-- Index on user_id (for listing a user's budgets and finding the ones an expense counts towards)
+CREATE INDEX IF NOT EXISTS idx_budgets_user_id ON budgets(user_id);
+
+-- One monthly budget per category (and one overall) per user, ignoring case like category names
+CREATE UNIQUE INDEX IF NOT EXISTS idx_budgets_user_monthly_category ON budgets(user_id, LOWER(COALESCE(category, ''))) WHERE period = 'monthly';
+
+-- Create the budget_alerts table
+-- One row per threshold crossed per budget period, so every alert is sent once
+CREATE TABLE IF NOT EXISTS budget_alerts (
+    budget_id UUID NOT NULL REFERENCES budgets(id) ON DELETE CASCADE,
+
+    -- First day of the period the threshold was crossed in (the month, or the custom start date)
+    period_start DATE NOT NULL,
+
+    -- The percentage that was crossed
+    threshold INTEGER NOT NULL,
+
+    -- When the alert was sent
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (budget_id, period_start, threshold)
+);
+
+-- Add comments to the tables (documentation)
+COMMENT ON TABLE budgets IS 'Per-user spending limits per category or overall, monthly or for a custom period';
+COMMENT ON TABLE budget_alerts IS 'Budget threshold alerts already sent (budget.threshold_crossed events), one per threshold and period';
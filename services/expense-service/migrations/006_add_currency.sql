@@ -0,0 +1,47 @@
+-- Migration: Add currencies
+-- Expenses, recurring expenses and budgets get an ISO 4217 currency, users a base currency
+-- that totals are converted into, and exchange rates are stored for the conversion
+-- Run this script after 005_create_budgets_tables.sql
+
+-- Currency of each expense (existing expenses were entered in US dollars)
+ALTER TABLE expenses ADD COLUMN IF NOT EXISTS currency CHAR(3) NOT NULL DEFAULT 'USD';
+
+-- Currency of the expenses a recurring expense creates
+ALTER TABLE recurring_expenses ADD COLUMN IF NOT EXISTS currency CHAR(3) NOT NULL DEFAULT 'USD';
+
+-- Currency of a budget's limit (existing budgets were in US dollars, like expenses)
+-- Spending in other currencies is converted into it
+ALTER TABLE budgets ADD COLUMN IF NOT EXISTS currency CHAR(3) NOT NULL DEFAULT 'USD';
+
+-- Create the user_settings table
+-- Users without a row use the service default (DEFAULT_CURRENCY)
+CREATE TABLE IF NOT EXISTS user_settings (
+    -- User ID from auth-service (UUID, no foreign key since different DB)
+    user_id UUID PRIMARY KEY,
+
+    -- Currency summaries and converted listings use, and the default for new budgets
+    base_currency CHAR(3) NOT NULL,
+
+    -- Timestamps for auditing
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- Create the exchange_rates table
+-- One unit of base_currency was worth rate units of quote_currency on rate_date
+-- A conversion uses the latest rate on or before the expense date
+CREATE TABLE IF NOT EXISTS exchange_rates (
+    base_currency CHAR(3) NOT NULL,
+    quote_currency CHAR(3) NOT NULL,
+    rate_date DATE NOT NULL,
+    rate NUMERIC(20, 10) NOT NULL CHECK (rate > 0),
+
+    -- When the rate was loaded
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (base_currency, quote_currency, rate_date)
+);
+
+-- Add comments to the tables (documentation)
+COMMENT ON TABLE user_settings IS 'Per-user expense settings (base currency)';
+COMMENT ON TABLE exchange_rates IS 'Daily exchange rates for converting expenses into users'' base currencies';
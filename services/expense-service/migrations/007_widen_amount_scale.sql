@@ -0,0 +1,9 @@
+-- Migration: Widen amount columns to three decimal places
+-- Some currencies have three (KWD, BHD, OMR, ...); amounts are rounded to their
+-- currency's decimal places when they are read. The limit stays the same:
+-- 8 digits before the decimal point (less than 100,000,000)
+-- Run this script after 006_add_currency.sql
+
+ALTER TABLE expenses ALTER COLUMN amount TYPE DECIMAL(11, 3);
+ALTER TABLE recurring_expenses ALTER COLUMN amount TYPE DECIMAL(11, 3);
+ALTER TABLE budgets ALTER COLUMN amount TYPE DECIMAL(11, 3);
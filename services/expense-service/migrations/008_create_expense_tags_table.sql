@@ -0,0 +1,23 @@
+-- Migration: Create expense_tags table
+-- Free-form tags on expenses (e.g. "client-acme", "reimbursable") - an expense
+-- can have many tags, unlike its single category
+-- Run this script after 007_widen_amount_scale.sql
+
+CREATE TABLE IF NOT EXISTS expense_tags (
+    -- Tags go away with their expense (hard deletes and account erasure)
+    expense_id UUID NOT NULL REFERENCES expenses(id) ON DELETE CASCADE,
+
+    -- Owner of the expense (copied so a user's tags can be listed without the expenses table)
+    user_id UUID NOT NULL,
+
+    -- Lower-case tag
+    tag VARCHAR(50) NOT NULL,
+
+    PRIMARY KEY (expense_id, tag)
+);
+
+-- Index for filtering a user's expenses by tag and counting tag usage
+CREATE INDEX IF NOT EXISTS idx_expense_tags_user_tag ON expense_tags(user_id, tag);
+
+-- Add a comment to the table (documentation)
+COMMENT ON TABLE expense_tags IS 'Free-form tags of expenses (many per expense)';
@@ -0,0 +1,35 @@
+-- Migration: Create categories table
+-- Per-user category catalog that expense categories are validated against,
+-- so "Food", "food" and "Foods" no longer end up as separate categories
+-- Run this script after 008_create_expense_tags_table.sql
+
+CREATE TABLE IF NOT EXISTS categories (
+    -- UUID primary key
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+
+    -- User ID from auth-service (UUID, no foreign key since different DB)
+    user_id UUID NOT NULL,
+
+    -- Name stored in expenses.category (same length)
+    name VARCHAR(50) NOT NULL,
+
+    -- Display metadata for clients (hex color like #FF7043, icon name)
+    color VARCHAR(7) NULL,
+    icon VARCHAR(50) NULL,
+
+    -- Parent category (one level deep) - subcategories become top-level when it is deleted
+    parent_id UUID NULL REFERENCES categories(id) ON DELETE SET NULL,
+
+    -- Timestamps for auditing
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- Names are unique per user, ignoring case (also used for case-insensitive lookups)
+CREATE UNIQUE INDEX IF NOT EXISTS idx_categories_user_name ON categories(user_id, LOWER(name));
+
+-- Index on parent_id (for finding subcategories)
+CREATE INDEX IF NOT EXISTS idx_categories_parent_id ON categories(parent_id);
+
+-- Add a comment to the table (documentation)
+COMMENT ON TABLE categories IS 'Per-user expense categories (seeded with defaults on first use)';
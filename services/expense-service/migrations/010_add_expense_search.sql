@@ -0,0 +1,58 @@
+-- Migration: Add full-text search to expenses
+-- search_vector holds the words of an expense's description, category and tags
+-- (weighted in that order, so description matches rank highest). Triggers keep
+-- it up to date whenever an expense's description or category or its tags change
+-- (a generated column cannot read the tags from expense_tags)
+-- Run this script after 009_create_categories_table.sql
+
+ALTER TABLE expenses ADD COLUMN IF NOT EXISTS search_vector TSVECTOR;
+
+-- Search words of an expense
+CREATE OR REPLACE FUNCTION expense_search_vector(expense_id UUID, description TEXT, category TEXT)
+RETURNS TSVECTOR AS $$
+    SELECT setweight(to_tsvector('english', description), 'A') ||
+        setweight(to_tsvector('english', category), 'B') ||
+        setweight(to_tsvector('english', COALESCE((SELECT string_agg(t.tag, ' ') FROM expense_tags t WHERE t.expense_id = $1), '')), 'C')
+$$ LANGUAGE sql STABLE;
+
+-- Recompute the search words of a new or changed expense (its tags are written afterwards)
+CREATE OR REPLACE FUNCTION expenses_search_vector_trigger() RETURNS TRIGGER AS $$
+BEGIN
+    NEW.search_vector := expense_search_vector(NEW.id, NEW.description, NEW.category);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS expenses_search_vector ON expenses;
+CREATE TRIGGER expenses_search_vector
+    BEFORE INSERT OR UPDATE OF description, category ON expenses
+    FOR EACH ROW EXECUTE FUNCTION expenses_search_vector_trigger();
+
+-- Recompute the search words of expenses whose tags were added or removed
+-- Runs once per statement, so writing all tags of an expense updates it once
+CREATE OR REPLACE FUNCTION expense_tags_search_vector_trigger() RETURNS TRIGGER AS $$
+BEGIN
+    UPDATE expenses e SET search_vector = expense_search_vector(e.id, e.description, e.category)
+    WHERE e.id IN (SELECT expense_id FROM changed_tags);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS expense_tags_inserted_search_vector ON expense_tags;
+CREATE TRIGGER expense_tags_inserted_search_vector
+    AFTER INSERT ON expense_tags REFERENCING NEW TABLE AS changed_tags
+    FOR EACH STATEMENT EXECUTE FUNCTION expense_tags_search_vector_trigger();
+
+DROP TRIGGER IF EXISTS expense_tags_deleted_search_vector ON expense_tags;
+CREATE TRIGGER expense_tags_deleted_search_vector
+    AFTER DELETE ON expense_tags REFERENCING OLD TABLE AS changed_tags
+    FOR EACH STATEMENT EXECUTE FUNCTION expense_tags_search_vector_trigger();
+
+-- Fill in existing expenses
+UPDATE expenses SET search_vector = expense_search_vector(id, description, category)
+WHERE search_vector IS NULL;
+
+-- GIN index for matching search queries (the q parameter)
+CREATE INDEX IF NOT EXISTS idx_expenses_search_vector ON expenses USING GIN (search_vector) WHERE deleted_at IS NULL;
+
+COMMENT ON COLUMN expenses.search_vector IS 'Full-text search words of description, category and tags (maintained by triggers)';
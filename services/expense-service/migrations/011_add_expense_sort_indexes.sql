@@ -0,0 +1,15 @@
+-- Migration: Add indexes for sorted, cursor-paginated expense listings
+-- Each index covers a sort field followed by the tie-breakers of the cursor
+-- (see expenseSortKeys), so a page is read straight from the index
+-- Run this script after 010_add_expense_search.sql
+
+-- Sorting by date (default); replaces idx_expenses_user_date
+CREATE INDEX IF NOT EXISTS idx_expenses_user_date_keyset ON expenses(user_id, expense_date, created_at, id) WHERE deleted_at IS NULL;
+DROP INDEX IF EXISTS idx_expenses_user_date;
+
+-- Sorting by amount
+CREATE INDEX IF NOT EXISTS idx_expenses_user_amount_keyset ON expenses(user_id, amount, id) WHERE deleted_at IS NULL;
+
+-- Sorting by category and by creation time
+CREATE INDEX IF NOT EXISTS idx_expenses_user_category_keyset ON expenses(user_id, category, id) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_expenses_user_created_keyset ON expenses(user_id, created_at, id) WHERE deleted_at IS NULL;
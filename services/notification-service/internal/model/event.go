@@ -15,29 +15,82 @@ type Event struct {
 const (
 	EventTypeExpenseCreated  = "expense.created"
 	EventTypeExpenseUpdated  = "expense.updated"
+	EventTypeExpenseImported = "expense.imported"
+	EventTypeExpenseBatch    = "expense.batch_applied"
 	EventTypeReceiptUploaded = "receipt.uploaded"
 	EventTypeReceiptLinked   = "receipt.linked"
 	EventTypeUserRegistered  = "user.registered"
+
+	EventTypeBudgetThresholdCrossed = "budget.threshold_crossed"
+
+	EventTypePasswordResetRequested     = "user.password_reset_requested"
+	EventTypeEmailVerificationRequested = "user.email_verification_requested"
+	EventTypeAccountLocked              = "user.account_locked"
+	EventTypeUserUpdated                = "user.updated"
+	EventTypeUserDeleted                = "user.deleted"
 )
 
 // ExpenseCreatedData represents data for expense.created event
 type ExpenseCreatedData struct {
 	ExpenseID   string `json:"expense_id"`
 	Amount      string `json:"amount"`
+	Currency    string `json:"currency"` // ISO 4217 code (absent in older events: USD)
 	Description string `json:"description"`
 	Category    string `json:"category"`
 	ExpenseDate string `json:"expense_date"`
+
+	// Set when the expense was created from a recurring expense by the scheduler
+	Recurring          bool   `json:"recurring,omitempty"`
+	RecurringExpenseID string `json:"recurring_expense_id,omitempty"`
 }
 
 // ExpenseUpdatedData represents data for expense.updated event
 type ExpenseUpdatedData struct {
 	ExpenseID   string `json:"expense_id"`
 	Amount      string `json:"amount"`
+	Currency    string `json:"currency"` // ISO 4217 code (absent in older events: USD)
 	Description string `json:"description"`
 	Category    string `json:"category"`
 	ExpenseDate string `json:"expense_date"`
 }
 
+// ExpenseImportedData represents data for expense.imported event
+// One event per imported file instead of an expense.created event per row
+type ExpenseImportedData struct {
+	Format     string `json:"format"` // csv or ofx
+	Imported   int    `json:"imported"`
+	Duplicates int    `json:"duplicates"` // Rows matching existing expenses (not imported)
+	Invalid    int    `json:"invalid"`
+	Skipped    int    `json:"skipped"` // Deposits and refunds
+	StartDate  string `json:"start_date"`
+	EndDate    string `json:"end_date"`
+}
+
+// ExpenseBatchData represents data for expense.batch_applied event
+// One event per batch of creates, updates and deletes (e.g. a mobile app syncing
+// offline edits) instead of an event per expense
+type ExpenseBatchData struct {
+	Mode    string `json:"mode"` // all_or_nothing or best_effort
+	Created int    `json:"created"`
+	Updated int    `json:"updated"`
+	Deleted int    `json:"deleted"`
+	Failed  int    `json:"failed"` // Operations that were not applied (best_effort only)
+}
+
+// BudgetThresholdCrossedData represents data for budget.threshold_crossed event
+type BudgetThresholdCrossedData struct {
+	BudgetID    string  `json:"budget_id"`
+	Category    string  `json:"category"` // Empty for an overall budget
+	Threshold   int     `json:"threshold"`
+	Limit       string  `json:"limit"`
+	Spent       string  `json:"spent"`
+	Currency    string  `json:"currency"` // The user's base currency
+	PercentUsed float64 `json:"percent_used"`
+	PeriodStart string  `json:"period_start"`
+	PeriodEnd   string  `json:"period_end"`
+	ExpenseID   string  `json:"expense_id"`
+}
+
 // ReceiptUploadedData represents data for receipt.uploaded event
 type ReceiptUploadedData struct {
 	ReceiptID string `json:"receipt_id"`
@@ -56,7 +109,53 @@ type ReceiptLinkedData struct {
 
 // UserRegisteredData represents data for user.registered event
 type UserRegisteredData struct {
-	UserID string `json:"user_id"`
-	Email  string `json:"email"`
-	Name   string `json:"name"`
+	UserID          string `json:"user_id"`
+	Email           string `json:"email"`
+	Name            string `json:"name"`
+	VerificationURL string `json:"verification_url,omitempty"`
+}
+
+// PasswordResetRequestedData represents data for user.password_reset_requested event
+type PasswordResetRequestedData struct {
+	UserID    string `json:"user_id"`
+	Email     string `json:"email"`
+	Name      string `json:"name"`
+	ResetURL  string `json:"reset_url"`
+	ExpiresAt string `json:"expires_at"`
+}
+
+// EmailVerificationRequestedData represents data for user.email_verification_requested event
+type EmailVerificationRequestedData struct {
+	UserID          string `json:"user_id"`
+	Email           string `json:"email"`
+	Name            string `json:"name"`
+	VerificationURL string `json:"verification_url"`
+}
+
+// AccountLockedData represents data for user.account_locked event
+type AccountLockedData struct {
+	UserID      string `json:"user_id"`
+	Email       string `json:"email"`
+	Name        string `json:"name"`
+	LockedUntil string `json:"locked_until"`
+	ClientIP    string `json:"client_ip"`
+}
+
+// UserUpdatedData represents data for user.updated event
+type UserUpdatedData struct {
+	UserID        string   `json:"user_id"`
+	Email         string   `json:"email"`
+	Name          string   `json:"name"`
+	ChangedFields []string `json:"changed_fields"`
+	PreviousEmail string   `json:"previous_email,omitempty"`
+}
+
+// UserDeletedData represents data for user.deleted event
+type UserDeletedData struct {
+	UserID     string `json:"user_id"`
+	Email      string `json:"email"`
+	Name       string `json:"name"`
+	DeletedAt  string `json:"deleted_at"`
+	PurgeAfter string `json:"purge_after"`
+	Final      bool   `json:"final,omitempty"` // Repeated right before the account is purged
 }
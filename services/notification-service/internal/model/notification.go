@@ -11,9 +11,19 @@ type Notification struct {
 type NotificationType string
 
 const (
-	NotificationTypeExpenseCreated  NotificationType = "expense_created"
-	NotificationTypeExpenseUpdated  NotificationType = "expense_updated"
-	NotificationTypeReceiptUploaded NotificationType = "receipt_uploaded"
-	NotificationTypeReceiptLinked   NotificationType = "receipt_linked"
-	NotificationTypeUserRegistered  NotificationType = "user_registered"
+	NotificationTypeExpenseCreated   NotificationType = "expense_created"
+	NotificationTypeExpenseUpdated   NotificationType = "expense_updated"
+	NotificationTypeExpensesImported NotificationType = "expenses_imported"
+	NotificationTypeExpensesSynced   NotificationType = "expenses_synced"
+	NotificationTypeReceiptUploaded  NotificationType = "receipt_uploaded"
+	NotificationTypeReceiptLinked    NotificationType = "receipt_linked"
+	NotificationTypeUserRegistered   NotificationType = "user_registered"
+
+	NotificationTypeBudgetThresholdCrossed NotificationType = "budget_threshold_crossed"
+
+	NotificationTypePasswordResetRequested     NotificationType = "password_reset_requested"
+	NotificationTypeEmailVerificationRequested NotificationType = "email_verification_requested"
+	NotificationTypeAccountLocked              NotificationType = "account_locked"
+	NotificationTypeUserUpdated                NotificationType = "user_updated"
+	NotificationTypeAccountDeleted             NotificationType = "account_deleted"
 )
@@ -5,6 +5,7 @@ import (
 	"expense-tracker/notification-service/internal/model"
 	"fmt"
 	"log"
+	"strings"
 )
 
 // NotificationService handles processing events and sending notifications
@@ -25,6 +26,13 @@ func NewNotificationService(snsPublisher *SNSPublisher, templateService *Templat
 func (s *NotificationService) ProcessEvent(ctx context.Context, event *model.Event) error {
 	log.Printf("Processing event: %s for user %s", event.EventType, event.UserID)
 
+	// auth-service repeats user.deleted right before it purges the account
+	// The user was already emailed when they deleted it
+	if final, _ := event.Data["final"].(bool); final && event.EventType == model.EventTypeUserDeleted {
+		log.Printf("Skipping final user.deleted event for user %s", event.UserID)
+		return nil
+	}
+
 	// Determine notification type and render template
 	var templateName string
 	var subject string
@@ -41,6 +49,16 @@ func (s *NotificationService) ProcessEvent(ctx context.Context, event *model.Eve
 		subject = "Expense Updated"
 		templateData = s.buildExpenseUpdatedData(event)
 
+	case model.EventTypeExpenseImported:
+		templateName = "expenses_imported"
+		subject = "Expenses Imported"
+		templateData = s.buildExpensesImportedData(event)
+
+	case model.EventTypeExpenseBatch:
+		templateName = "expenses_synced"
+		subject = "Expenses Synced"
+		templateData = s.buildExpensesSyncedData(event)
+
 	case model.EventTypeReceiptUploaded:
 		templateName = "receipt_uploaded"
 		subject = "Receipt Uploaded"
@@ -51,11 +69,41 @@ func (s *NotificationService) ProcessEvent(ctx context.Context, event *model.Eve
 		subject = "Receipt Linked to Expense"
 		templateData = s.buildReceiptLinkedData(event)
 
+	case model.EventTypeBudgetThresholdCrossed:
+		templateName = "budget_threshold_crossed"
+		subject = "Budget Alert"
+		templateData = s.buildBudgetThresholdCrossedData(event)
+
 	case model.EventTypeUserRegistered:
 		templateName = "user_registered"
 		subject = "Welcome to Expense Tracker!"
 		templateData = s.buildUserRegisteredData(event)
 
+	case model.EventTypePasswordResetRequested:
+		templateName = "password_reset_requested"
+		subject = "Reset Your Expense Tracker Password"
+		templateData = s.buildPasswordResetRequestedData(event)
+
+	case model.EventTypeEmailVerificationRequested:
+		templateName = "email_verification_requested"
+		subject = "Verify Your Email Address"
+		templateData = s.buildEmailVerificationRequestedData(event)
+
+	case model.EventTypeAccountLocked:
+		templateName = "account_locked"
+		subject = "Your Expense Tracker Account Was Locked"
+		templateData = s.buildAccountLockedData(event)
+
+	case model.EventTypeUserUpdated:
+		templateName = "user_updated"
+		subject = "Your Expense Tracker Account Was Updated"
+		templateData = s.buildUserUpdatedData(event)
+
+	case model.EventTypeUserDeleted:
+		templateName = "account_deleted"
+		subject = "Your Expense Tracker Account Was Deleted"
+		templateData = s.buildAccountDeletedData(event)
+
 	default:
 		return fmt.Errorf("unknown event type: %s", event.EventType)
 	}
@@ -93,6 +141,7 @@ func (s *NotificationService) buildExpenseCreatedData(event *model.Event) map[st
 	if amount, ok := event.Data["amount"].(string); ok {
 		data["Amount"] = amount
 	}
+	data["Currency"] = eventCurrency(event)
 	if description, ok := event.Data["description"].(string); ok {
 		data["Description"] = description
 	}
@@ -103,11 +152,22 @@ func (s *NotificationService) buildExpenseCreatedData(event *model.Event) map[st
 		data["ExpenseDate"] = expenseDate
 	}
 
+	// Expenses created by the scheduler from a recurring expense
+	recurring, _ := event.Data["recurring"].(bool)
+	data["Recurring"] = recurring
+
 	// Add user info
 	data["UserEmail"] = event.UserEmail
+	if recurring {
+		data["Content"] = fmt.Sprintf(
+			"<h2>Recurring Expense Added</h2><p>A recurring expense was added for you:</p><ul><li><strong>Amount:</strong> %s %s</li><li><strong>Description:</strong> %s</li><li><strong>Category:</strong> %s</li><li><strong>Date:</strong> %s</li></ul>",
+			data["Amount"], data["Currency"], data["Description"], data["Category"], data["ExpenseDate"],
+		)
+		return data
+	}
 	data["Content"] = fmt.Sprintf(
-		"<h2>New Expense Added</h2><p>You've added a new expense:</p><ul><li><strong>Amount:</strong> $%s</li><li><strong>Description:</strong> %s</li><li><strong>Category:</strong> %s</li><li><strong>Date:</strong> %s</li></ul>",
-		data["Amount"], data["Description"], data["Category"], data["ExpenseDate"],
+		"<h2>New Expense Added</h2><p>You've added a new expense:</p><ul><li><strong>Amount:</strong> %s %s</li><li><strong>Description:</strong> %s</li><li><strong>Category:</strong> %s</li><li><strong>Date:</strong> %s</li></ul>",
+		data["Amount"], data["Currency"], data["Description"], data["Category"], data["ExpenseDate"],
 	)
 
 	return data
@@ -123,6 +183,7 @@ func (s *NotificationService) buildExpenseUpdatedData(event *model.Event) map[st
 	if amount, ok := event.Data["amount"].(string); ok {
 		data["Amount"] = amount
 	}
+	data["Currency"] = eventCurrency(event)
 	if description, ok := event.Data["description"].(string); ok {
 		data["Description"] = description
 	}
@@ -135,8 +196,65 @@ func (s *NotificationService) buildExpenseUpdatedData(event *model.Event) map[st
 
 	data["UserEmail"] = event.UserEmail
 	data["Content"] = fmt.Sprintf(
-		"<h2>Expense Updated</h2><p>Your expense has been updated:</p><ul><li><strong>Amount:</strong> $%s</li><li><strong>Description:</strong> %s</li><li><strong>Category:</strong> %s</li><li><strong>Date:</strong> %s</li></ul>",
-		data["Amount"], data["Description"], data["Category"], data["ExpenseDate"],
+		"<h2>Expense Updated</h2><p>Your expense has been updated:</p><ul><li><strong>Amount:</strong> %s %s</li><li><strong>Description:</strong> %s</li><li><strong>Category:</strong> %s</li><li><strong>Date:</strong> %s</li></ul>",
+		data["Amount"], data["Currency"], data["Description"], data["Category"], data["ExpenseDate"],
+	)
+
+	return data
+}
+
+// buildExpensesImportedData builds template data for expense imported event
+func (s *NotificationService) buildExpensesImportedData(event *model.Event) map[string]interface{} {
+	data := make(map[string]interface{})
+
+	if format, ok := event.Data["format"].(string); ok {
+		data["Format"] = strings.ToUpper(format)
+	}
+	// JSON numbers decode as float64
+	for key, name := range map[string]string{
+		"imported":   "Imported",
+		"duplicates": "Duplicates",
+		"invalid":    "Invalid",
+		"skipped":    "Skipped",
+	} {
+		count, _ := event.Data[key].(float64)
+		data[name] = int(count)
+	}
+	if startDate, ok := event.Data["start_date"].(string); ok {
+		data["StartDate"] = startDate
+	}
+	if endDate, ok := event.Data["end_date"].(string); ok {
+		data["EndDate"] = endDate
+	}
+
+	data["UserEmail"] = event.UserEmail
+	data["Content"] = fmt.Sprintf(
+		"<h2>Expenses Imported</h2><p>%v expenses from %s to %s were imported from your %s file.</p><ul><li><strong>Duplicates left out:</strong> %v</li><li><strong>Invalid rows:</strong> %v</li><li><strong>Deposits and refunds skipped:</strong> %v</li></ul>",
+		data["Imported"], data["StartDate"], data["EndDate"], data["Format"], data["Duplicates"], data["Invalid"], data["Skipped"],
+	)
+
+	return data
+}
+
+// buildExpensesSyncedData builds template data for expense batch applied event
+func (s *NotificationService) buildExpensesSyncedData(event *model.Event) map[string]interface{} {
+	data := make(map[string]interface{})
+
+	// JSON numbers decode as float64
+	for key, name := range map[string]string{
+		"created": "Created",
+		"updated": "Updated",
+		"deleted": "Deleted",
+		"failed":  "Failed",
+	} {
+		count, _ := event.Data[key].(float64)
+		data[name] = int(count)
+	}
+
+	data["UserEmail"] = event.UserEmail
+	data["Content"] = fmt.Sprintf(
+		"<h2>Expenses Synced</h2><p>A batch of changes to your expenses was saved.</p><ul><li><strong>Created:</strong> %v</li><li><strong>Updated:</strong> %v</li><li><strong>Deleted:</strong> %v</li><li><strong>Failed:</strong> %v</li></ul>",
+		data["Created"], data["Updated"], data["Deleted"], data["Failed"],
 	)
 
 	return data
@@ -200,6 +318,59 @@ func (s *NotificationService) buildReceiptLinkedData(event *model.Event) map[str
 	return data
 }
 
+// buildBudgetThresholdCrossedData builds template data for budget threshold crossed event
+func (s *NotificationService) buildBudgetThresholdCrossedData(event *model.Event) map[string]interface{} {
+	data := make(map[string]interface{})
+
+	if budgetID, ok := event.Data["budget_id"].(string); ok {
+		data["BudgetID"] = budgetID
+	}
+	// JSON numbers decode as float64
+	if threshold, ok := event.Data["threshold"].(float64); ok {
+		data["Threshold"] = int(threshold)
+	}
+	if limit, ok := event.Data["limit"].(string); ok {
+		data["Limit"] = limit
+	}
+	if spent, ok := event.Data["spent"].(string); ok {
+		data["Spent"] = spent
+	}
+	data["Currency"] = eventCurrency(event)
+	if percentUsed, ok := event.Data["percent_used"].(float64); ok {
+		data["PercentUsed"] = percentUsed
+	}
+	if periodStart, ok := event.Data["period_start"].(string); ok {
+		data["PeriodStart"] = periodStart
+	}
+	if periodEnd, ok := event.Data["period_end"].(string); ok {
+		data["PeriodEnd"] = periodEnd
+	}
+
+	// An empty category is the overall budget
+	budgetName := "overall budget"
+	if category, ok := event.Data["category"].(string); ok && category != "" {
+		budgetName = category + " budget"
+	}
+	data["BudgetName"] = budgetName
+
+	data["UserEmail"] = event.UserEmail
+	data["Content"] = fmt.Sprintf(
+		"<h2>Budget Alert</h2><p>You've reached %v%% of your %s for %s to %s.</p><ul><li><strong>Spent:</strong> %s %s</li><li><strong>Limit:</strong> %s %s</li><li><strong>Used:</strong> %v%%</li></ul>",
+		data["Threshold"], data["BudgetName"], data["PeriodStart"], data["PeriodEnd"], data["Spent"], data["Currency"], data["Limit"], data["Currency"], data["PercentUsed"],
+	)
+
+	return data
+}
+
+// eventCurrency returns the currency of an expense or budget event
+// Events published before expenses had currencies were all in US dollars
+func eventCurrency(event *model.Event) string {
+	if currency, ok := event.Data["currency"].(string); ok && currency != "" {
+		return currency
+	}
+	return "USD"
+}
+
 // buildUserRegisteredData builds template data for user registered event
 func (s *NotificationService) buildUserRegisteredData(event *model.Event) map[string]interface{} {
 	data := make(map[string]interface{})
@@ -213,11 +384,157 @@ func (s *NotificationService) buildUserRegisteredData(event *model.Event) map[st
 	if name, ok := event.Data["name"].(string); ok {
 		data["Name"] = name
 	}
+	if verificationURL, ok := event.Data["verification_url"].(string); ok {
+		data["VerificationURL"] = verificationURL
+	}
+
+	verifyInfo := ""
+	if verificationURL, ok := data["VerificationURL"].(string); ok && verificationURL != "" {
+		verifyInfo = fmt.Sprintf("<p>Please confirm your email address: <a href=\"%s\">Verify email</a></p>", verificationURL)
+	}
+
+	data["UserEmail"] = event.UserEmail
+	data["Content"] = fmt.Sprintf(
+		"<h2>Welcome to Expense Tracker!</h2><p>Hi %s,</p><p>Thank you for registering with Expense Tracker. You can now start tracking your expenses and receipts.</p>%s<p>Get started by creating your first expense!</p>",
+		data["Name"], verifyInfo,
+	)
+
+	return data
+}
+
+// buildPasswordResetRequestedData builds template data for password reset requested event
+func (s *NotificationService) buildPasswordResetRequestedData(event *model.Event) map[string]interface{} {
+	data := make(map[string]interface{})
+
+	if userID, ok := event.Data["user_id"].(string); ok {
+		data["UserID"] = userID
+	}
+	if name, ok := event.Data["name"].(string); ok {
+		data["Name"] = name
+	}
+	if resetURL, ok := event.Data["reset_url"].(string); ok {
+		data["ResetURL"] = resetURL
+	}
+	if expiresAt, ok := event.Data["expires_at"].(string); ok {
+		data["ExpiresAt"] = expiresAt
+	}
+
+	data["UserEmail"] = event.UserEmail
+	data["Content"] = fmt.Sprintf(
+		"<h2>Reset Your Password</h2><p>Hi %s,</p><p>We received a request to reset your password. Use the link below to choose a new one:</p><p><a href=\"%s\">Reset password</a></p><p>This link expires at %s and can only be used once. If you didn't request a reset, you can ignore this email.</p>",
+		data["Name"], data["ResetURL"], data["ExpiresAt"],
+	)
+
+	return data
+}
+
+// buildEmailVerificationRequestedData builds template data for email verification requested event
+func (s *NotificationService) buildEmailVerificationRequestedData(event *model.Event) map[string]interface{} {
+	data := make(map[string]interface{})
+
+	if userID, ok := event.Data["user_id"].(string); ok {
+		data["UserID"] = userID
+	}
+	if email, ok := event.Data["email"].(string); ok {
+		data["Email"] = email
+	}
+	if name, ok := event.Data["name"].(string); ok {
+		data["Name"] = name
+	}
+	if verificationURL, ok := event.Data["verification_url"].(string); ok {
+		data["VerificationURL"] = verificationURL
+	}
+
+	data["UserEmail"] = event.UserEmail
+	data["Content"] = fmt.Sprintf(
+		"<h2>Verify Your Email Address</h2><p>Hi %s,</p><p>Please confirm that %s is your email address:</p><p><a href=\"%s\">Verify email</a></p><p>If you didn't request this, you can ignore this email.</p>",
+		data["Name"], data["Email"], data["VerificationURL"],
+	)
+
+	return data
+}
+
+// buildAccountLockedData builds template data for account locked event
+func (s *NotificationService) buildAccountLockedData(event *model.Event) map[string]interface{} {
+	data := make(map[string]interface{})
+
+	if userID, ok := event.Data["user_id"].(string); ok {
+		data["UserID"] = userID
+	}
+	if name, ok := event.Data["name"].(string); ok {
+		data["Name"] = name
+	}
+	if lockedUntil, ok := event.Data["locked_until"].(string); ok {
+		data["LockedUntil"] = lockedUntil
+	}
+	if clientIP, ok := event.Data["client_ip"].(string); ok {
+		data["ClientIP"] = clientIP
+	}
+
+	data["UserEmail"] = event.UserEmail
+	data["Content"] = fmt.Sprintf(
+		"<h2>Your Account Was Locked</h2><p>Hi %s,</p><p>There were too many failed sign-in attempts on your account (last from %s), so sign-ins are blocked until %s.</p><p>If this wasn't you, someone may be trying to guess your password. Consider resetting it once the lock ends.</p>",
+		data["Name"], data["ClientIP"], data["LockedUntil"],
+	)
+
+	return data
+}
+
+// buildUserUpdatedData builds template data for user updated event
+func (s *NotificationService) buildUserUpdatedData(event *model.Event) map[string]interface{} {
+	data := make(map[string]interface{})
+
+	if userID, ok := event.Data["user_id"].(string); ok {
+		data["UserID"] = userID
+	}
+	if name, ok := event.Data["name"].(string); ok {
+		data["Name"] = name
+	}
+	if email, ok := event.Data["email"].(string); ok {
+		data["Email"] = email
+	}
+	if previousEmail, ok := event.Data["previous_email"].(string); ok {
+		data["PreviousEmail"] = previousEmail
+	}
+
+	// JSON arrays decode as []interface{}
+	var changedFields []string
+	if fields, ok := event.Data["changed_fields"].([]interface{}); ok {
+		for _, field := range fields {
+			if name, ok := field.(string); ok {
+				changedFields = append(changedFields, name)
+			}
+		}
+	}
+	data["ChangedFields"] = strings.Join(changedFields, ", ")
+
+	data["UserEmail"] = event.UserEmail
+	data["Content"] = fmt.Sprintf(
+		"<h2>Your Account Was Updated</h2><p>Hi %s,</p><p>The following details of your account were changed: %s.</p><p>If you didn't make this change, reset your password right away.</p>",
+		data["Name"], data["ChangedFields"],
+	)
+
+	return data
+}
+
+// buildAccountDeletedData builds template data for user deleted event
+func (s *NotificationService) buildAccountDeletedData(event *model.Event) map[string]interface{} {
+	data := make(map[string]interface{})
+
+	if userID, ok := event.Data["user_id"].(string); ok {
+		data["UserID"] = userID
+	}
+	if name, ok := event.Data["name"].(string); ok {
+		data["Name"] = name
+	}
+	if purgeAfter, ok := event.Data["purge_after"].(string); ok {
+		data["PurgeAfter"] = purgeAfter
+	}
 
 	data["UserEmail"] = event.UserEmail
 	data["Content"] = fmt.Sprintf(
-		"<h2>Welcome to Expense Tracker!</h2><p>Hi %s,</p><p>Thank you for registering with Expense Tracker. You can now start tracking your expenses and receipts.</p><p>Get started by creating your first expense!</p>",
-		data["Name"],
+		"<h2>Your Account Was Deleted</h2><p>Hi %s,</p><p>Your Expense Tracker account was deleted. Your expenses, receipts and account data will be permanently erased on %s.</p><p>If you didn't delete your account, contact support before then.</p>",
+		data["Name"], data["PurgeAfter"],
 	)
 
 	return data
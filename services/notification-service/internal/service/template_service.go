@@ -24,9 +24,17 @@ func NewTemplateService(templatesDir string) (*TemplateService, error) {
 	templateFiles := []string{
 		"expense_created.html",
 		"expense_updated.html",
+		"expenses_imported.html",
+		"expenses_synced.html",
 		"receipt_uploaded.html",
 		"receipt_linked.html",
+		"budget_threshold_crossed.html",
 		"user_registered.html",
+		"password_reset_requested.html",
+		"email_verification_requested.html",
+		"account_locked.html",
+		"user_updated.html",
+		"account_deleted.html",
 	}
 
 	for _, filename := range templateFiles {
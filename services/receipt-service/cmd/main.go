@@ -9,6 +9,9 @@ import (
 	"expense-tracker/receipt-service/internal/middleware"
 	"expense-tracker/receipt-service/internal/repository"
 	"expense-tracker/receipt-service/internal/service"
+	"expense-tracker/shared/auth"
+	"expense-tracker/shared/clientip"
+	"expense-tracker/shared/events"
 	"log"
 	"net/http"
 	"os"
@@ -56,9 +59,38 @@ func main() {
 
 	// Initialize repository (data access layer)
 	receiptRepo := repository.NewPostgresReceiptRepository(dbPool)
+	adminAuditRepo := repository.NewPostgresAdminAuditRepository(dbPool)
+	userErasureRepo := repository.NewPostgresUserErasureRepository(dbPool)
+
+	// Context for background work (cancelled on shutdown)
+	bgCtx, bgCancel := context.WithCancel(context.Background())
+	defer bgCancel()
 
 	// Initialize auth client (for token validation via auth-service)
-	authClient := service.NewAuthClient(cfg.AuthServiceURL)
+	authClient := auth.NewAuthClient(cfg.AuthServiceURL)
+
+	// Pick how tokens are validated
+	// remote: every request calls auth-service /auth/validate (sees logouts immediately)
+	// local: verify signatures with auth-service's cached public keys (no call per request)
+	var tokenValidator auth.TokenValidator = authClient
+	if cfg.AuthValidationMode == "local" {
+		jwksClient := auth.NewJWKSClient(cfg.AuthServiceURL, cfg.JWKSRefreshInterval)
+		if err := jwksClient.Refresh(bgCtx); err != nil {
+			log.Printf("Warning: Failed to fetch signing keys: %v (will retry)", err)
+		}
+		go jwksClient.Run(bgCtx)
+
+		jwtService := auth.NewJWTService(jwksClient)
+		var jwtValidator auth.TokenValidator = jwtService
+		if cfg.AuthRemoteFallback {
+			jwtValidator = auth.NewFallbackValidator(jwtService, authClient)
+		}
+		// API keys aren't JWTs - they always go to auth-service
+		tokenValidator = auth.NewAPIKeyRouter(jwtValidator, authClient)
+		log.Printf("Validating tokens locally (remote fallback: %v)", cfg.AuthRemoteFallback)
+	} else {
+		log.Println("Validating tokens via auth-service")
+	}
 
 	// Initialize receipt service (business logic layer)
 	receiptService := service.NewReceiptService(receiptRepo, s3Service)
@@ -80,16 +112,43 @@ func main() {
 		}
 	}
 
+	// Erase the receipts of deleted accounts (user.deleted events from auth-service)
+	// Run purges erasures whose grace period is over; the consumer schedules new ones
+	erasureService := service.NewUserErasureService(userErasureRepo, s3Service, cfg.ErasureCheckInterval)
+	go erasureService.Run(bgCtx)
+	if cfg.UserEventsQueueURL != "" && cfg.AWSAccessKeyID != "" && cfg.AWSSecretKey != "" {
+		sqsConsumer, err := events.NewSQSConsumer(cfg.AWSRegion, cfg.AWSAccessKeyID, cfg.AWSSecretKey)
+		if err != nil {
+			log.Printf("Warning: Failed to initialize SQS consumer: %v (deleted accounts will not be erased)", err)
+		} else {
+			go sqsConsumer.ConsumeMessages(bgCtx, cfg.UserEventsQueueURL, erasureService.HandleEvent)
+			log.Println("Consuming user events for account erasure")
+		}
+	} else {
+		log.Println("WARNING: USER_EVENTS_QUEUE_URL not set - receipts of deleted accounts will not be erased")
+	}
+
 	// Initialize handlers (HTTP layer)
 	receiptHandler := handler.NewReceiptHandler(receiptService)
+	adminHandler := handler.NewAdminHandler(receiptService)
 
 	// Initialize middleware
-	authMiddleware := middleware.NewAuthMiddleware(authClient) // For token validation via auth-service
-	loggingMiddleware := middleware.NewLoggingMiddleware()     // For request/response logging
+	authMiddleware := middleware.NewAuthMiddleware(tokenValidator)   // For token validation
+	auditMiddleware := middleware.NewAuditMiddleware(adminAuditRepo) // For admin endpoints
+	loggingMiddleware := middleware.NewLoggingMiddleware()           // For request/response logging
+
+	// Works out the client IP (X-Forwarded-For only from TRUSTED_PROXIES)
+	ipResolver, err := clientip.NewResolver(cfg.TrustedProxies)
+	if err != nil {
+		log.Fatalf("Invalid TRUSTED_PROXIES: %v", err)
+	}
 
 	// Setup HTTP router
 	router := mux.NewRouter()
 
+	// Resolve the client IP before anything uses it
+	router.Use(ipResolver.Middleware)
+
 	// Add logging middleware FIRST (so it logs all requests)
 	router.Use(loggingMiddleware.LogRequest)
 
@@ -121,6 +180,9 @@ func main() {
 	router.HandleFunc("/receipts/{id}", authMiddleware.RequireAuth(receiptHandler.GetReceipt)).Methods("GET")
 	router.HandleFunc("/receipts/{id}", authMiddleware.RequireAuth(receiptHandler.DeleteReceipt)).Methods("DELETE")
 
+	// Admin routes (require the admin role, every request is audited)
+	router.HandleFunc("/receipts/admin/users/{userId}/receipts", authMiddleware.RequireRole(middleware.RoleAdmin, auditMiddleware.Audit("receipts.list", adminHandler.ListUserReceipts))).Methods("GET")
+
 	// Create HTTP server
 	server := &http.Server{
 		Addr:         ":" + cfg.ServerPort,
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 )
 
 // Config holds all application configuration
@@ -20,6 +22,11 @@ type Config struct {
 	// AUTH_SERVICE_URL is the base URL of auth-service for token validation
 	AuthServiceURL string
 
+	// Token validation configuration
+	AuthValidationMode  string        // "remote" (call /auth/validate) or "local" (verify with JWKS)
+	AuthRemoteFallback  bool          // In local mode, use remote validation while keys are unavailable
+	JWKSRefreshInterval time.Duration // How often cached signing keys are refreshed
+
 	// AWS S3 configuration
 	AWSRegion      string
 	AWSAccessKeyID string
@@ -29,8 +36,16 @@ type Config struct {
 	// AWS SNS configuration for event publishing
 	ReceiptEventsTopicARN string
 
+	// AWS SQS configuration for user events from auth-service (account deletion)
+	UserEventsQueueURL   string
+	ErasureCheckInterval time.Duration // How often due erasures are looked for
+
 	// Server configuration
 	ServerPort string
+
+	// TrustedProxies are the load balancers/proxies (CIDRs or IPs) whose
+	// X-Forwarded-For header is believed - for the admin audit log
+	TrustedProxies []string
 }
 
 // Load reads configuration from environment variables
@@ -51,6 +66,16 @@ func Load() (*Config, error) {
 		return nil, fmt.Errorf("AUTH_SERVICE_URL environment variable is required")
 	}
 
+	// Token validation (default: remote, i.e. call auth-service for every request)
+	// "local" verifies tokens with auth-service's public keys (GET /.well-known/jwks.json)
+	cfg.AuthValidationMode = getEnv("AUTH_VALIDATION_MODE", "remote")
+	if cfg.AuthValidationMode != "remote" && cfg.AuthValidationMode != "local" {
+		return nil, fmt.Errorf("AUTH_VALIDATION_MODE must be \"remote\" or \"local\", got %q", cfg.AuthValidationMode)
+	}
+	cfg.AuthRemoteFallback = getEnvAsBool("AUTH_REMOTE_FALLBACK", true)
+	jwksRefreshMinutes := getEnvAsInt("JWKS_REFRESH_INTERVAL_MINUTES", 5)
+	cfg.JWKSRefreshInterval = time.Duration(jwksRefreshMinutes) * time.Minute
+
 	// AWS S3 configuration
 	cfg.AWSRegion = getEnv("AWS_REGION", "us-east-1")
 	cfg.AWSAccessKeyID = getEnv("AWS_ACCESS_KEY_ID", "")
@@ -66,9 +91,18 @@ func Load() (*Config, error) {
 	cfg.ReceiptEventsTopicARN = getEnv("RECEIPT_EVENTS_TOPIC_ARN", "")
 	// Note: Topic ARN is optional - events won't be published if not configured
 
+	// User events queue (optional - deleted accounts' data is not erased if not configured)
+	cfg.UserEventsQueueURL = getEnv("USER_EVENTS_QUEUE_URL", "")
+	erasureCheckMinutes := getEnvAsInt("ERASURE_CHECK_INTERVAL_MINUTES", 60)
+	cfg.ErasureCheckInterval = time.Duration(erasureCheckMinutes) * time.Minute
+
 	// Server port (default: 8082 to avoid conflict with other services)
 	cfg.ServerPort = getEnv("SERVER_PORT", "8082")
 
+	// Trusted proxies (default: none - X-Forwarded-For is ignored)
+	// Comma-separated, e.g. "10.0.0.0/16" for a load balancer inside the VPC
+	cfg.TrustedProxies = strings.Split(getEnv("TRUSTED_PROXIES", ""), ",")
+
 	return cfg, nil
 }
 
@@ -94,6 +128,20 @@ func getEnvAsInt(key string, defaultValue int) int {
 	return value
 }
 
+// getEnvAsBool reads an environment variable as a boolean
+// strconv.ParseBool accepts 1, t, T, TRUE, true, True, 0, f, F, FALSE, false, False
+func getEnvAsBool(key string, defaultValue bool) bool {
+	valueStr := os.Getenv(key)
+	if valueStr == "" {
+		return defaultValue
+	}
+	value, err := strconv.ParseBool(valueStr)
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
 // GetDatabaseURL constructs a PostgreSQL connection string
 // Uses sslmode=require for AWS RDS
 func (c *Config) GetDatabaseURL() string {
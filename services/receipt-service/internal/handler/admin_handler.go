@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"expense-tracker/receipt-service/internal/service"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// AdminHandler handles HTTP requests for admin-only endpoints
+// Routes must be wrapped in RequireRole(middleware.RoleAdmin, ...) and Audit(...)
+type AdminHandler struct {
+	receiptService *service.ReceiptService
+}
+
+// NewAdminHandler creates a new admin handler
+func NewAdminHandler(receiptService *service.ReceiptService) *AdminHandler {
+	return &AdminHandler{
+		receiptService: receiptService,
+	}
+}
+
+// ListUserReceipts handles listing another user's receipts
+// GET /receipts/admin/users/{userId}/receipts?page=1&limit=20
+func (h *AdminHandler) ListUserReceipts(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID := mux.Vars(r)["userId"]
+	if userID == "" {
+		respondWithError(w, http.StatusBadRequest, "User ID is required")
+		return
+	}
+	if _, err := uuid.Parse(userID); err != nil {
+		respondWithError(w, http.StatusBadRequest, "User ID must be a valid UUID")
+		return
+	}
+
+	filters := parseListReceiptsRequest(r)
+
+	resp, err := h.receiptService.ListReceipts(r.Context(), userID, filters)
+	if err != nil {
+		if isListValidationError(err) {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Failed to list receipts")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, resp)
+}
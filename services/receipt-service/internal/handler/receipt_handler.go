@@ -142,7 +142,7 @@ func (h *ReceiptHandler) GetReceipt(w http.ResponseWriter, r *http.Request) {
 }
 
 // ListReceipts handles listing receipts with filters and pagination
-// GET /receipts?expense_id=xxx&page=1&limit=20
+// GET /receipts?expense_id=xxx&sort=amount&order=asc&cursor=xxx&count=false&page=1&limit=20
 func (h *ReceiptHandler) ListReceipts(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -174,8 +174,32 @@ func (h *ReceiptHandler) ListReceipts(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Parse query parameters for pagination
-	filters := &model.ListReceiptsRequest{}
+	filters := parseListReceiptsRequest(r)
+
+	// Call the receipt service
+	resp, err := h.receiptService.ListReceipts(r.Context(), userID, filters)
+	if err != nil {
+		if isListValidationError(err) {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Failed to list receipts")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+// parseListReceiptsRequest reads the sorting and pagination parameters from the query string
+// Shared by ListReceipts and the admin endpoint for viewing a user's receipts
+func parseListReceiptsRequest(r *http.Request) *model.ListReceiptsRequest {
+	// Parse query parameters for sorting and pagination
+	filters := &model.ListReceiptsRequest{
+		Sort:      r.URL.Query().Get("sort"),
+		Order:     r.URL.Query().Get("order"),
+		Cursor:    r.URL.Query().Get("cursor"),
+		SkipCount: r.URL.Query().Get("count") == "false",
+	}
 
 	// Parse pagination parameters
 	if pageStr := r.URL.Query().Get("page"); pageStr != "" {
@@ -203,14 +227,14 @@ func (h *ReceiptHandler) ListReceipts(w http.ResponseWriter, r *http.Request) {
 		filters.Limit = 20
 	}
 
-	// Call the receipt service
-	resp, err := h.receiptService.ListReceipts(r.Context(), userID, filters)
-	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, "Failed to list receipts")
-		return
-	}
+	return filters
+}
 
-	respondWithJSON(w, http.StatusOK, resp)
+// isListValidationError reports whether a listing failed because of invalid
+// sorting or cursor parameters (a client error)
+func isListValidationError(err error) bool {
+	msg := err.Error()
+	return strings.Contains(msg, "must") || strings.Contains(msg, "cannot")
 }
 
 // LinkReceipt handles linking a receipt to an expense
@@ -2,26 +2,33 @@ package middleware
 
 import (
 	"context"
-	"expense-tracker/receipt-service/internal/service"
+	"expense-tracker/shared/auth"
 	"net/http"
 	"strings"
 )
 
-// AuthMiddleware validates JWT tokens by calling auth-service
-// This centralizes authentication logic in auth-service
+// RoleAdmin is the role auth-service gives administrators
+const RoleAdmin = "admin"
+
+// scopeResource is the API key scope prefix for this service ("receipts:read", "receipts:write")
+const scopeResource = "receipts"
+
+// AuthMiddleware validates JWT tokens
+// Either by calling auth-service (AuthClient) or locally against
+// auth-service's public keys (JWTService) - see AUTH_VALIDATION_MODE
 type AuthMiddleware struct {
-	authClient *service.AuthClient
+	validator auth.TokenValidator
 }
 
 // NewAuthMiddleware creates a new authentication middleware
-func NewAuthMiddleware(authClient *service.AuthClient) *AuthMiddleware {
+func NewAuthMiddleware(validator auth.TokenValidator) *AuthMiddleware {
 	return &AuthMiddleware{
-		authClient: authClient,
+		validator: validator,
 	}
 }
 
 // RequireAuth is a middleware function that validates JWT tokens
-// It extracts the token from Authorization header, calls auth-service to validate it,
+// It extracts the token from Authorization header, validates it,
 // and attaches user_id to the request context
 func (m *AuthMiddleware) RequireAuth(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -41,17 +48,24 @@ func (m *AuthMiddleware) RequireAuth(next http.HandlerFunc) http.HandlerFunc {
 
 		token := parts[1]
 
-		// Validate the token by calling auth-service
-		userID, email, err := m.authClient.ValidateToken(r.Context(), token)
+		// Validate the token
+		user, err := m.validator.ValidateToken(r.Context(), token)
 		if err != nil {
 			http.Error(w, "Invalid or expired token", http.StatusUnauthorized)
 			return
 		}
 
+		// API keys may be limited to reading (see AuthUser.CanAccess)
+		if !user.CanAccess(scopeResource, r.Method) {
+			http.Error(w, "API key scope does not allow this request", http.StatusForbidden)
+			return
+		}
+
 		// Attach user info to request context
 		// This allows handlers to access user_id without parsing the token again
-		ctx := context.WithValue(r.Context(), "user_id", userID)
-		ctx = context.WithValue(ctx, "user_email", email)
+		ctx := context.WithValue(r.Context(), "user_id", user.UserID)
+		ctx = context.WithValue(ctx, "user_email", user.Email)
+		ctx = context.WithValue(ctx, "user_role", user.Role)
 
 		// Create a new request with the updated context
 		r = r.WithContext(ctx)
@@ -61,6 +75,20 @@ func (m *AuthMiddleware) RequireAuth(next http.HandlerFunc) http.HandlerFunc {
 	}
 }
 
+// RequireRole is like RequireAuth, but also requires the user to have a role
+// Authenticated users without the role get 403 Forbidden
+// Usage: authMiddleware.RequireRole(middleware.RoleAdmin, handler)
+func (m *AuthMiddleware) RequireRole(role string, next http.HandlerFunc) http.HandlerFunc {
+	return m.RequireAuth(func(w http.ResponseWriter, r *http.Request) {
+		if GetUserRole(r.Context()) != role {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+
+		next(w, r)
+	})
+}
+
 // GetUserID extracts the user ID from the request context
 // This is a helper function for handlers to get user info
 func GetUserID(ctx context.Context) string {
@@ -79,3 +107,12 @@ func GetUserEmail(ctx context.Context) string {
 	}
 	return email
 }
+
+// GetUserRole extracts the user role from the request context
+func GetUserRole(ctx context.Context) string {
+	role, ok := ctx.Value("user_role").(string)
+	if !ok {
+		return ""
+	}
+	return role
+}
@@ -0,0 +1,45 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AdminAuditEntry records one request to an admin endpoint
+// Admins can see other users' data, so every access is written down
+type AdminAuditEntry struct {
+	ID string `json:"id" db:"id"`
+
+	// AdminUserID and AdminEmail identify who made the request
+	AdminUserID string `json:"admin_user_id" db:"admin_user_id"`
+	AdminEmail  string `json:"admin_email" db:"admin_email"`
+
+	// Action names what was done (e.g. "receipts.list")
+	Action string `json:"action" db:"action"`
+
+	// TargetUserID is the user whose data was accessed (nil if not about one user)
+	TargetUserID *string `json:"target_user_id,omitempty" db:"target_user_id"`
+
+	// Request details
+	Method   string `json:"method" db:"method"`
+	Path     string `json:"path" db:"path"`
+	ClientIP string `json:"client_ip" db:"client_ip"`
+
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// NewAdminAuditEntry creates a new AdminAuditEntry with generated ID and timestamp
+func NewAdminAuditEntry(adminUserID, adminEmail, action string, targetUserID *string, method, path, clientIP string) *AdminAuditEntry {
+	return &AdminAuditEntry{
+		ID:           uuid.New().String(),
+		AdminUserID:  adminUserID,
+		AdminEmail:   adminEmail,
+		Action:       action,
+		TargetUserID: targetUserID,
+		Method:       method,
+		Path:         path,
+		ClientIP:     clientIP,
+		CreatedAt:    time.Now(),
+	}
+}
@@ -1,6 +1,10 @@
 package model
 
-import "time"
+import (
+	"expense-tracker/shared/money"
+	"expense-tracker/shared/pagination"
+	"time"
+)
 
 // DTOs (Data Transfer Objects) - used for API requests/responses
 
@@ -24,18 +28,18 @@ type LinkReceiptRequest struct {
 
 // ReceiptResponse is what we send back after creating/updating/getting a receipt
 type ReceiptResponse struct {
-	ID           string     `json:"id"`
-	UserID       string     `json:"user_id"`
-	ExpenseID    *string    `json:"expense_id,omitempty"`
-	FileName     string     `json:"file_name"`
-	FileURL      string     `json:"file_url"` // Presigned URL
-	FileSize     int64      `json:"file_size"`
-	MimeType     string     `json:"mime_type"`
-	MerchantName *string    `json:"merchant_name,omitempty"`
-	ReceiptDate  *time.Time `json:"receipt_date,omitempty"`
-	TotalAmount  *string    `json:"total_amount,omitempty"`
-	CreatedAt    time.Time  `json:"created_at"`
-	UpdatedAt    time.Time  `json:"updated_at"`
+	ID           string       `json:"id"`
+	UserID       string       `json:"user_id"`
+	ExpenseID    *string      `json:"expense_id,omitempty"`
+	FileName     string       `json:"file_name"`
+	FileURL      string       `json:"file_url"` // Presigned URL
+	FileSize     int64        `json:"file_size"`
+	MimeType     string       `json:"mime_type"`
+	MerchantName *string      `json:"merchant_name,omitempty"`
+	ReceiptDate  *time.Time   `json:"receipt_date,omitempty"`
+	TotalAmount  *money.Money `json:"total_amount,omitempty"`
+	CreatedAt    time.Time    `json:"created_at"`
+	UpdatedAt    time.Time    `json:"updated_at"`
 }
 
 // ListReceiptsRequest represents query parameters for listing receipts
@@ -44,18 +48,36 @@ type ListReceiptsRequest struct {
 	// ExpenseID filter (optional) - get receipts for a specific expense
 	ExpenseID string
 
+	// Sort is created_at (default), date, amount or merchant
+	// Order is desc (default) or asc
+	Sort  string
+	Order string
+
+	// Cursor is a next_cursor or prev_cursor of an earlier page (optional)
+	// Replaces Page; PageCursor is the decoded cursor, set by validation
+	Cursor     string
+	PageCursor *pagination.Cursor
+
 	// Page number for pagination (default: 1)
 	Page int
 
 	// Limit is items per page (default: 20, max: 100)
 	Limit int
+
+	// SkipCount skips counting the matching receipts (no total and pages)
+	SkipCount bool
 }
 
 // ListReceiptsResponse contains the list of receipts and pagination info
+// total and pages are left out with count=false, page and pages when paging with cursors
 type ListReceiptsResponse struct {
 	Receipts []ReceiptResponse `json:"receipts"`
-	Total    int               `json:"total"` // Total number of receipts (before pagination)
-	Page     int               `json:"page"`  // Current page number
-	Limit    int               `json:"limit"` // Items per page
-	Pages    int               `json:"pages"` // Total number of pages
+	Total    *int              `json:"total,omitempty"` // Total number of receipts (before pagination)
+	Page     int               `json:"page,omitempty"`  // Current page number
+	Limit    int               `json:"limit"`           // Items per page
+	Pages    *int              `json:"pages,omitempty"` // Total number of pages
+
+	// NextCursor and PrevCursor fetch the neighbouring pages (left out if there are none)
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
 }
@@ -0,0 +1,29 @@
+package model
+
+import "expense-tracker/shared/pagination"
+
+// Sort fields of receipt listings
+const (
+	SortCreatedAt = "created_at" // Upload time (default)
+	SortDate      = "date"       // Receipt date
+	SortAmount    = "amount"     // Total amount
+	SortMerchant  = "merchant"   // Merchant name
+)
+
+// Sort orders
+const (
+	SortAsc  = "asc"
+	SortDesc = "desc" // Default
+)
+
+// ReceiptPage is a page of a user's receipts
+type ReceiptPage struct {
+	Receipts []*Receipt
+
+	// Total is the number of matching receipts (nil if not counted)
+	Total *int
+
+	// NextCursor and PrevCursor point to the neighbouring pages (nil if there are none)
+	NextCursor *pagination.Cursor
+	PrevCursor *pagination.Cursor
+}
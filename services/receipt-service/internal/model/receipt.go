@@ -1,6 +1,7 @@
 package model
 
 import (
+	"expense-tracker/shared/money"
 	"time"
 
 	"github.com/google/uuid"
@@ -43,7 +44,8 @@ type Receipt struct {
 	ReceiptDate *time.Time `json:"receipt_date,omitempty" db:"receipt_date"`
 
 	// TotalAmount is the total amount from the receipt (nullable)
-	TotalAmount *string `json:"total_amount,omitempty" db:"total_amount"`
+	// DECIMAL(10,2) in database
+	TotalAmount *money.Money `json:"total_amount,omitempty" db:"total_amount"`
 
 	// CreatedAt tracks when the receipt was created
 	CreatedAt time.Time `json:"created_at" db:"created_at"`
@@ -0,0 +1,27 @@
+package model
+
+import "time"
+
+// UserErasure records that a user deleted their account
+// Their receipts are hidden when it is created and erased after PurgeAfter
+type UserErasure struct {
+	UserID string `json:"user_id" db:"user_id"`
+
+	// RequestedAt is when the user.deleted event was received
+	RequestedAt time.Time `json:"requested_at" db:"requested_at"`
+
+	// PurgeAfter is when the grace period ends (set by auth-service)
+	PurgeAfter time.Time `json:"purge_after" db:"purge_after"`
+
+	// PurgedAt is when the data was erased (nil until then)
+	PurgedAt *time.Time `json:"purged_at,omitempty" db:"purged_at"`
+}
+
+// NewUserErasure creates a new UserErasure requested now
+func NewUserErasure(userID string, purgeAfter time.Time) *UserErasure {
+	return &UserErasure{
+		UserID:      userID,
+		RequestedAt: time.Now(),
+		PurgeAfter:  purgeAfter,
+	}
+}
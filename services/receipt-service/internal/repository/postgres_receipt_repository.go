@@ -3,8 +3,12 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"expense-tracker/receipt-service/internal/model"
+	"expense-tracker/shared/money"
+	"expense-tracker/shared/pagination"
 	"fmt"
+	"slices"
 	"time"
 
 	"github.com/jackc/pgx/v5"
@@ -64,7 +68,7 @@ func (r *PostgresReceiptRepository) FindByID(ctx context.Context, id, userID str
 	var expenseID sql.NullString
 	var merchantName sql.NullString
 	var receiptDate sql.NullTime
-	var totalAmount sql.NullString
+	var totalAmount sql.Null[money.Money]
 	var deletedAt sql.NullTime
 
 	err := r.pool.QueryRow(ctx, query, id, userID).Scan(
@@ -102,7 +106,7 @@ func (r *PostgresReceiptRepository) FindByID(ctx context.Context, id, userID str
 		receipt.ReceiptDate = &receiptDate.Time
 	}
 	if totalAmount.Valid {
-		receipt.TotalAmount = &totalAmount.String
+		receipt.TotalAmount = &totalAmount.V
 	}
 	if deletedAt.Valid {
 		receipt.DeletedAt = &deletedAt.Time
@@ -133,7 +137,7 @@ func (r *PostgresReceiptRepository) FindByExpenseID(ctx context.Context, expense
 		var expenseIDVal sql.NullString
 		var merchantName sql.NullString
 		var receiptDate sql.NullTime
-		var totalAmount sql.NullString
+		var totalAmount sql.Null[money.Money]
 		var deletedAt sql.NullTime
 
 		err := rows.Scan(
@@ -167,7 +171,7 @@ func (r *PostgresReceiptRepository) FindByExpenseID(ctx context.Context, expense
 			receipt.ReceiptDate = &receiptDate.Time
 		}
 		if totalAmount.Valid {
-			receipt.TotalAmount = &totalAmount.String
+			receipt.TotalAmount = &totalAmount.V
 		}
 		if deletedAt.Valid {
 			receipt.DeletedAt = &deletedAt.Time
@@ -183,8 +187,12 @@ func (r *PostgresReceiptRepository) FindByExpenseID(ctx context.Context, expense
 	return receipts, nil
 }
 
-// FindByUserID finds all receipts for a user with optional filters and pagination
-func (r *PostgresReceiptRepository) FindByUserID(ctx context.Context, userID string, filters *model.ListReceiptsRequest) ([]*model.Receipt, int, error) {
+// FindByUserID finds a page of a user's receipts with optional filters and sorting
+//
+// Pages are found by keyset: with a cursor, the query continues after (or before)
+// the cursor's sort key values instead of skipping rows with OFFSET. One row more
+// than the limit is read to know whether there is another page
+func (r *PostgresReceiptRepository) FindByUserID(ctx context.Context, userID string, filters *model.ListReceiptsRequest) (*model.ReceiptPage, error) {
 	// Build WHERE clause dynamically based on filters
 	whereClause := "user_id = $1 AND deleted_at IS NULL"
 	args := []interface{}{userID}
@@ -194,15 +202,19 @@ func (r *PostgresReceiptRepository) FindByUserID(ctx context.Context, userID str
 	if filters.ExpenseID != "" {
 		whereClause += fmt.Sprintf(" AND expense_id = $%d", argIndex)
 		args = append(args, filters.ExpenseID)
-		argIndex++
 	}
 
+	page := &model.ReceiptPage{}
+
 	// Get total count (for pagination)
-	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM receipts WHERE %s", whereClause)
-	var total int
-	err := r.pool.QueryRow(ctx, countQuery, args...).Scan(&total)
-	if err != nil {
-		return nil, 0, err
+	if !filters.SkipCount {
+		countQuery := fmt.Sprintf("SELECT COUNT(*) FROM receipts WHERE %s", whereClause)
+		var total int
+		err := r.pool.QueryRow(ctx, countQuery, args...).Scan(&total)
+		if err != nil {
+			return nil, err
+		}
+		page.Total = &total
 	}
 
 	// Apply pagination
@@ -214,40 +226,59 @@ func (r *PostgresReceiptRepository) FindByUserID(ctx context.Context, userID str
 		limit = 100 // Max
 	}
 
-	page := filters.Page
-	if page <= 0 {
-		page = 1
+	offset := 0
+	cursor := filters.PageCursor
+	if cursor == nil && filters.Page > 1 {
+		offset = (filters.Page - 1) * limit
+	}
+
+	keys := receiptSortKeys(filters.Sort)
+	columns := ""
+	for _, key := range keys {
+		columns += ", " + key.Expr + "::text"
+	}
+
+	// A page before the cursor is read backwards from the cursor, then flipped
+	desc := filters.Order != model.SortAsc
+	backward := cursor != nil && cursor.Before
+	if cursor != nil {
+		if len(cursor.Values) != len(keys) {
+			return nil, errors.New("cursor must be a next_cursor or prev_cursor value")
+		}
+		var condition string
+		condition, args = pagination.After(keys, cursor.Values, desc != backward, args)
+		whereClause += " AND " + condition
 	}
-	offset := (page - 1) * limit
 
 	// Build SELECT query with pagination
 	query := fmt.Sprintf(`
 		SELECT id, user_id, expense_id, file_name, file_key, file_url, file_size, mime_type,
-		       merchant_name, receipt_date, total_amount, created_at, updated_at, deleted_at
+		       merchant_name, receipt_date, total_amount, created_at, updated_at, deleted_at%s
 		FROM receipts
 		WHERE %s
-		ORDER BY created_at DESC
+		ORDER BY %s
 		LIMIT $%d OFFSET $%d
-	`, whereClause, argIndex, argIndex+1)
+	`, columns, whereClause, pagination.OrderBy(keys, desc != backward), len(args)+1, len(args)+2)
 
-	args = append(args, limit, offset)
+	args = append(args, limit+1, offset)
 
 	rows, err := r.pool.Query(ctx, query, args...)
 	if err != nil {
-		return nil, 0, err
+		return nil, err
 	}
 	defer rows.Close()
 
 	var receipts []*model.Receipt
+	var values [][]string // Sort key values of each receipt
 	for rows.Next() {
 		var receipt model.Receipt
 		var expenseID sql.NullString
 		var merchantName sql.NullString
 		var receiptDate sql.NullTime
-		var totalAmount sql.NullString
+		var totalAmount sql.Null[money.Money]
 		var deletedAt sql.NullTime
 
-		err := rows.Scan(
+		dest := []interface{}{
 			&receipt.ID,
 			&receipt.UserID,
 			&expenseID,
@@ -262,9 +293,15 @@ func (r *PostgresReceiptRepository) FindByUserID(ctx context.Context, userID str
 			&receipt.CreatedAt,
 			&receipt.UpdatedAt,
 			&deletedAt,
-		)
+		}
+		keyValues := make([]string, len(keys))
+		for i := range keyValues {
+			dest = append(dest, &keyValues[i])
+		}
+
+		err := rows.Scan(dest...)
 		if err != nil {
-			return nil, 0, err
+			return nil, err
 		}
 
 		// Convert nullable fields
@@ -278,20 +315,33 @@ func (r *PostgresReceiptRepository) FindByUserID(ctx context.Context, userID str
 			receipt.ReceiptDate = &receiptDate.Time
 		}
 		if totalAmount.Valid {
-			receipt.TotalAmount = &totalAmount.String
+			receipt.TotalAmount = &totalAmount.V
 		}
 		if deletedAt.Valid {
 			receipt.DeletedAt = &deletedAt.Time
 		}
 
 		receipts = append(receipts, &receipt)
+		values = append(values, keyValues)
 	}
 
 	if err = rows.Err(); err != nil {
-		return nil, 0, err
+		return nil, err
+	}
+
+	more := len(receipts) > limit
+	if more {
+		receipts = receipts[:limit]
+		values = values[:limit]
+	}
+	if backward {
+		slices.Reverse(receipts)
+		slices.Reverse(values)
 	}
+	page.Receipts = receipts
 
-	return receipts, total, nil
+	page.NextCursor, page.PrevCursor = pagination.PageCursors(cursor, filters.Sort, filters.Order, values, more, offset)
+	return page, nil
 }
 
 // Update updates an existing receipt
@@ -351,3 +401,27 @@ func (r *PostgresReceiptRepository) Delete(ctx context.Context, id, userID strin
 
 	return nil
 }
+
+// ReceiptCursorTypes returns the types of the values of a cursor for a receipt
+// sorting (see pagination.DecodeCursor)
+func ReceiptCursorTypes(sort string) []pagination.KeyType {
+	return pagination.Types(receiptSortKeys(sort))
+}
+
+// receiptSortKeys returns the keys receipts are sorted by (see model.ListReceiptsRequest.Sort)
+// The last key is always the ID, so that the order is unique and a cursor points to one row.
+// Receipts without a date, amount or merchant sort as the lowest value (last when descending)
+func receiptSortKeys(sort string) []pagination.SortKey {
+	id := pagination.SortKey{Expr: "id", Type: pagination.UUID}
+
+	switch sort {
+	case model.SortDate:
+		return []pagination.SortKey{{Expr: "COALESCE(receipt_date, DATE '0001-01-01')", Type: pagination.Date}, id}
+	case model.SortAmount:
+		return []pagination.SortKey{{Expr: "COALESCE(total_amount, -1)", Type: pagination.Numeric}, id}
+	case model.SortMerchant:
+		return []pagination.SortKey{{Expr: "COALESCE(merchant_name, '')", Type: pagination.Text}, id}
+	default:
+		return []pagination.SortKey{{Expr: "created_at", Type: pagination.Timestamp}, id}
+	}
+}
@@ -19,10 +19,10 @@ type ReceiptRepository interface {
 	// Returns receipts linked to the given expense_id
 	FindByExpenseID(ctx context.Context, expenseID, userID string) ([]*model.Receipt, error)
 
-	// FindByUserID finds all receipts for a user with optional filters and pagination
+	// FindByUserID finds a page of a user's receipts with optional filters and sorting
 	// Filters: expense_id (optional)
-	// Pagination: page, limit
-	FindByUserID(ctx context.Context, userID string, filters *model.ListReceiptsRequest) ([]*model.Receipt, int, error)
+	// Pagination: cursor or page, limit; the total is not counted with SkipCount
+	FindByUserID(ctx context.Context, userID string, filters *model.ListReceiptsRequest) (*model.ReceiptPage, error)
 
 	// Update updates an existing receipt
 	// Verifies ownership through userID
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"expense-tracker/receipt-service/internal/model"
 	"expense-tracker/receipt-service/internal/repository"
+	"expense-tracker/shared/pagination"
 	"fmt"
 	"io"
 	"mime"
@@ -180,16 +181,20 @@ func (s *ReceiptService) GetReceiptsByExpense(ctx context.Context, expenseID, us
 	return responses, nil
 }
 
-// ListReceipts lists receipts for a user with optional filters and pagination
+// ListReceipts lists receipts for a user with optional filters, sorting and pagination
 func (s *ReceiptService) ListReceipts(ctx context.Context, userID string, filters *model.ListReceiptsRequest) (*model.ListReceiptsResponse, error) {
-	receipts, total, err := s.receiptRepo.FindByUserID(ctx, userID, filters)
+	if err := validateListReceiptsRequest(filters); err != nil {
+		return nil, err
+	}
+
+	result, err := s.receiptRepo.FindByUserID(ctx, userID, filters)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list receipts: %w", err)
 	}
 
 	// Generate presigned URLs for all receipts
-	responses := make([]model.ReceiptResponse, len(receipts))
-	for i, receipt := range receipts {
+	responses := make([]model.ReceiptResponse, len(result.Receipts))
+	for i, receipt := range result.Receipts {
 		presignedURL, err := s.s3Service.GetPresignedURL(ctx, receipt.FileKey, 1*time.Hour)
 		if err != nil {
 			return nil, fmt.Errorf("failed to generate presigned URL for receipt %s: %w", receipt.ID, err)
@@ -207,20 +212,66 @@ func (s *ReceiptService) ListReceipts(ctx context.Context, userID string, filter
 		limit = 100
 	}
 
-	page := filters.Page
-	if page <= 0 {
-		page = 1
+	resp := &model.ListReceiptsResponse{
+		Receipts: responses,
+		Total:    result.Total,
+		Limit:    limit,
 	}
 
-	pages := (total + limit - 1) / limit // Ceiling division
+	// Page numbers only apply without a cursor
+	if filters.PageCursor == nil {
+		resp.Page = filters.Page
+		if resp.Page <= 0 {
+			resp.Page = 1
+		}
+		if result.Total != nil {
+			pages := (*result.Total + limit - 1) / limit // Ceiling division
+			resp.Pages = &pages
+		}
+	}
 
-	return &model.ListReceiptsResponse{
-		Receipts: responses,
-		Total:    total,
-		Page:     page,
-		Limit:    limit,
-		Pages:    pages,
-	}, nil
+	if result.NextCursor != nil {
+		resp.NextCursor = result.NextCursor.Encode()
+	}
+	if result.PrevCursor != nil {
+		resp.PrevCursor = result.PrevCursor.Encode()
+	}
+
+	return resp, nil
+}
+
+// validateListReceiptsRequest validates the sorting and cursor of a listing
+// and fills in the default sorting (newest uploads first)
+func validateListReceiptsRequest(filters *model.ListReceiptsRequest) error {
+	switch filters.Sort {
+	case "":
+		filters.Sort = model.SortCreatedAt
+	case model.SortCreatedAt, model.SortDate, model.SortAmount, model.SortMerchant:
+	default:
+		return errors.New("sort must be created_at, date, amount or merchant")
+	}
+
+	switch filters.Order {
+	case "":
+		filters.Order = model.SortDesc
+	case model.SortAsc, model.SortDesc:
+	default:
+		return errors.New("order must be asc or desc")
+	}
+
+	// A cursor only works with the sorting it was created for
+	if filters.Cursor != "" {
+		cursor, err := pagination.DecodeCursor(filters.Cursor, repository.ReceiptCursorTypes(filters.Sort))
+		if err != nil {
+			return err
+		}
+		if cursor.Sort != filters.Sort || cursor.Order != filters.Order {
+			return errors.New("cursor cannot be used with a different sort or order")
+		}
+		filters.PageCursor = cursor
+	}
+
+	return nil
 }
 
 // LinkToExpense links a receipt to an expense
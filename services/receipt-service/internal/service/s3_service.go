@@ -12,6 +12,7 @@ import (
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 	"github.com/aws/smithy-go"
 )
 
@@ -126,6 +127,51 @@ func (s *S3Service) DeleteFile(ctx context.Context, key string) error {
 	return nil
 }
 
+// DeletePrefix deletes every file whose key starts with prefix
+// Returns how many files were deleted
+// Used to erase all receipts of a deleted user (prefix "userID/")
+func (s *S3Service) DeletePrefix(ctx context.Context, prefix string) (int, error) {
+	paginator := s3.NewListObjectsV2Paginator(s.client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(s.bucketName),
+		Prefix: aws.String(prefix),
+	})
+
+	deleted := 0
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return deleted, fmt.Errorf("failed to list files in S3: %w", err)
+		}
+		if len(page.Contents) == 0 {
+			continue
+		}
+
+		// A page holds at most 1000 keys - the DeleteObjects limit
+		objects := make([]types.ObjectIdentifier, 0, len(page.Contents))
+		for _, object := range page.Contents {
+			objects = append(objects, types.ObjectIdentifier{Key: object.Key})
+		}
+
+		output, err := s.client.DeleteObjects(ctx, &s3.DeleteObjectsInput{
+			Bucket: aws.String(s.bucketName),
+			Delete: &types.Delete{
+				Objects: objects,
+				Quiet:   aws.Bool(true), // Only report failures
+			},
+		})
+		if err != nil {
+			return deleted, fmt.Errorf("failed to delete files from S3: %w", err)
+		}
+		if len(output.Errors) > 0 {
+			return deleted, fmt.Errorf("failed to delete %d files from S3: %s", len(output.Errors), aws.ToString(output.Errors[0].Message))
+		}
+
+		deleted += len(objects)
+	}
+
+	return deleted, nil
+}
+
 // FileExists checks if a file exists in S3
 func (s *S3Service) FileExists(ctx context.Context, key string) (bool, error) {
 	input := &s3.HeadObjectInput{
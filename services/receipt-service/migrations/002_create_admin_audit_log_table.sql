@@ -0,0 +1,36 @@
+-- Migration: Create admin_audit_log table
+-- Records every request made to an admin endpoint
+-- Run this script after 001_create_receipts_table.sql
+
+-- Create the admin_audit_log table
+CREATE TABLE IF NOT EXISTS admin_audit_log (
+    -- UUID primary key
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+
+    -- Admin who made the request
+    admin_user_id UUID NOT NULL,
+    admin_email VARCHAR(255) NOT NULL,
+
+    -- What was done (e.g. "receipts.list")
+    action VARCHAR(100) NOT NULL,
+
+    -- User whose data was accessed - NULL for actions not about one user
+    target_user_id UUID NULL,
+
+    -- Request details
+    method VARCHAR(10) NOT NULL,
+    path TEXT NOT NULL,
+    client_ip VARCHAR(100) NOT NULL,
+
+    -- When the request was made
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- Index on admin_user_id (what did this admin look at?)
+CREATE INDEX IF NOT EXISTS idx_admin_audit_log_admin ON admin_audit_log(admin_user_id, created_at);
+
+-- Index on target_user_id (who looked at this user's data?)
+CREATE INDEX IF NOT EXISTS idx_admin_audit_log_target ON admin_audit_log(target_user_id, created_at);
+
+-- Add a comment to the table (documentation)
+COMMENT ON TABLE admin_audit_log IS 'Audit trail of admin endpoint access';
@@ -0,0 +1,22 @@
+-- Migration: Create user_erasures table
+-- Tracks deleted accounts whose data must be erased (from auth-service user.deleted events)
+-- Run this script after 002_create_admin_audit_log_table.sql
+
+-- Create the user_erasures table
+CREATE TABLE IF NOT EXISTS user_erasures (
+    -- One row per deleted user
+    user_id UUID PRIMARY KEY,
+
+    -- When the user.deleted event was received
+    requested_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    -- The user's receipts are hidden right away and erased after this time (grace period)
+    purge_after TIMESTAMP NOT NULL,
+
+    -- When the receipts were erased - NULL until then
+    purged_at TIMESTAMP NULL
+);
+
+-- Index on purge_after (for finding erasures that are due)
+-- Only pending erasures are indexed
+CREATE INDEX IF NOT EXISTS idx_user_erasures_purge_after ON user_erasures(purge_after) WHERE purged_at IS NULL;
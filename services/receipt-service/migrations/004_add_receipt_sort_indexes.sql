@@ -0,0 +1,12 @@
+-- Migration: Add indexes for sorted, cursor-paginated receipt listings
+-- Each index covers a sort key followed by the ID tie-breaker of the cursor
+-- (see receiptSortKeys), so a page is read straight from the index
+-- Run this script after 003_create_user_erasures_table.sql
+
+-- Sorting by upload time (default)
+CREATE INDEX IF NOT EXISTS idx_receipts_user_created_keyset ON receipts(user_id, created_at, id) WHERE deleted_at IS NULL;
+
+-- Sorting by receipt date, amount and merchant (the same expressions as receiptSortKeys)
+CREATE INDEX IF NOT EXISTS idx_receipts_user_date_keyset ON receipts(user_id, COALESCE(receipt_date, DATE '0001-01-01'), id) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_receipts_user_amount_keyset ON receipts(user_id, COALESCE(total_amount, -1), id) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_receipts_user_merchant_keyset ON receipts(user_id, COALESCE(merchant_name, ''), id) WHERE deleted_at IS NULL;
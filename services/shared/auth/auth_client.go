@@ -1,4 +1,4 @@
-package service
+package auth
 
 import (
 	"context"
@@ -21,6 +21,10 @@ type ValidateResponse struct {
 	Valid  bool   `json:"valid"`
 	UserID string `json:"user_id,omitempty"`
 	Email  string `json:"email,omitempty"`
+	Role   string `json:"role,omitempty"`
+
+	// Scopes is set for API keys that are limited to some scopes
+	Scopes []string `json:"scopes,omitempty"`
 }
 
 // NewAuthClient creates a new auth client
@@ -34,16 +38,16 @@ func NewAuthClient(authServiceURL string) *AuthClient {
 	}
 }
 
-// ValidateToken calls auth-service to validate a JWT token
-// Returns user_id and email if token is valid, error otherwise
-func (c *AuthClient) ValidateToken(ctx context.Context, token string) (userID, email string, err error) {
+// ValidateToken calls auth-service to validate a JWT token or API key
+// Returns the token's user if valid, error otherwise
+func (c *AuthClient) ValidateToken(ctx context.Context, token string) (*AuthUser, error) {
 	// Build the request URL
 	url := fmt.Sprintf("%s/auth/validate", c.authServiceURL)
 
 	// Create HTTP request
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
-		return "", "", fmt.Errorf("failed to create request: %w", err)
+		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	// Set Authorization header
@@ -53,35 +57,40 @@ func (c *AuthClient) ValidateToken(ctx context.Context, token string) (userID, e
 	// Make the HTTP call
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return "", "", fmt.Errorf("failed to call auth-service: %w", err)
+		return nil, fmt.Errorf("failed to call auth-service: %w", err)
 	}
 	defer resp.Body.Close()
 
 	// Read response body
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", "", fmt.Errorf("failed to read response: %w", err)
+		return nil, fmt.Errorf("failed to read response: %w", err)
 	}
 
 	// Check status code
 	if resp.StatusCode == http.StatusUnauthorized {
-		return "", "", fmt.Errorf("invalid token")
+		return nil, fmt.Errorf("invalid token")
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return "", "", fmt.Errorf("auth-service returned status %d: %s", resp.StatusCode, string(body))
+		return nil, fmt.Errorf("auth-service returned status %d: %s", resp.StatusCode, string(body))
 	}
 
 	// Parse response
 	var validateResp ValidateResponse
 	if err := json.Unmarshal(body, &validateResp); err != nil {
-		return "", "", fmt.Errorf("failed to parse response: %w", err)
+		return nil, fmt.Errorf("failed to parse response: %w", err)
 	}
 
 	// Check if token is valid
 	if !validateResp.Valid {
-		return "", "", fmt.Errorf("invalid token")
+		return nil, fmt.Errorf("invalid token")
 	}
 
-	return validateResp.UserID, validateResp.Email, nil
+	return &AuthUser{
+		UserID: validateResp.UserID,
+		Email:  validateResp.Email,
+		Role:   validateResp.Role,
+		Scopes: validateResp.Scopes,
+	}, nil
 }
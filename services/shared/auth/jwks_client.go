@@ -0,0 +1,233 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ErrSigningKeysUnavailable means tokens can't be verified locally right now
+// (auth-service's key set could not be fetched). Callers may fall back to
+// remote validation - it says nothing about whether the token is valid.
+var ErrSigningKeysUnavailable = errors.New("signing keys unavailable")
+
+// minJWKSRefetchInterval limits on-demand refreshes triggered by unknown key IDs
+// Without it, a flood of tokens with made-up "kid"s would hammer auth-service
+const minJWKSRefetchInterval = 30 * time.Second
+
+// JWKSClient fetches and caches auth-service's public signing keys
+// from GET /.well-known/jwks.json, so tokens can be verified without
+// calling auth-service on every request
+type JWKSClient struct {
+	jwksURL         string
+	httpClient      *http.Client
+	refreshInterval time.Duration
+
+	// mu protects keys; refreshMu makes sure only one refresh runs at a time
+	mu        sync.RWMutex
+	keys      map[string]*verificationKey
+	refreshMu sync.Mutex
+	lastFetch time.Time // Last refresh attempt (successful or not)
+}
+
+// verificationKey is one public key from the JWKS
+type verificationKey struct {
+	method    jwt.SigningMethod
+	publicKey crypto.PublicKey
+}
+
+// jwk mirrors the JSON Web Key format served by auth-service
+type jwk struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	N         string `json:"n"`
+	E         string `json:"e"`
+	Curve     string `json:"crv"`
+	X         string `json:"x"`
+}
+
+// NewJWKSClient creates a new key set client
+// authServiceURL: base URL of auth-service (e.g., "http://localhost:8080")
+// refreshInterval: how often the background refresh runs (see Run)
+func NewJWKSClient(authServiceURL string, refreshInterval time.Duration) *JWKSClient {
+	return &JWKSClient{
+		jwksURL: fmt.Sprintf("%s/.well-known/jwks.json", authServiceURL),
+		httpClient: &http.Client{
+			Timeout: 5 * time.Second, // 5 second timeout, like other auth calls
+		},
+		refreshInterval: refreshInterval,
+		keys:            make(map[string]*verificationKey),
+	}
+}
+
+// Run refreshes the key set periodically until ctx is cancelled
+// Start it in a goroutine. Failed refreshes keep the previously cached keys.
+func (c *JWKSClient) Run(ctx context.Context) {
+	ticker := time.NewTicker(c.refreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := c.Refresh(ctx); err != nil {
+				log.Printf("Warning: failed to refresh signing keys: %v (keeping cached keys)", err)
+			}
+		}
+	}
+}
+
+// Refresh fetches the key set from auth-service and replaces the cache
+func (c *JWKSClient) Refresh(ctx context.Context) error {
+	c.refreshMu.Lock()
+	defer c.refreshMu.Unlock()
+
+	return c.refreshLocked(ctx)
+}
+
+// key returns the public key with the given key ID
+// Unknown key IDs trigger a (rate-limited) refresh first, so keys that
+// auth-service just started publishing are picked up without waiting for Run
+func (c *JWKSClient) key(ctx context.Context, kid string) (*verificationKey, error) {
+	if key := c.cachedKey(kid); key != nil {
+		return key, nil
+	}
+
+	c.refreshMu.Lock()
+	// Another request may have refreshed while we waited for the lock
+	if key := c.cachedKey(kid); key != nil {
+		c.refreshMu.Unlock()
+		return key, nil
+	}
+	var refreshErr error
+	if time.Since(c.lastFetch) >= minJWKSRefetchInterval {
+		refreshErr = c.refreshLocked(ctx)
+	}
+	c.refreshMu.Unlock()
+
+	if key := c.cachedKey(kid); key != nil {
+		return key, nil
+	}
+
+	// Without any keys (or a working auth-service) we can't tell
+	// whether the token is good - let the caller decide what to do
+	if refreshErr != nil || c.keyCount() == 0 {
+		return nil, ErrSigningKeysUnavailable
+	}
+	return nil, errors.New("unknown signing key")
+}
+
+// refreshLocked fetches the key set - refreshMu must be held
+func (c *JWKSClient) refreshLocked(ctx context.Context) error {
+	c.lastFetch = time.Now()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", c.jwksURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call auth-service: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("auth-service returned status %d", resp.StatusCode)
+	}
+
+	var body struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return fmt.Errorf("failed to parse key set: %w", err)
+	}
+
+	keys := make(map[string]*verificationKey, len(body.Keys))
+	for _, k := range body.Keys {
+		key, err := parseJWK(k)
+		if err != nil {
+			// Skip keys we don't understand rather than failing the whole set
+			log.Printf("Warning: skipping signing key %q: %v", k.KeyID, err)
+			continue
+		}
+		keys[k.KeyID] = key
+	}
+	if len(keys) == 0 {
+		return errors.New("key set contains no usable keys")
+	}
+
+	c.mu.Lock()
+	c.keys = keys
+	c.mu.Unlock()
+
+	return nil
+}
+
+// cachedKey looks up a key without refreshing
+func (c *JWKSClient) cachedKey(kid string) *verificationKey {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.keys[kid]
+}
+
+// keyCount returns how many keys are cached
+func (c *JWKSClient) keyCount() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.keys)
+}
+
+// parseJWK converts a JSON Web Key into a public key
+// Supports RSA (RS256) and Ed25519 (EdDSA) - the key types auth-service signs with
+func parseJWK(k jwk) (*verificationKey, error) {
+	if k.Use != "" && k.Use != "sig" {
+		return nil, fmt.Errorf("key use %q is not \"sig\"", k.Use)
+	}
+
+	switch {
+	case k.KeyType == "RSA" && k.Algorithm == "RS256":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("invalid modulus: %w", err)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, fmt.Errorf("invalid exponent: %w", err)
+		}
+		return &verificationKey{
+			method: jwt.SigningMethodRS256,
+			publicKey: &rsa.PublicKey{
+				N: new(big.Int).SetBytes(n),
+				E: int(new(big.Int).SetBytes(e).Int64()),
+			},
+		}, nil
+
+	case k.KeyType == "OKP" && k.Curve == "Ed25519" && k.Algorithm == "EdDSA":
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid Ed25519 public key")
+		}
+		return &verificationKey{
+			method:    jwt.SigningMethodEdDSA,
+			publicKey: ed25519.PublicKey(x),
+		}, nil
+	}
+
+	return nil, fmt.Errorf("unsupported key type %q / algorithm %q", k.KeyType, k.Algorithm)
+}
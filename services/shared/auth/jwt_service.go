@@ -0,0 +1,88 @@
+package auth
+
+import (
+	"context"
+	"errors"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// JWTService verifies auth-service access tokens locally
+// This service only validates tokens (doesn't generate them)
+// Token generation is done by auth-service, which publishes its public keys as a JWKS
+// Ended sessions are not visible here: an access token stays valid until it
+// expires even if its session was signed out (only auth-service knows)
+type JWTService struct {
+	// keys is the cached set of auth-service public keys
+	keys *JWKSClient
+}
+
+// Claims represents the data stored in the JWT token
+// This must match the Claims structure in auth-service
+type Claims struct {
+	UserID    string `json:"user_id"`
+	Email     string `json:"email"`
+	Role      string `json:"role,omitempty"`
+	SessionID string `json:"sid,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// NewJWTService creates a new JWT validation service
+func NewJWTService(keys *JWKSClient) *JWTService {
+	return &JWTService{
+		keys: keys,
+	}
+}
+
+// ValidateToken verifies a token's signature and claims without calling auth-service
+// Returns the token's user if valid, error otherwise
+// Returns ErrSigningKeysUnavailable if the keys needed to decide aren't available
+//
+// Note: unlike /auth/validate, this can't see revoked sessions (logout) -
+// a revoked access token keeps working here until it expires. The same goes
+// for role changes, which only show up in newly issued tokens.
+func (s *JWTService) ValidateToken(ctx context.Context, tokenString string) (*AuthUser, error) {
+	// Parse the token
+	// The function validates the signature and expiration automatically
+	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
+		// The "kid" header tells us which of auth-service's keys signed the token
+		kid, _ := token.Header["kid"].(string)
+		key, err := s.keys.key(ctx, kid)
+		if err != nil {
+			return nil, err
+		}
+		// The algorithm must match the key type (prevents algorithm confusion attacks)
+		if token.Method.Alg() != key.method.Alg() {
+			return nil, errors.New("unexpected signing method")
+		}
+		return key.publicKey, nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodEdDSA.Alg()}),
+		jwt.WithIssuer("auth-service"),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		if errors.Is(err, ErrSigningKeysUnavailable) {
+			return nil, ErrSigningKeysUnavailable
+		}
+		return nil, errors.New("invalid token")
+	}
+
+	// Extract the claims
+	claims, ok := token.Claims.(*Claims)
+	if !ok || !token.Valid {
+		return nil, errors.New("invalid token")
+	}
+
+	// Access tokens have no audience and always belong to a session
+	// (MFA challenge tokens have an audience and must be rejected)
+	if len(claims.Audience) > 0 || claims.SessionID == "" || claims.UserID == "" {
+		return nil, errors.New("invalid token")
+	}
+
+	return &AuthUser{
+		UserID: claims.UserID,
+		Email:  claims.Email,
+		Role:   claims.Role,
+	}, nil
+}
@@ -0,0 +1,106 @@
+// Package auth validates the access tokens and API keys auth-service issues
+// It is shared by the services that authenticate requests with auth-service
+// (expense-service, receipt-service)
+package auth
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+)
+
+// apiKeyPrefix starts every personal API key issued by auth-service
+// API keys can only be checked by auth-service (they are not JWTs)
+const apiKeyPrefix = "etk_"
+
+// AuthUser is the user an access token belongs to
+type AuthUser struct {
+	UserID string
+	Email  string
+	// Role is "user" or "admin"
+	Role string
+	// Scopes limits what an API key can do (empty for JWTs and unlimited keys)
+	Scopes []string
+}
+
+// CanAccess reports whether the token may make a request with the given HTTP method
+// to a service whose scopes start with resource (e.g. "expenses")
+// Without scopes everything is allowed; "expenses:read" allows reads (GET),
+// "expenses:write" allows reads and writes
+func (u *AuthUser) CanAccess(resource, method string) bool {
+	if len(u.Scopes) == 0 {
+		return true
+	}
+
+	isRead := method == http.MethodGet || method == http.MethodHead
+	for _, scope := range u.Scopes {
+		if scope == resource+":write" || (isRead && scope == resource+":read") {
+			return true
+		}
+	}
+	return false
+}
+
+// isAPIKey reports whether a bearer token is an API key instead of a JWT
+func isAPIKey(token string) bool {
+	return strings.HasPrefix(token, apiKeyPrefix)
+}
+
+// TokenValidator validates an access token and returns who it belongs to
+// Implemented by AuthClient (asks auth-service) and JWTService (verifies locally)
+type TokenValidator interface {
+	ValidateToken(ctx context.Context, token string) (*AuthUser, error)
+}
+
+// APIKeyRouter sends API keys to auth-service and every other token to the
+// JWT validator. Local verification can't check API keys, so this must wrap
+// whichever validator the service is configured with
+type APIKeyRouter struct {
+	jwt    TokenValidator
+	remote *AuthClient
+}
+
+// NewAPIKeyRouter creates a validator that checks API keys with auth-service
+func NewAPIKeyRouter(jwt TokenValidator, remote *AuthClient) *APIKeyRouter {
+	return &APIKeyRouter{
+		jwt:    jwt,
+		remote: remote,
+	}
+}
+
+// ValidateToken validates API keys remotely and JWTs with the JWT validator
+func (v *APIKeyRouter) ValidateToken(ctx context.Context, token string) (*AuthUser, error) {
+	if isAPIKey(token) {
+		return v.remote.ValidateToken(ctx, token)
+	}
+	return v.jwt.ValidateToken(ctx, token)
+}
+
+// FallbackValidator verifies JWTs locally and only asks auth-service
+// when the signing keys are unavailable (e.g. the JWKS was never fetched).
+// JWTs that fail local verification are rejected without a remote call.
+// API keys are routed by APIKeyRouter
+type FallbackValidator struct {
+	local  *JWTService
+	remote *AuthClient
+}
+
+// NewFallbackValidator creates a validator that prefers local verification
+func NewFallbackValidator(local *JWTService, remote *AuthClient) *FallbackValidator {
+	return &FallbackValidator{
+		local:  local,
+		remote: remote,
+	}
+}
+
+// ValidateToken tries local verification first, then auth-service
+func (v *FallbackValidator) ValidateToken(ctx context.Context, token string) (*AuthUser, error) {
+	user, err := v.local.ValidateToken(ctx, token)
+	if errors.Is(err, ErrSigningKeysUnavailable) {
+		log.Printf("Signing keys unavailable - validating token via auth-service")
+		return v.remote.ValidateToken(ctx, token)
+	}
+	return user, err
+}
@@ -0,0 +1,61 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// stubValidator accepts every token as the given user
+type stubValidator struct {
+	user *AuthUser
+}
+
+func (v stubValidator) ValidateToken(ctx context.Context, token string) (*AuthUser, error) {
+	if v.user == nil {
+		return nil, errors.New("invalid token")
+	}
+	return v.user, nil
+}
+
+func TestAPIKeyRouter(t *testing.T) {
+	authService := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer etk_valid" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		json.NewEncoder(w).Encode(ValidateResponse{Valid: true, UserID: "key-user", Scopes: []string{"expenses:read"}})
+	}))
+	defer authService.Close()
+
+	remote := NewAuthClient(authService.URL)
+	jwtUser := &AuthUser{UserID: "jwt-user"}
+
+	tests := []struct {
+		name     string
+		jwt      TokenValidator
+		token    string
+		wantUser string
+		wantErr  bool
+	}{
+		{"api key goes to auth-service", stubValidator{}, "etk_valid", "key-user", false},
+		{"unknown api key", stubValidator{user: jwtUser}, "etk_revoked", "", true},
+		{"jwt stays local", stubValidator{user: jwtUser}, "eyJhbGciOi.x.y", "jwt-user", false},
+		{"invalid jwt", stubValidator{}, "eyJhbGciOi.x.y", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user, err := NewAPIKeyRouter(tt.jwt, remote).ValidateToken(context.Background(), tt.token)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateToken error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && user.UserID != tt.wantUser {
+				t.Errorf("ValidateToken user = %q, want %q", user.UserID, tt.wantUser)
+			}
+		})
+	}
+}
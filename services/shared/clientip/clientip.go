@@ -0,0 +1,131 @@
+// Package clientip works out the IP address of the client that made a request
+// X-Forwarded-For and X-Real-IP are only believed when the request comes from
+// a trusted proxy (e.g. the load balancer) - anyone else could send any value
+package clientip
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// contextKey is the type of the context key the resolved IP is stored under
+type contextKey struct{}
+
+// Resolver finds the client IP of requests
+type Resolver struct {
+	trusted []*net.IPNet
+}
+
+// NewResolver creates a resolver that trusts forwarding headers from the given
+// proxies - CIDRs ("10.0.0.0/16") or single addresses ("10.0.0.5")
+// Without trusted proxies the TCP peer address is always used
+func NewResolver(trustedProxies []string) (*Resolver, error) {
+	r := &Resolver{}
+	for _, proxy := range trustedProxies {
+		proxy = strings.TrimSpace(proxy)
+		if proxy == "" {
+			continue
+		}
+
+		if !strings.Contains(proxy, "/") {
+			ip := net.ParseIP(proxy)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q", proxy)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			r.trusted = append(r.trusted, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, network, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q", proxy)
+		}
+		r.trusted = append(r.trusted, network)
+	}
+	return r, nil
+}
+
+// ClientIP returns the IP address of the client that made the request
+// X-Forwarded-For is read from the right: every hop added by a trusted proxy is
+// skipped and the first address that isn't a trusted proxy is the client.
+// The result is always a plain IP address (never a header value as sent)
+func (r *Resolver) ClientIP(req *http.Request) string {
+	addr := peerIP(req.RemoteAddr)
+	if addr == nil {
+		return req.RemoteAddr
+	}
+	if !r.isTrusted(addr) {
+		return addr.String()
+	}
+
+	values := req.Header.Values("X-Forwarded-For")
+	if len(values) == 0 {
+		// Proxies that only set X-Real-IP (e.g. nginx)
+		if ip := net.ParseIP(strings.TrimSpace(req.Header.Get("X-Real-IP"))); ip != nil {
+			return ip.String()
+		}
+		return addr.String()
+	}
+
+	// Several X-Forwarded-For headers are one list
+	forwarded := strings.Split(strings.Join(values, ","), ",")
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		ip := net.ParseIP(strings.TrimSpace(forwarded[i]))
+		if ip == nil {
+			// Garbage in the header - the last proxy we trust is as far as we can tell
+			break
+		}
+		addr = ip
+		if !r.isTrusted(ip) {
+			break
+		}
+	}
+	return addr.String()
+}
+
+// Middleware resolves the client IP once per request and stores it in the
+// request context for FromRequest
+func (r *Resolver) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		ctx := context.WithValue(req.Context(), contextKey{}, r.ClientIP(req))
+		next.ServeHTTP(w, req.WithContext(ctx))
+	})
+}
+
+// FromRequest returns the client IP stored by Middleware
+// Without the middleware it is the TCP peer address (headers are never trusted)
+func FromRequest(req *http.Request) string {
+	if ip, ok := req.Context().Value(contextKey{}).(string); ok {
+		return ip
+	}
+	if ip := peerIP(req.RemoteAddr); ip != nil {
+		return ip.String()
+	}
+	return req.RemoteAddr
+}
+
+// isTrusted reports whether ip is one of the trusted proxies
+func (r *Resolver) isTrusted(ip net.IP) bool {
+	for _, network := range r.trusted {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// peerIP parses the IP address of a "host:port" remote address
+func peerIP(remoteAddr string) net.IP {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+	return net.ParseIP(host)
+}
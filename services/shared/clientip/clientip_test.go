@@ -0,0 +1,84 @@
+package clientip
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestClientIP(t *testing.T) {
+	resolver, err := NewResolver([]string{"10.0.0.0/8", "192.168.1.1", "fd00::/8"})
+	if err != nil {
+		t.Fatalf("NewResolver: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		forwarded  []string
+		realIP     string
+		want       string
+	}{
+		{"direct client", "203.0.113.7:5000", nil, "", "203.0.113.7"},
+		{"untrusted peer with spoofed header", "203.0.113.7:5000", []string{"1.2.3.4"}, "", "203.0.113.7"},
+		{"untrusted peer with spoofed real ip", "203.0.113.7:5000", nil, "1.2.3.4", "203.0.113.7"},
+		{"trusted proxy", "10.0.0.5:5000", []string{"198.51.100.9"}, "", "198.51.100.9"},
+		{"trusted single address", "192.168.1.1:5000", []string{"198.51.100.9"}, "", "198.51.100.9"},
+		{"client-sent prefix is ignored", "10.0.0.5:5000", []string{"1.2.3.4, 198.51.100.9"}, "", "198.51.100.9"},
+		{"chain of trusted proxies", "10.0.0.5:5000", []string{"198.51.100.9, 10.1.2.3"}, "", "198.51.100.9"},
+		{"several headers", "10.0.0.5:5000", []string{"1.2.3.4", "198.51.100.9"}, "", "198.51.100.9"},
+		{"garbage stops at last proxy", "10.0.0.5:5000", []string{"not-an-ip, 10.1.2.3"}, "", "10.1.2.3"},
+		{"overlong header", "10.0.0.5:5000", []string{strings.Repeat("x", 500)}, "", "10.0.0.5"},
+		{"only proxies", "10.0.0.5:5000", []string{"10.9.9.9"}, "", "10.9.9.9"},
+		{"real ip from trusted proxy", "10.0.0.5:5000", nil, "198.51.100.9", "198.51.100.9"},
+		{"invalid real ip", "10.0.0.5:5000", nil, "nope", "10.0.0.5"},
+		{"ipv6", "[fd00::1]:5000", []string{"2001:db8::1"}, "", "2001:db8::1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			for _, value := range tt.forwarded {
+				req.Header.Add("X-Forwarded-For", value)
+			}
+			if tt.realIP != "" {
+				req.Header.Set("X-Real-IP", tt.realIP)
+			}
+
+			if got := resolver.ClientIP(req); got != tt.want {
+				t.Errorf("ClientIP = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewResolverRejectsInvalidProxies(t *testing.T) {
+	for _, proxy := range []string{"10.0.0.0/33", "not-an-ip", "10.0.0"} {
+		if _, err := NewResolver([]string{proxy}); err == nil {
+			t.Errorf("NewResolver(%q) succeeded, want an error", proxy)
+		}
+	}
+}
+
+func TestFromRequest(t *testing.T) {
+	resolver, _ := NewResolver([]string{"10.0.0.0/8"})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "10.0.0.5:5000"
+	req.Header.Set("X-Forwarded-For", "198.51.100.9")
+
+	// Without the middleware headers are never trusted
+	if got := FromRequest(req); got != "10.0.0.5" {
+		t.Errorf("FromRequest without middleware = %q, want %q", got, "10.0.0.5")
+	}
+
+	var got string
+	resolver.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = FromRequest(r)
+	})).ServeHTTP(httptest.NewRecorder(), req)
+	if got != "198.51.100.9" {
+		t.Errorf("FromRequest with middleware = %q, want %q", got, "198.51.100.9")
+	}
+}
@@ -0,0 +1,14 @@
+// Package events holds the event format the services exchange through SNS and SQS
+// and the consumer that reads events from an SQS queue
+package events
+
+import "time"
+
+// Event is an event published to an SNS topic (and delivered to subscribed queues)
+type Event struct {
+	EventType string                 `json:"event_type"`
+	UserID    string                 `json:"user_id"`
+	UserEmail string                 `json:"user_email"`
+	Timestamp time.Time              `json:"timestamp"`
+	Data      map[string]interface{} `json:"data"`
+}
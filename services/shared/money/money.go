@@ -0,0 +1,217 @@
+// Package money holds exact decimal amounts and exchange rates shared by the services
+package money
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Money is an exact decimal amount - never a float, so nothing is lost to
+// binary rounding (0.1 + 0.2 is 0.3). 12.34 is stored as 1234 units with a scale of 2
+type Money struct {
+	units int64 // The amount times 10^scale
+	scale int   // Number of decimal places
+}
+
+// maxAmountDigits is how many digits an amount may have before the decimal point
+// Amount columns are DECIMAL(11, 3) in expense-service (8 integer digits, up to
+// 3 decimals) and DECIMAL(10, 2) in receipt-service (8 integer digits, 2 decimals)
+const maxAmountDigits = 8
+
+// maxMoneyDigits is how many digits fit into the int64 units
+const maxMoneyDigits = 18
+
+// ParseAmount strictly parses an amount a user entered in the given currency
+// Only plain decimals are accepted ("12", "12.5", "12.50") - no signs, exponents
+// ("1e3"), spaces, thousands separators, NaN or Infinity. The amount must be positive,
+// have no more decimals than the currency (2 for USD, 0 for JPY) and fit the amount columns.
+// The result has the currency's scale
+func ParseAmount(s, currency string) (Money, error) {
+	whole, fraction, hasPoint := strings.Cut(s, ".")
+	if !isDigits(whole) || (hasPoint && !isDigits(fraction)) {
+		return Money{}, errors.New("amount must be a decimal number like 12.34")
+	}
+
+	scale := CurrencyScale(currency)
+	if len(fraction) > scale {
+		if scale == 0 {
+			return Money{}, fmt.Errorf("amount must be a whole number for %s", currency)
+		}
+		return Money{}, fmt.Errorf("amount must have at most %d decimal places for %s", scale, currency)
+	}
+
+	if len(strings.TrimLeft(whole, "0")) > maxAmountDigits {
+		return Money{}, errors.New("amount must be less than 100000000")
+	}
+
+	amount, err := parseDecimal(s)
+	if err != nil {
+		return Money{}, errors.New("amount must be a decimal number like 12.34")
+	}
+	if amount.Sign() <= 0 {
+		return Money{}, errors.New("amount must be a positive number")
+	}
+
+	return amount.Round(scale), nil
+}
+
+// parseDecimal parses a decimal as PostgreSQL prints it ("-12.340")
+func parseDecimal(s string) (Money, error) {
+	digits := strings.TrimPrefix(s, "-")
+	negative := len(digits) < len(s)
+
+	whole, fraction, hasPoint := strings.Cut(digits, ".")
+	if !isDigits(whole) || (hasPoint && !isDigits(fraction)) {
+		return Money{}, fmt.Errorf("invalid decimal %q", s)
+	}
+
+	digits = strings.TrimLeft(whole+fraction, "0")
+	if len(digits) > maxMoneyDigits {
+		return Money{}, fmt.Errorf("decimal %q is out of range", s)
+	}
+
+	var units int64
+	if digits != "" {
+		units, _ = strconv.ParseInt(digits, 10, 64) // At most 18 digits always fit
+	}
+	if negative {
+		units = -units
+	}
+
+	return Money{units: units, scale: len(fraction)}, nil
+}
+
+// isDigits reports whether s is a non-empty string of ASCII digits
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// Round returns the amount with the given number of decimal places
+// Halves are rounded away from zero (0.125 -> 0.13)
+func (m Money) Round(scale int) Money {
+	switch {
+	case scale > m.scale:
+		return Money{units: m.units * pow10(scale-m.scale), scale: scale}
+	case scale < m.scale:
+		divisor := pow10(m.scale - scale)
+		units := m.units / divisor
+		if remainder := m.units % divisor; remainder*2 >= divisor {
+			units++
+		} else if remainder*2 <= -divisor {
+			units--
+		}
+		return Money{units: units, scale: scale}
+	default:
+		return m
+	}
+}
+
+// Add returns m + other, with the larger of the two scales
+func (m Money) Add(other Money) Money {
+	scale := m.scale
+	if other.scale > scale {
+		scale = other.scale
+	}
+	return Money{units: m.Round(scale).units + other.Round(scale).units, scale: scale}
+}
+
+// Sub returns m - other, with the larger of the two scales
+func (m Money) Sub(other Money) Money {
+	return m.Add(Money{units: -other.units, scale: other.scale})
+}
+
+// Sign returns -1, 0 or 1 for negative, zero and positive amounts
+func (m Money) Sign() int {
+	switch {
+	case m.units < 0:
+		return -1
+	case m.units > 0:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// Cmp compares two amounts: -1 if m < other, 0 if equal, 1 if m > other
+func (m Money) Cmp(other Money) int {
+	return m.Sub(other).Sign()
+}
+
+// Float64 returns the amount as a float - only for ratios (percentages, exchange rates)
+func (m Money) Float64() float64 {
+	return float64(m.units) / math.Pow10(m.scale)
+}
+
+// String formats the amount with all its decimal places ("12.50", "1000" for JPY)
+func (m Money) String() string {
+	units := m.units
+	sign := ""
+	if units < 0 {
+		sign = "-"
+		units = -units
+	}
+
+	digits := strconv.FormatInt(units, 10)
+	if m.scale == 0 {
+		return sign + digits
+	}
+	if len(digits) <= m.scale {
+		digits = strings.Repeat("0", m.scale-len(digits)+1) + digits
+	}
+	point := len(digits) - m.scale
+	return sign + digits[:point] + "." + digits[point:]
+}
+
+// MarshalJSON writes the amount as a JSON string ("12.50") so clients don't parse it as a float
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(m.String())
+}
+
+// Scan reads a NUMERIC column (implements sql.Scanner)
+func (m *Money) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case string:
+		parsed, err := parseDecimal(v)
+		if err != nil {
+			return err
+		}
+		*m = parsed
+		return nil
+	case []byte:
+		return m.Scan(string(v))
+	case int64:
+		*m = Money{units: v}
+		return nil
+	case nil:
+		return errors.New("cannot scan NULL into Money")
+	default:
+		return fmt.Errorf("cannot scan %T into Money", src)
+	}
+}
+
+// Value writes the amount to a NUMERIC column (implements driver.Valuer)
+func (m Money) Value() (driver.Value, error) {
+	return m.String(), nil
+}
+
+// pow10 returns 10^n as an int64
+func pow10(n int) int64 {
+	result := int64(1)
+	for i := 0; i < n; i++ {
+		result *= 10
+	}
+	return result
+}
@@ -0,0 +1,146 @@
+package money
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseAmount(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		currency string
+		want     string // Empty: the amount is rejected
+	}{
+		{"whole number", "12", "USD", "12.00"},
+		{"one decimal", "12.5", "USD", "12.50"},
+		{"two decimals", "12.34", "USD", "12.34"},
+		{"leading zeros", "0012.30", "USD", "12.30"},
+		{"smallest amount", "0.01", "USD", "0.01"},
+		{"no currency", "7.25", "", "7.25"},
+		{"yen", "1000", "JPY", "1000"},
+		{"three decimal currency", "1.234", "KWD", "1.234"},
+		{"largest amount", "99999999.99", "USD", "99999999.99"},
+		{"too many decimals", "12.345", "USD", ""},
+		{"decimals for yen", "1000.5", "JPY", ""},
+		{"too large", "100000000", "USD", ""},
+		{"zero", "0", "USD", ""},
+		{"zero with decimals", "0.00", "USD", ""},
+		{"negative", "-12.34", "USD", ""},
+		{"plus sign", "+12.34", "USD", ""},
+		{"exponent", "1e3", "USD", ""},
+		{"thousands separator", "1,000", "USD", ""},
+		{"decimal comma", "12,34", "USD", ""},
+		{"spaces", " 12.34", "USD", ""},
+		{"trailing point", "12.", "USD", ""},
+		{"leading point", ".5", "USD", ""},
+		{"NaN", "NaN", "USD", ""},
+		{"infinity", "Inf", "USD", ""},
+		{"empty", "", "USD", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseAmount(tt.input, tt.currency)
+			switch {
+			case tt.want == "" && err == nil:
+				t.Errorf("ParseAmount(%q, %q) = %s, want an error", tt.input, tt.currency, got)
+			case tt.want != "" && err != nil:
+				t.Errorf("ParseAmount(%q, %q) error = %v, want %s", tt.input, tt.currency, err, tt.want)
+			case tt.want != "" && got.String() != tt.want:
+				t.Errorf("ParseAmount(%q, %q) = %s, want %s", tt.input, tt.currency, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMoneyString(t *testing.T) {
+	tests := []struct {
+		name  string
+		money Money
+		want  string
+	}{
+		{"zero", Money{}, "0"},
+		{"zero cents", Money{scale: 2}, "0.00"},
+		{"cents only", Money{units: 5, scale: 2}, "0.05"},
+		{"amount", Money{units: 1234, scale: 2}, "12.34"},
+		{"three decimals", Money{units: 1234, scale: 3}, "1.234"},
+		{"whole", Money{units: 1000}, "1000"},
+		{"negative", Money{units: -1250, scale: 2}, "-12.50"},
+		{"negative cents", Money{units: -7, scale: 2}, "-0.07"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.money.String(); got != tt.want {
+				t.Errorf("String() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	data, err := json.Marshal(Money{units: 1250, scale: 2})
+	if err != nil || string(data) != `"12.50"` {
+		t.Errorf("MarshalJSON() = %s, %v, want \"12.50\"", data, err)
+	}
+}
+
+func TestMoneyRound(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		scale int
+		want  string
+	}{
+		{"more decimals", "12.5", 2, "12.50"},
+		{"same decimals", "12.34", 2, "12.34"},
+		{"round down", "12.344", 2, "12.34"},
+		{"half rounds up", "0.125", 2, "0.13"},
+		{"negative half rounds away from zero", "-0.125", 2, "-0.13"},
+		{"to whole", "999.5", 0, "1000"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, err := parseDecimal(tt.input)
+			if err != nil {
+				t.Fatalf("parseDecimal(%q) error = %v", tt.input, err)
+			}
+			if got := value.Round(tt.scale).String(); got != tt.want {
+				t.Errorf("Round(%d) = %s, want %s", tt.scale, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMoneyScan(t *testing.T) {
+	var m Money
+	if err := m.Scan("-12.340"); err != nil || m.String() != "-12.340" {
+		t.Errorf("Scan(\"-12.340\") = %s, %v", m, err)
+	}
+	if err := m.Scan([]byte("7.5")); err != nil || m.String() != "7.5" {
+		t.Errorf("Scan([]byte(\"7.5\")) = %s, %v", m, err)
+	}
+	if err := m.Scan(nil); err == nil {
+		t.Error("Scan(nil) succeeded, want an error")
+	}
+	if err := m.Scan("1234567890123456789"); err == nil {
+		t.Error("Scan of 19 digits succeeded, want an error")
+	}
+}
+
+func TestMoneyArithmetic(t *testing.T) {
+	a, _ := ParseAmount("0.1", "USD")
+	b, _ := ParseAmount("0.2", "USD")
+	c, _ := ParseAmount("0.3", "USD")
+
+	if sum := a.Add(b); sum.Cmp(c) != 0 {
+		t.Errorf("0.1 + 0.2 = %s, want 0.30", sum)
+	}
+	if diff := a.Sub(b); diff.String() != "-0.10" || diff.Sign() != -1 {
+		t.Errorf("0.1 - 0.2 = %s, want -0.10", diff)
+	}
+	yen, _ := ParseAmount("5", "JPY")
+	if sum := yen.Add(a); sum.String() != "5.10" {
+		t.Errorf("5 + 0.10 = %s, want 5.10", sum)
+	}
+}
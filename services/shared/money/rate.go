@@ -0,0 +1,170 @@
+package money
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// Rate is an exact exchange rate - how many units of one currency a unit of
+// another is worth. Like Money it is never a float: 0.92 is exactly 92/100, and
+// inverse and cross rates are exact fractions until an amount is rounded
+// The zero value is not a valid rate
+type Rate struct {
+	value *big.Rat
+}
+
+// rateScale is how many decimal places a stored rate keeps
+// Rate columns are NUMERIC(20, 10): 10 integer digits and 10 decimals
+const rateScale = 10
+
+// maxRateDigits is how many digits a rate may have before the decimal point
+const maxRateDigits = 10
+
+// ParseRate strictly parses an exchange rate ("0.92", "1.2e-5")
+// The rate must be positive and, rounded to 10 decimal places, fit the rate columns
+func ParseRate(s string) (Rate, error) {
+	// A plain decimal with an optional short exponent - no fractions ("1/3"),
+	// hex, NaN or exponents big enough to be expensive
+	mantissa, exponent, hasExponent := strings.Cut(strings.ToLower(s), "e")
+	whole, fraction, hasPoint := strings.Cut(mantissa, ".")
+	exponent = strings.TrimLeft(exponent, "+-")
+	if !isDigits(whole) || (hasPoint && !isDigits(fraction)) ||
+		(hasExponent && (!isDigits(exponent) || len(exponent) > 2)) {
+		return Rate{}, errors.New("rate must be a positive number")
+	}
+	value, ok := new(big.Rat).SetString(s)
+	if !ok || value.Sign() <= 0 {
+		return Rate{}, errors.New("rate must be a positive number")
+	}
+
+	rounded := new(big.Rat).SetFrac(roundRat(value, rateScale), bigPow10(rateScale))
+	if rounded.Sign() == 0 {
+		return Rate{}, fmt.Errorf("rate must be at least 0.%s1", strings.Repeat("0", rateScale-1))
+	}
+	if len(new(big.Int).Quo(rounded.Num(), rounded.Denom()).String()) > maxRateDigits {
+		return Rate{}, errors.New("rate must be less than 10000000000")
+	}
+
+	return Rate{value: rounded}, nil
+}
+
+// OneRate returns the rate of a currency into itself
+func OneRate() Rate {
+	return Rate{value: big.NewRat(1, 1)}
+}
+
+// rat returns the rate as a fraction (zero for the zero value)
+func (r Rate) rat() *big.Rat {
+	if r.value == nil {
+		return new(big.Rat)
+	}
+	return r.value
+}
+
+// Inverse returns the rate in the opposite direction (1 / r)
+func (r Rate) Inverse() Rate {
+	if r.rat().Sign() == 0 {
+		return Rate{}
+	}
+	return Rate{value: new(big.Rat).Inv(r.rat())}
+}
+
+// Mul chains two rates (EUR -> USD times USD -> GBP is EUR -> GBP)
+func (r Rate) Mul(other Rate) Rate {
+	return Rate{value: new(big.Rat).Mul(r.rat(), other.rat())}
+}
+
+// Equal reports whether two rates are exactly the same
+func (r Rate) Equal(other Rate) bool {
+	return r.rat().Cmp(other.rat()) == 0
+}
+
+// String formats the rate with up to 10 decimal places, without trailing zeros ("0.92")
+func (r Rate) String() string {
+	s := r.rat().FloatString(rateScale)
+	s = strings.TrimRight(s, "0")
+	return strings.TrimSuffix(s, ".")
+}
+
+// MarshalJSON writes the rate as a JSON number
+func (r Rate) MarshalJSON() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+// UnmarshalJSON reads a rate from a JSON number or string without going through a float
+func (r *Rate) UnmarshalJSON(data []byte) error {
+	var number json.Number
+	if err := json.Unmarshal(data, &number); err != nil {
+		return errors.New("rate must be a positive number")
+	}
+	parsed, err := ParseRate(number.String())
+	if err != nil {
+		return err
+	}
+	*r = parsed
+	return nil
+}
+
+// Scan reads a NUMERIC column (implements sql.Scanner)
+func (r *Rate) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case string:
+		parsed, err := ParseRate(v)
+		if err != nil {
+			return fmt.Errorf("invalid rate %q: %w", v, err)
+		}
+		*r = parsed
+		return nil
+	case []byte:
+		return r.Scan(string(v))
+	case nil:
+		return errors.New("cannot scan NULL into Rate")
+	default:
+		return fmt.Errorf("cannot scan %T into Rate", src)
+	}
+}
+
+// Value writes the rate to a NUMERIC column (implements driver.Valuer)
+func (r Rate) Value() (driver.Value, error) {
+	return r.rat().FloatString(rateScale), nil
+}
+
+// Convert multiplies the amount by an exchange rate, rounded to the given number
+// of decimal places (halves away from zero, like Round). The product is exact -
+// only the final rounding loses anything
+func (m Money) Convert(rate Rate, scale int) (Money, error) {
+	value := new(big.Rat).SetFrac(big.NewInt(m.units), bigPow10(m.scale))
+	value.Mul(value, rate.rat())
+
+	units := roundRat(value, scale)
+	if !units.IsInt64() || len(units.String()) > maxMoneyDigits {
+		return Money{}, fmt.Errorf("converted amount of %s is out of range", m)
+	}
+	return Money{units: units.Int64(), scale: scale}, nil
+}
+
+// roundRat returns value times 10^scale rounded to an integer, halves away from zero
+func roundRat(value *big.Rat, scale int) *big.Int {
+	scaled := new(big.Rat).Mul(value, new(big.Rat).SetInt(bigPow10(scale)))
+	quotient, remainder := new(big.Int).QuoRem(scaled.Num(), scaled.Denom(), new(big.Int))
+
+	twice := new(big.Int).Abs(remainder)
+	twice.Lsh(twice, 1)
+	if twice.Cmp(scaled.Denom()) >= 0 {
+		if scaled.Sign() < 0 {
+			quotient.Sub(quotient, big.NewInt(1))
+		} else {
+			quotient.Add(quotient, big.NewInt(1))
+		}
+	}
+	return quotient
+}
+
+// bigPow10 returns 10^n as a big integer
+func bigPow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
@@ -0,0 +1,118 @@
+package money
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseRate(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string // Empty: the rate is rejected
+	}{
+		{"decimal", "0.92", "0.92"},
+		{"whole", "150", "150"},
+		{"exponent", "1.2e-5", "0.000012"},
+		{"positive exponent", "1.5E+2", "150"},
+		{"rounded to 10 decimals", "0.123456789012", "0.123456789"},
+		{"smallest rate", "0.0000000001", "0.0000000001"},
+		{"rounds to zero", "0.00000000001", ""},
+		{"too large", "10000000000", ""},
+		{"zero", "0", ""},
+		{"negative", "-0.92", ""},
+		{"fraction", "1/3", ""},
+		{"hex", "0x1p-2", ""},
+		{"NaN", "NaN", ""},
+		{"huge exponent", "1e999999999", ""},
+		{"empty", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseRate(tt.input)
+			switch {
+			case tt.want == "" && err == nil:
+				t.Errorf("ParseRate(%q) = %s, want an error", tt.input, got)
+			case tt.want != "" && err != nil:
+				t.Errorf("ParseRate(%q) error = %v, want %s", tt.input, err, tt.want)
+			case tt.want != "" && got.String() != tt.want:
+				t.Errorf("ParseRate(%q) = %s, want %s", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRateJSON(t *testing.T) {
+	var body struct {
+		Number Rate `json:"number"`
+		String Rate `json:"string"`
+	}
+	if err := json.Unmarshal([]byte(`{"number": 0.92, "string": "1.08"}`), &body); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if body.Number.String() != "0.92" || body.String.String() != "1.08" {
+		t.Errorf("Unmarshal() = %s, %s, want 0.92, 1.08", body.Number, body.String)
+	}
+
+	if err := json.Unmarshal([]byte(`{"number": -1}`), &body); err == nil {
+		t.Error("Unmarshal() of a negative rate succeeded")
+	}
+
+	data, err := json.Marshal(body.Number)
+	if err != nil || string(data) != "0.92" {
+		t.Errorf("Marshal() = %s, %v, want 0.92", data, err)
+	}
+}
+
+func TestMoneyConvert(t *testing.T) {
+	rate := func(s string) Rate {
+		r, err := ParseRate(s)
+		if err != nil {
+			t.Fatalf("ParseRate(%q) error = %v", s, err)
+		}
+		return r
+	}
+
+	tests := []struct {
+		name   string
+		amount string
+		from   string
+		rate   Rate
+		to     string
+		want   string
+	}{
+		{"dollars to euros", "100.00", "USD", rate("0.92"), "EUR", "92.00"},
+		{"rounds half away from zero", "0.05", "USD", rate("0.5"), "EUR", "0.03"},
+		{"rounds down", "10.00", "USD", rate("0.3333"), "EUR", "3.33"},
+		{"into yen", "12.34", "USD", rate("150.5"), "JPY", "1857"},
+		{"from yen", "1000", "JPY", rate("0.0066"), "USD", "6.60"},
+		{"into three decimals", "10.00", "USD", rate("0.307"), "KWD", "3.070"},
+		// 1 / 3 is exact, so converting back and forth loses nothing until rounding
+		{"inverse rate", "3.00", "EUR", rate("3").Inverse(), "USD", "1.00"},
+		{"cross rate", "10.00", "GBP", rate("0.8").Inverse().Mul(rate("0.9")), "EUR", "11.25"},
+		// A float holds 1.005 as 1.00499999... and rounds it to 1.00
+		{"no binary rounding", "1.005", "KWD", rate("1"), "USD", "1.01"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			amount, err := ParseAmount(tt.amount, tt.from)
+			if err != nil {
+				t.Fatalf("ParseAmount(%q) error = %v", tt.amount, err)
+			}
+			got, err := amount.Convert(tt.rate, CurrencyScale(tt.to))
+			if err != nil {
+				t.Fatalf("Convert() error = %v", err)
+			}
+			if got.String() != tt.want {
+				t.Errorf("Convert(%s %s) = %s, want %s", tt.amount, tt.from, got, tt.want)
+			}
+		})
+	}
+
+	large, _ := ParseAmount("99999999.99", "USD")
+	if _, err := large.Convert(rate("9999999999"), 2); err == nil {
+		t.Error("Convert() out of range succeeded, want an error")
+	}
+}
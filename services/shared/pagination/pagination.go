@@ -0,0 +1,192 @@
+// Package pagination pages through sorted listings by keyset: a page starts
+// after (or ends before) the sort key values of a row, which clients get back as
+// an opaque cursor. Unlike page numbers, cursors don't skip or repeat rows when
+// rows are added while paging
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// KeyType is the Postgres type of a sort key
+type KeyType string
+
+// Sort key types
+const (
+	UUID      KeyType = "uuid"
+	Timestamp KeyType = "timestamp" // Without time zone
+	Date      KeyType = "date"
+	Numeric   KeyType = "numeric"
+	Real      KeyType = "real"
+	Text      KeyType = "text"
+)
+
+// SortKey is a column (or expression) listings are sorted by
+type SortKey struct {
+	Expr string  // SQL expression
+	Type KeyType // Postgres type of the expression (for cursor values)
+}
+
+// Types returns the types of sort keys (what DecodeCursor checks cursor values against)
+func Types(keys []SortKey) []KeyType {
+	types := make([]KeyType, len(keys))
+	for i, key := range keys {
+		types[i] = key.Type
+	}
+	return types
+}
+
+// OrderBy returns the ORDER BY list of sort keys
+func OrderBy(keys []SortKey, desc bool) string {
+	direction := " ASC"
+	if desc {
+		direction = " DESC"
+	}
+
+	exprs := make([]string, len(keys))
+	for i, key := range keys {
+		exprs[i] = key.Expr + direction
+	}
+	return strings.Join(exprs, ", ")
+}
+
+// After returns the WHERE condition for rows after a cursor's sort key values
+// in the given direction, e.g. (created_at, id) < ($3, $4), adding the values to args
+func After(keys []SortKey, values []string, desc bool, args []interface{}) (string, []interface{}) {
+	exprs := make([]string, len(keys))
+	params := make([]string, len(keys))
+	for i, key := range keys {
+		exprs[i] = key.Expr
+		params[i] = fmt.Sprintf("$%d::%s", len(args)+1, key.Type)
+		args = append(args, values[i])
+	}
+
+	op := ">"
+	if desc {
+		op = "<"
+	}
+	return fmt.Sprintf("(%s) %s (%s)", strings.Join(exprs, ", "), op, strings.Join(params, ", ")), args
+}
+
+// Cursor is the position of a page in a keyset-paginated listing
+// Clients get it as an opaque string (next_cursor/prev_cursor) and send it back
+// to fetch the neighbouring page
+type Cursor struct {
+	// Sort and Order the cursor was created for
+	Sort  string `json:"s"`
+	Order string `json:"o"`
+
+	// Values are the sort keys of the row the page starts after (or ends before)
+	// as Postgres text, the last one being the row's ID
+	Values []string `json:"v"`
+
+	// Before is set for prev_cursor: the page ends before the row
+	Before bool `json:"b,omitempty"`
+}
+
+// errInvalidCursor is returned for anything that isn't a cursor from Encode
+var errInvalidCursor = errors.New("cursor must be a next_cursor or prev_cursor value")
+
+// Encode returns the cursor as an opaque URL-safe string
+func (c *Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor decodes a cursor returned by Encode, checking that it has a
+// value of each type of the listing's sort keys
+// Cursors come from clients, so a value Postgres can't read as its type (which
+// would fail the query) makes the cursor invalid like any other tampering
+func DecodeCursor(s string, types []KeyType) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, errInvalidCursor
+	}
+
+	var cursor Cursor
+	if err := json.Unmarshal(data, &cursor); err != nil || len(cursor.Values) != len(types) {
+		return nil, errInvalidCursor
+	}
+
+	for i, value := range cursor.Values {
+		if !validValue(value, types[i]) {
+			return nil, errInvalidCursor
+		}
+	}
+
+	return &cursor, nil
+}
+
+var (
+	uuidPattern    = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	numericPattern = regexp.MustCompile(`^-?[0-9]{1,20}(\.[0-9]{1,20})?$`)
+	realPattern    = regexp.MustCompile(`^-?[0-9]+(\.[0-9]+)?(e[-+]?[0-9]+)?$`)
+)
+
+// validValue reports whether a cursor value is the text of a Postgres value of the type
+// (as the listing queries select it with ::text)
+func validValue(value string, keyType KeyType) bool {
+	switch keyType {
+	case UUID:
+		return uuidPattern.MatchString(value)
+	case Timestamp:
+		_, err := time.Parse("2006-01-02 15:04:05.999999", value)
+		return err == nil
+	case Date:
+		_, err := time.Parse("2006-01-02", value)
+		return err == nil
+	case Numeric:
+		return numericPattern.MatchString(value)
+	case Real:
+		if !realPattern.MatchString(value) {
+			return false
+		}
+		f, err := strconv.ParseFloat(value, 32)
+		return err == nil && !math.IsInf(f, 0)
+	case Text:
+		// Postgres text can't hold NUL bytes or invalid UTF-8
+		return utf8.ValidString(value) && !strings.ContainsRune(value, 0)
+	default:
+		return false
+	}
+}
+
+// PageCursors returns the cursors to the neighbouring pages of a page (nil if
+// there are none). cursor is the cursor the page was read from (nil for a page
+// read by offset), values the sort key values of the page's rows in page order,
+// and more whether there were more rows past the page in the reading direction
+// An empty page only leads back to the cursor
+func PageCursors(cursor *Cursor, sort, order string, values [][]string, more bool, offset int) (next, prev *Cursor) {
+	newCursor := func(values []string, before bool) *Cursor {
+		return &Cursor{Sort: sort, Order: order, Values: values, Before: before}
+	}
+	backward := cursor != nil && cursor.Before
+
+	if len(values) == 0 {
+		if cursor != nil {
+			if backward {
+				next = newCursor(cursor.Values, false)
+			} else {
+				prev = newCursor(cursor.Values, true)
+			}
+		}
+		return next, prev
+	}
+
+	if backward || more {
+		next = newCursor(values[len(values)-1], false)
+	}
+	if (backward && more) || (!backward && (cursor != nil || offset > 0)) {
+		prev = newCursor(values[0], true)
+	}
+	return next, prev
+}
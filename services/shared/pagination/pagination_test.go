@@ -0,0 +1,152 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"reflect"
+	"testing"
+)
+
+var dateTypes = []KeyType{Date, Timestamp, UUID}
+
+func TestCursorRoundTrip(t *testing.T) {
+	cursor := &Cursor{
+		Sort:   "date",
+		Order:  "desc",
+		Values: []string{"2024-01-15", "2024-01-15 10:30:00.123456", "0b0c5a1e-7d4f-4e8a-9c2b-3f6d8e1a2b3c"},
+		Before: true,
+	}
+
+	got, err := DecodeCursor(cursor.Encode(), dateTypes)
+	if err != nil {
+		t.Fatalf("DecodeCursor() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, cursor) {
+		t.Errorf("DecodeCursor() = %+v, want %+v", got, cursor)
+	}
+}
+
+func TestDecodeCursorRejectsTampering(t *testing.T) {
+	encode := func(values ...string) string {
+		return (&Cursor{Sort: "date", Order: "desc", Values: values}).Encode()
+	}
+	id := "0b0c5a1e-7d4f-4e8a-9c2b-3f6d8e1a2b3c"
+
+	tests := []struct {
+		name   string
+		cursor string
+		types  []KeyType
+	}{
+		{"not base64", "not a cursor!", dateTypes},
+		{"not JSON", base64.RawURLEncoding.EncodeToString([]byte("{")), dateTypes},
+		{"no values", encode(), dateTypes},
+		{"missing value", encode("2024-01-15", id), dateTypes},
+		{"extra value", encode("2024-01-15", "2024-01-15 10:30:00", id, id), dateTypes},
+		{"invalid id", encode("2024-01-15", "2024-01-15 10:30:00", "1; DROP TABLE expenses"), dateTypes},
+		{"id with braces", encode("2024-01-15", "2024-01-15 10:30:00", "{"+id+"}"), dateTypes},
+		{"invalid date", encode("2024-02-30", "2024-01-15 10:30:00", id), dateTypes},
+		{"invalid time", encode("2024-01-15", "2024-01-15 25:00:00", id), dateTypes},
+		{"time with zone", encode("2024-01-15", "2024-01-15T10:30:00Z", id), dateTypes},
+		{"invalid amount", encode("12,34", id), []KeyType{Numeric, UUID}},
+		{"amount with exponent", encode("1e3", id), []KeyType{Numeric, UUID}},
+		{"NaN rank", encode("NaN", id), []KeyType{Real, UUID}},
+		{"rank out of range", encode("1e39", id), []KeyType{Real, UUID}},
+		{"NUL in text", encode("a\x00b", id), []KeyType{Text, UUID}},
+		{"unknown type", encode("x", id), []KeyType{"bytea", UUID}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if cursor, err := DecodeCursor(tt.cursor, tt.types); err == nil {
+				t.Errorf("DecodeCursor() = %+v, want an error", cursor)
+			}
+		})
+	}
+}
+
+func TestDecodeCursorValues(t *testing.T) {
+	id := "0B0C5A1E-7D4F-4E8A-9C2B-3F6D8E1A2B3C"
+
+	tests := []struct {
+		name  string
+		value string
+		typ   KeyType
+	}{
+		{"whole second", "2024-01-15 10:30:00", Timestamp},
+		{"first date", "0001-01-01", Date},
+		{"amount", "12.34", Numeric},
+		{"missing amount", "-1", Numeric},
+		{"rank", "0.0607927", Real},
+		{"small rank", "6.07927e-05", Real},
+		{"text", "Café; ' OR 1=1", Text},
+		{"empty text", "", Text},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cursor := (&Cursor{Values: []string{tt.value, id}}).Encode()
+			if _, err := DecodeCursor(cursor, []KeyType{tt.typ, UUID}); err != nil {
+				t.Errorf("DecodeCursor(%q as %s) error = %v", tt.value, tt.typ, err)
+			}
+		})
+	}
+}
+
+func TestAfter(t *testing.T) {
+	keys := []SortKey{{Expr: "created_at", Type: Timestamp}, {Expr: "id", Type: UUID}}
+
+	condition, args := After(keys, []string{"2024-01-15 10:30:00", "id-1"}, true, []interface{}{"user-1"})
+	if want := "(created_at, id) < ($2::timestamp, $3::uuid)"; condition != want {
+		t.Errorf("After() = %q, want %q", condition, want)
+	}
+	if len(args) != 3 || args[1] != "2024-01-15 10:30:00" || args[2] != "id-1" {
+		t.Errorf("After() args = %v", args)
+	}
+
+	if got, want := OrderBy(keys, false), "created_at ASC, id ASC"; got != want {
+		t.Errorf("OrderBy() = %q, want %q", got, want)
+	}
+}
+
+func TestPageCursors(t *testing.T) {
+	values := [][]string{{"a"}, {"b"}}
+	after := &Cursor{Values: []string{"x"}}
+	before := &Cursor{Values: []string{"x"}, Before: true}
+
+	tests := []struct {
+		name     string
+		cursor   *Cursor
+		values   [][]string
+		more     bool
+		offset   int
+		wantNext []string
+		wantPrev []string
+	}{
+		{"only page", nil, values, false, 0, nil, nil},
+		{"first page", nil, values, true, 0, []string{"b"}, nil},
+		{"page by offset", nil, values, false, 20, nil, []string{"a"}},
+		{"middle page", after, values, true, 0, []string{"b"}, []string{"a"}},
+		{"last page", after, values, false, 0, nil, []string{"a"}},
+		{"page before", before, values, true, 0, []string{"b"}, []string{"a"}},
+		{"first page read backwards", before, values, false, 0, []string{"b"}, nil},
+		{"empty page after", after, nil, false, 0, nil, []string{"x"}},
+		{"empty page before", before, nil, false, 0, []string{"x"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next, prev := PageCursors(tt.cursor, "date", "desc", tt.values, tt.more, tt.offset)
+			check := func(label string, got *Cursor, want []string, before bool) {
+				switch {
+				case want == nil && got != nil:
+					t.Errorf("%s = %+v, want none", label, got)
+				case want != nil && got == nil:
+					t.Errorf("%s = none, want %v", label, want)
+				case want != nil && (!reflect.DeepEqual(got.Values, want) || got.Before != before || got.Sort != "date"):
+					t.Errorf("%s = %+v, want %v (before: %v)", label, got, want, before)
+				}
+			}
+			check("next", next, tt.wantNext, false)
+			check("prev", prev, tt.wantPrev, true)
+		})
+	}
+}